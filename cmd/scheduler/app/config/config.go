@@ -17,7 +17,10 @@ limitations under the License.
 package config
 
 import (
+	"time"
+
 	kubeschedulerconfig "github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/shard"
 	apiserver "k8s.io/apiserver/pkg/server"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
@@ -49,6 +52,25 @@ type Config struct {
 
 	// LeaderElection is optional.
 	LeaderElection *leaderelection.LeaderElectionConfig
+
+	// SchedulingWorkers is the number of pods scheduled in parallel.
+	SchedulingWorkers int32
+
+	// BindingDrainGracePeriod is how long the binding cycles in flight are
+	// given to finish when the scheduler stops.
+	BindingDrainGracePeriod time.Duration
+
+	// ExplanationMaxEntries is the number of pods the explanation of the last
+	// scheduling attempt is kept for.
+	ExplanationMaxEntries int
+
+	// ExplanationTTL is how long the explanation of a scheduling attempt is
+	// kept for.
+	ExplanationTTL time.Duration
+
+	// Shard is the membership of the replica in its shard group. It's nil
+	// unless sharding is enabled, in which case LeaderElection is nil.
+	Shard *shard.Membership
 }
 
 type completedConfig struct {
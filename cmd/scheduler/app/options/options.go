@@ -25,6 +25,8 @@ import (
 	schedulerappconfig "github.com/QuarfotPrice/sched.dev/cmd/scheduler/app/config"
 	kubeschedulerconfig "github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config/validation"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/explanation"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/shard"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
@@ -60,6 +62,7 @@ type Options struct {
 	Logs           *logs.Options
 	Deprecated     *DeprecatedOptions
 	LeaderElection *componentbaseconfig.LeaderElectionConfiguration
+	Sharding       *ShardingOptions
 
 	// ConfigFile is the location of the scheduler server's configuration file.
 	ConfigFile string
@@ -69,6 +72,21 @@ type Options struct {
 
 	Master string
 
+	// SchedulingWorkers is the number of pods scheduled in parallel.
+	SchedulingWorkers int32
+
+	// BindingDrainGracePeriod is how long the binding cycles in flight are
+	// given to finish when the scheduler stops.
+	BindingDrainGracePeriod time.Duration
+
+	// ExplanationMaxEntries is the number of pods the explanation of the last
+	// scheduling attempt is kept for.
+	ExplanationMaxEntries int
+
+	// ExplanationTTL is how long the explanation of a scheduling attempt is
+	// kept for.
+	ExplanationTTL time.Duration
+
 	// Flags hold the parsed CLI flags.
 	Flags *cliflag.NamedFlagSets
 }
@@ -89,8 +107,13 @@ func NewOptions() *Options {
 			ResourceName:      "kube-scheduler",
 			ResourceNamespace: "kube-system",
 		},
-		Metrics: metrics.NewOptions(),
-		Logs:    logs.NewOptions(),
+		Sharding:                NewShardingOptions(),
+		Metrics:                 metrics.NewOptions(),
+		Logs:                    logs.NewOptions(),
+		SchedulingWorkers:       1,
+		BindingDrainGracePeriod: 10 * time.Second,
+		ExplanationMaxEntries:   explanation.DefaultMaxEntries,
+		ExplanationTTL:          explanation.DefaultTTL,
 	}
 
 	o.Authentication.TolerateInClusterLookupFailure = true
@@ -184,12 +207,17 @@ func (o *Options) initFlags() {
 	fs.StringVar(&o.ConfigFile, "config", o.ConfigFile, "The path to the configuration file.")
 	fs.StringVar(&o.WriteConfigTo, "write-config-to", o.WriteConfigTo, "If set, write the configuration values to this file and exit.")
 	fs.StringVar(&o.Master, "master", o.Master, "The address of the Kubernetes API server (overrides any value in kubeconfig)")
+	fs.Int32Var(&o.SchedulingWorkers, "scheduling-workers", o.SchedulingWorkers, "The number of pods to schedule in parallel. Each worker finds a node for its pod on its own snapshot of the cluster, and the node is validated against the cluster before the pod is assumed. 1 schedules pods one at a time.")
+	fs.DurationVar(&o.BindingDrainGracePeriod, "binding-drain-grace-period", o.BindingDrainGracePeriod, "How long the pods being bound are given to finish binding when the scheduler stops or loses its leader lease. The pods that are still not bound are unreserved and forgotten. It should be shorter than the leader election lease duration.")
+	fs.IntVar(&o.ExplanationMaxEntries, "explanation-max-entries", o.ExplanationMaxEntries, "The number of pods the outcome of the last scheduling attempt is kept for, to be served under "+explanation.PathPrefix+". The least recently attempted pods are dropped first.")
+	fs.DurationVar(&o.ExplanationTTL, "explanation-ttl", o.ExplanationTTL, "How long the outcome of a scheduling attempt is kept for, to be served under "+explanation.PathPrefix+".")
 
 	o.SecureServing.AddFlags(nfs.FlagSet("secure serving"))
 	o.Authentication.AddFlags(nfs.FlagSet("authentication"))
 	o.Authorization.AddFlags(nfs.FlagSet("authorization"))
 	o.Deprecated.AddFlags(nfs.FlagSet("deprecated"))
 	options.BindLeaderElectionFlags(o.LeaderElection, nfs.FlagSet("leader election"))
+	o.Sharding.AddFlags(nfs.FlagSet("sharding"))
 	utilfeature.DefaultMutableFeatureGate.AddFlag(nfs.FlagSet("feature gate"))
 	o.Metrics.AddFlags(nfs.FlagSet("metrics"))
 	o.Logs.AddFlags(nfs.FlagSet("logs"))
@@ -230,6 +258,10 @@ func (o *Options) ApplyTo(c *schedulerappconfig.Config) error {
 			return err
 		}
 	}
+	c.SchedulingWorkers = o.SchedulingWorkers
+	c.BindingDrainGracePeriod = o.BindingDrainGracePeriod
+	c.ExplanationMaxEntries = o.ExplanationMaxEntries
+	c.ExplanationTTL = o.ExplanationTTL
 	o.Metrics.Apply()
 	return nil
 }
@@ -246,6 +278,19 @@ func (o *Options) Validate() []error {
 	errs = append(errs, o.Authorization.Validate()...)
 	errs = append(errs, o.Deprecated.Validate()...)
 	errs = append(errs, o.Metrics.Validate()...)
+	errs = append(errs, o.Sharding.Validate()...)
+	if o.BindingDrainGracePeriod < 0 {
+		errs = append(errs, fmt.Errorf("--binding-drain-grace-period must not be negative, got %v", o.BindingDrainGracePeriod))
+	}
+	if o.SchedulingWorkers < 0 {
+		errs = append(errs, fmt.Errorf("--scheduling-workers must not be negative, got %d", o.SchedulingWorkers))
+	}
+	if o.ExplanationMaxEntries < 0 {
+		errs = append(errs, fmt.Errorf("--explanation-max-entries must not be negative, got %d", o.ExplanationMaxEntries))
+	}
+	if o.ExplanationTTL < 0 {
+		errs = append(errs, fmt.Errorf("--explanation-ttl must not be negative, got %v", o.ExplanationTTL))
+	}
 
 	return errs
 }
@@ -277,9 +322,20 @@ func (o *Options) Config() (*schedulerappconfig.Config, error) {
 
 	c.EventBroadcaster = events.NewEventBroadcasterAdapter(eventClient)
 
+	// Set up sharding if enabled. The replicas of a shard group run
+	// active-active, so they don't elect a leader.
+	sharded := o.Sharding != nil && o.Sharding.Group != ""
+	if sharded {
+		id, err := replicaIdentity()
+		if err != nil {
+			return nil, err
+		}
+		c.Shard = shard.New(client, o.Sharding.Namespace, o.Sharding.Group, id, o.Sharding.LeaseDuration, o.Sharding.ConflictWindow)
+	}
+
 	// Set up leader election if enabled.
 	var leaderElectionConfig *leaderelection.LeaderElectionConfig
-	if c.ComponentConfig.LeaderElection.LeaderElect {
+	if c.ComponentConfig.LeaderElection.LeaderElect && !sharded {
 		// Use the scheduler name in the first profile to record leader election.
 		schedulerName := corev1.DefaultSchedulerName
 		if len(c.ComponentConfig.Profiles) != 0 {
@@ -302,15 +358,24 @@ func (o *Options) Config() (*schedulerappconfig.Config, error) {
 	return c, nil
 }
 
+// replicaIdentity returns the identity of the scheduler replica in leader
+// election and in its shard group.
+func replicaIdentity() (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("unable to get hostname: %v", err)
+	}
+	// add a uniquifier so that two processes on the same host don't accidentally both become active
+	return hostname + "_" + string(uuid.NewUUID()), nil
+}
+
 // makeLeaderElectionConfig builds a leader election configuration. It will
 // create a new resource lock associated with the configuration.
 func makeLeaderElectionConfig(config componentbaseconfig.LeaderElectionConfiguration, kubeConfig *restclient.Config, recorder record.EventRecorder) (*leaderelection.LeaderElectionConfig, error) {
-	hostname, err := os.Hostname()
+	id, err := replicaIdentity()
 	if err != nil {
-		return nil, fmt.Errorf("unable to get hostname: %v", err)
+		return nil, err
 	}
-	// add a uniquifier so that two processes on the same host don't accidentally both become active
-	id := hostname + "_" + string(uuid.NewUUID())
 
 	rl, err := resourcelock.NewFromKubeconfig(config.ResourceLock,
 		config.ResourceNamespace,
@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ShardingOptions configure the replicas of the scheduler to run
+// active-active, each scheduling a shard of the pending pods.
+type ShardingOptions struct {
+	// Group is the name of the shard group. Sharding is disabled if empty.
+	Group string
+	// Namespace is the namespace of the Leases of the replicas.
+	Namespace string
+	// LeaseDuration is how long a replica is considered live after renewing
+	// its Lease.
+	LeaseDuration time.Duration
+	// ConflictWindow is how long a replica waits for the other replicas to
+	// announce the pods they assumed before binding a pod.
+	ConflictWindow time.Duration
+}
+
+// NewShardingOptions returns the default sharding options.
+func NewShardingOptions() *ShardingOptions {
+	return &ShardingOptions{
+		Namespace:      "kube-system",
+		LeaseDuration:  15 * time.Second,
+		ConflictWindow: time.Second,
+	}
+}
+
+// AddFlags adds flags for the sharding options.
+func (o *ShardingOptions) AddFlags(fs *pflag.FlagSet) {
+	if o == nil {
+		return
+	}
+	fs.StringVar(&o.Group, "shard-group", o.Group, "If set, the replicas started with the same shard group schedule pods active-active instead of electing a leader. Pods are split between the live replicas by the hash of their pod group, or of their namespace if they belong to none.")
+	fs.StringVar(&o.Namespace, "shard-lease-namespace", o.Namespace, "The namespace of the Leases the replicas of the shard group renew.")
+	fs.DurationVar(&o.LeaseDuration, "shard-lease-duration", o.LeaseDuration, "How long a replica of the shard group keeps its pods after it last renewed its Lease.")
+	fs.DurationVar(&o.ConflictWindow, "shard-conflict-window", o.ConflictWindow, "How long a replica of the shard group waits for the others to announce the pods they assumed before binding a pod. It should exceed the delay for pod updates to reach the replicas.")
+}
+
+// Validate validates the sharding options.
+func (o *ShardingOptions) Validate() []error {
+	if o == nil || o.Group == "" {
+		return nil
+	}
+	var errs []error
+	for _, msg := range validation.IsDNS1123Label(o.Group) {
+		errs = append(errs, fmt.Errorf("invalid --shard-group %q: %s", o.Group, msg))
+	}
+	if o.LeaseDuration < time.Second {
+		errs = append(errs, fmt.Errorf("--shard-lease-duration must be at least 1s, got %v", o.LeaseDuration))
+	}
+	if o.ConflictWindow < 0 {
+		errs = append(errs, fmt.Errorf("--shard-conflict-window must not be negative, got %v", o.ConflictWindow))
+	}
+	return errs
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+
+	kubeschedulerconfig "github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config/latest"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config/validation"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	cliflag "k8s.io/component-base/cli/flag"
+	"k8s.io/component-base/logs"
+)
+
+const (
+	// SimulateOutputText prints a table of the results of the simulation.
+	SimulateOutputText = "text"
+	// SimulateOutputJSON prints the results of the simulation as JSON.
+	SimulateOutputJSON = "json"
+)
+
+// SimulateOptions has all the params needed to run a scheduling simulation.
+type SimulateOptions struct {
+	Logs *logs.Options
+
+	// ConfigFile is the location of the scheduler's configuration file. The
+	// default configuration is used if empty.
+	ConfigFile string
+
+	// ClusterFiles are the files holding the nodes, the pods and the other
+	// objects of the cluster.
+	ClusterFiles []string
+
+	// PodFiles are the files holding the pods to schedule.
+	PodFiles []string
+
+	// Output is the format of the results, either "text" or "json".
+	Output string
+
+	// Flags hold the parsed CLI flags.
+	Flags *cliflag.NamedFlagSets
+}
+
+// NewSimulateOptions returns default simulation options.
+func NewSimulateOptions() *SimulateOptions {
+	o := &SimulateOptions{
+		Logs:   logs.NewOptions(),
+		Output: SimulateOutputText,
+	}
+	o.initFlags()
+	return o
+}
+
+func (o *SimulateOptions) initFlags() {
+	if o.Flags != nil {
+		return
+	}
+
+	nfs := cliflag.NamedFlagSets{}
+	fs := nfs.FlagSet("simulation")
+	fs.StringVar(&o.ConfigFile, "config", o.ConfigFile, "The path to the configuration file. The default configuration is used if not set.")
+	fs.StringSliceVar(&o.ClusterFiles, "cluster", o.ClusterFiles, "Paths to YAML or JSON files holding the nodes, the pods and the other objects of the cluster. Pods not bound to a node are scheduled along with the pods of --pods.")
+	fs.StringSliceVar(&o.PodFiles, "pods", o.PodFiles, "Paths to YAML or JSON files holding the pods to schedule.")
+	fs.StringVarP(&o.Output, "output", "o", o.Output, "The format of the results, either \"text\" or \"json\".")
+
+	utilfeature.DefaultMutableFeatureGate.AddFlag(nfs.FlagSet("feature gate"))
+	o.Logs.AddFlags(nfs.FlagSet("logs"))
+
+	o.Flags = &nfs
+}
+
+// Validate validates all the required options.
+func (o *SimulateOptions) Validate() []error {
+	var errs []error
+	if len(o.ClusterFiles) == 0 {
+		errs = append(errs, fmt.Errorf("--cluster is required"))
+	}
+	if o.Output != SimulateOutputText && o.Output != SimulateOutputJSON {
+		errs = append(errs, fmt.Errorf("--output must be %q or %q, got %q", SimulateOutputText, SimulateOutputJSON, o.Output))
+	}
+	return errs
+}
+
+// ComponentConfig returns the configuration of the configuration file, or
+// the default configuration if no file is given.
+func (o *SimulateOptions) ComponentConfig() (*kubeschedulerconfig.KubeSchedulerConfiguration, error) {
+	if len(o.ConfigFile) == 0 {
+		return latest.Default()
+	}
+	cfg, err := loadConfigFromFile(o.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := validation.ValidateKubeSchedulerConfiguration(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"os"
 	goruntime "runtime"
+	"sync/atomic"
 
 	"github.com/spf13/cobra"
 
@@ -31,9 +32,11 @@ import (
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler"
 	kubeschedulerconfig "github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config/latest"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/explanation"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/runtime"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/metrics/resources"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/profile"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/queueinspect"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apiserver/pkg/authentication/authenticator"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
@@ -102,6 +105,8 @@ for more information about scheduling and the kube-scheduler component.`,
 
 	cmd.MarkFlagFilename("config", "yaml", "yml", "json")
 
+	cmd.AddCommand(newSimulateCommand(registryOptions...))
+
 	return cmd
 }
 
@@ -168,7 +173,7 @@ func Run(ctx context.Context, cc *schedulerserverconfig.CompletedConfig, sched *
 
 	// Start up the healthz server.
 	if cc.SecureServing != nil {
-		handler := buildHandlerChain(newHealthzAndMetricsHandler(&cc.ComponentConfig, cc.InformerFactory, isLeader, checks...), cc.Authentication.Authenticator, cc.Authorization.Authorizer)
+		handler := buildHandlerChain(newHealthzAndMetricsHandler(&cc.ComponentConfig, cc.InformerFactory, isLeader, sched.Explanations, sched.SchedulingQueue, checks...), cc.Authentication.Authenticator, cc.Authorization.Authorizer)
 		// TODO: handle stoppedCh returned by c.SecureServing.Serve
 		if _, err := cc.SecureServing.Serve(handler, 0, ctx.Done()); err != nil {
 			// fail early for secure handlers, removing the old error loop from above
@@ -192,12 +197,23 @@ func Run(ctx context.Context, cc *schedulerserverconfig.CompletedConfig, sched *
 
 	// If leader election is enabled, runCommand via LeaderElector until done and exit.
 	if cc.LeaderElection != nil {
+		// leading is set once the scheduler starts running, and drained is
+		// closed once it stopped popping pods and drained its binding cycles.
+		var leading int32
+		drained := make(chan struct{})
 		cc.LeaderElection.Callbacks = leaderelection.LeaderCallbacks{
 			OnStartedLeading: func(ctx context.Context) {
+				atomic.StoreInt32(&leading, 1)
 				close(waitingForLeader)
 				sched.Run(ctx)
+				close(drained)
 			},
 			OnStoppedLeading: func() {
+				// The context of OnStartedLeading is canceled by now, but the
+				// scheduler may still be draining.
+				if atomic.LoadInt32(&leading) == 1 {
+					<-drained
+				}
 				select {
 				case <-ctx.Done():
 					// We were asked to terminate. Exit 0.
@@ -220,8 +236,12 @@ func Run(ctx context.Context, cc *schedulerserverconfig.CompletedConfig, sched *
 		return fmt.Errorf("lost lease")
 	}
 
-	// Leader election is disabled, so runCommand inline until done.
+	// Leader election is disabled, so runCommand inline until done. The
+	// replicas of a shard group all run, each scheduling its own pods.
 	close(waitingForLeader)
+	if cc.Shard != nil {
+		go cc.Shard.Run(ctx)
+	}
 	sched.Run(ctx)
 	return fmt.Errorf("finished without leader elect")
 }
@@ -255,11 +275,18 @@ func installMetricHandler(pathRecorderMux *mux.PathRecorderMux, informers inform
 }
 
 // newHealthzAndMetricsHandler creates a healthz server from the config, and will also
-// embed the metrics handler.
-func newHealthzAndMetricsHandler(config *kubeschedulerconfig.KubeSchedulerConfiguration, informers informers.SharedInformerFactory, isLeader func() bool, checks ...healthz.HealthChecker) http.Handler {
+// embed the metrics handler, the scheduling explanations of pods and the pods
+// waiting in the scheduling queue.
+func newHealthzAndMetricsHandler(config *kubeschedulerconfig.KubeSchedulerConfiguration, informers informers.SharedInformerFactory, isLeader func() bool, explanations *explanation.Store, queue queueinspect.Lister, checks ...healthz.HealthChecker) http.Handler {
 	pathRecorderMux := mux.NewPathRecorderMux("kube-scheduler")
 	healthz.InstallHandler(pathRecorderMux, checks...)
 	installMetricHandler(pathRecorderMux, informers, isLeader)
+	if explanations != nil {
+		pathRecorderMux.HandlePrefix(explanation.PathPrefix, explanations)
+	}
+	if queue != nil {
+		pathRecorderMux.Handle(queueinspect.Path, queueinspect.NewHandler(queue))
+	}
 	if config.EnableProfiling {
 		routes.Profiling{}.Install(pathRecorderMux)
 		if config.EnableContentionProfiling {
@@ -328,6 +355,10 @@ func Setup(ctx context.Context, opts *options.Options, outOfTreeRegistryOptions
 		scheduler.WithPodInitialBackoffSeconds(cc.ComponentConfig.PodInitialBackoffSeconds),
 		scheduler.WithExtenders(cc.ComponentConfig.Extenders...),
 		scheduler.WithParallelism(cc.ComponentConfig.Parallelism),
+		scheduler.WithSchedulingWorkers(cc.SchedulingWorkers),
+		scheduler.WithBindingDrainGracePeriod(cc.BindingDrainGracePeriod),
+		scheduler.WithExplanations(cc.ExplanationMaxEntries, cc.ExplanationTTL),
+		scheduler.WithShardMembership(cc.Shard),
 		scheduler.WithBuildFrameworkCapturer(func(profile kubeschedulerconfig.KubeSchedulerProfile) {
 			// Profiles are processed during Framework instantiation to set default plugins and configurations. Capturing them for logging
 			completedProfiles = append(completedProfiles, profile)
@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/QuarfotPrice/sched.dev/cmd/scheduler/app/options"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/runtime"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/simulator"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	cliflag "k8s.io/component-base/cli/flag"
+	"k8s.io/component-base/term"
+)
+
+// newSimulateCommand creates the command scheduling pods against a cluster
+// snapshot read from files.
+func newSimulateCommand(registryOptions ...Option) *cobra.Command {
+	opts := options.NewSimulateOptions()
+
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Schedule pods against a cluster snapshot, without cluster access",
+		Long: `Simulate schedules pods against a snapshot of a cluster read from YAML or
+JSON files, and prints the node each pod is placed on or the reasons it is
+unschedulable. Nothing is read from or written to a cluster.
+
+Pods are scheduled one after the other in the order of the queue sort plugin,
+and each placed pod takes resources from its node for the pods after it. Pods
+run through the filter, score and reserve plugins of their profile only: they
+don't preempt other pods nor wait at permit, and extenders aren't called.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := opts.Logs.ValidateAndApply(); err != nil {
+				return err
+			}
+			if errs := opts.Validate(); len(errs) > 0 {
+				return utilerrors.NewAggregate(errs)
+			}
+			return runSimulate(cmd.Context(), cmd.OutOrStdout(), opts, registryOptions...)
+		},
+		Args: cobra.NoArgs,
+	}
+
+	fs := cmd.Flags()
+	for _, f := range opts.Flags.FlagSets {
+		fs.AddFlagSet(f)
+	}
+
+	cols, _, _ := term.TerminalSize(cmd.OutOrStdout())
+	cliflag.SetUsageAndHelpFunc(cmd, *opts.Flags, cols)
+
+	cmd.MarkFlagFilename("config", "yaml", "yml", "json")
+	cmd.MarkFlagFilename("cluster", "yaml", "yml", "json")
+	cmd.MarkFlagFilename("pods", "yaml", "yml", "json")
+
+	return cmd
+}
+
+// runSimulate schedules the pods of the files and writes the results to out.
+func runSimulate(ctx context.Context, out io.Writer, opts *options.SimulateOptions, registryOptions ...Option) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cfg, err := opts.ComponentConfig()
+	if err != nil {
+		return err
+	}
+	outOfTreeRegistry := make(runtime.Registry)
+	for _, option := range registryOptions {
+		if err := option(outOfTreeRegistry); err != nil {
+			return err
+		}
+	}
+
+	var objs []k8sruntime.Object
+	for _, file := range append(append([]string(nil), opts.ClusterFiles...), opts.PodFiles...) {
+		fileObjs, err := decodeFile(file)
+		if err != nil {
+			return err
+		}
+		objs = append(objs, fileObjs...)
+	}
+
+	sim, err := simulator.New(ctx, cfg, objs, outOfTreeRegistry)
+	if err != nil {
+		return err
+	}
+	results := sim.Schedule(ctx)
+
+	if opts.Output == options.SimulateOutputJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "POD\tPROFILE\tNODE\tREASON")
+	placed := 0
+	for _, r := range results {
+		node := r.Node
+		if node == "" {
+			node = "<none>"
+		} else {
+			placed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Pod, r.Profile, node, r.Reason)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "\n%d of %d pods placed\n", placed, len(results))
+	return err
+}
+
+func decodeFile(file string) ([]k8sruntime.Object, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	objs, err := simulator.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", file, err)
+	}
+	return objs, nil
+}
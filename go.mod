@@ -57,6 +57,7 @@ replace k8s.io/sample-cli-plugin => k8s.io/sample-cli-plugin v0.23.4
 replace k8s.io/sample-controller => k8s.io/sample-controller v0.23.4
 
 require (
+	github.com/evanphx/json-patch v4.12.0+incompatible
 	github.com/google/go-cmp v0.5.5
 	github.com/google/uuid v1.1.2
 	github.com/spf13/cobra v1.2.1
@@ -92,7 +93,6 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/docker/distribution v2.7.1+incompatible // indirect
 	github.com/emicklei/go-restful v2.9.5+incompatible // indirect
-	github.com/evanphx/json-patch v4.12.0+incompatible // indirect
 	github.com/felixge/httpsnoop v1.0.1 // indirect
 	github.com/fsnotify/fsnotify v1.4.9 // indirect
 	github.com/go-logr/logr v1.2.0 // indirect
@@ -38,10 +38,16 @@ var (
 func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&KubeSchedulerConfiguration{},
+		&CoschedulingArgs{},
 		&DefaultPreemptionArgs{},
+		&DominantResourceFairnessArgs{},
+		&ElasticQuotaArgs{},
+		&GPUTopologyArgs{},
 		&InterPodAffinityArgs{},
 		&NodeResourcesFitArgs{},
+		&PodBackoffArgs{},
 		&PodTopologySpreadArgs{},
+		&TopologyPackingArgs{},
 		&VolumeBindingArgs{},
 		&NodeResourcesBalancedAllocationArgs{},
 		&NodeAffinityArgs{},
@@ -24,6 +24,7 @@ import (
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config/testing/defaults"
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/kube-scheduler/config/v1beta2"
@@ -394,6 +395,19 @@ profiles:
     args:
       minCandidateNodesPercentage: 50
       minCandidateNodesAbsolute: 500
+      evictVictims: true
+      candidateRanking: ["PDBViolations", "HighestPriority", "LostWork"]
+      lostWorkResources:
+      - name: nvidia.com/gpu
+        weight: 10
+      podGroupAware: true
+      preemptWholeGroups: true
+      protectedNamespaces: ["kube-system"]
+      protectedPodSelector:
+        matchLabels:
+          tier: critical
+      maxEvictionsPerNamespace: 5
+      minRuntimeSeconds: 300
   - name: InterPodAffinity
     args:
       hardPodAffinityWeight: 5
@@ -439,7 +453,20 @@ profiles:
 					PluginConfig: []config.PluginConfig{
 						{
 							Name: "DefaultPreemption",
-							Args: &config.DefaultPreemptionArgs{MinCandidateNodesPercentage: 50, MinCandidateNodesAbsolute: 500},
+							Args: &config.DefaultPreemptionArgs{
+								MinCandidateNodesPercentage: 50,
+								MinCandidateNodesAbsolute:   500,
+								EvictVictims:                true,
+								CandidateRanking:            []config.CandidateRankingCriterion{config.RankByPDBViolations, config.RankByHighestPriority, config.RankByLostWork},
+								LostWorkResources:           []config.ResourceSpec{{Name: "nvidia.com/gpu", Weight: 10}},
+								PodGroupAware:               true,
+								PreemptWholeGroups:          true,
+								ProtectedNamespaces:         []string{"kube-system"},
+								ProtectedPodSelector:        &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "critical"}},
+								MaxEvictionsPerNamespace:    5,
+								EvictionWindowSeconds:       3600,
+								MinRuntimeSeconds:           300,
+							},
 						},
 						{
 							Name: "InterPodAffinity",
@@ -23,6 +23,18 @@ import (
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+// CoschedulingArgs holds arguments used to configure the Coscheduling plugin.
+type CoschedulingArgs struct {
+	metav1.TypeMeta
+
+	// PermitWaitingTimeSeconds is the maximum time in seconds a member of a
+	// pod group waits at the permit extension point for the rest of its group
+	// before the whole group is rejected. Must be greater than 0.
+	PermitWaitingTimeSeconds int64
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 // DefaultPreemptionArgs holds arguments used to configure the
 // DefaultPreemption plugin.
 type DefaultPreemptionArgs struct {
@@ -41,6 +53,138 @@ type DefaultPreemptionArgs struct {
 	// that play a role in the number of candidates shortlisted. Must be at least
 	// 0 nodes. Defaults to 100 nodes if unspecified.
 	MinCandidateNodesAbsolute int32
+	// EvictVictims, if true, removes the victims through the Eviction API
+	// instead of deleting them, so that their PodDisruptionBudgets are
+	// enforced. The evictions of the victims on a node are dry-run before
+	// any of them is evicted.
+	EvictVictims bool
+	// CandidateRanking lists, in order, the criteria the candidate nodes for
+	// preemption are compared by. Each criterion keeps the nodes that do best
+	// on it and the next one breaks the ties. Empty means the upstream order:
+	// PDBViolations, HighestPriority, SumOfPriorities, NumVictims and
+	// LatestStartTime.
+	CandidateRanking []CandidateRankingCriterion
+	// LostWorkResources are the resources, and their weights, the LostWork
+	// criterion counts the work of the victims in. Empty means cpu, memory and
+	// nvidia.com/gpu with a weight of 1.
+	LostWorkResources []ResourceSpec
+	// PodGroupAware makes preemption account for the pod groups of the
+	// victims and of the preemptor. Preempting a member of a group costs the
+	// whole group, and a preemptor that is a member of a group also preempts
+	// for its pending replicas in the same cycle.
+	PodGroupAware bool
+	// PreemptWholeGroups, if true, also preempts the other members of the
+	// groups of the victims, on whatever node they run.
+	PreemptWholeGroups bool
+	// ProtectedNamespaces are the namespaces whose pods are never preempted.
+	ProtectedNamespaces []string
+	// ProtectedPodSelector selects the pods that are never preempted.
+	ProtectedPodSelector *metav1.LabelSelector
+	// MaxEvictionsPerNamespace, if greater than 0, is the maximum number of
+	// pods of a namespace preempted within EvictionWindowSeconds. The
+	// evictions are counted in memory by each scheduler replica, are lost on
+	// restart and don't include the preemptions of ElasticQuota, which has a
+	// budget of its own.
+	MaxEvictionsPerNamespace int32
+	// EvictionWindowSeconds is the sliding window MaxEvictionsPerNamespace
+	// applies to.
+	EvictionWindowSeconds int64
+	// MinRuntimeSeconds is how long a pod must have run before it may be
+	// preempted.
+	MinRuntimeSeconds int64
+}
+
+// CandidateRankingCriterion is a criterion the candidate nodes for preemption
+// are ranked by. The node with the lowest cost on it wins.
+type CandidateRankingCriterion string
+
+const (
+	// RankByPDBViolations counts the victims whose PodDisruptionBudget would be
+	// violated.
+	RankByPDBViolations CandidateRankingCriterion = "PDBViolations"
+	// RankByHighestPriority is the priority of the highest priority victim.
+	RankByHighestPriority CandidateRankingCriterion = "HighestPriority"
+	// RankBySumOfPriorities is the sum of the priorities of the victims.
+	RankBySumOfPriorities CandidateRankingCriterion = "SumOfPriorities"
+	// RankByNumVictims is the number of victims.
+	RankByNumVictims CandidateRankingCriterion = "NumVictims"
+	// RankByLatestStartTime prefers the node whose highest priority victims
+	// started the latest.
+	RankByLatestStartTime CandidateRankingCriterion = "LatestStartTime"
+	// RankByLostWork is the work the victims lose: the weighted resources of
+	// each victim times the time it ran since it started or last checkpointed.
+	RankByLostWork CandidateRankingCriterion = "LostWork"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DominantResourceFairnessArgs holds arguments used to configure the
+// DominantResourceFairness plugin.
+type DominantResourceFairnessArgs struct {
+	metav1.TypeMeta
+
+	// Resources are the resources the dominant share of a namespace is
+	// computed over.
+	Resources []string
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ElasticQuotaArgs holds arguments used to configure the ElasticQuota plugin.
+type ElasticQuotaArgs struct {
+	metav1.TypeMeta
+
+	// MinCandidateNodesPercentage is the minimum number of candidates to
+	// shortlist when dry running preemption as a percentage of number of
+	// nodes, as for DefaultPreemption. Must be in the range [0, 100].
+	MinCandidateNodesPercentage int32
+	// MinCandidateNodesAbsolute is the absolute minimum number of candidates
+	// to shortlist, as for DefaultPreemption. Must be at least 0 nodes.
+	MinCandidateNodesAbsolute int32
+	// ProtectedNamespaces are the namespaces whose pods are never preempted,
+	// as for DefaultPreemption.
+	ProtectedNamespaces []string
+	// ProtectedPodSelector selects the pods that are never preempted, as for
+	// DefaultPreemption.
+	ProtectedPodSelector *metav1.LabelSelector
+	// MaxEvictionsPerNamespace, if greater than 0, is the maximum number of
+	// pods of a namespace preempted by ElasticQuota within
+	// EvictionWindowSeconds.
+	MaxEvictionsPerNamespace int32
+	// EvictionWindowSeconds is the sliding window MaxEvictionsPerNamespace
+	// applies to.
+	EvictionWindowSeconds int64
+	// MinRuntimeSeconds is how long a pod must have run before it may be
+	// preempted.
+	MinRuntimeSeconds int64
+}
+
+// GPULinkType is the kind of interconnect between two GPUs of a node.
+type GPULinkType string
+
+const (
+	// GPULinkNVLink means that the GPUs are directly connected with NVLink.
+	GPULinkNVLink GPULinkType = "NVLink"
+	// GPULinkPCIeSwitch means that the GPUs are behind the same PCIe switch.
+	GPULinkPCIeSwitch GPULinkType = "PCIeSwitch"
+	// GPULinkNUMANode means that the GPUs are attached to the same NUMA node.
+	GPULinkNUMANode GPULinkType = "NUMANode"
+	// GPULinkSystem means that traffic between the GPUs crosses NUMA nodes.
+	GPULinkSystem GPULinkType = "System"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GPUTopologyArgs holds arguments used to configure the GPUTopology plugin.
+type GPUTopologyArgs struct {
+	metav1.TypeMeta
+
+	// ResourceName is the extended resource GPUs are requested with.
+	ResourceName string
+	// MinimumLinkType is the weakest interconnect allowed between any two
+	// GPUs allocated to the same pod. Nodes that can't provide such a set of
+	// GPUs are filtered out.
+	MinimumLinkType GPULinkType
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -73,6 +217,64 @@ type NodeResourcesFitArgs struct {
 	ScoringStrategy *ScoringStrategy
 }
 
+// PodBackoffName is the name the PodBackoffArgs of a profile are set under in
+// its plugin config. The args configure the scheduling queue rather than a
+// plugin.
+const PodBackoffName = "PodBackoff"
+
+// BackoffPolicyType defines how the backoff of a pod grows with its attempts.
+type BackoffPolicyType string
+
+const (
+	// ExponentialBackoff doubles the backoff with every attempt.
+	ExponentialBackoff BackoffPolicyType = "Exponential"
+	// LinearBackoff grows the backoff by the initial backoff with every attempt.
+	LinearBackoff BackoffPolicyType = "Linear"
+	// FixedBackoff keeps the backoff at the initial backoff.
+	FixedBackoff BackoffPolicyType = "Fixed"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodBackoffArgs holds arguments used to configure the backoff of the
+// unschedulable pods of a profile, and when the scheduler gives up on them.
+type PodBackoffArgs struct {
+	metav1.TypeMeta
+
+	// Policies are matched in order against a pod that failed to schedule and
+	// the first matching one sets its backoff. Pods matching none back off
+	// exponentially between PodInitialBackoffSeconds and PodMaxBackoffSeconds.
+	Policies []BackoffPolicy
+	// MaxAttempts, if greater than 0, is the number of failed attempts after
+	// which the scheduler stops retrying a pod.
+	MaxAttempts int32
+	// SchedulingDeadlineSeconds, if greater than 0, is the time since the
+	// first attempt after which the scheduler stops retrying a pod.
+	SchedulingDeadlineSeconds int64
+}
+
+// BackoffPolicy sets the backoff of the pods it matches.
+type BackoffPolicy struct {
+	// Type defines how the backoff grows with the attempts of a pod.
+	Type BackoffPolicyType
+	// InitialBackoffSeconds is the backoff after the first attempt.
+	InitialBackoffSeconds int64
+	// MaxBackoffSeconds caps the backoff of Exponential and Linear policies.
+	MaxBackoffSeconds int64
+	// JitterPercent is the largest share of the backoff, in percent, added to
+	// it at random to spread the retries of pods failing together. The
+	// backoff of Exponential and Linear policies still doesn't go over
+	// MaxBackoffSeconds.
+	JitterPercent int32
+	// MinPriority and MaxPriority, if set, bound the priorities of the pods
+	// the policy matches.
+	MinPriority *int32
+	MaxPriority *int32
+	// UnschedulablePlugins, if not empty, restricts the policy to the pods
+	// rejected by any of these plugins in their last attempt.
+	UnschedulablePlugins []string
+}
+
 // PodTopologySpreadConstraintsDefaulting defines how to set default constraints
 // for the PodTopologySpread plugin.
 type PodTopologySpreadConstraintsDefaulting string
@@ -113,6 +315,21 @@ type PodTopologySpreadArgs struct {
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+// TopologyPackingArgs holds arguments used to configure the TopologyPacking plugin.
+type TopologyPackingArgs struct {
+	metav1.TypeMeta
+
+	// TopologyKeys are the node labels describing the network hierarchy,
+	// from the broadest domain to the narrowest, e.g. spine block, rack and
+	// host.
+	TopologyKeys []string
+	// RequiredTopologyKey, if not empty, is one of TopologyKeys whose domain
+	// all the members of a pod group must share.
+	RequiredTopologyKey string
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 // NodeResourcesBalancedAllocationArgs holds arguments used to configure NodeResourcesBalancedAllocation plugin.
 type NodeResourcesBalancedAllocationArgs struct {
 	metav1.TypeMeta
@@ -105,3 +105,9 @@ func convertToExternalPluginConfigArgs(out *v1beta2.KubeSchedulerConfiguration)
 	}
 	return nil
 }
+
+// Convert_config_DefaultPreemptionArgs_To_v1beta2_DefaultPreemptionArgs drops
+// the arguments v1beta2 doesn't have, which are only configurable in v1beta3.
+func Convert_config_DefaultPreemptionArgs_To_v1beta2_DefaultPreemptionArgs(in *config.DefaultPreemptionArgs, out *v1beta2.DefaultPreemptionArgs, s conversion.Scope) error {
+	return autoConvert_config_DefaultPreemptionArgs_To_v1beta2_DefaultPreemptionArgs(in, out, s)
+}
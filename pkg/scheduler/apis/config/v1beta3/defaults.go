@@ -175,13 +175,88 @@ func SetDefaults_KubeSchedulerConfiguration(obj *v1beta3.KubeSchedulerConfigurat
 	}
 }
 
-func SetDefaults_DefaultPreemptionArgs(obj *v1beta3.DefaultPreemptionArgs) {
+func SetDefaults_CoschedulingArgs(obj *CoschedulingArgs) {
+	if obj.PermitWaitingTimeSeconds == nil {
+		obj.PermitWaitingTimeSeconds = pointer.Int64Ptr(60)
+	}
+}
+
+func SetDefaults_DefaultPreemptionArgs(obj *DefaultPreemptionArgs) {
+	if obj.MinCandidateNodesPercentage == nil {
+		obj.MinCandidateNodesPercentage = pointer.Int32Ptr(10)
+	}
+	if obj.MinCandidateNodesAbsolute == nil {
+		obj.MinCandidateNodesAbsolute = pointer.Int32Ptr(100)
+	}
+	if obj.EvictVictims == nil {
+		obj.EvictVictims = pointer.BoolPtr(false)
+	}
+	if obj.PodGroupAware == nil {
+		obj.PodGroupAware = pointer.BoolPtr(false)
+	}
+	if obj.PreemptWholeGroups == nil {
+		obj.PreemptWholeGroups = pointer.BoolPtr(false)
+	}
+	if obj.MaxEvictionsPerNamespace == nil {
+		obj.MaxEvictionsPerNamespace = pointer.Int32Ptr(0)
+	}
+	if *obj.MaxEvictionsPerNamespace > 0 && obj.EvictionWindowSeconds == nil {
+		obj.EvictionWindowSeconds = pointer.Int64Ptr(3600)
+	}
+	if obj.MinRuntimeSeconds == nil {
+		obj.MinRuntimeSeconds = pointer.Int64Ptr(0)
+	}
+}
+
+func SetDefaults_DominantResourceFairnessArgs(obj *DominantResourceFairnessArgs) {
+	if len(obj.Resources) == 0 {
+		obj.Resources = []string{string(v1.ResourceCPU), string(v1.ResourceMemory), "nvidia.com/gpu"}
+	}
+}
+
+func SetDefaults_ElasticQuotaArgs(obj *ElasticQuotaArgs) {
 	if obj.MinCandidateNodesPercentage == nil {
 		obj.MinCandidateNodesPercentage = pointer.Int32Ptr(10)
 	}
 	if obj.MinCandidateNodesAbsolute == nil {
 		obj.MinCandidateNodesAbsolute = pointer.Int32Ptr(100)
 	}
+	if obj.MaxEvictionsPerNamespace == nil {
+		obj.MaxEvictionsPerNamespace = pointer.Int32Ptr(0)
+	}
+	if *obj.MaxEvictionsPerNamespace > 0 && obj.EvictionWindowSeconds == nil {
+		obj.EvictionWindowSeconds = pointer.Int64Ptr(3600)
+	}
+	if obj.MinRuntimeSeconds == nil {
+		obj.MinRuntimeSeconds = pointer.Int64Ptr(0)
+	}
+}
+
+func SetDefaults_GPUTopologyArgs(obj *GPUTopologyArgs) {
+	if len(obj.ResourceName) == 0 {
+		obj.ResourceName = "nvidia.com/gpu"
+	}
+	if len(obj.MinimumLinkType) == 0 {
+		obj.MinimumLinkType = GPULinkNUMANode
+	}
+}
+
+func SetDefaults_BackoffPolicy(obj *BackoffPolicy) {
+	if len(obj.Type) == 0 {
+		obj.Type = ExponentialBackoff
+	}
+	if obj.InitialBackoffSeconds == nil {
+		obj.InitialBackoffSeconds = pointer.Int64Ptr(1)
+	}
+	if obj.MaxBackoffSeconds == nil {
+		obj.MaxBackoffSeconds = pointer.Int64Ptr(10)
+	}
+}
+
+func SetDefaults_TopologyPackingArgs(obj *TopologyPackingArgs) {
+	if len(obj.TopologyKeys) == 0 {
+		obj.TopologyKeys = []string{"topology.sched.dev/block", "topology.sched.dev/rack", v1.LabelHostname}
+	}
 }
 
 func SetDefaults_InterPodAffinityArgs(obj *v1beta3.InterPodAffinityArgs) {
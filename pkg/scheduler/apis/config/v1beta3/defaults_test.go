@@ -40,13 +40,18 @@ var pluginConfigs = []v1beta3.PluginConfig{
 	{
 		Name: "DefaultPreemption",
 		Args: runtime.RawExtension{
-			Object: &v1beta3.DefaultPreemptionArgs{
+			Object: &DefaultPreemptionArgs{
 				TypeMeta: metav1.TypeMeta{
 					Kind:       "DefaultPreemptionArgs",
 					APIVersion: "kubescheduler.config.k8s.io/v1beta3",
 				},
 				MinCandidateNodesPercentage: pointer.Int32Ptr(10),
 				MinCandidateNodesAbsolute:   pointer.Int32Ptr(100),
+				EvictVictims:                pointer.BoolPtr(false),
+				PodGroupAware:               pointer.BoolPtr(false),
+				PreemptWholeGroups:          pointer.BoolPtr(false),
+				MaxEvictionsPerNamespace:    pointer.Int32Ptr(0),
+				MinRuntimeSeconds:           pointer.Int64Ptr(0),
 			}},
 	},
 	{
@@ -248,13 +253,18 @@ func TestSchedulerDefaults(t *testing.T) {
 							{
 								Name: "DefaultPreemption",
 								Args: runtime.RawExtension{
-									Object: &v1beta3.DefaultPreemptionArgs{
+									Object: &DefaultPreemptionArgs{
 										TypeMeta: metav1.TypeMeta{
 											Kind:       "DefaultPreemptionArgs",
 											APIVersion: "kubescheduler.config.k8s.io/v1beta3",
 										},
 										MinCandidateNodesPercentage: pointer.Int32Ptr(10),
 										MinCandidateNodesAbsolute:   pointer.Int32Ptr(100),
+										EvictVictims:                pointer.BoolPtr(false),
+										PodGroupAware:               pointer.BoolPtr(false),
+										PreemptWholeGroups:          pointer.BoolPtr(false),
+										MaxEvictionsPerNamespace:    pointer.Int32Ptr(0),
+										MinRuntimeSeconds:           pointer.Int64Ptr(0),
 									}},
 							},
 							{
@@ -455,22 +465,162 @@ func TestPluginArgsDefaults(t *testing.T) {
 		in       runtime.Object
 		want     runtime.Object
 	}{
+		{
+			name: "CoschedulingArgs empty",
+			in:   &CoschedulingArgs{},
+			want: &CoschedulingArgs{
+				PermitWaitingTimeSeconds: pointer.Int64Ptr(60),
+			},
+		},
+		{
+			name: "CoschedulingArgs with value",
+			in: &CoschedulingArgs{
+				PermitWaitingTimeSeconds: pointer.Int64Ptr(300),
+			},
+			want: &CoschedulingArgs{
+				PermitWaitingTimeSeconds: pointer.Int64Ptr(300),
+			},
+		},
+		{
+			name: "ElasticQuotaArgs empty",
+			in:   &ElasticQuotaArgs{},
+			want: &ElasticQuotaArgs{
+				MinCandidateNodesPercentage: pointer.Int32Ptr(10),
+				MinCandidateNodesAbsolute:   pointer.Int32Ptr(100),
+				MaxEvictionsPerNamespace:    pointer.Int32Ptr(0),
+				MinRuntimeSeconds:           pointer.Int64Ptr(0),
+			},
+		},
+		{
+			name: "ElasticQuotaArgs with eviction budget",
+			in: &ElasticQuotaArgs{
+				MaxEvictionsPerNamespace: pointer.Int32Ptr(5),
+			},
+			want: &ElasticQuotaArgs{
+				MinCandidateNodesPercentage: pointer.Int32Ptr(10),
+				MinCandidateNodesAbsolute:   pointer.Int32Ptr(100),
+				MaxEvictionsPerNamespace:    pointer.Int32Ptr(5),
+				EvictionWindowSeconds:       pointer.Int64Ptr(3600),
+				MinRuntimeSeconds:           pointer.Int64Ptr(0),
+			},
+		},
+		{
+			name: "GPUTopologyArgs empty",
+			in:   &GPUTopologyArgs{},
+			want: &GPUTopologyArgs{
+				ResourceName:    "nvidia.com/gpu",
+				MinimumLinkType: GPULinkNUMANode,
+			},
+		},
+		{
+			name: "GPUTopologyArgs with value",
+			in: &GPUTopologyArgs{
+				ResourceName:    "amd.com/gpu",
+				MinimumLinkType: GPULinkNVLink,
+			},
+			want: &GPUTopologyArgs{
+				ResourceName:    "amd.com/gpu",
+				MinimumLinkType: GPULinkNVLink,
+			},
+		},
+		{
+			name: "DominantResourceFairnessArgs empty",
+			in:   &DominantResourceFairnessArgs{},
+			want: &DominantResourceFairnessArgs{
+				Resources: []string{"cpu", "memory", "nvidia.com/gpu"},
+			},
+		},
+		{
+			name: "DominantResourceFairnessArgs with value",
+			in: &DominantResourceFairnessArgs{
+				Resources: []string{"cpu", "amd.com/gpu"},
+			},
+			want: &DominantResourceFairnessArgs{
+				Resources: []string{"cpu", "amd.com/gpu"},
+			},
+		},
+		{
+			name: "TopologyPackingArgs empty",
+			in:   &TopologyPackingArgs{},
+			want: &TopologyPackingArgs{
+				TopologyKeys: []string{"topology.sched.dev/block", "topology.sched.dev/rack", "kubernetes.io/hostname"},
+			},
+		},
+		{
+			name: "TopologyPackingArgs with value",
+			in: &TopologyPackingArgs{
+				TopologyKeys:        []string{"spine", "kubernetes.io/hostname"},
+				RequiredTopologyKey: "spine",
+			},
+			want: &TopologyPackingArgs{
+				TopologyKeys:        []string{"spine", "kubernetes.io/hostname"},
+				RequiredTopologyKey: "spine",
+			},
+		},
+		{
+			name: "PodBackoffArgs empty",
+			in:   &PodBackoffArgs{},
+			want: &PodBackoffArgs{},
+		},
+		{
+			name: "PodBackoffArgs with partial policies",
+			in: &PodBackoffArgs{
+				Policies: []BackoffPolicy{
+					{UnschedulablePlugins: []string{"VolumeBinding"}},
+					{
+						Type:                  FixedBackoff,
+						InitialBackoffSeconds: pointer.Int64Ptr(30),
+						JitterPercent:         20,
+						UnschedulablePlugins:  []string{"Coscheduling", "ElasticQuota"},
+					},
+				},
+			},
+			want: &PodBackoffArgs{
+				Policies: []BackoffPolicy{
+					{
+						Type:                  ExponentialBackoff,
+						InitialBackoffSeconds: pointer.Int64Ptr(1),
+						MaxBackoffSeconds:     pointer.Int64Ptr(10),
+						UnschedulablePlugins:  []string{"VolumeBinding"},
+					},
+					{
+						Type:                  FixedBackoff,
+						InitialBackoffSeconds: pointer.Int64Ptr(30),
+						MaxBackoffSeconds:     pointer.Int64Ptr(10),
+						JitterPercent:         20,
+						UnschedulablePlugins:  []string{"Coscheduling", "ElasticQuota"},
+					},
+				},
+			},
+		},
 		{
 			name: "DefaultPreemptionArgs empty",
-			in:   &v1beta3.DefaultPreemptionArgs{},
-			want: &v1beta3.DefaultPreemptionArgs{
+			in:   &DefaultPreemptionArgs{},
+			want: &DefaultPreemptionArgs{
 				MinCandidateNodesPercentage: pointer.Int32Ptr(10),
 				MinCandidateNodesAbsolute:   pointer.Int32Ptr(100),
+				EvictVictims:                pointer.BoolPtr(false),
+				PodGroupAware:               pointer.BoolPtr(false),
+				PreemptWholeGroups:          pointer.BoolPtr(false),
+				MaxEvictionsPerNamespace:    pointer.Int32Ptr(0),
+				MinRuntimeSeconds:           pointer.Int64Ptr(0),
 			},
 		},
 		{
 			name: "DefaultPreemptionArgs with value",
-			in: &v1beta3.DefaultPreemptionArgs{
+			in: &DefaultPreemptionArgs{
 				MinCandidateNodesPercentage: pointer.Int32Ptr(50),
+				EvictVictims:                pointer.BoolPtr(true),
+				PodGroupAware:               pointer.BoolPtr(true),
 			},
-			want: &v1beta3.DefaultPreemptionArgs{
+			want: &DefaultPreemptionArgs{
 				MinCandidateNodesPercentage: pointer.Int32Ptr(50),
 				MinCandidateNodesAbsolute:   pointer.Int32Ptr(100),
+				EvictVictims:                pointer.BoolPtr(true),
+				PodGroupAware:               pointer.BoolPtr(true),
+				PreemptWholeGroups:          pointer.BoolPtr(false),
+				MaxEvictionsPerNamespace:    pointer.Int32Ptr(0),
+				MinRuntimeSeconds:           pointer.Int64Ptr(0),
 			},
 		},
 		{
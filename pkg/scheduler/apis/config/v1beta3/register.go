@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta3
 
 import (
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/kube-scheduler/config/v1beta3"
 )
 
@@ -27,9 +28,13 @@ const GroupName = v1beta3.GroupName
 var SchemeGroupVersion = v1beta3.SchemeGroupVersion
 
 var (
-	// localSchemeBuilder extends the SchemeBuilder instance with the external types. In this package,
-	// defaulting and conversion init funcs are registered as well.
-	localSchemeBuilder = &v1beta3.SchemeBuilder
+	// SchemeBuilder registers the external types of
+	// k8s.io/kube-scheduler/config/v1beta3 along with the ones of this
+	// package. It doesn't extend the upstream SchemeBuilder, which registers
+	// the upstream DefaultPreemptionArgs this package replaces. In this
+	// package, defaulting and conversion init funcs are registered as well.
+	SchemeBuilder      runtime.SchemeBuilder
+	localSchemeBuilder = &SchemeBuilder
 	// AddToScheme is a global function that registers this API group & version to a scheme
 	AddToScheme = localSchemeBuilder.AddToScheme
 )
@@ -38,5 +43,28 @@ func init() {
 	// We only register manually written functions here. The registration of the
 	// generated functions takes place in the generated files. The separation
 	// makes the code compile even when the generated files are missing.
-	localSchemeBuilder.Register(addDefaultingFuncs)
+	localSchemeBuilder.Register(addKnownTypes, addDefaultingFuncs)
+}
+
+// addKnownTypes registers the upstream types of
+// k8s.io/kube-scheduler/config/v1beta3, except for DefaultPreemptionArgs, and
+// the plugin args of this package.
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(SchemeGroupVersion,
+		&v1beta3.KubeSchedulerConfiguration{},
+		&v1beta3.InterPodAffinityArgs{},
+		&v1beta3.NodeResourcesBalancedAllocationArgs{},
+		&v1beta3.NodeResourcesFitArgs{},
+		&v1beta3.PodTopologySpreadArgs{},
+		&v1beta3.VolumeBindingArgs{},
+		&v1beta3.NodeAffinityArgs{},
+		&CoschedulingArgs{},
+		&DefaultPreemptionArgs{},
+		&DominantResourceFairnessArgs{},
+		&ElasticQuotaArgs{},
+		&GPUTopologyArgs{},
+		&PodBackoffArgs{},
+		&TopologyPackingArgs{},
+	)
+	return nil
 }
@@ -0,0 +1,339 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta3
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-scheduler/config/v1beta3"
+)
+
+// The types in this file are the versioned arguments of plugins that only
+// exist in this scheduler, or that take more arguments than upstream. They
+// are registered in the kubescheduler.config.k8s.io/v1beta3 group next to the
+// upstream types from k8s.io/kube-scheduler/config/v1beta3.
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CoschedulingArgs holds arguments used to configure the Coscheduling plugin.
+type CoschedulingArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// PermitWaitingTimeSeconds is the maximum time in seconds a member of a
+	// pod group waits at the permit extension point for the rest of its group
+	// before the whole group is rejected.
+	// Defaults to 60 seconds.
+	// +optional
+	PermitWaitingTimeSeconds *int64 `json:"permitWaitingTimeSeconds,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DefaultPreemptionArgs holds arguments used to configure the
+// DefaultPreemption plugin. It replaces the upstream type of the same name,
+// whose fields it keeps.
+type DefaultPreemptionArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// MinCandidateNodesPercentage is the minimum number of candidates to
+	// shortlist when dry running preemption as a percentage of number of nodes.
+	// Must be in the range [0, 100]. Defaults to 10% of the cluster size if
+	// unspecified.
+	MinCandidateNodesPercentage *int32 `json:"minCandidateNodesPercentage,omitempty"`
+	// MinCandidateNodesAbsolute is the absolute minimum number of candidates to
+	// shortlist. The likely number of candidates enumerated for dry running
+	// preemption is given by the formula:
+	// numCandidates = max(numNodes * minCandidateNodesPercentage, minCandidateNodesAbsolute)
+	// We say "likely" because there are other factors such as PDB violations
+	// that play a role in the number of candidates shortlisted. Must be at least
+	// 0 nodes. Defaults to 100 nodes if unspecified.
+	MinCandidateNodesAbsolute *int32 `json:"minCandidateNodesAbsolute,omitempty"`
+	// EvictVictims, if true, removes the victims through the policy/v1
+	// Eviction API instead of deleting them, so that the apiserver enforces
+	// their PodDisruptionBudgets. A refused eviction is retried a few times,
+	// then the preemptor falls back to the next best candidate node. The
+	// evictions of the victims on a node are dry-run before any of them is
+	// evicted, but if one is still refused afterwards, the victims evicted
+	// before it stay evicted, while the notices given to the others are
+	// canceled.
+	// Defaults to false.
+	// +optional
+	EvictVictims *bool `json:"evictVictims,omitempty"`
+	// CandidateRanking lists, in order, the criteria the candidate nodes for
+	// preemption are compared by. Each criterion keeps the nodes that do best
+	// on it and the next one breaks the ties. One of "PDBViolations",
+	// "HighestPriority", "SumOfPriorities", "NumVictims", "LatestStartTime" or
+	// "LostWork". Empty means the upstream order, which leaves out "LostWork".
+	// +optional
+	CandidateRanking []CandidateRankingCriterion `json:"candidateRanking,omitempty"`
+	// LostWorkResources are the resources, and their weights, the "LostWork"
+	// criterion counts the work of the victims in: the sum over the victims
+	// of their weighted requests times the hours they ran since they started,
+	// or since the time in their
+	// preemption.scheduling.sched.dev/last-checkpoint annotation. CPU is
+	// counted in cores, memory in GiB and other resources in units.
+	// Empty means cpu, memory and nvidia.com/gpu with a weight of 1.
+	// +optional
+	LostWorkResources []v1beta3.ResourceSpec `json:"lostWorkResources,omitempty"`
+	// PodGroupAware makes preemption account for the pod groups, given by the
+	// pod-group.scheduling.sched.dev label, of the victims and of the
+	// preemptor. When ranking the candidate nodes, the victims count with
+	// the members of their groups running on other nodes, which are useless
+	// without them. Among victims of the same priority, members of larger
+	// groups are spared first. A preemptor that is a member of a group also
+	// preempts, in the same cycle, on other candidate nodes for the pending
+	// members of its group that are replicas of it.
+	// Defaults to false.
+	// +optional
+	PodGroupAware *bool `json:"podGroupAware,omitempty"`
+	// PreemptWholeGroups, if true, also preempts the other members of the
+	// groups of the victims, on whatever node they run, so that the
+	// resources they hold are freed at once. Members with a priority not
+	// lower than the preemptor's are kept.
+	// Defaults to false.
+	// +optional
+	PreemptWholeGroups *bool `json:"preemptWholeGroups,omitempty"`
+	// ProtectedNamespaces are the namespaces whose pods are never preempted,
+	// whatever their priority.
+	// +optional
+	ProtectedNamespaces []string `json:"protectedNamespaces,omitempty"`
+	// ProtectedPodSelector selects the pods that are never preempted,
+	// whatever their priority. An empty selector selects no pod.
+	// +optional
+	ProtectedPodSelector *metav1.LabelSelector `json:"protectedPodSelector,omitempty"`
+	// MaxEvictionsPerNamespace, if greater than 0, is the maximum number of
+	// pods of a namespace preempted within evictionWindowSeconds. Candidate
+	// nodes that would preempt more pods of a namespace are rejected.
+	// The evictions are counted in memory by each scheduler replica, so the
+	// budget starts over when the scheduler restarts, and it doesn't include
+	// the pods preempted by other replicas or by the ElasticQuota plugin,
+	// which has a budget of its own.
+	// Defaults to 0, no limit.
+	// +optional
+	MaxEvictionsPerNamespace *int32 `json:"maxEvictionsPerNamespace,omitempty"`
+	// EvictionWindowSeconds is the sliding window maxEvictionsPerNamespace
+	// applies to. Defaults to 3600 seconds when maxEvictionsPerNamespace is
+	// set.
+	// +optional
+	EvictionWindowSeconds *int64 `json:"evictionWindowSeconds,omitempty"`
+	// MinRuntimeSeconds is how long a pod must have run, since its start
+	// time, before it may be preempted. Defaults to 0.
+	// +optional
+	MinRuntimeSeconds *int64 `json:"minRuntimeSeconds,omitempty"`
+}
+
+// CandidateRankingCriterion is a criterion the candidate nodes for preemption
+// are ranked by. The node with the lowest cost on it wins.
+type CandidateRankingCriterion string
+
+const (
+	// RankByPDBViolations counts the victims whose PodDisruptionBudget would be
+	// violated.
+	RankByPDBViolations CandidateRankingCriterion = "PDBViolations"
+	// RankByHighestPriority is the priority of the highest priority victim.
+	RankByHighestPriority CandidateRankingCriterion = "HighestPriority"
+	// RankBySumOfPriorities is the sum of the priorities of the victims.
+	RankBySumOfPriorities CandidateRankingCriterion = "SumOfPriorities"
+	// RankByNumVictims is the number of victims.
+	RankByNumVictims CandidateRankingCriterion = "NumVictims"
+	// RankByLatestStartTime prefers the node whose highest priority victims
+	// started the latest.
+	RankByLatestStartTime CandidateRankingCriterion = "LatestStartTime"
+	// RankByLostWork is the work the victims lose.
+	RankByLostWork CandidateRankingCriterion = "LostWork"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DominantResourceFairnessArgs holds arguments used to configure the
+// DominantResourceFairness plugin.
+type DominantResourceFairnessArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Resources are the resources the dominant share of a namespace is
+	// computed over. Defaults to "cpu", "memory" and "nvidia.com/gpu".
+	// +optional
+	Resources []string `json:"resources,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ElasticQuotaArgs holds arguments used to configure the ElasticQuota plugin.
+type ElasticQuotaArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// MinCandidateNodesPercentage is the minimum number of candidates to
+	// shortlist when dry running preemption as a percentage of number of
+	// nodes, as for DefaultPreemption. Defaults to 10.
+	// +optional
+	MinCandidateNodesPercentage *int32 `json:"minCandidateNodesPercentage,omitempty"`
+	// MinCandidateNodesAbsolute is the absolute minimum number of candidates
+	// to shortlist, as for DefaultPreemption. Defaults to 100.
+	// +optional
+	MinCandidateNodesAbsolute *int32 `json:"minCandidateNodesAbsolute,omitempty"`
+	// ProtectedNamespaces are the namespaces whose pods are never preempted,
+	// whatever their priority and the quota of their namespace. They are not
+	// shared with DefaultPreemption, so both plugins are usually given the
+	// same ones.
+	// +optional
+	ProtectedNamespaces []string `json:"protectedNamespaces,omitempty"`
+	// ProtectedPodSelector selects the pods that are never preempted,
+	// whatever their priority and the quota of their namespace. An empty
+	// selector selects no pod.
+	// +optional
+	ProtectedPodSelector *metav1.LabelSelector `json:"protectedPodSelector,omitempty"`
+	// MaxEvictionsPerNamespace, if greater than 0, is the maximum number of
+	// pods of a namespace preempted by ElasticQuota within
+	// evictionWindowSeconds. The budget is separate from the one of
+	// DefaultPreemption, and counted in memory like it.
+	// Defaults to 0, no limit.
+	// +optional
+	MaxEvictionsPerNamespace *int32 `json:"maxEvictionsPerNamespace,omitempty"`
+	// EvictionWindowSeconds is the sliding window maxEvictionsPerNamespace
+	// applies to. Defaults to 3600 seconds when maxEvictionsPerNamespace is
+	// set.
+	// +optional
+	EvictionWindowSeconds *int64 `json:"evictionWindowSeconds,omitempty"`
+	// MinRuntimeSeconds is how long a pod must have run, since its start
+	// time, before it may be preempted. Defaults to 0.
+	// +optional
+	MinRuntimeSeconds *int64 `json:"minRuntimeSeconds,omitempty"`
+}
+
+// GPULinkType is the kind of interconnect between two GPUs of a node.
+type GPULinkType string
+
+const (
+	// GPULinkNVLink means that the GPUs are directly connected with NVLink.
+	GPULinkNVLink GPULinkType = "NVLink"
+	// GPULinkPCIeSwitch means that the GPUs are behind the same PCIe switch.
+	GPULinkPCIeSwitch GPULinkType = "PCIeSwitch"
+	// GPULinkNUMANode means that the GPUs are attached to the same NUMA node.
+	GPULinkNUMANode GPULinkType = "NUMANode"
+	// GPULinkSystem means that traffic between the GPUs crosses NUMA nodes.
+	GPULinkSystem GPULinkType = "System"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GPUTopologyArgs holds arguments used to configure the GPUTopology plugin.
+type GPUTopologyArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// ResourceName is the extended resource GPUs are requested with.
+	// Defaults to "nvidia.com/gpu".
+	// +optional
+	ResourceName string `json:"resourceName,omitempty"`
+	// MinimumLinkType is the weakest interconnect allowed between any two
+	// GPUs allocated to the same pod. One of "NVLink", "PCIeSwitch",
+	// "NUMANode" or "System". Defaults to "NUMANode".
+	// +optional
+	MinimumLinkType GPULinkType `json:"minimumLinkType,omitempty"`
+}
+
+// BackoffPolicyType defines how the backoff of a pod grows with its attempts.
+type BackoffPolicyType string
+
+const (
+	// ExponentialBackoff doubles the backoff with every attempt.
+	ExponentialBackoff BackoffPolicyType = "Exponential"
+	// LinearBackoff grows the backoff by the initial backoff with every attempt.
+	LinearBackoff BackoffPolicyType = "Linear"
+	// FixedBackoff keeps the backoff at the initial backoff.
+	FixedBackoff BackoffPolicyType = "Fixed"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodBackoffArgs holds arguments used to configure the backoff of the
+// unschedulable pods of a profile, and when the scheduler gives up on them.
+// They are set in the pluginConfig of the profile under the name
+// "PodBackoff".
+type PodBackoffArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Policies are matched in order against a pod that failed to schedule and
+	// the first matching one sets its backoff. Pods matching none back off
+	// exponentially between podInitialBackoffSeconds and
+	// podMaxBackoffSeconds.
+	// +optional
+	Policies []BackoffPolicy `json:"policies,omitempty"`
+	// MaxAttempts, if greater than 0, is the number of failed attempts after
+	// which the scheduler stops retrying a pod and sets its PodScheduled
+	// condition to False with the reason SchedulingDeadlineExceeded. Pods
+	// override it with the scheduling.sched.dev/max-attempts annotation.
+	// +optional
+	MaxAttempts int32 `json:"maxAttempts,omitempty"`
+	// SchedulingDeadlineSeconds, if greater than 0, is the time since the
+	// first attempt after which the scheduler stops retrying a pod, the same
+	// way as MaxAttempts. Pods override it with the
+	// scheduling.sched.dev/scheduling-deadline annotation.
+	// +optional
+	SchedulingDeadlineSeconds int64 `json:"schedulingDeadlineSeconds,omitempty"`
+}
+
+// BackoffPolicy sets the backoff of the pods it matches.
+type BackoffPolicy struct {
+	// Type defines how the backoff grows with the attempts of a pod. One of
+	// "Exponential", "Linear" or "Fixed". Defaults to "Exponential".
+	// +optional
+	Type BackoffPolicyType `json:"type,omitempty"`
+	// InitialBackoffSeconds is the backoff after the first attempt.
+	// Defaults to 1 second.
+	// +optional
+	InitialBackoffSeconds *int64 `json:"initialBackoffSeconds,omitempty"`
+	// MaxBackoffSeconds caps the backoff of Exponential and Linear policies.
+	// Defaults to 10 seconds.
+	// +optional
+	MaxBackoffSeconds *int64 `json:"maxBackoffSeconds,omitempty"`
+	// JitterPercent is the largest share of the backoff, in percent, added to
+	// it at random to spread the retries of pods failing together. The
+	// backoff of Exponential and Linear policies still doesn't go over
+	// MaxBackoffSeconds.
+	// +optional
+	JitterPercent int32 `json:"jitterPercent,omitempty"`
+	// MinPriority and MaxPriority, if set, bound the priorities of the pods
+	// the policy matches.
+	// +optional
+	MinPriority *int32 `json:"minPriority,omitempty"`
+	// +optional
+	MaxPriority *int32 `json:"maxPriority,omitempty"`
+	// UnschedulablePlugins, if not empty, restricts the policy to the pods
+	// rejected by any of these plugins in their last attempt, e.g.
+	// "Coscheduling" and "ElasticQuota" for gangs waiting on quota or
+	// "VolumeBinding" for pods waiting on volumes.
+	// +optional
+	UnschedulablePlugins []string `json:"unschedulablePlugins,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// TopologyPackingArgs holds arguments used to configure the TopologyPacking plugin.
+type TopologyPackingArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// TopologyKeys are the node labels describing the network hierarchy,
+	// from the broadest domain to the narrowest, e.g. spine block, rack and
+	// host. Defaults to "topology.sched.dev/block", "topology.sched.dev/rack"
+	// and "kubernetes.io/hostname".
+	// +optional
+	TopologyKeys []string `json:"topologyKeys,omitempty"`
+	// RequiredTopologyKey, if not empty, is one of TopologyKeys whose domain
+	// all the members of a pod group must share. Nodes outside the domain of
+	// the members already placed, or without the label, are filtered out.
+	// +optional
+	RequiredTopologyKey string `json:"requiredTopologyKey,omitempty"`
+}
@@ -40,13 +40,43 @@ func init() {
 // RegisterConversions adds conversion functions to the given scheme.
 // Public to allow building arbitrary schemes.
 func RegisterConversions(s *runtime.Scheme) error {
-	if err := s.AddGeneratedConversionFunc((*v1beta3.DefaultPreemptionArgs)(nil), (*config.DefaultPreemptionArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_v1beta3_DefaultPreemptionArgs_To_config_DefaultPreemptionArgs(a.(*v1beta3.DefaultPreemptionArgs), b.(*config.DefaultPreemptionArgs), scope)
+	if err := s.AddGeneratedConversionFunc((*BackoffPolicy)(nil), (*config.BackoffPolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta3_BackoffPolicy_To_config_BackoffPolicy(a.(*BackoffPolicy), b.(*config.BackoffPolicy), scope)
 	}); err != nil {
 		return err
 	}
-	if err := s.AddGeneratedConversionFunc((*config.DefaultPreemptionArgs)(nil), (*v1beta3.DefaultPreemptionArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
-		return Convert_config_DefaultPreemptionArgs_To_v1beta3_DefaultPreemptionArgs(a.(*config.DefaultPreemptionArgs), b.(*v1beta3.DefaultPreemptionArgs), scope)
+	if err := s.AddGeneratedConversionFunc((*config.BackoffPolicy)(nil), (*BackoffPolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_BackoffPolicy_To_v1beta3_BackoffPolicy(a.(*config.BackoffPolicy), b.(*BackoffPolicy), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*CoschedulingArgs)(nil), (*config.CoschedulingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta3_CoschedulingArgs_To_config_CoschedulingArgs(a.(*CoschedulingArgs), b.(*config.CoschedulingArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.CoschedulingArgs)(nil), (*CoschedulingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_CoschedulingArgs_To_v1beta3_CoschedulingArgs(a.(*config.CoschedulingArgs), b.(*CoschedulingArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*DefaultPreemptionArgs)(nil), (*config.DefaultPreemptionArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta3_DefaultPreemptionArgs_To_config_DefaultPreemptionArgs(a.(*DefaultPreemptionArgs), b.(*config.DefaultPreemptionArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.DefaultPreemptionArgs)(nil), (*DefaultPreemptionArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_DefaultPreemptionArgs_To_v1beta3_DefaultPreemptionArgs(a.(*config.DefaultPreemptionArgs), b.(*DefaultPreemptionArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*DominantResourceFairnessArgs)(nil), (*config.DominantResourceFairnessArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta3_DominantResourceFairnessArgs_To_config_DominantResourceFairnessArgs(a.(*DominantResourceFairnessArgs), b.(*config.DominantResourceFairnessArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.DominantResourceFairnessArgs)(nil), (*DominantResourceFairnessArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_DominantResourceFairnessArgs_To_v1beta3_DominantResourceFairnessArgs(a.(*config.DominantResourceFairnessArgs), b.(*DominantResourceFairnessArgs), scope)
 	}); err != nil {
 		return err
 	}
@@ -80,6 +110,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ElasticQuotaArgs)(nil), (*config.ElasticQuotaArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta3_ElasticQuotaArgs_To_config_ElasticQuotaArgs(a.(*ElasticQuotaArgs), b.(*config.ElasticQuotaArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.ElasticQuotaArgs)(nil), (*ElasticQuotaArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_ElasticQuotaArgs_To_v1beta3_ElasticQuotaArgs(a.(*config.ElasticQuotaArgs), b.(*ElasticQuotaArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*GPUTopologyArgs)(nil), (*config.GPUTopologyArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta3_GPUTopologyArgs_To_config_GPUTopologyArgs(a.(*GPUTopologyArgs), b.(*config.GPUTopologyArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.GPUTopologyArgs)(nil), (*GPUTopologyArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_GPUTopologyArgs_To_v1beta3_GPUTopologyArgs(a.(*config.GPUTopologyArgs), b.(*GPUTopologyArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1beta3.InterPodAffinityArgs)(nil), (*config.InterPodAffinityArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta3_InterPodAffinityArgs_To_config_InterPodAffinityArgs(a.(*v1beta3.InterPodAffinityArgs), b.(*config.InterPodAffinityArgs), scope)
 	}); err != nil {
@@ -170,6 +220,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*PodBackoffArgs)(nil), (*config.PodBackoffArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta3_PodBackoffArgs_To_config_PodBackoffArgs(a.(*PodBackoffArgs), b.(*config.PodBackoffArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.PodBackoffArgs)(nil), (*PodBackoffArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_PodBackoffArgs_To_v1beta3_PodBackoffArgs(a.(*config.PodBackoffArgs), b.(*PodBackoffArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1beta3.PodTopologySpreadArgs)(nil), (*config.PodTopologySpreadArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta3_PodTopologySpreadArgs_To_config_PodTopologySpreadArgs(a.(*v1beta3.PodTopologySpreadArgs), b.(*config.PodTopologySpreadArgs), scope)
 	}); err != nil {
@@ -210,6 +270,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*TopologyPackingArgs)(nil), (*config.TopologyPackingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta3_TopologyPackingArgs_To_config_TopologyPackingArgs(a.(*TopologyPackingArgs), b.(*config.TopologyPackingArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.TopologyPackingArgs)(nil), (*TopologyPackingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_TopologyPackingArgs_To_v1beta3_TopologyPackingArgs(a.(*config.TopologyPackingArgs), b.(*TopologyPackingArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*v1beta3.UtilizationShapePoint)(nil), (*config.UtilizationShapePoint)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta3_UtilizationShapePoint_To_config_UtilizationShapePoint(a.(*v1beta3.UtilizationShapePoint), b.(*config.UtilizationShapePoint), scope)
 	}); err != nil {
@@ -243,36 +313,164 @@ func RegisterConversions(s *runtime.Scheme) error {
 	return nil
 }
 
-func autoConvert_v1beta3_DefaultPreemptionArgs_To_config_DefaultPreemptionArgs(in *v1beta3.DefaultPreemptionArgs, out *config.DefaultPreemptionArgs, s conversion.Scope) error {
+func autoConvert_v1beta3_BackoffPolicy_To_config_BackoffPolicy(in *BackoffPolicy, out *config.BackoffPolicy, s conversion.Scope) error {
+	out.Type = config.BackoffPolicyType(in.Type)
+	if err := v1.Convert_Pointer_int64_To_int64(&in.InitialBackoffSeconds, &out.InitialBackoffSeconds, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int64_To_int64(&in.MaxBackoffSeconds, &out.MaxBackoffSeconds, s); err != nil {
+		return err
+	}
+	out.JitterPercent = in.JitterPercent
+	out.MinPriority = (*int32)(unsafe.Pointer(in.MinPriority))
+	out.MaxPriority = (*int32)(unsafe.Pointer(in.MaxPriority))
+	out.UnschedulablePlugins = *(*[]string)(unsafe.Pointer(&in.UnschedulablePlugins))
+	return nil
+}
+
+// Convert_v1beta3_BackoffPolicy_To_config_BackoffPolicy is an autogenerated conversion function.
+func Convert_v1beta3_BackoffPolicy_To_config_BackoffPolicy(in *BackoffPolicy, out *config.BackoffPolicy, s conversion.Scope) error {
+	return autoConvert_v1beta3_BackoffPolicy_To_config_BackoffPolicy(in, out, s)
+}
+
+func autoConvert_config_BackoffPolicy_To_v1beta3_BackoffPolicy(in *config.BackoffPolicy, out *BackoffPolicy, s conversion.Scope) error {
+	out.Type = BackoffPolicyType(in.Type)
+	if err := v1.Convert_int64_To_Pointer_int64(&in.InitialBackoffSeconds, &out.InitialBackoffSeconds, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int64_To_Pointer_int64(&in.MaxBackoffSeconds, &out.MaxBackoffSeconds, s); err != nil {
+		return err
+	}
+	out.JitterPercent = in.JitterPercent
+	out.MinPriority = (*int32)(unsafe.Pointer(in.MinPriority))
+	out.MaxPriority = (*int32)(unsafe.Pointer(in.MaxPriority))
+	out.UnschedulablePlugins = *(*[]string)(unsafe.Pointer(&in.UnschedulablePlugins))
+	return nil
+}
+
+// Convert_config_BackoffPolicy_To_v1beta3_BackoffPolicy is an autogenerated conversion function.
+func Convert_config_BackoffPolicy_To_v1beta3_BackoffPolicy(in *config.BackoffPolicy, out *BackoffPolicy, s conversion.Scope) error {
+	return autoConvert_config_BackoffPolicy_To_v1beta3_BackoffPolicy(in, out, s)
+}
+
+func autoConvert_v1beta3_CoschedulingArgs_To_config_CoschedulingArgs(in *CoschedulingArgs, out *config.CoschedulingArgs, s conversion.Scope) error {
+	if err := v1.Convert_Pointer_int64_To_int64(&in.PermitWaitingTimeSeconds, &out.PermitWaitingTimeSeconds, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1beta3_CoschedulingArgs_To_config_CoschedulingArgs is an autogenerated conversion function.
+func Convert_v1beta3_CoschedulingArgs_To_config_CoschedulingArgs(in *CoschedulingArgs, out *config.CoschedulingArgs, s conversion.Scope) error {
+	return autoConvert_v1beta3_CoschedulingArgs_To_config_CoschedulingArgs(in, out, s)
+}
+
+func autoConvert_config_CoschedulingArgs_To_v1beta3_CoschedulingArgs(in *config.CoschedulingArgs, out *CoschedulingArgs, s conversion.Scope) error {
+	if err := v1.Convert_int64_To_Pointer_int64(&in.PermitWaitingTimeSeconds, &out.PermitWaitingTimeSeconds, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_CoschedulingArgs_To_v1beta3_CoschedulingArgs is an autogenerated conversion function.
+func Convert_config_CoschedulingArgs_To_v1beta3_CoschedulingArgs(in *config.CoschedulingArgs, out *CoschedulingArgs, s conversion.Scope) error {
+	return autoConvert_config_CoschedulingArgs_To_v1beta3_CoschedulingArgs(in, out, s)
+}
+
+func autoConvert_v1beta3_DefaultPreemptionArgs_To_config_DefaultPreemptionArgs(in *DefaultPreemptionArgs, out *config.DefaultPreemptionArgs, s conversion.Scope) error {
 	if err := v1.Convert_Pointer_int32_To_int32(&in.MinCandidateNodesPercentage, &out.MinCandidateNodesPercentage, s); err != nil {
 		return err
 	}
 	if err := v1.Convert_Pointer_int32_To_int32(&in.MinCandidateNodesAbsolute, &out.MinCandidateNodesAbsolute, s); err != nil {
 		return err
 	}
+	if err := v1.Convert_Pointer_bool_To_bool(&in.EvictVictims, &out.EvictVictims, s); err != nil {
+		return err
+	}
+	out.CandidateRanking = *(*[]config.CandidateRankingCriterion)(unsafe.Pointer(&in.CandidateRanking))
+	out.LostWorkResources = *(*[]config.ResourceSpec)(unsafe.Pointer(&in.LostWorkResources))
+	if err := v1.Convert_Pointer_bool_To_bool(&in.PodGroupAware, &out.PodGroupAware, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_bool_To_bool(&in.PreemptWholeGroups, &out.PreemptWholeGroups, s); err != nil {
+		return err
+	}
+	out.ProtectedNamespaces = *(*[]string)(unsafe.Pointer(&in.ProtectedNamespaces))
+	out.ProtectedPodSelector = (*v1.LabelSelector)(unsafe.Pointer(in.ProtectedPodSelector))
+	if err := v1.Convert_Pointer_int32_To_int32(&in.MaxEvictionsPerNamespace, &out.MaxEvictionsPerNamespace, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int64_To_int64(&in.EvictionWindowSeconds, &out.EvictionWindowSeconds, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int64_To_int64(&in.MinRuntimeSeconds, &out.MinRuntimeSeconds, s); err != nil {
+		return err
+	}
 	return nil
 }
 
 // Convert_v1beta3_DefaultPreemptionArgs_To_config_DefaultPreemptionArgs is an autogenerated conversion function.
-func Convert_v1beta3_DefaultPreemptionArgs_To_config_DefaultPreemptionArgs(in *v1beta3.DefaultPreemptionArgs, out *config.DefaultPreemptionArgs, s conversion.Scope) error {
+func Convert_v1beta3_DefaultPreemptionArgs_To_config_DefaultPreemptionArgs(in *DefaultPreemptionArgs, out *config.DefaultPreemptionArgs, s conversion.Scope) error {
 	return autoConvert_v1beta3_DefaultPreemptionArgs_To_config_DefaultPreemptionArgs(in, out, s)
 }
 
-func autoConvert_config_DefaultPreemptionArgs_To_v1beta3_DefaultPreemptionArgs(in *config.DefaultPreemptionArgs, out *v1beta3.DefaultPreemptionArgs, s conversion.Scope) error {
+func autoConvert_config_DefaultPreemptionArgs_To_v1beta3_DefaultPreemptionArgs(in *config.DefaultPreemptionArgs, out *DefaultPreemptionArgs, s conversion.Scope) error {
 	if err := v1.Convert_int32_To_Pointer_int32(&in.MinCandidateNodesPercentage, &out.MinCandidateNodesPercentage, s); err != nil {
 		return err
 	}
 	if err := v1.Convert_int32_To_Pointer_int32(&in.MinCandidateNodesAbsolute, &out.MinCandidateNodesAbsolute, s); err != nil {
 		return err
 	}
+	if err := v1.Convert_bool_To_Pointer_bool(&in.EvictVictims, &out.EvictVictims, s); err != nil {
+		return err
+	}
+	out.CandidateRanking = *(*[]CandidateRankingCriterion)(unsafe.Pointer(&in.CandidateRanking))
+	out.LostWorkResources = *(*[]v1beta3.ResourceSpec)(unsafe.Pointer(&in.LostWorkResources))
+	if err := v1.Convert_bool_To_Pointer_bool(&in.PodGroupAware, &out.PodGroupAware, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_bool_To_Pointer_bool(&in.PreemptWholeGroups, &out.PreemptWholeGroups, s); err != nil {
+		return err
+	}
+	out.ProtectedNamespaces = *(*[]string)(unsafe.Pointer(&in.ProtectedNamespaces))
+	out.ProtectedPodSelector = (*v1.LabelSelector)(unsafe.Pointer(in.ProtectedPodSelector))
+	if err := v1.Convert_int32_To_Pointer_int32(&in.MaxEvictionsPerNamespace, &out.MaxEvictionsPerNamespace, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int64_To_Pointer_int64(&in.EvictionWindowSeconds, &out.EvictionWindowSeconds, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int64_To_Pointer_int64(&in.MinRuntimeSeconds, &out.MinRuntimeSeconds, s); err != nil {
+		return err
+	}
 	return nil
 }
 
 // Convert_config_DefaultPreemptionArgs_To_v1beta3_DefaultPreemptionArgs is an autogenerated conversion function.
-func Convert_config_DefaultPreemptionArgs_To_v1beta3_DefaultPreemptionArgs(in *config.DefaultPreemptionArgs, out *v1beta3.DefaultPreemptionArgs, s conversion.Scope) error {
+func Convert_config_DefaultPreemptionArgs_To_v1beta3_DefaultPreemptionArgs(in *config.DefaultPreemptionArgs, out *DefaultPreemptionArgs, s conversion.Scope) error {
 	return autoConvert_config_DefaultPreemptionArgs_To_v1beta3_DefaultPreemptionArgs(in, out, s)
 }
 
+func autoConvert_v1beta3_DominantResourceFairnessArgs_To_config_DominantResourceFairnessArgs(in *DominantResourceFairnessArgs, out *config.DominantResourceFairnessArgs, s conversion.Scope) error {
+	out.Resources = *(*[]string)(unsafe.Pointer(&in.Resources))
+	return nil
+}
+
+// Convert_v1beta3_DominantResourceFairnessArgs_To_config_DominantResourceFairnessArgs is an autogenerated conversion function.
+func Convert_v1beta3_DominantResourceFairnessArgs_To_config_DominantResourceFairnessArgs(in *DominantResourceFairnessArgs, out *config.DominantResourceFairnessArgs, s conversion.Scope) error {
+	return autoConvert_v1beta3_DominantResourceFairnessArgs_To_config_DominantResourceFairnessArgs(in, out, s)
+}
+
+func autoConvert_config_DominantResourceFairnessArgs_To_v1beta3_DominantResourceFairnessArgs(in *config.DominantResourceFairnessArgs, out *DominantResourceFairnessArgs, s conversion.Scope) error {
+	out.Resources = *(*[]string)(unsafe.Pointer(&in.Resources))
+	return nil
+}
+
+// Convert_config_DominantResourceFairnessArgs_To_v1beta3_DominantResourceFairnessArgs is an autogenerated conversion function.
+func Convert_config_DominantResourceFairnessArgs_To_v1beta3_DominantResourceFairnessArgs(in *config.DominantResourceFairnessArgs, out *DominantResourceFairnessArgs, s conversion.Scope) error {
+	return autoConvert_config_DominantResourceFairnessArgs_To_v1beta3_DominantResourceFairnessArgs(in, out, s)
+}
+
 func autoConvert_v1beta3_Extender_To_config_Extender(in *v1beta3.Extender, out *config.Extender, s conversion.Scope) error {
 	out.URLPrefix = in.URLPrefix
 	out.FilterVerb = in.FilterVerb
@@ -371,6 +569,80 @@ func Convert_config_ExtenderTLSConfig_To_v1beta3_ExtenderTLSConfig(in *config.Ex
 	return autoConvert_config_ExtenderTLSConfig_To_v1beta3_ExtenderTLSConfig(in, out, s)
 }
 
+func autoConvert_v1beta3_ElasticQuotaArgs_To_config_ElasticQuotaArgs(in *ElasticQuotaArgs, out *config.ElasticQuotaArgs, s conversion.Scope) error {
+	if err := v1.Convert_Pointer_int32_To_int32(&in.MinCandidateNodesPercentage, &out.MinCandidateNodesPercentage, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int32_To_int32(&in.MinCandidateNodesAbsolute, &out.MinCandidateNodesAbsolute, s); err != nil {
+		return err
+	}
+	out.ProtectedNamespaces = *(*[]string)(unsafe.Pointer(&in.ProtectedNamespaces))
+	out.ProtectedPodSelector = (*v1.LabelSelector)(unsafe.Pointer(in.ProtectedPodSelector))
+	if err := v1.Convert_Pointer_int32_To_int32(&in.MaxEvictionsPerNamespace, &out.MaxEvictionsPerNamespace, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int64_To_int64(&in.EvictionWindowSeconds, &out.EvictionWindowSeconds, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_Pointer_int64_To_int64(&in.MinRuntimeSeconds, &out.MinRuntimeSeconds, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1beta3_ElasticQuotaArgs_To_config_ElasticQuotaArgs is an autogenerated conversion function.
+func Convert_v1beta3_ElasticQuotaArgs_To_config_ElasticQuotaArgs(in *ElasticQuotaArgs, out *config.ElasticQuotaArgs, s conversion.Scope) error {
+	return autoConvert_v1beta3_ElasticQuotaArgs_To_config_ElasticQuotaArgs(in, out, s)
+}
+
+func autoConvert_config_ElasticQuotaArgs_To_v1beta3_ElasticQuotaArgs(in *config.ElasticQuotaArgs, out *ElasticQuotaArgs, s conversion.Scope) error {
+	if err := v1.Convert_int32_To_Pointer_int32(&in.MinCandidateNodesPercentage, &out.MinCandidateNodesPercentage, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int32_To_Pointer_int32(&in.MinCandidateNodesAbsolute, &out.MinCandidateNodesAbsolute, s); err != nil {
+		return err
+	}
+	out.ProtectedNamespaces = *(*[]string)(unsafe.Pointer(&in.ProtectedNamespaces))
+	out.ProtectedPodSelector = (*v1.LabelSelector)(unsafe.Pointer(in.ProtectedPodSelector))
+	if err := v1.Convert_int32_To_Pointer_int32(&in.MaxEvictionsPerNamespace, &out.MaxEvictionsPerNamespace, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int64_To_Pointer_int64(&in.EvictionWindowSeconds, &out.EvictionWindowSeconds, s); err != nil {
+		return err
+	}
+	if err := v1.Convert_int64_To_Pointer_int64(&in.MinRuntimeSeconds, &out.MinRuntimeSeconds, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_ElasticQuotaArgs_To_v1beta3_ElasticQuotaArgs is an autogenerated conversion function.
+func Convert_config_ElasticQuotaArgs_To_v1beta3_ElasticQuotaArgs(in *config.ElasticQuotaArgs, out *ElasticQuotaArgs, s conversion.Scope) error {
+	return autoConvert_config_ElasticQuotaArgs_To_v1beta3_ElasticQuotaArgs(in, out, s)
+}
+
+func autoConvert_v1beta3_GPUTopologyArgs_To_config_GPUTopologyArgs(in *GPUTopologyArgs, out *config.GPUTopologyArgs, s conversion.Scope) error {
+	out.ResourceName = in.ResourceName
+	out.MinimumLinkType = config.GPULinkType(in.MinimumLinkType)
+	return nil
+}
+
+// Convert_v1beta3_GPUTopologyArgs_To_config_GPUTopologyArgs is an autogenerated conversion function.
+func Convert_v1beta3_GPUTopologyArgs_To_config_GPUTopologyArgs(in *GPUTopologyArgs, out *config.GPUTopologyArgs, s conversion.Scope) error {
+	return autoConvert_v1beta3_GPUTopologyArgs_To_config_GPUTopologyArgs(in, out, s)
+}
+
+func autoConvert_config_GPUTopologyArgs_To_v1beta3_GPUTopologyArgs(in *config.GPUTopologyArgs, out *GPUTopologyArgs, s conversion.Scope) error {
+	out.ResourceName = in.ResourceName
+	out.MinimumLinkType = GPULinkType(in.MinimumLinkType)
+	return nil
+}
+
+// Convert_config_GPUTopologyArgs_To_v1beta3_GPUTopologyArgs is an autogenerated conversion function.
+func Convert_config_GPUTopologyArgs_To_v1beta3_GPUTopologyArgs(in *config.GPUTopologyArgs, out *GPUTopologyArgs, s conversion.Scope) error {
+	return autoConvert_config_GPUTopologyArgs_To_v1beta3_GPUTopologyArgs(in, out, s)
+}
+
 func autoConvert_v1beta3_InterPodAffinityArgs_To_config_InterPodAffinityArgs(in *v1beta3.InterPodAffinityArgs, out *config.InterPodAffinityArgs, s conversion.Scope) error {
 	if err := v1.Convert_Pointer_int32_To_int32(&in.HardPodAffinityWeight, &out.HardPodAffinityWeight, s); err != nil {
 		return err
@@ -803,6 +1075,50 @@ func Convert_config_Plugins_To_v1beta3_Plugins(in *config.Plugins, out *v1beta3.
 	return autoConvert_config_Plugins_To_v1beta3_Plugins(in, out, s)
 }
 
+func autoConvert_v1beta3_PodBackoffArgs_To_config_PodBackoffArgs(in *PodBackoffArgs, out *config.PodBackoffArgs, s conversion.Scope) error {
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]config.BackoffPolicy, len(*in))
+		for i := range *in {
+			if err := Convert_v1beta3_BackoffPolicy_To_config_BackoffPolicy(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Policies = nil
+	}
+	out.MaxAttempts = in.MaxAttempts
+	out.SchedulingDeadlineSeconds = in.SchedulingDeadlineSeconds
+	return nil
+}
+
+// Convert_v1beta3_PodBackoffArgs_To_config_PodBackoffArgs is an autogenerated conversion function.
+func Convert_v1beta3_PodBackoffArgs_To_config_PodBackoffArgs(in *PodBackoffArgs, out *config.PodBackoffArgs, s conversion.Scope) error {
+	return autoConvert_v1beta3_PodBackoffArgs_To_config_PodBackoffArgs(in, out, s)
+}
+
+func autoConvert_config_PodBackoffArgs_To_v1beta3_PodBackoffArgs(in *config.PodBackoffArgs, out *PodBackoffArgs, s conversion.Scope) error {
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]BackoffPolicy, len(*in))
+		for i := range *in {
+			if err := Convert_config_BackoffPolicy_To_v1beta3_BackoffPolicy(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Policies = nil
+	}
+	out.MaxAttempts = in.MaxAttempts
+	out.SchedulingDeadlineSeconds = in.SchedulingDeadlineSeconds
+	return nil
+}
+
+// Convert_config_PodBackoffArgs_To_v1beta3_PodBackoffArgs is an autogenerated conversion function.
+func Convert_config_PodBackoffArgs_To_v1beta3_PodBackoffArgs(in *config.PodBackoffArgs, out *PodBackoffArgs, s conversion.Scope) error {
+	return autoConvert_config_PodBackoffArgs_To_v1beta3_PodBackoffArgs(in, out, s)
+}
+
 func autoConvert_v1beta3_PodTopologySpreadArgs_To_config_PodTopologySpreadArgs(in *v1beta3.PodTopologySpreadArgs, out *config.PodTopologySpreadArgs, s conversion.Scope) error {
 	out.DefaultConstraints = *(*[]corev1.TopologySpreadConstraint)(unsafe.Pointer(&in.DefaultConstraints))
 	out.DefaultingType = config.PodTopologySpreadConstraintsDefaulting(in.DefaultingType)
@@ -891,6 +1207,28 @@ func Convert_config_ScoringStrategy_To_v1beta3_ScoringStrategy(in *config.Scorin
 	return autoConvert_config_ScoringStrategy_To_v1beta3_ScoringStrategy(in, out, s)
 }
 
+func autoConvert_v1beta3_TopologyPackingArgs_To_config_TopologyPackingArgs(in *TopologyPackingArgs, out *config.TopologyPackingArgs, s conversion.Scope) error {
+	out.TopologyKeys = *(*[]string)(unsafe.Pointer(&in.TopologyKeys))
+	out.RequiredTopologyKey = in.RequiredTopologyKey
+	return nil
+}
+
+// Convert_v1beta3_TopologyPackingArgs_To_config_TopologyPackingArgs is an autogenerated conversion function.
+func Convert_v1beta3_TopologyPackingArgs_To_config_TopologyPackingArgs(in *TopologyPackingArgs, out *config.TopologyPackingArgs, s conversion.Scope) error {
+	return autoConvert_v1beta3_TopologyPackingArgs_To_config_TopologyPackingArgs(in, out, s)
+}
+
+func autoConvert_config_TopologyPackingArgs_To_v1beta3_TopologyPackingArgs(in *config.TopologyPackingArgs, out *TopologyPackingArgs, s conversion.Scope) error {
+	out.TopologyKeys = *(*[]string)(unsafe.Pointer(&in.TopologyKeys))
+	out.RequiredTopologyKey = in.RequiredTopologyKey
+	return nil
+}
+
+// Convert_config_TopologyPackingArgs_To_v1beta3_TopologyPackingArgs is an autogenerated conversion function.
+func Convert_config_TopologyPackingArgs_To_v1beta3_TopologyPackingArgs(in *config.TopologyPackingArgs, out *TopologyPackingArgs, s conversion.Scope) error {
+	return autoConvert_config_TopologyPackingArgs_To_v1beta3_TopologyPackingArgs(in, out, s)
+}
+
 func autoConvert_v1beta3_UtilizationShapePoint_To_config_UtilizationShapePoint(in *v1beta3.UtilizationShapePoint, out *config.UtilizationShapePoint, s conversion.Scope) error {
 	out.Utilization = in.Utilization
 	out.Score = in.Score
@@ -20,3 +20,342 @@ limitations under the License.
 // Code generated by deepcopy-gen. DO NOT EDIT.
 
 package v1beta3
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	v1beta3 "k8s.io/kube-scheduler/config/v1beta3"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackoffPolicy) DeepCopyInto(out *BackoffPolicy) {
+	*out = *in
+	if in.InitialBackoffSeconds != nil {
+		in, out := &in.InitialBackoffSeconds, &out.InitialBackoffSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxBackoffSeconds != nil {
+		in, out := &in.MaxBackoffSeconds, &out.MaxBackoffSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MinPriority != nil {
+		in, out := &in.MinPriority, &out.MinPriority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxPriority != nil {
+		in, out := &in.MaxPriority, &out.MaxPriority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UnschedulablePlugins != nil {
+		in, out := &in.UnschedulablePlugins, &out.UnschedulablePlugins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackoffPolicy.
+func (in *BackoffPolicy) DeepCopy() *BackoffPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BackoffPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoschedulingArgs) DeepCopyInto(out *CoschedulingArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.PermitWaitingTimeSeconds != nil {
+		in, out := &in.PermitWaitingTimeSeconds, &out.PermitWaitingTimeSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoschedulingArgs.
+func (in *CoschedulingArgs) DeepCopy() *CoschedulingArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(CoschedulingArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CoschedulingArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DefaultPreemptionArgs) DeepCopyInto(out *DefaultPreemptionArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.MinCandidateNodesPercentage != nil {
+		in, out := &in.MinCandidateNodesPercentage, &out.MinCandidateNodesPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinCandidateNodesAbsolute != nil {
+		in, out := &in.MinCandidateNodesAbsolute, &out.MinCandidateNodesAbsolute
+		*out = new(int32)
+		**out = **in
+	}
+	if in.EvictVictims != nil {
+		in, out := &in.EvictVictims, &out.EvictVictims
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CandidateRanking != nil {
+		in, out := &in.CandidateRanking, &out.CandidateRanking
+		*out = make([]CandidateRankingCriterion, len(*in))
+		copy(*out, *in)
+	}
+	if in.LostWorkResources != nil {
+		in, out := &in.LostWorkResources, &out.LostWorkResources
+		*out = make([]v1beta3.ResourceSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodGroupAware != nil {
+		in, out := &in.PodGroupAware, &out.PodGroupAware
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PreemptWholeGroups != nil {
+		in, out := &in.PreemptWholeGroups, &out.PreemptWholeGroups
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ProtectedNamespaces != nil {
+		in, out := &in.ProtectedNamespaces, &out.ProtectedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProtectedPodSelector != nil {
+		in, out := &in.ProtectedPodSelector, &out.ProtectedPodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxEvictionsPerNamespace != nil {
+		in, out := &in.MaxEvictionsPerNamespace, &out.MaxEvictionsPerNamespace
+		*out = new(int32)
+		**out = **in
+	}
+	if in.EvictionWindowSeconds != nil {
+		in, out := &in.EvictionWindowSeconds, &out.EvictionWindowSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MinRuntimeSeconds != nil {
+		in, out := &in.MinRuntimeSeconds, &out.MinRuntimeSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DefaultPreemptionArgs.
+func (in *DefaultPreemptionArgs) DeepCopy() *DefaultPreemptionArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(DefaultPreemptionArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DefaultPreemptionArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DominantResourceFairnessArgs) DeepCopyInto(out *DominantResourceFairnessArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DominantResourceFairnessArgs.
+func (in *DominantResourceFairnessArgs) DeepCopy() *DominantResourceFairnessArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(DominantResourceFairnessArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DominantResourceFairnessArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticQuotaArgs) DeepCopyInto(out *ElasticQuotaArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.MinCandidateNodesPercentage != nil {
+		in, out := &in.MinCandidateNodesPercentage, &out.MinCandidateNodesPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinCandidateNodesAbsolute != nil {
+		in, out := &in.MinCandidateNodesAbsolute, &out.MinCandidateNodesAbsolute
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ProtectedNamespaces != nil {
+		in, out := &in.ProtectedNamespaces, &out.ProtectedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProtectedPodSelector != nil {
+		in, out := &in.ProtectedPodSelector, &out.ProtectedPodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxEvictionsPerNamespace != nil {
+		in, out := &in.MaxEvictionsPerNamespace, &out.MaxEvictionsPerNamespace
+		*out = new(int32)
+		**out = **in
+	}
+	if in.EvictionWindowSeconds != nil {
+		in, out := &in.EvictionWindowSeconds, &out.EvictionWindowSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MinRuntimeSeconds != nil {
+		in, out := &in.MinRuntimeSeconds, &out.MinRuntimeSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticQuotaArgs.
+func (in *ElasticQuotaArgs) DeepCopy() *ElasticQuotaArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticQuotaArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticQuotaArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GPUTopologyArgs) DeepCopyInto(out *GPUTopologyArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GPUTopologyArgs.
+func (in *GPUTopologyArgs) DeepCopy() *GPUTopologyArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(GPUTopologyArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GPUTopologyArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodBackoffArgs) DeepCopyInto(out *PodBackoffArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]BackoffPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodBackoffArgs.
+func (in *PodBackoffArgs) DeepCopy() *PodBackoffArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(PodBackoffArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PodBackoffArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologyPackingArgs) DeepCopyInto(out *TopologyPackingArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.TopologyKeys != nil {
+		in, out := &in.TopologyKeys, &out.TopologyKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyPackingArgs.
+func (in *TopologyPackingArgs) DeepCopy() *TopologyPackingArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologyPackingArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TopologyPackingArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
@@ -30,7 +30,15 @@ import (
 // Public to allow building arbitrary schemes.
 // All generated defaulters are covering - they call all nested defaulters.
 func RegisterDefaults(scheme *runtime.Scheme) error {
-	scheme.AddTypeDefaultingFunc(&v1beta3.DefaultPreemptionArgs{}, func(obj interface{}) { SetObjectDefaults_DefaultPreemptionArgs(obj.(*v1beta3.DefaultPreemptionArgs)) })
+	scheme.AddTypeDefaultingFunc(&CoschedulingArgs{}, func(obj interface{}) { SetObjectDefaults_CoschedulingArgs(obj.(*CoschedulingArgs)) })
+	scheme.AddTypeDefaultingFunc(&DominantResourceFairnessArgs{}, func(obj interface{}) {
+		SetObjectDefaults_DominantResourceFairnessArgs(obj.(*DominantResourceFairnessArgs))
+	})
+	scheme.AddTypeDefaultingFunc(&ElasticQuotaArgs{}, func(obj interface{}) { SetObjectDefaults_ElasticQuotaArgs(obj.(*ElasticQuotaArgs)) })
+	scheme.AddTypeDefaultingFunc(&GPUTopologyArgs{}, func(obj interface{}) { SetObjectDefaults_GPUTopologyArgs(obj.(*GPUTopologyArgs)) })
+	scheme.AddTypeDefaultingFunc(&PodBackoffArgs{}, func(obj interface{}) { SetObjectDefaults_PodBackoffArgs(obj.(*PodBackoffArgs)) })
+	scheme.AddTypeDefaultingFunc(&TopologyPackingArgs{}, func(obj interface{}) { SetObjectDefaults_TopologyPackingArgs(obj.(*TopologyPackingArgs)) })
+	scheme.AddTypeDefaultingFunc(&DefaultPreemptionArgs{}, func(obj interface{}) { SetObjectDefaults_DefaultPreemptionArgs(obj.(*DefaultPreemptionArgs)) })
 	scheme.AddTypeDefaultingFunc(&v1beta3.InterPodAffinityArgs{}, func(obj interface{}) { SetObjectDefaults_InterPodAffinityArgs(obj.(*v1beta3.InterPodAffinityArgs)) })
 	scheme.AddTypeDefaultingFunc(&v1beta3.KubeSchedulerConfiguration{}, func(obj interface{}) {
 		SetObjectDefaults_KubeSchedulerConfiguration(obj.(*v1beta3.KubeSchedulerConfiguration))
@@ -44,10 +52,26 @@ func RegisterDefaults(scheme *runtime.Scheme) error {
 	return nil
 }
 
-func SetObjectDefaults_DefaultPreemptionArgs(in *v1beta3.DefaultPreemptionArgs) {
+func SetObjectDefaults_CoschedulingArgs(in *CoschedulingArgs) {
+	SetDefaults_CoschedulingArgs(in)
+}
+
+func SetObjectDefaults_DefaultPreemptionArgs(in *DefaultPreemptionArgs) {
 	SetDefaults_DefaultPreemptionArgs(in)
 }
 
+func SetObjectDefaults_DominantResourceFairnessArgs(in *DominantResourceFairnessArgs) {
+	SetDefaults_DominantResourceFairnessArgs(in)
+}
+
+func SetObjectDefaults_ElasticQuotaArgs(in *ElasticQuotaArgs) {
+	SetDefaults_ElasticQuotaArgs(in)
+}
+
+func SetObjectDefaults_GPUTopologyArgs(in *GPUTopologyArgs) {
+	SetDefaults_GPUTopologyArgs(in)
+}
+
 func SetObjectDefaults_InterPodAffinityArgs(in *v1beta3.InterPodAffinityArgs) {
 	SetDefaults_InterPodAffinityArgs(in)
 }
@@ -64,10 +88,21 @@ func SetObjectDefaults_NodeResourcesFitArgs(in *v1beta3.NodeResourcesFitArgs) {
 	SetDefaults_NodeResourcesFitArgs(in)
 }
 
+func SetObjectDefaults_PodBackoffArgs(in *PodBackoffArgs) {
+	for i := range in.Policies {
+		a := &in.Policies[i]
+		SetDefaults_BackoffPolicy(a)
+	}
+}
+
 func SetObjectDefaults_PodTopologySpreadArgs(in *v1beta3.PodTopologySpreadArgs) {
 	SetDefaults_PodTopologySpreadArgs(in)
 }
 
+func SetObjectDefaults_TopologyPackingArgs(in *TopologyPackingArgs) {
+	SetDefaults_TopologyPackingArgs(in)
+}
+
 func SetObjectDefaults_VolumeBindingArgs(in *v1beta3.VolumeBindingArgs) {
 	SetDefaults_VolumeBindingArgs(in)
 }
@@ -174,12 +174,18 @@ func validateKubeSchedulerProfile(path *field.Path, apiVersion string, profile *
 func validatePluginConfig(path *field.Path, apiVersion string, profile *config.KubeSchedulerProfile) []error {
 	var errs []error
 	m := map[string]interface{}{
+		"Coscheduling":                    ValidateCoschedulingArgs,
 		"DefaultPreemption":               ValidateDefaultPreemptionArgs,
+		"DominantResourceFairness":        ValidateDominantResourceFairnessArgs,
+		"ElasticQuota":                    ValidateElasticQuotaArgs,
+		"GPUTopology":                     ValidateGPUTopologyArgs,
 		"InterPodAffinity":                ValidateInterPodAffinityArgs,
 		"NodeAffinity":                    ValidateNodeAffinityArgs,
 		"NodeResourcesBalancedAllocation": ValidateNodeResourcesBalancedAllocationArgs,
 		"NodeResourcesFitArgs":            ValidateNodeResourcesFitArgs,
+		"PodBackoff":                      ValidatePodBackoffArgs,
 		"PodTopologySpread":               ValidatePodTopologySpreadArgs,
+		"TopologyPacking":                 ValidateTopologyPackingArgs,
 		"VolumeBinding":                   ValidateVolumeBindingArgs,
 	}
 
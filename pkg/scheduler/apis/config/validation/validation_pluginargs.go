@@ -22,6 +22,7 @@ import (
 
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
 	v1 "k8s.io/api/core/v1"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -31,6 +32,14 @@ import (
 	"k8s.io/kubernetes/pkg/features"
 )
 
+// ValidateCoschedulingArgs validates that CoschedulingArgs are correct.
+func ValidateCoschedulingArgs(path *field.Path, args *config.CoschedulingArgs) error {
+	if args.PermitWaitingTimeSeconds <= 0 {
+		return field.Invalid(path.Child("permitWaitingTimeSeconds"), args.PermitWaitingTimeSeconds, "should be greater than 0")
+	}
+	return nil
+}
+
 // ValidateDefaultPreemptionArgs validates that DefaultPreemptionArgs are correct.
 func ValidateDefaultPreemptionArgs(path *field.Path, args *config.DefaultPreemptionArgs) error {
 	var allErrs field.ErrorList
@@ -47,9 +56,72 @@ func ValidateDefaultPreemptionArgs(path *field.Path, args *config.DefaultPreempt
 			field.Invalid(percentagePath, args.MinCandidateNodesPercentage, "cannot be zero at the same time as minCandidateNodesAbsolute"),
 			field.Invalid(absolutePath, args.MinCandidateNodesAbsolute, "cannot be zero at the same time as minCandidateNodesPercentage"))
 	}
+	allErrs = append(allErrs, validateCandidateRanking(args.CandidateRanking, path.Child("candidateRanking"))...)
+	lostWorkPath := path.Child("lostWorkResources")
+	seenResources := sets.NewString()
+	for i, resource := range args.LostWorkResources {
+		if seenResources.Has(resource.Name) {
+			allErrs = append(allErrs, field.Duplicate(lostWorkPath.Index(i).Child("name"), resource.Name))
+		} else {
+			seenResources.Insert(resource.Name)
+		}
+	}
+	allErrs = append(allErrs, validateResources(args.LostWorkResources, lostWorkPath)...)
+	allErrs = append(allErrs, validatePreemptionPolicy(args, path)...)
 	return allErrs.ToAggregate()
 }
 
+// validatePreemptionPolicy validates the arguments that protect pods from
+// preemption.
+func validatePreemptionPolicy(args *config.DefaultPreemptionArgs, path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	namespacesPath := path.Child("protectedNamespaces")
+	seenNamespaces := sets.NewString()
+	for i, ns := range args.ProtectedNamespaces {
+		for _, msg := range apimachineryvalidation.ValidateNamespaceName(ns, false) {
+			allErrs = append(allErrs, field.Invalid(namespacesPath.Index(i), ns, msg))
+		}
+		if seenNamespaces.Has(ns) {
+			allErrs = append(allErrs, field.Duplicate(namespacesPath.Index(i), ns))
+		}
+		seenNamespaces.Insert(ns)
+	}
+	if args.ProtectedPodSelector != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(args.ProtectedPodSelector, path.Child("protectedPodSelector"))...)
+	}
+	if args.MaxEvictionsPerNamespace < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("maxEvictionsPerNamespace"), args.MaxEvictionsPerNamespace, "must be greater than or equal to 0"))
+	}
+	if args.MaxEvictionsPerNamespace > 0 && args.EvictionWindowSeconds <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("evictionWindowSeconds"), args.EvictionWindowSeconds, "must be greater than 0 when maxEvictionsPerNamespace is set"))
+	}
+	if args.MinRuntimeSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("minRuntimeSeconds"), args.MinRuntimeSeconds, "must be greater than or equal to 0"))
+	}
+	return allErrs
+}
+
+// validateCandidateRanking validates that the candidate ranking criteria are
+// known and listed once.
+func validateCandidateRanking(criteria []config.CandidateRankingCriterion, p *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	supported := sets.NewString(string(config.RankByPDBViolations), string(config.RankByHighestPriority),
+		string(config.RankBySumOfPriorities), string(config.RankByNumVictims), string(config.RankByLatestStartTime),
+		string(config.RankByLostWork))
+	seen := sets.NewString()
+	for i, c := range criteria {
+		if !supported.Has(string(c)) {
+			allErrs = append(allErrs, field.NotSupported(p.Index(i), c, supported.List()))
+			continue
+		}
+		if seen.Has(string(c)) {
+			allErrs = append(allErrs, field.Duplicate(p.Index(i), c))
+		}
+		seen.Insert(string(c))
+	}
+	return allErrs
+}
+
 // validateMinCandidateNodesPercentage validates that
 // minCandidateNodesPercentage is within the allowed range.
 func validateMinCandidateNodesPercentage(minCandidateNodesPercentage int32, p *field.Path) *field.Error {
@@ -68,6 +140,69 @@ func validateMinCandidateNodesAbsolute(minCandidateNodesAbsolute int32, p *field
 	return nil
 }
 
+// ValidateDominantResourceFairnessArgs validates that
+// DominantResourceFairnessArgs are correct.
+func ValidateDominantResourceFairnessArgs(path *field.Path, args *config.DominantResourceFairnessArgs) error {
+	var allErrs field.ErrorList
+	resPath := path.Child("resources")
+	if len(args.Resources) == 0 {
+		allErrs = append(allErrs, field.Required(resPath, "can not be empty"))
+	}
+	seen := sets.NewString()
+	for i, name := range args.Resources {
+		if seen.Has(name) {
+			allErrs = append(allErrs, field.Duplicate(resPath.Index(i), name))
+			continue
+		}
+		seen.Insert(name)
+		allErrs = append(allErrs, metav1validation.ValidateLabelName(name, resPath.Index(i))...)
+	}
+	return allErrs.ToAggregate()
+}
+
+// ValidateElasticQuotaArgs validates that ElasticQuotaArgs are correct.
+func ValidateElasticQuotaArgs(path *field.Path, args *config.ElasticQuotaArgs) error {
+	var allErrs field.ErrorList
+	percentagePath := path.Child("minCandidateNodesPercentage")
+	absolutePath := path.Child("minCandidateNodesAbsolute")
+	if err := validateMinCandidateNodesPercentage(args.MinCandidateNodesPercentage, percentagePath); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateMinCandidateNodesAbsolute(args.MinCandidateNodesAbsolute, absolutePath); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if args.MinCandidateNodesPercentage == 0 && args.MinCandidateNodesAbsolute == 0 {
+		allErrs = append(allErrs,
+			field.Invalid(percentagePath, args.MinCandidateNodesPercentage, "cannot be zero at the same time as minCandidateNodesAbsolute"),
+			field.Invalid(absolutePath, args.MinCandidateNodesAbsolute, "cannot be zero at the same time as minCandidateNodesPercentage"))
+	}
+	allErrs = append(allErrs, validatePreemptionPolicy(&config.DefaultPreemptionArgs{
+		ProtectedNamespaces:      args.ProtectedNamespaces,
+		ProtectedPodSelector:     args.ProtectedPodSelector,
+		MaxEvictionsPerNamespace: args.MaxEvictionsPerNamespace,
+		EvictionWindowSeconds:    args.EvictionWindowSeconds,
+		MinRuntimeSeconds:        args.MinRuntimeSeconds,
+	}, path)...)
+	return allErrs.ToAggregate()
+}
+
+// ValidateGPUTopologyArgs validates that GPUTopologyArgs are correct.
+func ValidateGPUTopologyArgs(path *field.Path, args *config.GPUTopologyArgs) error {
+	var allErrs field.ErrorList
+	resPath := path.Child("resourceName")
+	if len(args.ResourceName) == 0 {
+		allErrs = append(allErrs, field.Required(resPath, "can not be empty"))
+	} else {
+		allErrs = append(allErrs, metav1validation.ValidateLabelName(args.ResourceName, resPath)...)
+	}
+	supportedLinkTypes := sets.NewString(string(config.GPULinkNVLink), string(config.GPULinkPCIeSwitch),
+		string(config.GPULinkNUMANode), string(config.GPULinkSystem))
+	if !supportedLinkTypes.Has(string(args.MinimumLinkType)) {
+		allErrs = append(allErrs, field.NotSupported(path.Child("minimumLinkType"), args.MinimumLinkType, supportedLinkTypes.List()))
+	}
+	return allErrs.ToAggregate()
+}
+
 // ValidateInterPodAffinityArgs validates that InterPodAffinityArgs are correct.
 func ValidateInterPodAffinityArgs(path *field.Path, args *config.InterPodAffinityArgs) error {
 	return validateHardPodAffinityWeight(path.Child("hardPodAffinityWeight"), args.HardPodAffinityWeight)
@@ -164,6 +299,64 @@ func validateConstraintNotRepeat(path *field.Path, constraints []v1.TopologySpre
 	return nil
 }
 
+// ValidatePodBackoffArgs validates that PodBackoffArgs are correct.
+func ValidatePodBackoffArgs(path *field.Path, args *config.PodBackoffArgs) error {
+	var allErrs field.ErrorList
+	supportedTypes := []string{string(config.ExponentialBackoff), string(config.LinearBackoff), string(config.FixedBackoff)}
+	for i, policy := range args.Policies {
+		policyPath := path.Child("policies").Index(i)
+		if !sets.NewString(supportedTypes...).Has(string(policy.Type)) {
+			allErrs = append(allErrs, field.NotSupported(policyPath.Child("type"), policy.Type, supportedTypes))
+		}
+		if policy.InitialBackoffSeconds <= 0 {
+			allErrs = append(allErrs, field.Invalid(policyPath.Child("initialBackoffSeconds"), policy.InitialBackoffSeconds, "should be greater than 0"))
+		}
+		if policy.Type != config.FixedBackoff && policy.MaxBackoffSeconds < policy.InitialBackoffSeconds {
+			allErrs = append(allErrs, field.Invalid(policyPath.Child("maxBackoffSeconds"), policy.MaxBackoffSeconds, "should be greater than or equal to initialBackoffSeconds"))
+		}
+		if policy.JitterPercent < 0 || policy.JitterPercent > 100 {
+			allErrs = append(allErrs, field.Invalid(policyPath.Child("jitterPercent"), policy.JitterPercent, "not in valid range [0, 100]"))
+		}
+		if policy.MinPriority != nil && policy.MaxPriority != nil && *policy.MinPriority > *policy.MaxPriority {
+			allErrs = append(allErrs, field.Invalid(policyPath.Child("maxPriority"), *policy.MaxPriority, "should be greater than or equal to minPriority"))
+		}
+		for j, name := range policy.UnschedulablePlugins {
+			if len(name) == 0 {
+				allErrs = append(allErrs, field.Required(policyPath.Child("unschedulablePlugins").Index(j), "can not be empty"))
+			}
+		}
+	}
+	if args.MaxAttempts < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("maxAttempts"), args.MaxAttempts, "not in valid range [0, inf)"))
+	}
+	if args.SchedulingDeadlineSeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("schedulingDeadlineSeconds"), args.SchedulingDeadlineSeconds, "not in valid range [0, inf)"))
+	}
+	return allErrs.ToAggregate()
+}
+
+// ValidateTopologyPackingArgs validates that TopologyPackingArgs are correct.
+func ValidateTopologyPackingArgs(path *field.Path, args *config.TopologyPackingArgs) error {
+	var allErrs field.ErrorList
+	keysPath := path.Child("topologyKeys")
+	if len(args.TopologyKeys) == 0 {
+		allErrs = append(allErrs, field.Required(keysPath, "can not be empty"))
+	}
+	seen := sets.NewString()
+	for i, key := range args.TopologyKeys {
+		if seen.Has(key) {
+			allErrs = append(allErrs, field.Duplicate(keysPath.Index(i), key))
+			continue
+		}
+		seen.Insert(key)
+		allErrs = append(allErrs, validateTopologyKey(keysPath.Index(i), key)...)
+	}
+	if len(args.RequiredTopologyKey) != 0 && !seen.Has(args.RequiredTopologyKey) {
+		allErrs = append(allErrs, field.NotSupported(path.Child("requiredTopologyKey"), args.RequiredTopologyKey, args.TopologyKeys))
+	}
+	return allErrs.ToAggregate()
+}
+
 func validateFunctionShape(shape []config.UtilizationShapePoint, path *field.Path) field.ErrorList {
 	const (
 		minUtilization = 0
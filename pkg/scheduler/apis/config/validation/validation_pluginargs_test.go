@@ -38,6 +38,203 @@ var (
 	ignoreBadValueDetail = cmpopts.IgnoreFields(field.Error{}, "BadValue", "Detail")
 )
 
+func TestValidateCoschedulingArgs(t *testing.T) {
+	cases := map[string]struct {
+		args    config.CoschedulingArgs
+		wantErr error
+	}{
+		"valid config": {
+			args: config.CoschedulingArgs{
+				PermitWaitingTimeSeconds: 60,
+			},
+		},
+		"zero waiting time": {
+			args: config.CoschedulingArgs{
+				PermitWaitingTimeSeconds: 0,
+			},
+			wantErr: &field.Error{
+				Type:  field.ErrorTypeInvalid,
+				Field: "permitWaitingTimeSeconds",
+			},
+		},
+		"negative waiting time": {
+			args: config.CoschedulingArgs{
+				PermitWaitingTimeSeconds: -1,
+			},
+			wantErr: &field.Error{
+				Type:  field.ErrorTypeInvalid,
+				Field: "permitWaitingTimeSeconds",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateCoschedulingArgs(nil, &tc.args)
+			if diff := cmp.Diff(tc.wantErr, err, ignoreBadValueDetail); diff != "" {
+				t.Errorf("ValidateCoschedulingArgs returned err (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateDominantResourceFairnessArgs(t *testing.T) {
+	cases := map[string]struct {
+		args    config.DominantResourceFairnessArgs
+		wantErr error
+	}{
+		"valid config": {
+			args: config.DominantResourceFairnessArgs{
+				Resources: []string{"cpu", "memory", "nvidia.com/gpu"},
+			},
+		},
+		"empty resources": {
+			wantErr: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeRequired,
+					Field: "resources",
+				},
+			}.ToAggregate(),
+		},
+		"duplicate and invalid resources": {
+			args: config.DominantResourceFairnessArgs{
+				Resources: []string{"cpu", "cpu", "nvidia.com/gpu/"},
+			},
+			wantErr: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeDuplicate,
+					Field: "resources[1]",
+				},
+				&field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "resources[2]",
+				},
+			}.ToAggregate(),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateDominantResourceFairnessArgs(nil, &tc.args)
+			if diff := cmp.Diff(tc.wantErr, err, ignoreBadValueDetail); diff != "" {
+				t.Errorf("ValidateDominantResourceFairnessArgs returned err (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateElasticQuotaArgs(t *testing.T) {
+	cases := map[string]struct {
+		args    config.ElasticQuotaArgs
+		wantErr error
+	}{
+		"valid args (default)": {
+			args: config.ElasticQuotaArgs{
+				MinCandidateNodesPercentage: 10,
+				MinCandidateNodesAbsolute:   100,
+			},
+		},
+		"both zero": {
+			args: config.ElasticQuotaArgs{},
+			wantErr: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "minCandidateNodesPercentage",
+				},
+				&field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "minCandidateNodesAbsolute",
+				},
+			}.ToAggregate(),
+		},
+		"minCandidateNodesPercentage over 100": {
+			args: config.ElasticQuotaArgs{
+				MinCandidateNodesPercentage: 900,
+				MinCandidateNodesAbsolute:   100,
+			},
+			wantErr: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "minCandidateNodesPercentage",
+				},
+			}.ToAggregate(),
+		},
+		"invalid preemption policy": {
+			args: config.ElasticQuotaArgs{
+				MinCandidateNodesPercentage: 10,
+				MinCandidateNodesAbsolute:   100,
+				ProtectedNamespaces:         []string{"kube-system", "kube-system"},
+				MaxEvictionsPerNamespace:    5,
+			},
+			wantErr: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeDuplicate,
+					Field: "protectedNamespaces[1]",
+				},
+				&field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "evictionWindowSeconds",
+				},
+			}.ToAggregate(),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateElasticQuotaArgs(nil, &tc.args)
+			if diff := cmp.Diff(tc.wantErr, err, ignoreBadValueDetail); diff != "" {
+				t.Errorf("ValidateElasticQuotaArgs returned err (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateGPUTopologyArgs(t *testing.T) {
+	cases := map[string]struct {
+		args    config.GPUTopologyArgs
+		wantErr error
+	}{
+		"valid config": {
+			args: config.GPUTopologyArgs{
+				ResourceName:    "nvidia.com/gpu",
+				MinimumLinkType: config.GPULinkPCIeSwitch,
+			},
+		},
+		"empty resource name": {
+			args: config.GPUTopologyArgs{
+				MinimumLinkType: config.GPULinkNUMANode,
+			},
+			wantErr: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeRequired,
+					Field: "resourceName",
+				},
+			}.ToAggregate(),
+		},
+		"unknown link type": {
+			args: config.GPUTopologyArgs{
+				ResourceName:    "nvidia.com/gpu",
+				MinimumLinkType: "InfiniBand",
+			},
+			wantErr: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeNotSupported,
+					Field: "minimumLinkType",
+				},
+			}.ToAggregate(),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateGPUTopologyArgs(nil, &tc.args)
+			if diff := cmp.Diff(tc.wantErr, err, ignoreBadValueDetail); diff != "" {
+				t.Errorf("ValidateGPUTopologyArgs returned err (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestValidateDefaultPreemptionArgs(t *testing.T) {
 	cases := map[string]struct {
 		args     config.DefaultPreemptionArgs
@@ -115,6 +312,114 @@ func TestValidateDefaultPreemptionArgs(t *testing.T) {
 				},
 			},
 		},
+		"valid candidate ranking": {
+			args: config.DefaultPreemptionArgs{
+				MinCandidateNodesPercentage: 10,
+				MinCandidateNodesAbsolute:   100,
+				CandidateRanking:            []config.CandidateRankingCriterion{config.RankByPDBViolations, config.RankByHighestPriority, config.RankByLostWork},
+				LostWorkResources:           []config.ResourceSpec{{Name: "nvidia.com/gpu", Weight: 10}, {Name: "cpu", Weight: 1}},
+			},
+		},
+		"unknown and repeated candidate ranking criteria": {
+			args: config.DefaultPreemptionArgs{
+				MinCandidateNodesPercentage: 10,
+				MinCandidateNodesAbsolute:   100,
+				CandidateRanking:            []config.CandidateRankingCriterion{config.RankByLostWork, "Cheapest", config.RankByLostWork},
+			},
+			wantErrs: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeNotSupported,
+					Field: "candidateRanking[1]",
+				},
+				&field.Error{
+					Type:  field.ErrorTypeDuplicate,
+					Field: "candidateRanking[2]",
+				},
+			},
+		},
+		"invalid lost work resources": {
+			args: config.DefaultPreemptionArgs{
+				MinCandidateNodesPercentage: 10,
+				MinCandidateNodesAbsolute:   100,
+				LostWorkResources:           []config.ResourceSpec{{Name: "cpu", Weight: 1}, {Name: "cpu", Weight: 0}},
+			},
+			wantErrs: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeDuplicate,
+					Field: "lostWorkResources[1].name",
+				},
+				&field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "lostWorkResources[1].weight",
+				},
+			},
+		},
+		"valid preemption policy": {
+			args: config.DefaultPreemptionArgs{
+				MinCandidateNodesPercentage: 10,
+				MinCandidateNodesAbsolute:   100,
+				ProtectedNamespaces:         []string{"kube-system"},
+				ProtectedPodSelector:        &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "critical"}},
+				MaxEvictionsPerNamespace:    5,
+				EvictionWindowSeconds:       600,
+				MinRuntimeSeconds:           300,
+			},
+		},
+		"invalid protected pods": {
+			args: config.DefaultPreemptionArgs{
+				MinCandidateNodesPercentage: 10,
+				MinCandidateNodesAbsolute:   100,
+				ProtectedNamespaces:         []string{"kube-system", "Not_A_Namespace", "kube-system"},
+				ProtectedPodSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: metav1.LabelSelectorOpIn}},
+				},
+			},
+			wantErrs: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "protectedNamespaces[1]",
+				},
+				&field.Error{
+					Type:  field.ErrorTypeDuplicate,
+					Field: "protectedNamespaces[2]",
+				},
+				&field.Error{
+					Type:  field.ErrorTypeRequired,
+					Field: "protectedPodSelector.matchExpressions[0].values",
+				},
+			},
+		},
+		"invalid eviction budget and min runtime": {
+			args: config.DefaultPreemptionArgs{
+				MinCandidateNodesPercentage: 10,
+				MinCandidateNodesAbsolute:   100,
+				MaxEvictionsPerNamespace:    3,
+				MinRuntimeSeconds:           -1,
+			},
+			wantErrs: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "evictionWindowSeconds",
+				},
+				&field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "minRuntimeSeconds",
+				},
+			},
+		},
+		"negative maxEvictionsPerNamespace": {
+			args: config.DefaultPreemptionArgs{
+				MinCandidateNodesPercentage: 10,
+				MinCandidateNodesAbsolute:   100,
+				MaxEvictionsPerNamespace:    -1,
+			},
+			wantErrs: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "maxEvictionsPerNamespace",
+				},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -358,6 +663,179 @@ func TestValidatePodTopologySpreadArgs(t *testing.T) {
 	}
 }
 
+func TestValidatePodBackoffArgs(t *testing.T) {
+	lowPriority, highPriority := int32(0), int32(1000)
+	cases := map[string]struct {
+		args    config.PodBackoffArgs
+		wantErr error
+	}{
+		"valid config": {
+			args: config.PodBackoffArgs{
+				Policies: []config.BackoffPolicy{
+					{
+						Type:                  config.FixedBackoff,
+						InitialBackoffSeconds: 30,
+						JitterPercent:         20,
+						UnschedulablePlugins:  []string{"Coscheduling", "ElasticQuota"},
+					},
+					{
+						Type:                  config.LinearBackoff,
+						InitialBackoffSeconds: 1,
+						MaxBackoffSeconds:     5,
+						MinPriority:           &lowPriority,
+						MaxPriority:           &highPriority,
+					},
+				},
+				MaxAttempts:               20,
+				SchedulingDeadlineSeconds: 3600,
+			},
+		},
+		"no policies": {},
+		"negative limits": {
+			args: config.PodBackoffArgs{
+				MaxAttempts:               -1,
+				SchedulingDeadlineSeconds: -1,
+			},
+			wantErr: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "maxAttempts",
+				},
+				&field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "schedulingDeadlineSeconds",
+				},
+			}.ToAggregate(),
+		},
+		"invalid type and backoffs": {
+			args: config.PodBackoffArgs{
+				Policies: []config.BackoffPolicy{
+					{
+						Type:                  "Random",
+						InitialBackoffSeconds: 0,
+						MaxBackoffSeconds:     -1,
+					},
+				},
+			},
+			wantErr: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeNotSupported,
+					Field: "policies[0].type",
+				},
+				&field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "policies[0].initialBackoffSeconds",
+				},
+				&field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "policies[0].maxBackoffSeconds",
+				},
+			}.ToAggregate(),
+		},
+		"invalid jitter, priorities and plugins": {
+			args: config.PodBackoffArgs{
+				Policies: []config.BackoffPolicy{
+					{
+						Type:                  config.ExponentialBackoff,
+						InitialBackoffSeconds: 1,
+						MaxBackoffSeconds:     10,
+					},
+					{
+						Type:                  config.ExponentialBackoff,
+						InitialBackoffSeconds: 1,
+						MaxBackoffSeconds:     10,
+						JitterPercent:         101,
+						MinPriority:           &highPriority,
+						MaxPriority:           &lowPriority,
+						UnschedulablePlugins:  []string{""},
+					},
+				},
+			},
+			wantErr: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "policies[1].jitterPercent",
+				},
+				&field.Error{
+					Type:  field.ErrorTypeInvalid,
+					Field: "policies[1].maxPriority",
+				},
+				&field.Error{
+					Type:  field.ErrorTypeRequired,
+					Field: "policies[1].unschedulablePlugins[0]",
+				},
+			}.ToAggregate(),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidatePodBackoffArgs(nil, &tc.args)
+			if diff := cmp.Diff(tc.wantErr, err, ignoreBadValueDetail); diff != "" {
+				t.Errorf("ValidatePodBackoffArgs returned err (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateTopologyPackingArgs(t *testing.T) {
+	cases := map[string]struct {
+		args    config.TopologyPackingArgs
+		wantErr error
+	}{
+		"valid config": {
+			args: config.TopologyPackingArgs{
+				TopologyKeys:        []string{"topology.sched.dev/block", "topology.sched.dev/rack", "kubernetes.io/hostname"},
+				RequiredTopologyKey: "topology.sched.dev/block",
+			},
+		},
+		"empty topology keys": {
+			wantErr: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeRequired,
+					Field: "topologyKeys",
+				},
+			}.ToAggregate(),
+		},
+		"duplicate and empty topology keys": {
+			args: config.TopologyPackingArgs{
+				TopologyKeys: []string{"rack", "rack", ""},
+			},
+			wantErr: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeDuplicate,
+					Field: "topologyKeys[1]",
+				},
+				&field.Error{
+					Type:  field.ErrorTypeRequired,
+					Field: "topologyKeys[2]",
+				},
+			}.ToAggregate(),
+		},
+		"required topology key not in the hierarchy": {
+			args: config.TopologyPackingArgs{
+				TopologyKeys:        []string{"rack", "kubernetes.io/hostname"},
+				RequiredTopologyKey: "zone",
+			},
+			wantErr: field.ErrorList{
+				&field.Error{
+					Type:  field.ErrorTypeNotSupported,
+					Field: "requiredTopologyKey",
+				},
+			}.ToAggregate(),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateTopologyPackingArgs(nil, &tc.args)
+			if diff := cmp.Diff(tc.wantErr, err, ignoreBadValueDetail); diff != "" {
+				t.Errorf("ValidateTopologyPackingArgs returned err (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestValidateNodeResourcesBalancedAllocationArgs(t *testing.T) {
 	cases := map[string]struct {
 		args     *config.NodeResourcesBalancedAllocationArgs
@@ -23,13 +23,90 @@ package config
 
 import (
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackoffPolicy) DeepCopyInto(out *BackoffPolicy) {
+	*out = *in
+	if in.MinPriority != nil {
+		in, out := &in.MinPriority, &out.MinPriority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxPriority != nil {
+		in, out := &in.MaxPriority, &out.MaxPriority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UnschedulablePlugins != nil {
+		in, out := &in.UnschedulablePlugins, &out.UnschedulablePlugins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackoffPolicy.
+func (in *BackoffPolicy) DeepCopy() *BackoffPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BackoffPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoschedulingArgs) DeepCopyInto(out *CoschedulingArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoschedulingArgs.
+func (in *CoschedulingArgs) DeepCopy() *CoschedulingArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(CoschedulingArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CoschedulingArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DefaultPreemptionArgs) DeepCopyInto(out *DefaultPreemptionArgs) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
+	if in.CandidateRanking != nil {
+		in, out := &in.CandidateRanking, &out.CandidateRanking
+		*out = make([]CandidateRankingCriterion, len(*in))
+		copy(*out, *in)
+	}
+	if in.LostWorkResources != nil {
+		in, out := &in.LostWorkResources, &out.LostWorkResources
+		*out = make([]ResourceSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProtectedNamespaces != nil {
+		in, out := &in.ProtectedNamespaces, &out.ProtectedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProtectedPodSelector != nil {
+		in, out := &in.ProtectedPodSelector, &out.ProtectedPodSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -51,6 +128,71 @@ func (in *DefaultPreemptionArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DominantResourceFairnessArgs) DeepCopyInto(out *DominantResourceFairnessArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DominantResourceFairnessArgs.
+func (in *DominantResourceFairnessArgs) DeepCopy() *DominantResourceFairnessArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(DominantResourceFairnessArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DominantResourceFairnessArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticQuotaArgs) DeepCopyInto(out *ElasticQuotaArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.ProtectedNamespaces != nil {
+		in, out := &in.ProtectedNamespaces, &out.ProtectedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProtectedPodSelector != nil {
+		in, out := &in.ProtectedPodSelector, &out.ProtectedPodSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticQuotaArgs.
+func (in *ElasticQuotaArgs) DeepCopy() *ElasticQuotaArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticQuotaArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticQuotaArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Extender) DeepCopyInto(out *Extender) {
 	*out = *in
@@ -125,6 +267,31 @@ func (in *ExtenderTLSConfig) DeepCopy() *ExtenderTLSConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GPUTopologyArgs) DeepCopyInto(out *GPUTopologyArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GPUTopologyArgs.
+func (in *GPUTopologyArgs) DeepCopy() *GPUTopologyArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(GPUTopologyArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GPUTopologyArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InterPodAffinityArgs) DeepCopyInto(out *InterPodAffinityArgs) {
 	*out = *in
@@ -409,6 +576,38 @@ func (in *Plugins) DeepCopy() *Plugins {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodBackoffArgs) DeepCopyInto(out *PodBackoffArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]BackoffPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodBackoffArgs.
+func (in *PodBackoffArgs) DeepCopy() *PodBackoffArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(PodBackoffArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PodBackoffArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodTopologySpreadArgs) DeepCopyInto(out *PodTopologySpreadArgs) {
 	*out = *in
@@ -504,6 +703,36 @@ func (in *ScoringStrategy) DeepCopy() *ScoringStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologyPackingArgs) DeepCopyInto(out *TopologyPackingArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.TopologyKeys != nil {
+		in, out := &in.TopologyKeys, &out.TopologyKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyPackingArgs.
+func (in *TopologyPackingArgs) DeepCopy() *TopologyPackingArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologyPackingArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TopologyPackingArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UtilizationShapePoint) DeepCopyInto(out *UtilizationShapePoint) {
 	*out = *in
@@ -0,0 +1,225 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PodGroupLabel is the label a pod carries to declare the pod group it
+	// belongs to. Its value is the name of a PodGroup in the pod's namespace.
+	PodGroupLabel = "pod-group.scheduling.sched.dev"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodGroup is a collection of pods that must be scheduled together. Pods
+// join a group by setting the PodGroupLabel to the name of the group.
+type PodGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the scheduling requirements of the group.
+	Spec PodGroupSpec `json:"spec,omitempty"`
+
+	// Status reports the scheduling progress of the group. It's maintained
+	// by the scheduler.
+	// +optional
+	Status PodGroupStatus `json:"status,omitempty"`
+}
+
+// PodGroupSpec represents the scheduling requirements of a pod group.
+type PodGroupSpec struct {
+	// MinMember is the minimum number of members that must be scheduled
+	// together. If fewer members can be placed, none of them is bound.
+	MinMember int32 `json:"minMember,omitempty"`
+
+	// MinResources is the minimum amount of resources the cluster must have
+	// available to run MinMember pods of the group. Members are not
+	// considered for scheduling while the cluster can't provide it.
+	// +optional
+	MinResources v1.ResourceList `json:"minResources,omitempty"`
+
+	// ScheduleTimeoutSeconds is the maximum time members wait for the rest
+	// of the group once they have been reserved. When not set, the timeout
+	// configured for the Coscheduling plugin is used.
+	// +optional
+	ScheduleTimeoutSeconds *int32 `json:"scheduleTimeoutSeconds,omitempty"`
+}
+
+// PodGroupPhase is the scheduling phase of a pod group.
+type PodGroupPhase string
+
+const (
+	// PodGroupPending means no member of the group has been attempted yet.
+	PodGroupPending PodGroupPhase = "Pending"
+	// PodGroupScheduling means members of the group are being scheduled but
+	// fewer than MinMember are bound.
+	PodGroupScheduling PodGroupPhase = "Scheduling"
+	// PodGroupScheduled means at least MinMember members are bound.
+	PodGroupScheduled PodGroupPhase = "Scheduled"
+	// PodGroupFailed means the last scheduling attempt of a member failed
+	// before MinMember members could be bound.
+	PodGroupFailed PodGroupPhase = "Failed"
+)
+
+// PodGroupStatus represents the scheduling progress of a pod group.
+type PodGroupStatus struct {
+	// Phase is the scheduling phase of the group.
+	// +optional
+	Phase PodGroupPhase `json:"phase,omitempty"`
+
+	// Scheduled is the number of members bound to a node.
+	// +optional
+	Scheduled int32 `json:"scheduled,omitempty"`
+
+	// Pending is the number of members not bound to a node yet.
+	// +optional
+	Pending int32 `json:"pending,omitempty"`
+
+	// Failed is the number of pending members whose last scheduling attempt
+	// failed.
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+
+	// ScheduleStartTime is the time the scheduler first attempted a member
+	// of the group.
+	// +optional
+	ScheduleStartTime *metav1.Time `json:"scheduleStartTime,omitempty"`
+
+	// Message is a human readable explanation of the last failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PodGroupList is a collection of pod groups.
+type PodGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of PodGroup.
+	Items []PodGroup `json:"items"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ElasticQuota bounds the resources the pods of its namespace may request.
+// A namespace is guaranteed its Min, and may borrow the guarantees other
+// namespaces don't use up to its Max. Borrowed resources are reclaimed by
+// preemption when their owner needs them back.
+type ElasticQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the guaranteed and maximum resources of the namespace.
+	Spec ElasticQuotaSpec `json:"spec,omitempty"`
+}
+
+// ElasticQuotaSpec represents the guaranteed and maximum resources of a
+// namespace.
+type ElasticQuotaSpec struct {
+	// Min is the amount of resources guaranteed to the namespace. Resources
+	// not listed are not guaranteed.
+	// +optional
+	Min v1.ResourceList `json:"min,omitempty"`
+
+	// Max is the upper bound of the resources the namespace may use,
+	// including borrowed resources. Resources not listed are not bounded.
+	// +optional
+	Max v1.ResourceList `json:"max,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ElasticQuotaList is a collection of elastic quotas.
+type ElasticQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of ElasticQuota.
+	Items []ElasticQuota `json:"items"`
+}
+
+const (
+	// SingleNUMANodeTopologyPolicy is the topology manager policy under which
+	// the kubelet only admits a Guaranteed pod if the resources of each of
+	// its containers, or of the whole pod, come from a single NUMA zone.
+	SingleNUMANodeTopologyPolicy = "single-numa-node"
+
+	// ContainerTopologyManagerScope aligns the resources of each container
+	// separately.
+	ContainerTopologyManagerScope = "container"
+	// PodTopologyManagerScope aligns the resources of all the containers of
+	// a pod together.
+	PodTopologyManagerScope = "pod"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeResourceTopology describes the NUMA zones of a node and the resources
+// available in each of them. It's published by an agent running on the node
+// and named after it.
+type NodeResourceTopology struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// TopologyPolicy is the policy of the kubelet's topology manager, one of
+	// "none", "best-effort", "restricted" or "single-numa-node".
+	// +optional
+	TopologyPolicy string `json:"topologyPolicy,omitempty"`
+
+	// TopologyManagerScope is the scope of the kubelet's topology manager,
+	// either "container" or "pod". Defaults to "container".
+	// +optional
+	TopologyManagerScope string `json:"topologyManagerScope,omitempty"`
+
+	// Zones are the NUMA zones of the node.
+	// +optional
+	Zones []NUMAZone `json:"zones,omitempty"`
+}
+
+// NUMAZone represents the resources of a NUMA zone of a node.
+type NUMAZone struct {
+	// Name is the name of the zone, such as "node-0". The kubelet prefers
+	// zones in the order of their names.
+	Name string `json:"name"`
+
+	// Allocatable is the amount of resources of the zone that can be
+	// allocated to pods. Resources not listed are not bound to a zone.
+	// +optional
+	Allocatable v1.ResourceList `json:"allocatable,omitempty"`
+
+	// Available is the amount of allocatable resources of the zone not
+	// allocated to pods yet.
+	// +optional
+	Available v1.ResourceList `json:"available,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeResourceTopologyList is a collection of node resource topologies.
+type NodeResourceTopologyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is the list of NodeResourceTopology.
+	Items []NodeResourceTopology `json:"items"`
+}
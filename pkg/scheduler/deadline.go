@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	schedulerapi "github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/metrics"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+const (
+	// SchedulingDeadlineExceeded is the reason of the PodScheduled condition,
+	// and of the event, of a pod the scheduler stopped retrying because it
+	// exceeded its scheduling attempts or deadline. The scheduler retries the
+	// pod again once the condition is removed.
+	SchedulingDeadlineExceeded = "SchedulingDeadlineExceeded"
+	// MaxSchedulingAttemptsAnnotation is the pod annotation overriding the
+	// maximum number of attempts of the profile of the pod, as an integer.
+	// "0" means no limit.
+	MaxSchedulingAttemptsAnnotation = "scheduling.sched.dev/max-attempts"
+	// SchedulingDeadlineAnnotation is the pod annotation overriding the
+	// scheduling deadline of the profile of the pod, as a duration since its
+	// first attempt such as "2h". "0" means no limit.
+	SchedulingDeadlineAnnotation = "scheduling.sched.dev/scheduling-deadline"
+)
+
+// schedulingLimits bound how long the scheduler retries the pods of a
+// profile. Zero values mean no limit.
+type schedulingLimits struct {
+	maxAttempts int
+	deadline    time.Duration
+}
+
+// newSchedulingLimits returns the scheduling limits of the profiles, keyed by
+// scheduler name.
+func newSchedulingLimits(args map[string]*schedulerapi.PodBackoffArgs) map[string]schedulingLimits {
+	limits := make(map[string]schedulingLimits, len(args))
+	for name, a := range args {
+		limits[name] = schedulingLimits{
+			maxAttempts: int(a.MaxAttempts),
+			deadline:    time.Duration(a.SchedulingDeadlineSeconds) * time.Second,
+		}
+	}
+	return limits
+}
+
+// exceeded returns the limit the pod exceeded, "attempts" or "deadline",
+// along with a message explaining it. It returns an empty limit if the pod is
+// still within its limits.
+func (l schedulingLimits) exceeded(podInfo *framework.QueuedPodInfo, now time.Time) (string, string) {
+	pod := podInfo.Pod
+	maxAttempts, deadline := l.maxAttempts, l.deadline
+	if v, ok := pod.Annotations[MaxSchedulingAttemptsAnnotation]; ok {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			maxAttempts = n
+		} else {
+			klog.V(4).InfoS("Ignoring invalid annotation", "pod", klog.KObj(pod), "annotation", MaxSchedulingAttemptsAnnotation, "value", v)
+		}
+	}
+	if v, ok := pod.Annotations[SchedulingDeadlineAnnotation]; ok {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			deadline = d
+		} else {
+			klog.V(4).InfoS("Ignoring invalid annotation", "pod", klog.KObj(pod), "annotation", SchedulingDeadlineAnnotation, "value", v)
+		}
+	}
+	if maxAttempts > 0 && podInfo.Attempts >= maxAttempts {
+		return "attempts", fmt.Sprintf("gave up scheduling the pod after %d attempts", podInfo.Attempts)
+	}
+	if deadline > 0 && now.Sub(podInfo.InitialAttemptTimestamp) >= deadline {
+		return "deadline", fmt.Sprintf("gave up scheduling the pod %v after its first attempt", deadline)
+	}
+	return "", ""
+}
+
+// schedulingDeadlineExceeded returns true if the scheduler stopped retrying
+// the pod.
+func schedulingDeadlineExceeded(pod *v1.Pod) bool {
+	_, cond := podutil.GetPodCondition(&pod.Status, v1.PodScheduled)
+	return cond != nil && cond.Status == v1.ConditionFalse && cond.Reason == SchedulingDeadlineExceeded
+}
+
+// giveUpOnPod stops retrying a pod that exceeded one of its scheduling
+// limits. The pod isn't added back to the scheduling queue and its
+// PodScheduled condition tells its controller that it won't be scheduled.
+func (sched *Scheduler) giveUpOnPod(fwk framework.Framework, podInfo *framework.QueuedPodInfo, err error, limit, msg string) {
+	pod := podInfo.Pod
+	msg = fmt.Sprintf("%s: %v", msg, err)
+	klog.V(2).InfoS("Giving up scheduling pod", "pod", klog.KObj(pod), "limit", limit, "attempts", podInfo.Attempts)
+	// Here we check for nil only for tests.
+	if sched.SchedulingQueue != nil {
+		sched.SchedulingQueue.DeleteNominatedPodIfExists(pod)
+	}
+	metrics.SchedulingDeadlineExceeded.WithLabelValues(fwk.ProfileName(), limit).Inc()
+	fwk.EventRecorder().Eventf(pod, nil, v1.EventTypeWarning, SchedulingDeadlineExceeded, "Scheduling", truncateMessage(msg))
+	if err := updatePod(sched.client, pod, &v1.PodCondition{
+		Type:    v1.PodScheduled,
+		Status:  v1.ConditionFalse,
+		Reason:  SchedulingDeadlineExceeded,
+		Message: msg,
+	}, &framework.NominatingInfo{NominatingMode: framework.ModeOverride}); err != nil {
+		klog.ErrorS(err, "Error updating pod", "pod", klog.KObj(pod))
+	}
+	sched.podGroupStatus.Failed(pod, truncateMessage(msg))
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/defaultbinder"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/runtime"
+	fakecache "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/cache/fake"
+	internalqueue "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/queue"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/profile"
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	v1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+func TestSchedulingLimitsExceeded(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		limits      schedulingLimits
+		annotations map[string]string
+		attempts    int
+		pending     time.Duration
+		wantLimit   string
+	}{
+		{
+			name:     "no limits",
+			attempts: 100,
+			pending:  time.Hour,
+		},
+		{
+			name:     "within limits",
+			limits:   schedulingLimits{maxAttempts: 5, deadline: time.Hour},
+			attempts: 4,
+			pending:  time.Minute,
+		},
+		{
+			name:      "attempts exceeded",
+			limits:    schedulingLimits{maxAttempts: 5, deadline: time.Hour},
+			attempts:  5,
+			pending:   time.Minute,
+			wantLimit: "attempts",
+		},
+		{
+			name:      "deadline exceeded",
+			limits:    schedulingLimits{maxAttempts: 5, deadline: time.Hour},
+			attempts:  2,
+			pending:   time.Hour,
+			wantLimit: "deadline",
+		},
+		{
+			name:        "annotations override the profile",
+			limits:      schedulingLimits{maxAttempts: 5},
+			annotations: map[string]string{MaxSchedulingAttemptsAnnotation: "0", SchedulingDeadlineAnnotation: "10m"},
+			attempts:    10,
+			pending:     time.Hour,
+			wantLimit:   "deadline",
+		},
+		{
+			name:        "invalid annotations are ignored",
+			limits:      schedulingLimits{maxAttempts: 5},
+			annotations: map[string]string{MaxSchedulingAttemptsAnnotation: "many", SchedulingDeadlineAnnotation: "soon"},
+			attempts:    5,
+			pending:     time.Hour,
+			wantLimit:   "attempts",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := st.MakePod().Name("p").UID("p").Obj()
+			pod.Annotations = tt.annotations
+			podInfo := &framework.QueuedPodInfo{
+				PodInfo:                 framework.NewPodInfo(pod),
+				Attempts:                tt.attempts,
+				InitialAttemptTimestamp: now.Add(-tt.pending),
+			}
+			limit, msg := tt.limits.exceeded(podInfo, now)
+			if limit != tt.wantLimit {
+				t.Errorf("got limit %q, want %q", limit, tt.wantLimit)
+			}
+			if (limit == "") != (msg == "") {
+				t.Errorf("got limit %q with message %q", limit, msg)
+			}
+		})
+	}
+}
+
+func TestSchedulerGivesUpOnPod(t *testing.T) {
+	tests := []struct {
+		name        string
+		attempts    int
+		wantRetry   bool
+		eventReason string
+	}{
+		{
+			name:        "pod within its limits is retried",
+			attempts:    2,
+			wantRetry:   true,
+			eventReason: "FailedScheduling",
+		},
+		{
+			name:        "pod exceeding its attempts is given up",
+			attempts:    3,
+			eventReason: SchedulingDeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := podWithID("foo", "")
+			client := clientsetfake.NewSimpleClientset(pod)
+			eventBroadcaster := events.NewBroadcaster(&events.EventSinkImpl{Interface: client.EventsV1()})
+			fwk, err := st.NewFramework([]st.RegisterPluginFunc{
+				st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+				st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			}, testSchedulerName,
+				frameworkruntime.WithClientSet(client),
+				frameworkruntime.WithEventRecorder(eventBroadcaster.NewRecorder(scheme.Scheme, testSchedulerName)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			retried := false
+			s := &Scheduler{
+				SchedulerCache: &fakecache.Cache{
+					IsAssumedPodFunc: func(*v1.Pod) bool { return false },
+				},
+				Algorithm: mockScheduler{ScheduleResult{}, errors.New("no fit")},
+				client:    client,
+				Error: func(*framework.QueuedPodInfo, error) {
+					retried = true
+				},
+				NextPod: func() *framework.QueuedPodInfo {
+					return &framework.QueuedPodInfo{
+						PodInfo:                 framework.NewPodInfo(pod),
+						Attempts:                tt.attempts,
+						InitialAttemptTimestamp: time.Now(),
+					}
+				},
+				Profiles:         profile.Map{testSchedulerName: fwk},
+				SchedulingQueue:  internalqueue.NewTestQueue(context.Background(), nil),
+				schedulingLimits: map[string]schedulingLimits{testSchedulerName: {maxAttempts: 3}},
+			}
+			called := make(chan struct{})
+			stopFunc := eventBroadcaster.StartEventWatcher(func(obj runtime.Object) {
+				e, _ := obj.(*eventsv1.Event)
+				if e.Reason != tt.eventReason {
+					t.Errorf("got event %v, want %v", e.Reason, tt.eventReason)
+				}
+				close(called)
+			})
+			defer stopFunc()
+			s.scheduleOne(context.Background())
+			<-called
+			if retried != tt.wantRetry {
+				t.Errorf("got retried %v, want %v", retried, tt.wantRetry)
+			}
+			got, err := client.CoreV1().Pods(pod.Namespace).Get(context.Background(), pod.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, cond := podutil.GetPodCondition(&got.Status, v1.PodScheduled)
+			if cond == nil {
+				t.Fatal("got no PodScheduled condition")
+			}
+			if (cond.Reason == SchedulingDeadlineExceeded) == tt.wantRetry {
+				t.Errorf("got condition reason %q", cond.Reason)
+			}
+			if exceeded := schedulingDeadlineExceeded(got); exceeded == tt.wantRetry {
+				t.Errorf("got schedulingDeadlineExceeded %v, want %v", exceeded, !tt.wantRetry)
+			}
+		})
+	}
+}
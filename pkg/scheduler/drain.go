@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/metrics"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultBindingDrainGracePeriod is how long the binding cycles in flight
+	// are given to finish when the scheduler stops.
+	DefaultBindingDrainGracePeriod = 10 * time.Second
+	// bindingCancelTimeout is how long the binding cycles canceled at the end
+	// of the grace period are given to unreserve their pods.
+	bindingCancelTimeout = 5 * time.Second
+)
+
+// Results of the binding cycles in flight when the scheduler stops.
+const (
+	drainBound      = "bound"
+	drainUnreserved = "unreserved"
+)
+
+// bindingCycles tracks the binding cycles in flight, so that the scheduler
+// lets them finish when it stops rather than leaving volumes half
+// provisioned and pods assumed. A nil *bindingCycles is valid: the binding
+// cycles then stop with the scheduling context.
+type bindingCycles struct {
+	wg          sync.WaitGroup
+	ctx         context.Context
+	cancel      context.CancelFunc
+	gracePeriod time.Duration
+
+	mu       sync.Mutex
+	draining bool
+}
+
+func newBindingCycles(gracePeriod time.Duration) *bindingCycles {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &bindingCycles{
+		ctx:         ctx,
+		cancel:      cancel,
+		gracePeriod: gracePeriod,
+	}
+}
+
+// start records a binding cycle in flight. It returns the context the cycle
+// runs with, which outlives the scheduling context until the end of the
+// grace period.
+func (b *bindingCycles) start(ctx context.Context) context.Context {
+	if b == nil {
+		return ctx
+	}
+	b.wg.Add(1)
+	return b.ctx
+}
+
+// finish records the end of a binding cycle, and whether its pod was bound.
+func (b *bindingCycles) finish(bound bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	draining := b.draining
+	b.mu.Unlock()
+	if draining {
+		result := drainUnreserved
+		if bound {
+			result = drainBound
+		}
+		metrics.DrainedBindingCycles.WithLabelValues(result).Inc()
+	}
+	b.wg.Done()
+}
+
+// drainBindingCycles waits for the binding cycles in flight to finish. It
+// must be called once no more pods are popped from the scheduling queue.
+// The pods waiting on Permit are rejected right away, as the pods they wait
+// for won't be scheduled anymore. The binding cycles still running at the
+// end of the grace period are canceled, which makes them unreserve and
+// forget their pods.
+func (sched *Scheduler) drainBindingCycles() {
+	b := sched.bindingCycles
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	b.draining = true
+	b.mu.Unlock()
+	defer b.cancel()
+
+	start := time.Now()
+	klog.InfoS("Draining binding cycles", "gracePeriod", b.gracePeriod)
+	for _, fwk := range sched.Profiles {
+		fwk.IterateOverWaitingPods(func(wp framework.WaitingPod) {
+			wp.Reject("", "scheduler is stopping")
+		})
+	}
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		klog.InfoS("Drained binding cycles", "duration", time.Since(start))
+	case <-time.After(b.gracePeriod):
+		klog.InfoS("Canceling binding cycles still in flight after the grace period", "gracePeriod", b.gracePeriod)
+		b.cancel()
+		select {
+		case <-done:
+			klog.InfoS("Drained binding cycles", "duration", time.Since(start))
+		case <-time.After(bindingCancelTimeout):
+			klog.ErrorS(nil, "Binding cycles didn't stop after being canceled", "duration", time.Since(start))
+		}
+	}
+	metrics.BindingDrainDuration.Observe(metrics.SinceInSeconds(start))
+}
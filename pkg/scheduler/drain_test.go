@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestDrainBindingCycles(t *testing.T) {
+	tests := []struct {
+		name        string
+		gracePeriod time.Duration
+		// bindingDuration is how long the binding cycle takes unless it's
+		// canceled.
+		bindingDuration time.Duration
+		wantBound       bool
+	}{
+		{
+			name:            "binding cycle finishes within the grace period",
+			gracePeriod:     wait.ForeverTestTimeout,
+			bindingDuration: 50 * time.Millisecond,
+			wantBound:       true,
+		},
+		{
+			name:            "binding cycle canceled at the end of the grace period",
+			gracePeriod:     50 * time.Millisecond,
+			bindingDuration: wait.ForeverTestTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sched := &Scheduler{bindingCycles: newBindingCycles(tt.gracePeriod)}
+			ctx, cancel := context.WithCancel(context.Background())
+			bindingCtx := sched.bindingCycles.start(ctx)
+			// Stopping the scheduler doesn't interrupt the binding cycle.
+			cancel()
+
+			gotBound := make(chan bool, 1)
+			go func() {
+				bound := false
+				select {
+				case <-time.After(tt.bindingDuration):
+					bound = true
+				case <-bindingCtx.Done():
+				}
+				gotBound <- bound
+				sched.bindingCycles.finish(bound)
+			}()
+
+			start := time.Now()
+			sched.drainBindingCycles()
+			if d := time.Since(start); d >= wait.ForeverTestTimeout {
+				t.Errorf("Draining took %v", d)
+			}
+			select {
+			case bound := <-gotBound:
+				if bound != tt.wantBound {
+					t.Errorf("Unexpected binding result, want bound: %v, got %v", tt.wantBound, bound)
+				}
+			default:
+				t.Errorf("Binding cycle still in flight after draining")
+			}
+		})
+	}
+}
@@ -56,7 +56,7 @@ func (sched *Scheduler) onStorageClassAdd(obj interface{}) {
 	// We don't need to invalidate cached results because results will not be
 	// cached for pod that has unbound immediate PVCs.
 	if sc.VolumeBindingMode != nil && *sc.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer {
-		sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(queue.StorageClassAdd, nil)
+		sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(queue.StorageClassAdd, nil, sc, nil)
 	}
 }
 
@@ -69,7 +69,7 @@ func (sched *Scheduler) addNodeToCache(obj interface{}) {
 
 	nodeInfo := sched.SchedulerCache.AddNode(node)
 	klog.V(3).InfoS("Add event for node", "node", klog.KObj(node))
-	sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(queue.NodeAdd, preCheckForNode(nodeInfo))
+	sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(queue.NodeAdd, nil, node, preCheckForNode(nodeInfo))
 }
 
 func (sched *Scheduler) updateNodeInCache(oldObj, newObj interface{}) {
@@ -87,7 +87,7 @@ func (sched *Scheduler) updateNodeInCache(oldObj, newObj interface{}) {
 	nodeInfo := sched.SchedulerCache.UpdateNode(oldNode, newNode)
 	// Only requeue unschedulable pods if the node became more schedulable.
 	if event := nodeSchedulingPropertiesChange(newNode, oldNode); event != nil {
-		sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(*event, preCheckForNode(nodeInfo))
+		sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(*event, oldNode, newNode, preCheckForNode(nodeInfo))
 	}
 }
 
@@ -159,6 +159,7 @@ func (sched *Scheduler) deletePodFromSchedulingQueue(obj interface{}) {
 		return
 	}
 	klog.V(3).InfoS("Delete event for unscheduled pod", "pod", klog.KObj(pod))
+	sched.Explanations.Forget(pod)
 	if err := sched.SchedulingQueue.Delete(pod); err != nil {
 		utilruntime.HandleError(fmt.Errorf("unable to dequeue %T: %v", obj, err))
 	}
@@ -173,7 +174,7 @@ func (sched *Scheduler) deletePodFromSchedulingQueue(obj interface{}) {
 	// removing it from the scheduler cache. In this case, signal a AssignedPodDelete
 	// event to immediately retry some unscheduled Pods.
 	if fwk.RejectWaitingPod(pod.UID) {
-		sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(queue.AssignedPodDelete, nil)
+		sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(queue.AssignedPodDelete, pod, nil, nil)
 	}
 }
 
@@ -217,7 +218,7 @@ func (sched *Scheduler) updatePodInCache(oldObj, newObj interface{}) {
 		klog.ErrorS(err, "Scheduler cache UpdatePod failed", "oldPod", klog.KObj(oldPod), "newPod", klog.KObj(newPod))
 	}
 
-	sched.SchedulingQueue.AssignedPodUpdated(newPod)
+	sched.SchedulingQueue.AssignedPodUpdated(oldPod, newPod)
 }
 
 func (sched *Scheduler) deletePodFromCache(obj interface{}) {
@@ -237,11 +238,12 @@ func (sched *Scheduler) deletePodFromCache(obj interface{}) {
 		return
 	}
 	klog.V(3).InfoS("Delete event for scheduled pod", "pod", klog.KObj(pod))
+	sched.Explanations.Forget(pod)
 	if err := sched.SchedulerCache.RemovePod(pod); err != nil {
 		klog.ErrorS(err, "Scheduler cache RemovePod failed", "pod", klog.KObj(pod))
 	}
 
-	sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(queue.AssignedPodDelete, nil)
+	sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(queue.AssignedPodDelete, pod, nil, nil)
 }
 
 // assignedPod selects pods that are assigned (scheduled and running).
@@ -295,7 +297,10 @@ func addAllEventHandlers(
 			FilterFunc: func(obj interface{}) bool {
 				switch t := obj.(type) {
 				case *v1.Pod:
-					return !assignedPod(t) && responsibleForPod(t, sched.Profiles)
+					// Pods the scheduler gave up on are left out of the queue until
+					// their condition is removed, and so are the pods of the other
+					// replicas' shards.
+					return !assignedPod(t) && responsibleForPod(t, sched.Profiles) && !schedulingDeadlineExceeded(t) && sched.ownsPod(t)
 				case cache.DeletedFinalStateUnknown:
 					if pod, ok := t.Obj.(*v1.Pod); ok {
 						// The carried object may be stale, so we don't use it to check if
@@ -317,6 +322,31 @@ func addAllEventHandlers(
 		},
 	)
 
+	// pods assumed by the other replicas of the shard group
+	if sched.shard != nil {
+		informerFactory.Core().V1().Pods().Informer().AddEventHandler(
+			cache.FilteringResourceEventHandler{
+				FilterFunc: func(obj interface{}) bool {
+					switch t := obj.(type) {
+					case *v1.Pod:
+						return !assignedPod(t) && sched.announcedByOtherReplica(t)
+					case cache.DeletedFinalStateUnknown:
+						// The pod is only forgotten if it's still assumed.
+						_, ok := t.Obj.(*v1.Pod)
+						return ok
+					default:
+						return false
+					}
+				},
+				Handler: cache.ResourceEventHandlerFuncs{
+					AddFunc:    sched.addAnnouncedPodToCache,
+					UpdateFunc: sched.updateAnnouncedPodInCache,
+					DeleteFunc: sched.deleteAnnouncedPodFromCache,
+				},
+			},
+		)
+	}
+
 	informerFactory.Core().V1().Nodes().Informer().AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
 			AddFunc:    sched.addNodeToCache,
@@ -329,20 +359,20 @@ func addAllEventHandlers(
 		funcs := cache.ResourceEventHandlerFuncs{}
 		if at&framework.Add != 0 {
 			evt := framework.ClusterEvent{Resource: gvk, ActionType: framework.Add, Label: fmt.Sprintf("%vAdd", shortGVK)}
-			funcs.AddFunc = func(_ interface{}) {
-				sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(evt, nil)
+			funcs.AddFunc = func(obj interface{}) {
+				sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(evt, nil, obj, nil)
 			}
 		}
 		if at&framework.Update != 0 {
 			evt := framework.ClusterEvent{Resource: gvk, ActionType: framework.Update, Label: fmt.Sprintf("%vUpdate", shortGVK)}
-			funcs.UpdateFunc = func(_, _ interface{}) {
-				sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(evt, nil)
+			funcs.UpdateFunc = func(oldObj, newObj interface{}) {
+				sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(evt, oldObj, newObj, nil)
 			}
 		}
 		if at&framework.Delete != 0 {
 			evt := framework.ClusterEvent{Resource: gvk, ActionType: framework.Delete, Label: fmt.Sprintf("%vDelete", shortGVK)}
-			funcs.DeleteFunc = func(_ interface{}) {
-				sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(evt, nil)
+			funcs.DeleteFunc = func(obj interface{}) {
+				sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(evt, obj, nil, nil)
 			}
 		}
 		return funcs
@@ -397,8 +427,8 @@ func addAllEventHandlers(
 			if at&framework.Update != 0 {
 				informerFactory.Storage().V1().StorageClasses().Informer().AddEventHandler(
 					cache.ResourceEventHandlerFuncs{
-						UpdateFunc: func(_, _ interface{}) {
-							sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(queue.StorageClassUpdate, nil)
+						UpdateFunc: func(oldObj, newObj interface{}) {
+							sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(queue.StorageClassUpdate, oldObj, newObj, nil)
 						},
 					},
 				)
@@ -0,0 +1,320 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package explanation keeps the outcome of the last scheduling attempt of
+// recent pods, for operators to find out why a pod is pending or why it was
+// placed on its node.
+package explanation
+
+import (
+	"container/list"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+// PathPrefix is the path the explanations are served under, followed by the
+// namespace and name of a pod.
+const PathPrefix = "/debug/scheduling/pods/"
+
+const (
+	// DefaultMaxEntries is the default number of pods the explanation of the
+	// last scheduling attempt is kept for.
+	DefaultMaxEntries = 1000
+	// DefaultTTL is the default duration the explanation of a scheduling
+	// attempt is kept for.
+	DefaultTTL = 15 * time.Minute
+	// maxSampleNodes is the number of nodes kept as samples of the nodes that
+	// rejected a pod for the same reasons.
+	maxSampleNodes = 3
+	// maxScoredNodes is the number of nodes with the highest total score
+	// whose scores are kept.
+	maxScoredNodes = 10
+)
+
+// Explanation is the outcome of the last scheduling attempt of a pod.
+type Explanation struct {
+	// Namespace and Name identify the pod.
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	UID       types.UID `json:"uid"`
+	// Profile is the scheduler name of the profile that attempted the pod.
+	Profile string `json:"profile"`
+	// Timestamp is the time of the attempt.
+	Timestamp time.Time `json:"timestamp"`
+	// Attempts is the number of attempts to schedule the pod so far.
+	Attempts int `json:"attempts"`
+	// Node is the node chosen for the pod. It's empty if the attempt failed.
+	Node string `json:"node,omitempty"`
+	// Message explains why the attempt failed.
+	Message string `json:"message,omitempty"`
+	// UnschedulablePlugins are the plugins that rejected the pod.
+	UnschedulablePlugins []string `json:"unschedulablePlugins,omitempty"`
+	// NodeStatuses are the reasons the nodes rejected the pod, with the
+	// number of nodes that rejected it for each, the most common first.
+	NodeStatuses []NodeStatus `json:"nodeStatuses,omitempty"`
+	// ScoredNodes is the number of feasible nodes that were scored.
+	ScoredNodes int `json:"scoredNodes,omitempty"`
+	// Scores are the scores of the feasible nodes with the highest total
+	// score, keyed by plugin name and node name. They are only set when the
+	// attempt succeeded and more than one node was feasible.
+	Scores map[string]map[string]int64 `json:"scores,omitempty"`
+}
+
+// NodeStatus is a reason nodes rejected a pod for.
+type NodeStatus struct {
+	Code    string   `json:"code"`
+	Plugin  string   `json:"plugin,omitempty"`
+	Reasons []string `json:"reasons,omitempty"`
+	// Nodes is the number of nodes that rejected the pod for this reason.
+	Nodes int `json:"nodes"`
+	// SampleNodes are some of these nodes.
+	SampleNodes []string `json:"sampleNodes,omitempty"`
+}
+
+// Store holds the explanations of the most recently attempted pods. It holds
+// at most maxEntries explanations, evicting the least recently recorded
+// first, and drops the explanations older than the TTL. The size of each
+// explanation doesn't depend on the number of nodes, as only the reasons
+// nodes rejected the pod for and a few of the best scored nodes are kept. A
+// nil Store records nothing.
+type Store struct {
+	clock      clock.Clock
+	ttl        time.Duration
+	maxEntries int
+
+	mu sync.Mutex
+	// entries index the elements of lru by namespace and name.
+	entries map[string]*list.Element
+	// lru holds the explanations, the most recently recorded first.
+	lru *list.List
+}
+
+// NewStore returns an empty store.
+func NewStore(maxEntries int, ttl time.Duration, clock clock.Clock) *Store {
+	return &Store{
+		clock:      clock,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+func key(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// RecordFailure records the failed scheduling attempt of a pod.
+func (s *Store) RecordFailure(podInfo *framework.QueuedPodInfo, profile string, err error) {
+	if s == nil {
+		return
+	}
+	e := s.newExplanation(podInfo, profile)
+	e.Message = err.Error()
+	if fitErr, ok := err.(*framework.FitError); ok {
+		e.UnschedulablePlugins = fitErr.Diagnosis.UnschedulablePlugins.List()
+		e.NodeStatuses = groupNodeStatuses(fitErr.Diagnosis.NodeToStatusMap)
+	}
+	s.add(e)
+}
+
+// groupNodeStatuses groups the nodes by the reasons they rejected the pod
+// for, keeping a few sample nodes of each group.
+func groupNodeStatuses(statuses framework.NodeToStatusMap) []NodeStatus {
+	// Nodes are visited in order for the samples to be stable.
+	nodes := make([]string, 0, len(statuses))
+	for node := range statuses {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	var groups []NodeStatus
+	index := make(map[string]int)
+	for _, node := range nodes {
+		status := statuses[node]
+		code, plugin, reasons := status.Code().String(), status.FailedPlugin(), status.Reasons()
+		k := code + "\x00" + plugin + "\x00" + strings.Join(reasons, "\x00")
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, NodeStatus{Code: code, Plugin: plugin, Reasons: reasons})
+		}
+		groups[i].Nodes++
+		if len(groups[i].SampleNodes) < maxSampleNodes {
+			groups[i].SampleNodes = append(groups[i].SampleNodes, node)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Nodes > groups[j].Nodes })
+	return groups
+}
+
+// RecordSuccess records the node chosen for a pod along with the scores of
+// the feasible nodes with the highest total score.
+func (s *Store) RecordSuccess(podInfo *framework.QueuedPodInfo, profile, node string, scores framework.PluginToNodeScores) {
+	if s == nil {
+		return
+	}
+	e := s.newExplanation(podInfo, profile)
+	e.Node = node
+	if len(scores) > 0 {
+		var top sets.String
+		e.ScoredNodes, top = topScoredNodes(scores, node)
+		e.Scores = make(map[string]map[string]int64, len(scores))
+		for plugin, nodeScores := range scores {
+			m := make(map[string]int64, len(top))
+			for _, ns := range nodeScores {
+				if top.Has(ns.Name) {
+					m[ns.Name] = ns.Score
+				}
+			}
+			e.Scores[plugin] = m
+		}
+	}
+	s.add(e)
+}
+
+// topScoredNodes returns the number of scored nodes, and the maxScoredNodes
+// ones with the highest total score, the chosen node included.
+func topScoredNodes(scores framework.PluginToNodeScores, chosen string) (int, sets.String) {
+	totals := make(map[string]int64)
+	for _, nodeScores := range scores {
+		for _, ns := range nodeScores {
+			totals[ns.Name] += ns.Score
+		}
+	}
+	nodes := make([]string, 0, len(totals))
+	for node := range totals {
+		if node != chosen {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if totals[nodes[i]] != totals[nodes[j]] {
+			return totals[nodes[i]] > totals[nodes[j]]
+		}
+		return nodes[i] < nodes[j]
+	})
+	top := sets.NewString(chosen)
+	for _, node := range nodes {
+		if top.Len() >= maxScoredNodes {
+			break
+		}
+		top.Insert(node)
+	}
+	return len(totals), top
+}
+
+func (s *Store) newExplanation(podInfo *framework.QueuedPodInfo, profile string) *Explanation {
+	pod := podInfo.Pod
+	return &Explanation{
+		Namespace: pod.Namespace,
+		Name:      pod.Name,
+		UID:       pod.UID,
+		Profile:   profile,
+		Timestamp: s.clock.Now(),
+		Attempts:  podInfo.Attempts,
+	}
+}
+
+func (s *Store) add(e *Explanation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := key(e.Namespace, e.Name)
+	if elem, ok := s.entries[k]; ok {
+		s.lru.Remove(elem)
+	}
+	s.entries[k] = s.lru.PushFront(e)
+	s.evictLocked()
+}
+
+// evictLocked drops the explanations beyond maxEntries and the ones older
+// than the TTL. As the least recently recorded are at the back, it stops at
+// the first explanation it keeps.
+func (s *Store) evictLocked() {
+	now := s.clock.Now()
+	for elem := s.lru.Back(); elem != nil; elem = s.lru.Back() {
+		e := elem.Value.(*Explanation)
+		if s.lru.Len() <= s.maxEntries && now.Sub(e.Timestamp) <= s.ttl {
+			return
+		}
+		s.lru.Remove(elem)
+		delete(s.entries, key(e.Namespace, e.Name))
+	}
+}
+
+// Get returns the explanation of the last attempt of the pod, if it's
+// still held.
+func (s *Store) Get(namespace, name string) (*Explanation, bool) {
+	if s == nil {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictLocked()
+	elem, ok := s.entries[key(namespace, name)]
+	if !ok {
+		return nil, false
+	}
+	return elem.Value.(*Explanation), true
+}
+
+// Forget drops the explanation of the pod, such as when it's deleted.
+func (s *Store) Forget(pod *v1.Pod) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := key(pod.Namespace, pod.Name)
+	if elem, ok := s.entries[k]; ok {
+		s.lru.Remove(elem)
+		delete(s.entries, k)
+	}
+}
+
+// ServeHTTP serves the explanation of the pod of the path, which is the
+// PathPrefix followed by the namespace and name of the pod, as JSON.
+func (s *Store) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, PathPrefix), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		http.Error(w, "expected a path of the form "+PathPrefix+"{namespace}/{name}", http.StatusNotFound)
+		return
+	}
+	e, ok := s.Get(parts[0], parts[1])
+	if !ok {
+		http.Error(w, "no recent scheduling attempt of pod "+key(parts[0], parts[1]), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(e); err != nil {
+		klog.ErrorS(err, "Failed to write scheduling explanation", "pod", klog.KRef(parts[0], parts[1]))
+	}
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package explanation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/util/sets"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func queuedPodInfo(namespace, name string, attempts int) *framework.QueuedPodInfo {
+	pod := st.MakePod().Namespace(namespace).Name(name).UID(name).Obj()
+	return &framework.QueuedPodInfo{PodInfo: framework.NewPodInfo(pod), Attempts: attempts}
+}
+
+func TestRecord(t *testing.T) {
+	now := time.Now()
+	s := NewStore(10, time.Minute, testingclock.NewFakeClock(now))
+
+	fitErr := &framework.FitError{
+		Pod:         queuedPodInfo("ns", "pending", 2).Pod,
+		NumAllNodes: 2,
+		Diagnosis: framework.Diagnosis{
+			NodeToStatusMap: framework.NodeToStatusMap{
+				"n1": framework.NewStatus(framework.Unschedulable, "Insufficient cpu").WithFailedPlugin("NodeResourcesFit"),
+				"n2": framework.NewStatus(framework.UnschedulableAndUnresolvable, "node(s) had taint").WithFailedPlugin("TaintToleration"),
+			},
+			UnschedulablePlugins: sets.NewString("NodeResourcesFit", "TaintToleration"),
+		},
+	}
+	s.RecordFailure(queuedPodInfo("ns", "pending", 2), "default-scheduler", fitErr)
+	s.RecordSuccess(queuedPodInfo("ns", "placed", 1), "default-scheduler", "n2", framework.PluginToNodeScores{
+		"NodeResourcesBalancedAllocation": {{Name: "n1", Score: 10}, {Name: "n2", Score: 90}},
+	})
+
+	tests := map[string]*Explanation{
+		"pending": {
+			Namespace: "ns", Name: "pending", UID: "pending", Profile: "default-scheduler",
+			Timestamp: now, Attempts: 2,
+			Message:              fitErr.Error(),
+			UnschedulablePlugins: []string{"NodeResourcesFit", "TaintToleration"},
+			NodeStatuses: []NodeStatus{
+				{Code: "Unschedulable", Plugin: "NodeResourcesFit", Reasons: []string{"Insufficient cpu"}, Nodes: 1, SampleNodes: []string{"n1"}},
+				{Code: "UnschedulableAndUnresolvable", Plugin: "TaintToleration", Reasons: []string{"node(s) had taint"}, Nodes: 1, SampleNodes: []string{"n2"}},
+			},
+		},
+		"placed": {
+			Namespace: "ns", Name: "placed", UID: "placed", Profile: "default-scheduler",
+			Timestamp: now, Attempts: 1,
+			Node:        "n2",
+			ScoredNodes: 2,
+			Scores:      map[string]map[string]int64{"NodeResourcesBalancedAllocation": {"n1": 10, "n2": 90}},
+		},
+	}
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := s.Get("ns", name)
+			if !ok {
+				t.Fatalf("no explanation for %s", name)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Unexpected explanation (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRecordManyNodes(t *testing.T) {
+	s := NewStore(10, time.Minute, testingclock.NewFakeClock(time.Now()))
+
+	statuses := make(framework.NodeToStatusMap)
+	var nodeScores framework.NodeScoreList
+	for i := 0; i < 100; i++ {
+		node := fmt.Sprintf("n%02d", i)
+		if i%10 == 0 {
+			statuses[node] = framework.NewStatus(framework.UnschedulableAndUnresolvable, "node(s) had taint").WithFailedPlugin("TaintToleration")
+		} else {
+			statuses[node] = framework.NewStatus(framework.Unschedulable, "Insufficient cpu").WithFailedPlugin("NodeResourcesFit")
+		}
+		nodeScores = append(nodeScores, framework.NodeScore{Name: node, Score: int64(i)})
+	}
+	fitErr := &framework.FitError{
+		Pod:         queuedPodInfo("ns", "pending", 1).Pod,
+		NumAllNodes: 100,
+		Diagnosis:   framework.Diagnosis{NodeToStatusMap: statuses},
+	}
+	s.RecordFailure(queuedPodInfo("ns", "pending", 1), "default-scheduler", fitErr)
+	e, _ := s.Get("ns", "pending")
+	wantStatuses := []NodeStatus{
+		{Code: "Unschedulable", Plugin: "NodeResourcesFit", Reasons: []string{"Insufficient cpu"}, Nodes: 90, SampleNodes: []string{"n01", "n02", "n03"}},
+		{Code: "UnschedulableAndUnresolvable", Plugin: "TaintToleration", Reasons: []string{"node(s) had taint"}, Nodes: 10, SampleNodes: []string{"n00", "n10", "n20"}},
+	}
+	if diff := cmp.Diff(wantStatuses, e.NodeStatuses); diff != "" {
+		t.Errorf("Unexpected node statuses (-want,+got):\n%s", diff)
+	}
+
+	// The chosen node is kept along with the best scored ones.
+	s.RecordSuccess(queuedPodInfo("ns", "placed", 1), "default-scheduler", "n00", framework.PluginToNodeScores{"ImageLocality": nodeScores})
+	e, _ = s.Get("ns", "placed")
+	wantScores := map[string]int64{"n00": 0}
+	for i := 91; i < 100; i++ {
+		wantScores[fmt.Sprintf("n%02d", i)] = int64(i)
+	}
+	if e.ScoredNodes != 100 {
+		t.Errorf("Got %d scored nodes, want 100", e.ScoredNodes)
+	}
+	if diff := cmp.Diff(wantScores, e.Scores["ImageLocality"]); diff != "" {
+		t.Errorf("Unexpected scores (-want,+got):\n%s", diff)
+	}
+}
+
+func TestEviction(t *testing.T) {
+	clock := testingclock.NewFakeClock(time.Now())
+	s := NewStore(2, time.Minute, clock)
+	err := errors.New("binding rejected")
+
+	s.RecordFailure(queuedPodInfo("ns", "a", 1), "default-scheduler", err)
+	clock.Step(30 * time.Second)
+	s.RecordFailure(queuedPodInfo("ns", "b", 1), "default-scheduler", err)
+	s.RecordFailure(queuedPodInfo("ns", "c", 1), "default-scheduler", err)
+	if _, ok := s.Get("ns", "a"); ok {
+		t.Errorf("explanation of a should be evicted beyond the maximum number of entries")
+	}
+
+	// Recording b again makes c the least recently recorded.
+	s.RecordFailure(queuedPodInfo("ns", "b", 2), "default-scheduler", err)
+	s.RecordFailure(queuedPodInfo("ns", "d", 1), "default-scheduler", err)
+	if _, ok := s.Get("ns", "c"); ok {
+		t.Errorf("explanation of c should be evicted beyond the maximum number of entries")
+	}
+	if e, ok := s.Get("ns", "b"); !ok || e.Attempts != 2 {
+		t.Errorf("expected the explanation of the second attempt of b, got %v", e)
+	}
+
+	clock.Step(61 * time.Second)
+	if _, ok := s.Get("ns", "b"); ok {
+		t.Errorf("explanation of b should be evicted after the TTL")
+	}
+
+	s.RecordFailure(queuedPodInfo("ns", "e", 1), "default-scheduler", err)
+	s.Forget(queuedPodInfo("ns", "e", 1).Pod)
+	if _, ok := s.Get("ns", "e"); ok {
+		t.Errorf("explanation of e should be dropped when the pod is forgotten")
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	s := NewStore(10, time.Minute, testingclock.NewFakeClock(time.Now()))
+	s.RecordSuccess(queuedPodInfo("ns", "placed", 1), "default-scheduler", "n1", nil)
+
+	tests := map[string]struct {
+		method   string
+		path     string
+		wantCode int
+		wantNode string
+	}{
+		"recorded pod": {
+			method:   http.MethodGet,
+			path:     PathPrefix + "ns/placed",
+			wantCode: http.StatusOK,
+			wantNode: "n1",
+		},
+		"unknown pod": {
+			method:   http.MethodGet,
+			path:     PathPrefix + "ns/unknown",
+			wantCode: http.StatusNotFound,
+		},
+		"missing name": {
+			method:   http.MethodGet,
+			path:     PathPrefix + "ns",
+			wantCode: http.StatusNotFound,
+		},
+		"not a GET": {
+			method:   http.MethodPost,
+			path:     PathPrefix + "ns/placed",
+			wantCode: http.StatusMethodNotAllowed,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+			if w.Code != tc.wantCode {
+				t.Fatalf("got status %d, want %d", w.Code, tc.wantCode)
+			}
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+			var e Explanation
+			if err := json.NewDecoder(w.Body).Decode(&e); err != nil {
+				t.Fatal(err)
+			}
+			if e.Node != tc.wantNode {
+				t.Errorf("got node %q, want %q", e.Node, tc.wantNode)
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	schedulerapi "github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/explanation"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/noderesources"
 	frameworkruntime "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/runtime"
@@ -34,11 +35,13 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 )
 
 // Binder knows how to write a binding.
@@ -56,6 +59,8 @@ type Configurator struct {
 
 	informerFactory informers.SharedInformerFactory
 
+	dynInformerFactory dynamicinformer.DynamicSharedInformerFactory
+
 	// Close this to stop all reflectors
 	StopEverything <-chan struct{}
 
@@ -81,6 +86,14 @@ type Configurator struct {
 	parallellism      int32
 	// A "cluster event" -> "plugin names" map.
 	clusterEventMap map[framework.ClusterEvent]sets.String
+	// A "cluster event" -> "plugin name" -> "queueing hints" map.
+	queueingHintMap framework.QueueingHintMap
+	// schedulingWorkers is the number of pods scheduled in parallel.
+	schedulingWorkers int32
+	// explanationMaxEntries and explanationTTL bound the explanations of the
+	// last scheduling attempts.
+	explanationMaxEntries int
+	explanationTTL        time.Duration
 }
 
 // create a scheduler from a set of registered plugins.
@@ -145,11 +158,14 @@ func (c *Configurator) create() (*Scheduler, error) {
 		frameworkruntime.WithClientSet(c.client),
 		frameworkruntime.WithKubeConfig(c.kubeConfig),
 		frameworkruntime.WithInformerFactory(c.informerFactory),
+		frameworkruntime.WithDynInformerFactory(c.dynInformerFactory),
 		frameworkruntime.WithSnapshotSharedLister(c.nodeInfoSnapshot),
+		frameworkruntime.WithNamespaceUsages(c.schedulerCache.NamespaceUsages()),
 		frameworkruntime.WithRunAllFilters(c.alwaysCheckAllPredicates),
 		frameworkruntime.WithPodNominator(nominator),
 		frameworkruntime.WithCaptureProfile(frameworkruntime.CaptureProfile(c.frameworkCapturer)),
 		frameworkruntime.WithClusterEventMap(c.clusterEventMap),
+		frameworkruntime.WithQueueingHintMap(c.queueingHintMap),
 		frameworkruntime.WithParallelism(int(c.parallellism)),
 		frameworkruntime.WithExtenders(extenders),
 	)
@@ -161,13 +177,23 @@ func (c *Configurator) create() (*Scheduler, error) {
 	}
 	// Profiles are required to have equivalent queue sort plugins.
 	lessFn := profiles[c.profiles[0].SchedulerName].QueueSortFunc()
+	sortKeyFn := profiles[c.profiles[0].SchedulerName].QueueSortKeyFunc()
+	backoffArgs := podBackoffArgs(c.profiles)
+	preEnqueuePluginMap := make(map[string][]framework.PreEnqueuePlugin, len(profiles))
+	for name, fwk := range profiles {
+		preEnqueuePluginMap[name] = fwk.PreEnqueuePlugins()
+	}
 	podQueue := internalqueue.NewSchedulingQueue(
 		lessFn,
 		c.informerFactory,
+		internalqueue.WithSortKeyFunc(sortKeyFn),
 		internalqueue.WithPodInitialBackoffDuration(time.Duration(c.podInitialBackoffSeconds)*time.Second),
 		internalqueue.WithPodMaxBackoffDuration(time.Duration(c.podMaxBackoffSeconds)*time.Second),
+		internalqueue.WithPodBackoffArgs(backoffArgs),
 		internalqueue.WithPodNominator(nominator),
 		internalqueue.WithClusterEventMap(c.clusterEventMap),
+		internalqueue.WithQueueingHintMap(c.queueingHintMap),
+		internalqueue.WithPreEnqueuePluginMap(preEnqueuePluginMap),
 	)
 
 	// Setup cache debugger.
@@ -185,18 +211,89 @@ func (c *Configurator) create() (*Scheduler, error) {
 		c.percentageOfNodesToScore,
 	)
 
+	// Each scheduling worker finds nodes on its own snapshot, with its own
+	// instances of the plugins. The pods are committed with the profiles
+	// above, so the workers don't register for cluster events, and their
+	// frameworks leave out the extension points they never run.
+	var workers []*schedulingWorker
+	if c.schedulingWorkers > 1 {
+		workerConfigs := schedulingWorkerProfiles(c.profiles)
+		for i := 0; i < int(c.schedulingWorkers); i++ {
+			snapshot := internalcache.NewEmptySnapshot()
+			workerProfiles, err := profile.NewMap(workerConfigs, c.registry, c.recorderFactory,
+				frameworkruntime.WithComponentConfigVersion(c.componentConfigVersion),
+				frameworkruntime.WithClientSet(c.client),
+				frameworkruntime.WithKubeConfig(c.kubeConfig),
+				frameworkruntime.WithInformerFactory(c.informerFactory),
+				frameworkruntime.WithDynInformerFactory(c.dynInformerFactory),
+				frameworkruntime.WithSnapshotSharedLister(snapshot),
+				frameworkruntime.WithNamespaceUsages(c.schedulerCache.NamespaceUsages()),
+				frameworkruntime.WithRunAllFilters(c.alwaysCheckAllPredicates),
+				frameworkruntime.WithPodNominator(nominator),
+				frameworkruntime.WithParallelism(int(c.parallellism)),
+				frameworkruntime.WithExtenders(extenders),
+				frameworkruntime.WithSchedulingWorker(true),
+			)
+			if err != nil {
+				return nil, fmt.Errorf("initializing profiles of scheduling worker %d: %v", i, err)
+			}
+			workerAlgo := NewGenericScheduler(c.schedulerCache, snapshot, c.percentageOfNodesToScore)
+			workers = append(workers, newSchedulingWorker(i, snapshot, workerAlgo, workerProfiles))
+		}
+	}
+
 	return &Scheduler{
-		SchedulerCache:  c.schedulerCache,
-		Algorithm:       algo,
-		Extenders:       extenders,
-		Profiles:        profiles,
-		NextPod:         internalqueue.MakeNextPodFunc(podQueue),
-		Error:           MakeDefaultErrorFunc(c.client, c.informerFactory.Core().V1().Pods().Lister(), podQueue, c.schedulerCache),
-		StopEverything:  c.StopEverything,
-		SchedulingQueue: podQueue,
+		SchedulerCache:   c.schedulerCache,
+		Algorithm:        algo,
+		Extenders:        extenders,
+		Profiles:         profiles,
+		NextPod:          internalqueue.MakeNextPodFunc(podQueue),
+		Error:            MakeDefaultErrorFunc(c.client, c.informerFactory.Core().V1().Pods().Lister(), podQueue, c.schedulerCache),
+		StopEverything:   c.StopEverything,
+		SchedulingQueue:  podQueue,
+		Explanations:     explanation.NewStore(c.explanationMaxEntries, c.explanationTTL, clock.RealClock{}),
+		schedulingLimits: newSchedulingLimits(backoffArgs),
+		workers:          workers,
+		nodeInfoSnapshot: c.nodeInfoSnapshot,
 	}, nil
 }
 
+// podBackoffArgs returns the PodBackoffArgs set in the plugin config of the
+// profiles, keyed by scheduler name.
+// schedulingWorkerProfiles returns copies of the given profiles for the frameworks of
+// scheduling workers. Workers only find nodes for pods, so the PostFilter,
+// Reserve, Permit, PreBind and PostBind extension points are disabled, which
+// also keeps MultiPoint plugins out of them. The QueueSort and Bind plugins
+// are kept, as every framework needs them.
+func schedulingWorkerProfiles(profiles []schedulerapi.KubeSchedulerProfile) []schedulerapi.KubeSchedulerProfile {
+	disabled := schedulerapi.PluginSet{Disabled: []schedulerapi.Plugin{{Name: "*"}}}
+	out := make([]schedulerapi.KubeSchedulerProfile, len(profiles))
+	for i := range profiles {
+		p := profiles[i].DeepCopy()
+		if p.Plugins != nil {
+			p.Plugins.PostFilter = *disabled.DeepCopy()
+			p.Plugins.Reserve = *disabled.DeepCopy()
+			p.Plugins.Permit = *disabled.DeepCopy()
+			p.Plugins.PreBind = *disabled.DeepCopy()
+			p.Plugins.PostBind = *disabled.DeepCopy()
+		}
+		out[i] = *p
+	}
+	return out
+}
+
+func podBackoffArgs(profiles []schedulerapi.KubeSchedulerProfile) map[string]*schedulerapi.PodBackoffArgs {
+	args := make(map[string]*schedulerapi.PodBackoffArgs)
+	for i := range profiles {
+		for _, pc := range profiles[i].PluginConfig {
+			if a, ok := pc.Args.(*schedulerapi.PodBackoffArgs); ok && pc.Name == schedulerapi.PodBackoffName {
+				args[profiles[i].SchedulerName] = a
+			}
+		}
+	}
+	return args
+}
+
 // MakeDefaultErrorFunc construct a function to handle pod scheduler error
 func MakeDefaultErrorFunc(client clientset.Interface, podLister corelisters.PodLister, podQueue internalqueue.SchedulingQueue, schedulerCache internalcache.Cache) func(*framework.QueuedPodInfo, error) {
 	return func(podInfo *framework.QueuedPodInfo, err error) {
@@ -60,6 +60,47 @@ func TestCreate(t *testing.T) {
 	}
 }
 
+func TestCreateSchedulingWorkers(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory := newConfigFactory(client, stopCh)
+	factory.schedulingWorkers = 2
+	factory.profiles[0].Plugins = &schedulerapi.Plugins{
+		MultiPoint: schedulerapi.PluginSet{Enabled: []schedulerapi.Plugin{
+			{Name: "PrioritySort"},
+			{Name: "DefaultBinder"},
+			{Name: "DefaultPreemption"},
+		}},
+	}
+	factory.profiles[0].PluginConfig = []schedulerapi.PluginConfig{{
+		Name: "DefaultPreemption",
+		Args: &schedulerapi.DefaultPreemptionArgs{MinCandidateNodesPercentage: 10, MinCandidateNodesAbsolute: 100},
+	}}
+	sched, err := factory.create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sched.Profiles[testSchedulerName].HasPostFilterPlugins() {
+		t.Error("Scheduler profile has no PostFilter plugins")
+	}
+	if len(sched.workers) != 2 {
+		t.Fatalf("Got %d scheduling workers, want 2", len(sched.workers))
+	}
+	for _, w := range sched.workers {
+		fwk := w.profiles[testSchedulerName]
+		if fwk.HasPostFilterPlugins() {
+			t.Errorf("Profile of scheduling worker %s has PostFilter plugins", w.name)
+		}
+		if !fwk.SchedulingWorker() {
+			t.Errorf("Profile of scheduling worker %s isn't marked as a scheduling worker", w.name)
+		}
+	}
+	if len(factory.profiles[0].Plugins.PostFilter.Disabled) != 0 {
+		t.Error("Building the scheduling workers changed the scheduler profile")
+	}
+}
+
 func TestDefaultErrorFunc(t *testing.T) {
 	testPod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"}}
 	testPodUpdated := testPod.DeepCopy()
@@ -284,8 +325,10 @@ func newConfigFactoryWithFrameworkRegistry(
 			},
 		},
 		recorderFactory:  recorderFactory,
+		schedulerCache:   internalcache.New(30*time.Second, stopCh),
 		nodeInfoSnapshot: snapshot,
 		clusterEventMap:  make(map[framework.ClusterEvent]sets.String),
+		queueingHintMap:  make(framework.QueueingHintMap),
 	}
 }
 
@@ -264,6 +264,29 @@ func NewNodeInfoLister(nodes []*v1.Node) framework.NodeInfoLister {
 	return NodeInfoLister(nodeInfoList)
 }
 
+// NamespaceUsageLister declares a map of namespace to framework.Resource for testing.
+type NamespaceUsageLister map[string]*framework.Resource
+
+// Get returns the fake usage of the namespace.
+func (usages NamespaceUsageLister) Get(namespace string) *framework.Resource {
+	if usage, ok := usages[namespace]; ok {
+		return usage
+	}
+	return &framework.Resource{}
+}
+
+// PodGroupPlacementLister declares a map of "namespace/name" pod group key to
+// the number of members on each node for testing.
+type PodGroupPlacementLister map[string]map[string]int
+
+// Get returns the fake placement of the pod group.
+func (placements PodGroupPlacementLister) Get(namespace, name string) map[string]int {
+	if placement, ok := placements[namespace+"/"+name]; ok {
+		return placement
+	}
+	return map[string]int{}
+}
+
 var _ storagelisters.CSINodeLister = CSINodeLister{}
 
 // CSINodeLister declares a storagev1.CSINode type for testing.
@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// GPUMemoryResourceName is the extended resource pods request a slice of
+	// the memory of a single GPU with, in bytes. Nodes advertise the total
+	// memory of their shareable GPUs with it.
+	GPUMemoryResourceName v1.ResourceName = "sched.dev/gpu-mem"
+	// GPUComputeResourceName is the extended resource pods request a share of
+	// the compute of a single GPU with, in percent of the device.
+	GPUComputeResourceName v1.ResourceName = "sched.dev/gpu-compute"
+	// GPUCountResourceName is the extended resource nodes advertise the number
+	// of their shareable GPUs with. The memory of the node is evenly split
+	// between them.
+	GPUCountResourceName v1.ResourceName = "sched.dev/gpu-count"
+
+	// GPUShareDeviceAnnotation is the pod annotation holding the index of the
+	// GPU a pod requesting GPUMemoryResourceName was placed on.
+	GPUShareDeviceAnnotation = "gpu-share.sched.dev/device"
+)
+
+// GPUShare is the share of a GPU of a node allocated to a pod.
+type GPUShare struct {
+	// Device is the index of the GPU in the node.
+	Device int
+	// Memory is the allocated memory in bytes.
+	Memory int64
+	// Compute is the allocated compute in percent of the GPU.
+	Compute int64
+}
+
+// GPUShareOf returns the share of a GPU allocated to the pod, and false if
+// the pod doesn't request a share or wasn't placed on a GPU yet.
+func GPUShareOf(pod *v1.Pod) (GPUShare, bool) {
+	value, ok := pod.Annotations[GPUShareDeviceAnnotation]
+	if !ok {
+		return GPUShare{}, false
+	}
+	device, err := strconv.Atoi(value)
+	if err != nil || device < 0 {
+		return GPUShare{}, false
+	}
+	memory, compute := GPUShareRequest(pod)
+	if memory == 0 {
+		return GPUShare{}, false
+	}
+	return GPUShare{Device: device, Memory: memory, Compute: compute}, true
+}
+
+// GPUShareRequest returns the GPU memory and compute requested by the pod.
+// The containers of a pod share the same GPU, so their requests add up.
+func GPUShareRequest(pod *v1.Pod) (memory int64, compute int64) {
+	for _, c := range pod.Spec.Containers {
+		memory += c.Resources.Requests.Name(GPUMemoryResourceName, "").Value()
+		compute += c.Resources.Requests.Name(GPUComputeResourceName, "").Value()
+	}
+	return memory, compute
+}
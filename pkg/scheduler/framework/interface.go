@@ -32,6 +32,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
@@ -130,6 +131,33 @@ func NewPodsToActivate() *PodsToActivate {
 	return &PodsToActivate{Map: make(map[string]*v1.Pod)}
 }
 
+// IgnoredNominatedPodsKey is a reserved state key for the nominated pods that
+// the pod being scheduled doesn't compete with. RunFilterPluginsWithNominatedPods
+// doesn't add these pods to the nodes they are nominated to, which lets a pod
+// use a node held for another pod as long as it is gone by the time the other
+// pod needs it.
+var IgnoredNominatedPodsKey StateKey = "sched.dev/ignored-nominated-pods"
+
+// IgnoredNominatedPods stores the nominated pods to ignore.
+type IgnoredNominatedPods struct {
+	// UIDs are the UIDs of the ignored pods.
+	UIDs map[types.UID]struct{}
+}
+
+// Clone just returns the same state.
+func (s *IgnoredNominatedPods) Clone() StateData {
+	return s
+}
+
+// Has returns true if the nominated pod is ignored.
+func (s *IgnoredNominatedPods) Has(uid types.UID) bool {
+	if s == nil {
+		return false
+	}
+	_, ok := s.UIDs[uid]
+	return ok
+}
+
 // Status indicates the result of running a plugin. It consists of a code, a
 // message, (optionally) an error, and a plugin name it fails by.
 // When the status code is not Success, the reasons should explain why.
@@ -307,6 +335,36 @@ type QueueSortPlugin interface {
 	Less(*QueuedPodInfo, *QueuedPodInfo) bool
 }
 
+// SortKeyFunc is the function to compute the sort key of pod info
+type SortKeyFunc func(podInfo *QueuedPodInfo) float64
+
+// QueueSortKeyPlugin is an optional interface that QueueSort plugins can
+// implement when the order of pods depends on state that changes while they
+// wait in the scheduling queue. The key is computed whenever a pod is added
+// to or moved in the active queue, and stored in QueuedPodInfo.SortKey for
+// Less to compare, so that the order of the queue doesn't change in between.
+type QueueSortKeyPlugin interface {
+	QueueSortPlugin
+	// SortKey returns the sort key of the pod.
+	SortKey(*QueuedPodInfo) float64
+}
+
+// PreEnqueuePlugin is an interface for plugins that keep Pods out of the active
+// queue until an external condition holds, such as the quota of the Pod being
+// admitted or all the members of its gang being created. They are called
+// before a Pod enters the active queue. Enabled plugins of a profile
+// implementing this interface are run for the Pods of the profile; they
+// aren't configured at an extension point of their own.
+type PreEnqueuePlugin interface {
+	Plugin
+	// PreEnqueue is called before adding the Pod to the active queue. Unless
+	// all PreEnqueue plugins return success, the Pod is parked among the gated
+	// Pods, and is checked again when it's updated, when an event registered
+	// by the rejecting plugin occurs and periodically. Pods added to the queue
+	// only check again the gated Pods of their namespace, asynchronously.
+	PreEnqueue(ctx context.Context, p *v1.Pod) *Status
+}
+
 // EnqueueExtensions is an optional interface that plugins can implement to efficiently
 // move unschedulable Pods in internal scheduling queues. Plugins
 // that fail pod scheduling (e.g., Filter plugins) are expected to implement this interface.
@@ -320,6 +378,19 @@ type EnqueueExtensions interface {
 	EventsToRegister() []ClusterEvent
 }
 
+// QueueingHintExtensions is an optional interface that plugins implementing
+// EnqueueExtensions can implement to tell apart the occurrences of their
+// registered events that may make a Pod they failed schedulable from those
+// that can't, so that the latter don't move the Pod out of the unschedulable
+// Pods.
+type QueueingHintExtensions interface {
+	EnqueueExtensions
+	// QueueingHints returns the QueueingHintFns of some of the events returned
+	// by EventsToRegister. The other events always move the Pods.
+	// Note: like EventsToRegister, the returned events need to be static.
+	QueueingHints() map[ClusterEvent]QueueingHintFn
+}
+
 // PreFilterExtensions is an interface that is included in plugins that allow specifying
 // callbacks to make incremental updates to its supposedly pre-calculated
 // state.
@@ -371,6 +442,22 @@ type FilterPlugin interface {
 	Filter(ctx context.Context, state *CycleState, pod *v1.Pod, nodeInfo *NodeInfo) *Status
 }
 
+// CacheableFilterPlugin is an optional interface for Filter plugins whose
+// verdicts can be reused for equivalent pods, i.e. pods with the same
+// scheduling-relevant spec. The scheduler only reuses the Filter verdicts of a
+// node if all the Filter plugins of the profile implement this interface.
+type CacheableFilterPlugin interface {
+	FilterPlugin
+	// FilterCacheable is called after PreFilter. It returns true if the
+	// verdict of Filter for the pod only depends on the pod and on the
+	// NodeInfo of the evaluated node, so that it holds for equivalent pods
+	// as long as the generation of the NodeInfo doesn't change. Plugins whose
+	// verdict depends on other nodes (e.g. the pods in the same topology
+	// domain) or on objects that aren't tracked in the NodeInfo must return
+	// false.
+	FilterCacheable(state *CycleState, pod *v1.Pod) bool
+}
+
 // PostFilterPlugin is an interface for "PostFilter" plugins. These plugins are called
 // after a pod cannot be scheduled.
 type PostFilterPlugin interface {
@@ -494,6 +581,10 @@ type Framework interface {
 	// QueueSortFunc returns the function to sort pods in scheduling queue
 	QueueSortFunc() LessFunc
 
+	// QueueSortKeyFunc returns the function to compute the sort key of pods
+	// in scheduling queue, or nil if the QueueSort plugin has none.
+	QueueSortKeyFunc() SortKeyFunc
+
 	// RunPreFilterPlugins runs the set of configured PreFilter plugins. It returns
 	// *Status and its code is set to non-success if any of the plugins returns
 	// anything but Success. If a non-success status is returned, then the scheduling
@@ -544,9 +635,17 @@ type Framework interface {
 	// code=5("skip") status.
 	RunBindPlugins(ctx context.Context, state *CycleState, pod *v1.Pod, nodeName string) *Status
 
+	// PreEnqueuePlugins returns the enabled plugins implementing PreEnqueuePlugin.
+	PreEnqueuePlugins() []PreEnqueuePlugin
+
 	// HasFilterPlugins returns true if at least one Filter plugin is defined.
 	HasFilterPlugins() bool
 
+	// FilterVerdictsCacheable returns true if all the Filter plugins implement
+	// CacheableFilterPlugin and report that their verdicts for the pod can be
+	// reused for equivalent pods.
+	FilterVerdictsCacheable(state *CycleState, pod *v1.Pod) bool
+
 	// HasPostFilterPlugins returns true if at least one PostFilter plugin is defined.
 	HasPostFilterPlugins() bool
 
@@ -577,6 +676,12 @@ type Handle interface {
 	// cache instead.
 	SnapshotSharedLister() SharedLister
 
+	// NamespaceUsages returns the resources used by namespaces as tracked by
+	// the scheduler cache, including assumed pods. Unlike the usage in
+	// SnapshotSharedLister, it can be used outside of scheduling cycles, e.g.
+	// to sort the scheduling queue. It can be nil, e.g. in tests.
+	NamespaceUsages() NamespaceUsageLister
+
 	// IterateOverWaitingPods acquires a read lock and iterates over the WaitingPods map.
 	IterateOverWaitingPods(callback func(WaitingPod))
 
@@ -598,6 +703,10 @@ type Handle interface {
 
 	SharedInformerFactory() informers.SharedInformerFactory
 
+	// DynInformerFactory returns a dynamic shared informer factory used to
+	// watch custom resources. It can be nil, e.g. in tests.
+	DynInformerFactory() dynamicinformer.DynamicSharedInformerFactory
+
 	// RunFilterPluginsWithNominatedPods runs the set of configured filter plugins for nominated pod on the given node.
 	RunFilterPluginsWithNominatedPods(ctx context.Context, state *CycleState, pod *v1.Pod, info *NodeInfo) *Status
 
@@ -606,6 +715,13 @@ type Handle interface {
 
 	// Parallelizer returns a parallelizer holding parallelism for scheduler.
 	Parallelizer() parallelize.Parallelizer
+
+	// SchedulingWorker returns true if the framework only finds nodes for
+	// pods on behalf of a scheduling worker. Such a framework never runs the
+	// QueueSort, PostFilter, Reserve, Permit or binding plugins, so plugins
+	// shouldn't register event handlers that only serve those extension
+	// points.
+	SchedulingWorker() bool
 }
 
 type NominatingMode int
@@ -28,7 +28,26 @@ type NodeInfoLister interface {
 	Get(nodeName string) (*NodeInfo, error)
 }
 
+// NamespaceUsageLister interface represents anything that can get the
+// resources used by the pods of a namespace.
+type NamespaceUsageLister interface {
+	// Returns the resources requested by the pods of the namespace that are
+	// assigned or assumed to nodes. It is never nil.
+	Get(namespace string) *Resource
+}
+
+// PodGroupPlacementLister interface represents anything that can get where
+// the members of pod groups are placed.
+type PodGroupPlacementLister interface {
+	// Returns the number of members of the pod group that are assigned or
+	// assumed to each node, keyed by node name. It is never nil and must not
+	// be modified.
+	Get(namespace, name string) map[string]int
+}
+
 // SharedLister groups scheduler-specific listers.
 type SharedLister interface {
 	NodeInfos() NodeInfoLister
+	NamespaceUsages() NamespaceUsageLister
+	PodGroupPlacements() PodGroupPlacementLister
 }
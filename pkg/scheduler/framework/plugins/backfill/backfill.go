@@ -0,0 +1,309 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backfill
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/names"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/podgroup"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+const (
+	// Name is the name of the plugin used in the plugin registry and configurations.
+	Name = names.Backfill
+
+	// ExpectedRuntimeAnnotation is the pod annotation declaring how long the
+	// pod runs once started, as a duration such as "45m". Only pods declaring
+	// an expected runtime are backfilled onto reserved nodes, and only running
+	// pods declaring one are expected to ever free up their resources.
+	ExpectedRuntimeAnnotation = "backfill.scheduling.sched.dev/expected-runtime"
+)
+
+// Backfill is a plugin that reserves nodes for the pod, or pod group, at the
+// head of the line when it cannot be scheduled, and lets shorter pods run on
+// those nodes until the reservation starts.
+//
+// When the pod fails to schedule, the plugin estimates from the expected
+// runtime of the running pods when each node will have freed up enough
+// resources for it, and nominates the pod to the node that does so first.
+// The nomination makes the scheduler keep the node for the pod from pods of
+// the same or lower priority. Pods whose expected runtime ends before the
+// reservation starts ignore the nomination in RunFilterPluginsWithNominatedPods
+// and can use the node in the meantime.
+type Backfill struct {
+	fh        framework.Handle
+	podLister corelisters.PodLister
+	clock     clock.Clock
+
+	mu sync.Mutex
+	// holder identifies the pod or pod group the reservations are made for.
+	holder         string
+	holderPriority int32
+	// reservations are keyed by the UID of the pod they are made for.
+	reservations map[types.UID]*reservation
+	// displaced are the pods whose reservations were taken over by a pod of a
+	// higher priority, and whose nomination must be cleared.
+	displaced map[types.UID]struct{}
+}
+
+// reservation is a node held for a pod from the time enough resources are
+// expected to be freed up for it.
+type reservation struct {
+	pod      *v1.Pod
+	nodeName string
+	start    time.Time
+}
+
+var _ framework.PreFilterPlugin = &Backfill{}
+var _ framework.PostFilterPlugin = &Backfill{}
+var _ framework.ReservePlugin = &Backfill{}
+
+// New initializes a new plugin and returns it.
+func New(_ runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+	return &Backfill{
+		fh:           fh,
+		podLister:    fh.SharedInformerFactory().Core().V1().Pods().Lister(),
+		clock:        clock.RealClock{},
+		reservations: make(map[types.UID]*reservation),
+		displaced:    make(map[types.UID]struct{}),
+	}, nil
+}
+
+// Name returns name of the plugin. It is used in logs, etc.
+func (pl *Backfill) Name() string {
+	return Name
+}
+
+// PreFilter lets a pod that declares an expected runtime ignore the
+// reservations starting after it is expected to have finished.
+func (pl *Backfill) PreFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod) *framework.Status {
+	duration, ok, err := expectedRuntime(pod)
+	if err != nil {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, err.Error())
+	}
+	if !ok {
+		return nil
+	}
+	finish := pl.clock.Now().Add(duration)
+
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	pl.pruneLocked()
+	if pl.holder == holderOf(pod) {
+		// Members of the group the nodes are reserved for don't backfill
+		// each other's nodes.
+		return nil
+	}
+	ignored := &framework.IgnoredNominatedPods{UIDs: make(map[types.UID]struct{})}
+	for uid, r := range pl.reservations {
+		if !finish.After(r.start) {
+			ignored.UIDs[uid] = struct{}{}
+		}
+	}
+	if len(ignored.UIDs) > 0 {
+		state.Write(framework.IgnoredNominatedPodsKey, ignored)
+	}
+	return nil
+}
+
+// PreFilterExtensions returns nil as the plugin doesn't keep any state that
+// depends on the pods of a node.
+func (pl *Backfill) PreFilterExtensions() framework.PreFilterExtensions {
+	return nil
+}
+
+// PostFilter reserves the node that frees up enough resources for the pod the
+// soonest, unless the nodes are already reserved for a pod or pod group of the
+// same or a higher priority. The reservation takes the form of a nomination.
+func (pl *Backfill) PostFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, m framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+	holder, priority := holderOf(pod), corev1helpers.PodPriority(pod)
+
+	pl.mu.Lock()
+	pl.pruneLocked()
+	_, reserved := pl.reservations[pod.UID]
+	_, displaced := pl.displaced[pod.UID]
+	delete(pl.reservations, pod.UID)
+	delete(pl.displaced, pod.UID)
+	eligible := pl.holder == "" || pl.holder == holder || priority > pl.holderPriority
+	pl.mu.Unlock()
+
+	// The pod loses its nomination if it holds no reservation anymore.
+	var result *framework.PostFilterResult
+	if reserved || displaced {
+		result = framework.NewPostFilterResultWithNominatedNode("")
+	}
+	if !eligible {
+		return result, framework.NewStatus(framework.Unschedulable, "nodes are reserved for another pod")
+	}
+
+	nodeName, start, err := pl.earliestNode(ctx, state, pod, m)
+	if err != nil {
+		return nil, framework.AsStatus(err)
+	}
+	if nodeName == "" {
+		return result, framework.NewStatus(framework.Unschedulable, "no node is expected to free up enough resources")
+	}
+
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if pl.holder != holder {
+		for uid, r := range pl.reservations {
+			klog.V(4).InfoS("Reservation taken over by a pod of a higher priority", "pod", klog.KObj(r.pod), "node", r.nodeName, "preemptor", klog.KObj(pod))
+			pl.fh.DeleteNominatedPodIfExists(r.pod)
+			pl.displaced[uid] = struct{}{}
+		}
+		pl.reservations = make(map[types.UID]*reservation)
+		pl.holder, pl.holderPriority = holder, priority
+	}
+	pl.reservations[pod.UID] = &reservation{pod: pod, nodeName: nodeName, start: start}
+	klog.V(3).InfoS("Reserved node", "pod", klog.KObj(pod), "node", nodeName, "start", start)
+	return framework.NewPostFilterResultWithNominatedNode(nodeName), framework.NewStatus(framework.Success)
+}
+
+// Reserve releases the reservation of the pod now that it is scheduled.
+func (pl *Backfill) Reserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	delete(pl.reservations, pod.UID)
+	delete(pl.displaced, pod.UID)
+	if len(pl.reservations) == 0 {
+		pl.holder = ""
+	}
+	return nil
+}
+
+// Unreserve does nothing. A pod that fails to bind makes a new reservation
+// the next time it cannot be scheduled.
+func (pl *Backfill) Unreserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) {
+}
+
+// pruneLocked drops the reservations of the pods that were deleted or bound
+// since the reservations were made. pl.mu must be held.
+func (pl *Backfill) pruneLocked() {
+	for uid, r := range pl.reservations {
+		pod, err := pl.podLister.Pods(r.pod.Namespace).Get(r.pod.Name)
+		if err != nil || pod.UID != uid || pod.Spec.NodeName != "" {
+			delete(pl.reservations, uid)
+		}
+	}
+	if len(pl.reservations) == 0 {
+		pl.holder = ""
+	}
+}
+
+// earliestNode returns the node that frees up enough resources for the pod
+// the soonest, and the time it does. It returns an empty node name if no
+// node is expected to.
+func (pl *Backfill) earliestNode(ctx context.Context, state *framework.CycleState, pod *v1.Pod, m framework.NodeToStatusMap) (string, time.Time, error) {
+	nodeInfos, err := pl.fh.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	now := pl.clock.Now()
+	var nodeName string
+	var start time.Time
+	for _, nodeInfo := range nodeInfos {
+		name := nodeInfo.Node().Name
+		// Removing pods doesn't help on nodes that failed unresolvably.
+		if m[name].Code() == framework.UnschedulableAndUnresolvable {
+			continue
+		}
+		t, ok := pl.freeAt(ctx, state, pod, nodeInfo, now)
+		if ok && (nodeName == "" || t.Before(start)) {
+			nodeName, start = name, t
+		}
+	}
+	return nodeName, start, nil
+}
+
+// freeAt returns the time the node is expected to have freed up enough
+// resources for the pod, and false if it never is. Running pods leave when
+// their expected runtime is over, or right away if it already is, and never
+// if they don't declare one.
+func (pl *Backfill) freeAt(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo, now time.Time) (time.Time, bool) {
+	type departure struct {
+		podInfo *framework.PodInfo
+		at      time.Time
+	}
+	var departures []departure
+	for _, pi := range nodeInfo.Pods {
+		duration, ok, err := expectedRuntime(pi.Pod)
+		if err != nil || !ok {
+			continue
+		}
+		at := util.GetPodStartTime(pi.Pod).Add(duration)
+		if at.Before(now) {
+			at = now
+		}
+		departures = append(departures, departure{podInfo: pi, at: at})
+	}
+	sort.SliceStable(departures, func(i, j int) bool {
+		return departures[i].at.Before(departures[j].at)
+	})
+
+	nodeInfoCopy := nodeInfo.Clone()
+	stateCopy := state.Clone()
+	for _, d := range departures {
+		if err := nodeInfoCopy.RemovePod(d.podInfo.Pod); err != nil {
+			return time.Time{}, false
+		}
+		if s := pl.fh.RunPreFilterExtensionRemovePod(ctx, stateCopy, pod, d.podInfo, nodeInfoCopy); !s.IsSuccess() {
+			return time.Time{}, false
+		}
+		if s := pl.fh.RunFilterPluginsWithNominatedPods(ctx, stateCopy, pod, nodeInfoCopy); s.IsSuccess() {
+			return d.at, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// expectedRuntime returns the expected runtime the pod declares, and false
+// if it doesn't declare one.
+func expectedRuntime(pod *v1.Pod) (time.Duration, bool, error) {
+	value, ok := pod.Annotations[ExpectedRuntimeAnnotation]
+	if !ok {
+		return 0, false, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, false, fmt.Errorf("invalid %s annotation %q: must be a positive duration", ExpectedRuntimeAnnotation, value)
+	}
+	return duration, true, nil
+}
+
+// holderOf returns the key reservations are made for: the pod group of the
+// pod if it belongs to one, or else the pod itself.
+func holderOf(pod *v1.Pod) string {
+	if name := podgroup.Name(pod); name != "" {
+		return "podgroup/" + pod.Namespace + "/" + name
+	}
+	return "pod/" + pod.Namespace + "/" + pod.Name
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backfill
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/defaultbinder"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/feature"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/noderesources"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/runtime"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/cache"
+	internalqueue "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/queue"
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func cpuPod(name, cpu string, priority int32) *st.PodWrapper {
+	return st.MakePod().Namespace("default").Name(name).UID(name).Priority(priority).
+		Req(map[v1.ResourceName]string{v1.ResourceCPU: cpu})
+}
+
+func TestBackfill(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	now := time.Now()
+	started := metav1.NewTime(now.Add(-10 * time.Minute))
+
+	nodes := []*v1.Node{
+		st.MakeNode().Name("n1").Capacity(map[v1.ResourceName]string{v1.ResourceCPU: "8"}).Obj(),
+		st.MakeNode().Name("n2").Capacity(map[v1.ResourceName]string{v1.ResourceCPU: "8"}).Obj(),
+	}
+	// n1 frees up 4 CPUs in 20 minutes, n2 in 110 minutes.
+	existing := []*v1.Pod{
+		cpuPod("short", "4", 0).Node("n1").StartTime(started).Annotation(ExpectedRuntimeAnnotation, "30m").Obj(),
+		cpuPod("unknown-1", "2", 0).Node("n1").StartTime(started).Obj(),
+		cpuPod("long", "4", 0).Node("n2").StartTime(started).Annotation(ExpectedRuntimeAnnotation, "2h").Obj(),
+		cpuPod("unknown-2", "2", 0).Node("n2").StartTime(started).Obj(),
+	}
+	big := cpuPod("big", "6", 100).Obj()
+	medium := cpuPod("medium", "6", 50).Obj()
+	urgent := cpuPod("urgent", "6", 200).Obj()
+
+	client := clientsetfake.NewSimpleClientset(big, medium, urgent)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	var pl *Backfill
+	fwk, err := st.NewFramework(
+		[]st.RegisterPluginFunc{
+			st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			st.RegisterPluginAsExtensions(noderesources.FitName, frameworkruntime.FactoryAdapter(feature.Features{}, noderesources.NewFit), "Filter", "PreFilter"),
+			st.RegisterPluginAsExtensions(Name, func(obj runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+				p, err := New(obj, fh)
+				pl = p.(*Backfill)
+				pl.clock = testingclock.NewFakeClock(now)
+				return p, err
+			}, "PreFilter", "PostFilter", "Reserve"),
+		},
+		"",
+		frameworkruntime.WithInformerFactory(informerFactory),
+		frameworkruntime.WithPodNominator(internalqueue.NewPodNominator(informerFactory.Core().V1().Pods().Lister())),
+		frameworkruntime.WithSnapshotSharedLister(cache.NewSnapshot(existing, nodes)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	informerFactory.Start(ctx.Done())
+	informerFactory.WaitForCacheSync(ctx.Done())
+
+	postFilter := func(pod *v1.Pod) (*framework.PostFilterResult, *framework.Status) {
+		state := framework.NewCycleState()
+		if s := fwk.RunPreFilterPlugins(ctx, state, pod); !s.IsSuccess() {
+			t.Fatalf("PreFilter of %v failed: %v", pod.Name, s)
+		}
+		result, status := fwk.RunPostFilterPlugins(ctx, state, pod, framework.NodeToStatusMap{})
+		if result != nil {
+			fwk.AddNominatedPod(framework.NewPodInfo(pod), result.NominatingInfo)
+		}
+		return result, status
+	}
+
+	result, status := postFilter(big)
+	if !status.IsSuccess() || result == nil || result.NominatedNodeName != "n1" {
+		t.Fatalf("expected big to reserve n1, got %v, %v", result, status)
+	}
+	if got, want := pl.reservations[big.UID].start, now.Add(20*time.Minute); !got.Equal(want) {
+		t.Errorf("unexpected reservation start: want %v, got %v", want, got)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		pod     *v1.Pod
+		wantFit bool
+	}{
+		{
+			name:    "pod finishing before the reservation starts is backfilled",
+			pod:     cpuPod("quick", "2", 0).Annotation(ExpectedRuntimeAnnotation, "10m").Obj(),
+			wantFit: true,
+		},
+		{
+			name: "pod finishing after the reservation starts doesn't fit",
+			pod:  cpuPod("slow", "2", 0).Annotation(ExpectedRuntimeAnnotation, "1h").Obj(),
+		},
+		{
+			name: "pod without an expected runtime doesn't fit",
+			pod:  cpuPod("unbounded", "2", 0).Obj(),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			state := framework.NewCycleState()
+			if s := fwk.RunPreFilterPlugins(ctx, state, tt.pod); !s.IsSuccess() {
+				t.Fatalf("PreFilter failed: %v", s)
+			}
+			nodeInfo, err := fwk.SnapshotSharedLister().NodeInfos().Get("n1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := fwk.RunFilterPluginsWithNominatedPods(ctx, state, tt.pod, nodeInfo); s.IsSuccess() != tt.wantFit {
+				t.Errorf("unexpected fit: want %v, got %v", tt.wantFit, s)
+			}
+		})
+	}
+
+	if result, status := postFilter(medium); status.IsSuccess() || result != nil {
+		t.Errorf("expected medium to not take over the reservation, got %v, %v", result, status)
+	}
+	if result, status := postFilter(urgent); !status.IsSuccess() || result == nil || result.NominatedNodeName != "n1" {
+		t.Errorf("expected urgent to take over the reservation, got %v, %v", result, status)
+	}
+	// big lost its reservation and must lose its nomination too.
+	if result, status := postFilter(big); status.IsSuccess() || result == nil || result.Mode() != framework.ModeOverride || result.NominatedNodeName != "" {
+		t.Errorf("expected the nomination of big to be cleared, got %v, %v", result, status)
+	}
+}
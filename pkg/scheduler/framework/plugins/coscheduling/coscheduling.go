@@ -0,0 +1,367 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coscheduling
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config/validation"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/scheduling/v1alpha1"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/names"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/podgroup"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+	v1resource "k8s.io/kubernetes/pkg/api/v1/resource"
+)
+
+const (
+	// Name is the name of the plugin used in the plugin registry and configurations.
+	Name = names.Coscheduling
+
+	// PodGroupLabel is the label a pod carries to declare the pod group it
+	// belongs to. Its value is the name of the group, which is scoped to the
+	// pod's namespace. If a PodGroup object with that name exists, its spec
+	// takes precedence over PodGroupMinAvailableLabel.
+	PodGroupLabel = v1alpha1.PodGroupLabel
+	// PodGroupMinAvailableLabel is the label holding the minimum number of
+	// members of the group that must be scheduled together, for groups
+	// without a PodGroup object.
+	PodGroupMinAvailableLabel = "pod-group.scheduling.sched.dev/min-available"
+)
+
+// Coscheduling is a plugin that schedules the members of a pod group on an
+// all-or-nothing basis. Members are held at the permit extension point until
+// minAvailable of them have been reserved, at which point the whole group is
+// allowed to bind. If the group cannot be completed in time, every waiting
+// member is rejected and unreserved.
+type Coscheduling struct {
+	fh                framework.Handle
+	podLister         corelisters.PodLister
+	pgLister          podgroup.Lister
+	permitWaitingTime time.Duration
+}
+
+// podGroup is the scheduling view of a pod group, built either from its
+// PodGroup object or from the labels of its members.
+type podGroup struct {
+	name         string
+	minAvailable int
+	minResources v1.ResourceList
+	// waitingTime overrides the plugin's permit waiting time if not zero.
+	waitingTime time.Duration
+}
+
+var _ framework.PreFilterPlugin = &Coscheduling{}
+var _ framework.PostFilterPlugin = &Coscheduling{}
+var _ framework.ReservePlugin = &Coscheduling{}
+var _ framework.PermitPlugin = &Coscheduling{}
+var _ framework.EnqueueExtensions = &Coscheduling{}
+
+// New initializes a new plugin and returns it.
+func New(obj runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+	args, ok := obj.(*config.CoschedulingArgs)
+	if !ok {
+		return nil, fmt.Errorf("got args of type %T, want *CoschedulingArgs", obj)
+	}
+	if err := validation.ValidateCoschedulingArgs(nil, args); err != nil {
+		return nil, err
+	}
+	cs := &Coscheduling{
+		fh:                fh,
+		podLister:         fh.SharedInformerFactory().Core().V1().Pods().Lister(),
+		permitWaitingTime: time.Duration(args.PermitWaitingTimeSeconds) * time.Second,
+	}
+	if fh.DynInformerFactory() != nil {
+		cs.pgLister = podgroup.NewLister(fh.DynInformerFactory())
+	}
+	return cs, nil
+}
+
+// Name returns name of the plugin. It is used in logs, etc.
+func (cs *Coscheduling) Name() string {
+	return Name
+}
+
+// EventsToRegister returns the possible events that may make a Pod
+// failed by this plugin schedulable.
+func (cs *Coscheduling) EventsToRegister() []framework.ClusterEvent {
+	return []framework.ClusterEvent{
+		// A new member may complete a group that was rejected in PreFilter.
+		{Resource: framework.Pod, ActionType: framework.Add},
+		// Deleting pods frees resources for a group whose members did not all fit.
+		{Resource: framework.Pod, ActionType: framework.Delete},
+		{Resource: framework.Node, ActionType: framework.Add},
+		// Creating or relaxing a PodGroup may lower minMember or minResources.
+		{Resource: podgroup.GVK, ActionType: framework.Add | framework.Update},
+	}
+}
+
+// PreFilter rejects the pod if its group doesn't have enough members to ever
+// reach minAvailable, or if the cluster doesn't have the minimum resources
+// the group requires.
+func (cs *Coscheduling) PreFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod) *framework.Status {
+	pg, err := cs.podGroupOf(pod)
+	if err != nil {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, err.Error())
+	}
+	if pg == nil {
+		return nil
+	}
+	total, err := cs.countGroupPods(pod.Namespace, pg.name)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if total < pg.minAvailable {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable,
+			fmt.Sprintf("pod group %q has %d pods, less than minAvailable %d", pg.name, total, pg.minAvailable))
+	}
+	if len(pg.minResources) != 0 {
+		if insufficient := cs.insufficientResources(pod.Namespace, pg); len(insufficient) != 0 {
+			return framework.NewStatus(framework.Unschedulable,
+				fmt.Sprintf("pod group %q requires more %v than available in the cluster", pg.name, insufficient))
+		}
+	}
+	return nil
+}
+
+// PreFilterExtensions returns nil as the plugin doesn't need to react to
+// pods being added or removed during preemption dry runs.
+func (cs *Coscheduling) PreFilterExtensions() framework.PreFilterExtensions {
+	return nil
+}
+
+// PostFilter releases the members of the pod's group that are waiting on
+// permit when the pod itself could not be placed, so that they don't hold
+// resources while the group can't be completed. It never makes the pod
+// schedulable by itself.
+func (cs *Coscheduling) PostFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, _ framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+	pg, err := cs.podGroupOf(pod)
+	if err != nil || pg == nil {
+		return nil, framework.NewStatus(framework.Unschedulable)
+	}
+	if assigned := cs.countAssignedPods(pod.Namespace, pg.name); assigned >= pg.minAvailable {
+		return nil, framework.NewStatus(framework.Unschedulable)
+	}
+	msg := fmt.Sprintf("pod group %q cannot be scheduled: member %v/%v is unschedulable", pg.name, pod.Namespace, pod.Name)
+	if n := cs.rejectWaitingPods(pod.Namespace, pg.name, msg); n > 0 {
+		klog.V(3).InfoS("Rejected waiting pod group members", "podGroup", klog.KRef(pod.Namespace, pg.name), "pod", klog.KObj(pod), "rejected", n)
+	}
+	return nil, framework.NewStatus(framework.Unschedulable, msg)
+}
+
+// Reserve is a no-op. The plugin implements the reserve extension point to
+// be notified through Unreserve when a member of a group fails after being
+// reserved.
+func (cs *Coscheduling) Reserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	return nil
+}
+
+// Unreserve rejects all the members of the pod's group that are waiting on
+// permit. It's called when the pod is rejected at permit, including when its
+// waiting time expires, or when any later phase fails, so that a group is
+// never partially bound.
+func (cs *Coscheduling) Unreserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) {
+	groupName := podgroup.Name(pod)
+	if len(groupName) == 0 {
+		return
+	}
+	msg := fmt.Sprintf("pod group %q was rejected: member %v/%v was unreserved", groupName, pod.Namespace, pod.Name)
+	if n := cs.rejectWaitingPods(pod.Namespace, groupName, msg); n > 0 {
+		klog.V(3).InfoS("Rejected waiting pod group members", "podGroup", klog.KRef(pod.Namespace, groupName), "pod", klog.KObj(pod), "rejected", n)
+	}
+}
+
+// Permit holds the pod until minAvailable members of its group have been
+// reserved. The member that completes the group allows all the waiting ones.
+func (cs *Coscheduling) Permit(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (*framework.Status, time.Duration) {
+	pg, err := cs.podGroupOf(pod)
+	if err != nil {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, err.Error()), 0
+	}
+	if pg == nil {
+		return nil, 0
+	}
+	// The snapshot includes the members assumed in previous cycles, which
+	// covers the ones waiting on permit, but not the pod being scheduled.
+	assigned := cs.countAssignedPods(pod.Namespace, pg.name)
+	if assigned+1 < pg.minAvailable {
+		klog.V(3).InfoS("Pod is waiting for its group to be scheduled", "pod", klog.KObj(pod), "podGroup", klog.KRef(pod.Namespace, pg.name), "assigned", assigned+1, "minAvailable", pg.minAvailable)
+		waitingTime := cs.permitWaitingTime
+		if pg.waitingTime != 0 {
+			waitingTime = pg.waitingTime
+		}
+		return framework.NewStatus(framework.Wait), waitingTime
+	}
+
+	klog.V(3).InfoS("Pod group is complete, allowing waiting members", "podGroup", klog.KRef(pod.Namespace, pg.name), "minAvailable", pg.minAvailable)
+	cs.fh.IterateOverWaitingPods(func(wp framework.WaitingPod) {
+		if isGroupMember(wp.GetPod(), pod.Namespace, pg.name) {
+			wp.Allow(cs.Name())
+		}
+	})
+	return nil, 0
+}
+
+// rejectWaitingPods rejects the members of the given group that are waiting
+// on permit and returns how many were rejected.
+func (cs *Coscheduling) rejectWaitingPods(namespace, groupName, msg string) int {
+	var rejected int
+	cs.fh.IterateOverWaitingPods(func(wp framework.WaitingPod) {
+		if isGroupMember(wp.GetPod(), namespace, groupName) {
+			wp.Reject(cs.Name(), msg)
+			rejected++
+		}
+	})
+	return rejected
+}
+
+// countGroupPods returns the number of live pods of the given group,
+// regardless of whether they are scheduled.
+func (cs *Coscheduling) countGroupPods(namespace, groupName string) (int, error) {
+	selector := labels.SelectorFromSet(labels.Set{PodGroupLabel: groupName})
+	pods, err := cs.podLister.Pods(namespace).List(selector)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	for _, p := range pods {
+		if p.DeletionTimestamp != nil || p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed {
+			continue
+		}
+		n++
+	}
+	return n, nil
+}
+
+// countAssignedPods returns the number of pods of the given group that are
+// assigned to a node in the current snapshot, either bound or assumed.
+func (cs *Coscheduling) countAssignedPods(namespace, groupName string) int {
+	nodeInfos, err := cs.fh.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		klog.ErrorS(err, "Cannot list nodes from the snapshot")
+		return 0
+	}
+	var n int
+	for _, nodeInfo := range nodeInfos {
+		for _, pi := range nodeInfo.Pods {
+			if pi.Pod.DeletionTimestamp == nil && isGroupMember(pi.Pod, namespace, groupName) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// insufficientResources returns the resources of the group's minResources
+// that are not available in the cluster. Resources requested by the members
+// already assigned to nodes count as available to the group.
+func (cs *Coscheduling) insufficientResources(namespace string, pg *podGroup) []v1.ResourceName {
+	nodeInfos, err := cs.fh.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		klog.ErrorS(err, "Cannot list nodes from the snapshot")
+		return nil
+	}
+	available := make(map[v1.ResourceName]*resource.Quantity, len(pg.minResources))
+	for name := range pg.minResources {
+		available[name] = resource.NewQuantity(0, resource.DecimalSI)
+	}
+	for _, nodeInfo := range nodeInfos {
+		if nodeInfo.Node() == nil {
+			continue
+		}
+		for name, quant := range nodeInfo.Node().Status.Allocatable {
+			if total, ok := available[name]; ok {
+				total.Add(quant)
+			}
+		}
+		for _, pi := range nodeInfo.Pods {
+			if isGroupMember(pi.Pod, namespace, pg.name) {
+				continue
+			}
+			reqs, _ := v1resource.PodRequestsAndLimits(pi.Pod)
+			for name, quant := range reqs {
+				if total, ok := available[name]; ok {
+					total.Sub(quant)
+				}
+			}
+		}
+	}
+
+	var insufficient []v1.ResourceName
+	for name, quant := range pg.minResources {
+		if available[name].Cmp(quant) < 0 {
+			insufficient = append(insufficient, name)
+		}
+	}
+	sort.Slice(insufficient, func(i, j int) bool { return insufficient[i] < insufficient[j] })
+	return insufficient
+}
+
+// isGroupMember returns whether the pod belongs to the given group.
+func isGroupMember(pod *v1.Pod, namespace, groupName string) bool {
+	return pod.Namespace == namespace && pod.Labels[PodGroupLabel] == groupName
+}
+
+// podGroupOf returns the group of the pod, or nil if the pod doesn't belong
+// to a group. The PodGroup object is used if it exists, otherwise the group
+// is described by the labels of the pod.
+func (cs *Coscheduling) podGroupOf(pod *v1.Pod) (*podGroup, error) {
+	groupName := podgroup.Name(pod)
+	if len(groupName) == 0 {
+		return nil, nil
+	}
+	if cs.pgLister != nil {
+		obj, err := cs.pgLister.Get(pod.Namespace, groupName)
+		if err == nil {
+			pg := &podGroup{
+				name:         groupName,
+				minAvailable: int(obj.Spec.MinMember),
+				minResources: obj.Spec.MinResources,
+			}
+			if pg.minAvailable < 1 {
+				pg.minAvailable = 1
+			}
+			if obj.Spec.ScheduleTimeoutSeconds != nil && *obj.Spec.ScheduleTimeoutSeconds > 0 {
+				pg.waitingTime = time.Duration(*obj.Spec.ScheduleTimeoutSeconds) * time.Second
+			}
+			return pg, nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	value, ok := pod.Labels[PodGroupMinAvailableLabel]
+	if !ok {
+		return &podGroup{name: groupName, minAvailable: 1}, nil
+	}
+	minAvailable, err := strconv.Atoi(value)
+	if err != nil || minAvailable < 1 {
+		return nil, fmt.Errorf("invalid value %q for label %s", value, PodGroupMinAvailableLabel)
+	}
+	return &podGroup{name: groupName, minAvailable: minAvailable}, nil
+}
@@ -0,0 +1,405 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coscheduling
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/scheduling/v1alpha1"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/fake"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/defaultbinder"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/queuesort"
+	plugintesting "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/testing"
+	frameworkruntime "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/runtime"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/cache"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/podgroup"
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dyfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+)
+
+type sharedLister struct {
+	nodeInfos fake.NodeInfoLister
+}
+
+func (s *sharedLister) NodeInfos() framework.NodeInfoLister {
+	return s.nodeInfos
+}
+
+func (s *sharedLister) NamespaceUsages() framework.NamespaceUsageLister {
+	return fake.NamespaceUsageLister{}
+}
+
+func (s *sharedLister) PodGroupPlacements() framework.PodGroupPlacementLister {
+	return fake.PodGroupPlacementLister{}
+}
+
+func groupPod(name, group, minAvailable string) *st.PodWrapper {
+	return st.MakePod().Namespace("ns").Name(name).UID(name).
+		Label(PodGroupLabel, group).Label(PodGroupMinAvailableLabel, minAvailable)
+}
+
+func TestPreFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		pod      *v1.Pod
+		existing []runtime.Object
+		wantCode framework.Code
+	}{
+		{
+			name:     "pod without group",
+			pod:      st.MakePod().Namespace("ns").Name("p").Obj(),
+			wantCode: framework.Success,
+		},
+		{
+			name: "group with enough members",
+			pod:  groupPod("p1", "pg", "2").Obj(),
+			existing: []runtime.Object{
+				groupPod("p1", "pg", "2").Obj(),
+				groupPod("p2", "pg", "2").Obj(),
+			},
+			wantCode: framework.Success,
+		},
+		{
+			name: "group with too few members",
+			pod:  groupPod("p1", "pg", "3").Obj(),
+			existing: []runtime.Object{
+				groupPod("p1", "pg", "3").Obj(),
+				groupPod("p2", "pg", "3").Obj(),
+				groupPod("p3", "other", "3").Obj(),
+			},
+			wantCode: framework.UnschedulableAndUnresolvable,
+		},
+		{
+			name: "terminating members are not counted",
+			pod:  groupPod("p1", "pg", "2").Obj(),
+			existing: []runtime.Object{
+				groupPod("p1", "pg", "2").Obj(),
+				groupPod("p2", "pg", "2").Terminating().Obj(),
+			},
+			wantCode: framework.UnschedulableAndUnresolvable,
+		},
+		{
+			name:     "invalid minAvailable",
+			pod:      groupPod("p1", "pg", "zero").Obj(),
+			wantCode: framework.UnschedulableAndUnresolvable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			args := &config.CoschedulingArgs{PermitWaitingTimeSeconds: 10}
+			p := plugintesting.SetupPluginWithInformers(ctx, t, New, args, cache.NewEmptySnapshot(), tt.existing)
+			status := p.(framework.PreFilterPlugin).PreFilter(ctx, framework.NewCycleState(), tt.pod)
+			if status.Code() != tt.wantCode {
+				t.Errorf("unexpected status code: want %v, got %v (%v)", tt.wantCode, status.Code(), status.Message())
+			}
+		})
+	}
+}
+
+func newTestFramework(t *testing.T, args *config.CoschedulingArgs, lister framework.SharedLister) framework.Framework {
+	factory := func(_ runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+		return New(args, fh)
+	}
+	informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0)
+	fwk, err := st.NewFramework(
+		[]st.RegisterPluginFunc{
+			st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			st.RegisterPluginAsExtensions(Name, factory, "Reserve", "Permit"),
+		},
+		"",
+		frameworkruntime.WithInformerFactory(informerFactory),
+		frameworkruntime.WithSnapshotSharedLister(lister),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fwk
+}
+
+func newNodeInfo(name string) *framework.NodeInfo {
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(st.MakeNode().Name(name).Obj())
+	return nodeInfo
+}
+
+func TestPermitAllowsCompleteGroup(t *testing.T) {
+	ctx := context.Background()
+	nodeInfo := newNodeInfo("node")
+	fwk := newTestFramework(t, &config.CoschedulingArgs{PermitWaitingTimeSeconds: 10}, &sharedLister{nodeInfos: fake.NodeInfoLister{nodeInfo}})
+
+	pods := []*v1.Pod{
+		groupPod("p1", "pg", "3").Obj(),
+		groupPod("p2", "pg", "3").Obj(),
+		groupPod("p3", "pg", "3").Obj(),
+	}
+	for i, pod := range pods {
+		status := fwk.RunPermitPlugins(ctx, framework.NewCycleState(), pod, "node")
+		wantCode := framework.Wait
+		if i == len(pods)-1 {
+			wantCode = framework.Success
+		}
+		if status.Code() != wantCode {
+			t.Fatalf("pod %v: want status code %v, got %v", pod.Name, wantCode, status.Code())
+		}
+		// Simulate the pod being assumed and reflected in the next snapshot.
+		nodeInfo.AddPod(pod)
+	}
+	for _, pod := range pods[:2] {
+		if status := fwk.WaitOnPermit(ctx, pod); !status.IsSuccess() {
+			t.Errorf("pod %v: expected to be allowed, got %v", pod.Name, status)
+		}
+	}
+}
+
+func TestPermitIgnoresOtherGroups(t *testing.T) {
+	ctx := context.Background()
+	nodeInfo := newNodeInfo("node")
+	fwk := newTestFramework(t, &config.CoschedulingArgs{PermitWaitingTimeSeconds: 10}, &sharedLister{nodeInfos: fake.NodeInfoLister{nodeInfo}})
+
+	waiting := groupPod("a1", "a", "2").Obj()
+	if status := fwk.RunPermitPlugins(ctx, framework.NewCycleState(), waiting, "node"); status.Code() != framework.Wait {
+		t.Fatalf("want status code %v, got %v", framework.Wait, status.Code())
+	}
+	nodeInfo.AddPod(waiting)
+
+	other := groupPod("b1", "b", "1").Obj()
+	if status := fwk.RunPermitPlugins(ctx, framework.NewCycleState(), other, "node"); !status.IsSuccess() {
+		t.Fatalf("expected pod of complete group to be allowed, got %v", status)
+	}
+	if wp := fwk.GetWaitingPod(waiting.UID); wp == nil || len(wp.GetPendingPlugins()) == 0 {
+		t.Errorf("expected pod %v to still be waiting", waiting.Name)
+	}
+	fwk.RejectWaitingPod(waiting.UID)
+}
+
+func TestPermitTimeoutRejectsGroup(t *testing.T) {
+	ctx := context.Background()
+	nodeInfo := newNodeInfo("node")
+	fwk := newTestFramework(t, &config.CoschedulingArgs{PermitWaitingTimeSeconds: 1}, &sharedLister{nodeInfos: fake.NodeInfoLister{nodeInfo}})
+
+	pod := groupPod("p1", "pg", "2").Obj()
+	if status := fwk.RunPermitPlugins(ctx, framework.NewCycleState(), pod, "node"); status.Code() != framework.Wait {
+		t.Fatalf("want status code %v, got %v", framework.Wait, status.Code())
+	}
+	status := fwk.WaitOnPermit(ctx, pod)
+	if !status.IsUnschedulable() || !strings.Contains(status.Message(), "timeout") {
+		t.Errorf("expected pod to be rejected on timeout, got %v", status)
+	}
+}
+
+func TestUnreserveRejectsWaitingMembers(t *testing.T) {
+	ctx := context.Background()
+	nodeInfo := newNodeInfo("node")
+	fwk := newTestFramework(t, &config.CoschedulingArgs{PermitWaitingTimeSeconds: 10}, &sharedLister{nodeInfos: fake.NodeInfoLister{nodeInfo}})
+
+	pods := []*v1.Pod{
+		groupPod("p1", "pg", "4").Obj(),
+		groupPod("p2", "pg", "4").Obj(),
+	}
+	for _, pod := range pods {
+		if status := fwk.RunPermitPlugins(ctx, framework.NewCycleState(), pod, "node"); status.Code() != framework.Wait {
+			t.Fatalf("pod %v: want status code %v, got %v", pod.Name, framework.Wait, status.Code())
+		}
+		nodeInfo.AddPod(pod)
+	}
+
+	failed := groupPod("p3", "pg", "4").Obj()
+	fwk.RunReservePluginsUnreserve(ctx, framework.NewCycleState(), failed, "node")
+
+	for _, pod := range pods {
+		status := fwk.WaitOnPermit(ctx, pod)
+		if !status.IsUnschedulable() || !strings.Contains(status.Message(), "was unreserved") {
+			t.Errorf("pod %v: expected to be rejected, got %v", pod.Name, status)
+		}
+	}
+}
+
+func makePodGroup(name string, minMember int32, minResources v1.ResourceList) *v1alpha1.PodGroup {
+	return &v1alpha1.PodGroup{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "PodGroup"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name},
+		Spec:       v1alpha1.PodGroupSpec{MinMember: minMember, MinResources: minResources},
+	}
+}
+
+func TestPreFilterWithPodGroup(t *testing.T) {
+	tests := []struct {
+		name      string
+		pod       *v1.Pod
+		existing  []runtime.Object
+		podGroups []runtime.Object
+		nodes     []*v1.Node
+		wantCode  framework.Code
+	}{
+		{
+			name: "pod group overrides min available label",
+			pod:  groupPod("p1", "pg", "1").Obj(),
+			existing: []runtime.Object{
+				groupPod("p1", "pg", "1").Obj(),
+			},
+			podGroups: []runtime.Object{makePodGroup("pg", 2, nil)},
+			wantCode:  framework.UnschedulableAndUnresolvable,
+		},
+		{
+			name: "labels are used when the pod group doesn't exist",
+			pod:  groupPod("p1", "pg", "1").Obj(),
+			existing: []runtime.Object{
+				groupPod("p1", "pg", "1").Obj(),
+			},
+			podGroups: []runtime.Object{makePodGroup("other", 2, nil)},
+			wantCode:  framework.Success,
+		},
+		{
+			name: "cluster has the minimum resources",
+			pod:  groupPod("p1", "pg", "1").Obj(),
+			existing: []runtime.Object{
+				groupPod("p1", "pg", "1").Obj(),
+			},
+			podGroups: []runtime.Object{makePodGroup("pg", 1, v1.ResourceList{v1.ResourceCPU: resource.MustParse("6")})},
+			nodes: []*v1.Node{
+				st.MakeNode().Name("n1").Capacity(map[v1.ResourceName]string{v1.ResourceCPU: "4"}).Obj(),
+				st.MakeNode().Name("n2").Capacity(map[v1.ResourceName]string{v1.ResourceCPU: "4"}).Obj(),
+			},
+			wantCode: framework.Success,
+		},
+		{
+			name: "cluster doesn't have the minimum resources",
+			pod:  groupPod("p1", "pg", "1").Obj(),
+			existing: []runtime.Object{
+				groupPod("p1", "pg", "1").Obj(),
+				st.MakePod().Namespace("ns").Name("other").Req(map[v1.ResourceName]string{v1.ResourceCPU: "3"}).Node("n1").Obj(),
+			},
+			podGroups: []runtime.Object{makePodGroup("pg", 1, v1.ResourceList{v1.ResourceCPU: resource.MustParse("6")})},
+			nodes: []*v1.Node{
+				st.MakeNode().Name("n1").Capacity(map[v1.ResourceName]string{v1.ResourceCPU: "4"}).Obj(),
+				st.MakeNode().Name("n2").Capacity(map[v1.ResourceName]string{v1.ResourceCPU: "4"}).Obj(),
+			},
+			wantCode: framework.Unschedulable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var pods []*v1.Pod
+			for _, obj := range tt.existing {
+				pods = append(pods, obj.(*v1.Pod))
+			}
+			snapshot := cache.NewSnapshot(pods, tt.nodes)
+			informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(tt.existing...), 0)
+			scheme := runtime.NewScheme()
+			if err := v1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			dynClient := dyfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+				map[schema.GroupVersionResource]string{podgroup.Resource: "PodGroupList"}, tt.podGroups...)
+			dynInformerFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynClient, 0)
+
+			factory := func(_ runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+				return New(&config.CoschedulingArgs{PermitWaitingTimeSeconds: 10}, fh)
+			}
+			fwk, err := st.NewFramework(
+				[]st.RegisterPluginFunc{
+					st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+					st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+					st.RegisterPreFilterPlugin(Name, factory),
+				},
+				"",
+				frameworkruntime.WithInformerFactory(informerFactory),
+				frameworkruntime.WithDynInformerFactory(dynInformerFactory),
+				frameworkruntime.WithSnapshotSharedLister(snapshot),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			informerFactory.Start(ctx.Done())
+			informerFactory.WaitForCacheSync(ctx.Done())
+			dynInformerFactory.Start(ctx.Done())
+			dynInformerFactory.WaitForCacheSync(ctx.Done())
+
+			status := fwk.RunPreFilterPlugins(ctx, framework.NewCycleState(), tt.pod)
+			if status.Code() != tt.wantCode {
+				t.Errorf("unexpected status code: want %v, got %v (%v)", tt.wantCode, status.Code(), status.Message())
+			}
+		})
+	}
+}
+
+func TestPermitUsesPodGroupTimeout(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	timeout := int32(1)
+	pg := makePodGroup("pg", 2, nil)
+	pg.Spec.ScheduleTimeoutSeconds = &timeout
+	dynClient := dyfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{podgroup.Resource: "PodGroupList"}, pg)
+	dynInformerFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynClient, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	factory := func(_ runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+		return New(&config.CoschedulingArgs{PermitWaitingTimeSeconds: 600}, fh)
+	}
+	fwk, err := st.NewFramework(
+		[]st.RegisterPluginFunc{
+			st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			st.RegisterPluginAsExtensions(Name, factory, "Reserve", "Permit"),
+		},
+		"",
+		frameworkruntime.WithInformerFactory(informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0)),
+		frameworkruntime.WithDynInformerFactory(dynInformerFactory),
+		frameworkruntime.WithSnapshotSharedLister(&sharedLister{nodeInfos: fake.NodeInfoLister{newNodeInfo("node")}}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dynInformerFactory.Start(ctx.Done())
+	dynInformerFactory.WaitForCacheSync(ctx.Done())
+
+	pod := groupPod("p1", "pg", "1").Obj()
+	if status := fwk.RunPermitPlugins(ctx, framework.NewCycleState(), pod, "node"); status.Code() != framework.Wait {
+		t.Fatalf("want status code %v, got %v", framework.Wait, status.Code())
+	}
+	start := time.Now()
+	status := fwk.WaitOnPermit(ctx, pod)
+	if !status.IsUnschedulable() || !strings.Contains(status.Message(), "timeout") {
+		t.Errorf("expected pod to be rejected on timeout, got %v", status)
+	}
+	if elapsed := time.Since(start); elapsed > 30*time.Second {
+		t.Errorf("expected the timeout of the pod group to be used, waited %v", elapsed)
+	}
+}
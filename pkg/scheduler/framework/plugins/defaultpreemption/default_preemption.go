@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"math/rand"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config/validation"
@@ -29,12 +31,15 @@ import (
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/names"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/preemption"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/metrics"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/podgroup"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/util"
 	v1 "k8s.io/api/core/v1"
 	policy "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/informers"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	policylisters "k8s.io/client-go/listers/policy/v1"
@@ -54,6 +59,11 @@ type DefaultPreemption struct {
 	args      config.DefaultPreemptionArgs
 	podLister corelisters.PodLister
 	pdbLister policylisters.PodDisruptionBudgetLister
+	notices   *preemption.Notices
+	criteria  []preemption.CandidateCriterion
+	// preemptionPolicy protects pods from preemption on top of their
+	// priority, or is nil.
+	preemptionPolicy *preemptionPolicy
 }
 
 var _ framework.PostFilterPlugin = &DefaultPreemption{}
@@ -72,11 +82,21 @@ func New(dpArgs runtime.Object, fh framework.Handle, fts feature.Features) (fram
 	if err := validation.ValidateDefaultPreemptionArgs(nil, args); err != nil {
 		return nil, err
 	}
+	pp, err := newPreemptionPolicy(args)
+	if err != nil {
+		return nil, err
+	}
 	pl := DefaultPreemption{
-		fh:        fh,
-		args:      *args,
-		podLister: fh.SharedInformerFactory().Core().V1().Pods().Lister(),
-		pdbLister: getPDBLister(fh.SharedInformerFactory(), fts.EnablePodDisruptionBudget),
+		fh:               fh,
+		args:             *args,
+		podLister:        fh.SharedInformerFactory().Core().V1().Pods().Lister(),
+		pdbLister:        getPDBLister(fh.SharedInformerFactory(), fts.EnablePodDisruptionBudget),
+		criteria:         candidateCriteria(args),
+		preemptionPolicy: pp,
+	}
+	// Scheduling workers never preempt.
+	if !fh.SchedulingWorker() {
+		pl.notices = preemption.NewNotices(fh.ClientSet(), fh.SharedInformerFactory().Core().V1().Pods().Informer(), args.EvictVictims)
 	}
 	return &pl, nil
 }
@@ -88,12 +108,18 @@ func (pl *DefaultPreemption) PostFilter(ctx context.Context, state *framework.Cy
 	}()
 
 	pe := preemption.Evaluator{
-		PluginName: names.DefaultPreemption,
-		Handler:    pl.fh,
-		PodLister:  pl.podLister,
-		PdbLister:  pl.pdbLister,
-		State:      state,
-		Interface:  pl,
+		PluginName:         names.DefaultPreemption,
+		Handler:            pl.fh,
+		PodLister:          pl.podLister,
+		PdbLister:          pl.pdbLister,
+		State:              state,
+		Notices:            pl.notices,
+		EvictVictims:       pl.args.EvictVictims,
+		CandidateCriteria:  pl.criteria,
+		PodGroupAware:      pl.args.PodGroupAware,
+		PreemptWholeGroups: pl.args.PreemptWholeGroups,
+		Budget:             pl.preemptionPolicy.evictionBudget(),
+		Interface:          pl,
 	}
 	return pe.Preempt(ctx, pod, m)
 }
@@ -156,11 +182,19 @@ func (pl *DefaultPreemption) SelectVictimsOnNode(
 		}
 		return nil
 	}
-	// As the first step, remove all the lower priority pods from the node and
-	// check if the given pod can be scheduled.
+	// As the first step, remove all the lower priority pods that aren't
+	// protected from preemption from the node and check if the given pod can
+	// be scheduled.
 	podPriority := corev1helpers.PodPriority(pod)
+	now := time.Now()
+	protected := sets.NewString()
 	for _, pi := range nodeInfo.Pods {
 		if corev1helpers.PodPriority(pi.Pod) < podPriority {
+			if reason := pl.preemptionPolicy.protects(pi.Pod, now); len(reason) != 0 {
+				klog.V(5).InfoS("Pod is protected from preemption", "pod", klog.KObj(pi.Pod), "node", klog.KObj(nodeInfo.Node()), "reason", reason)
+				protected.Insert(reason)
+				continue
+			}
 			potentialVictims = append(potentialVictims, pi)
 			if err := removePod(pi); err != nil {
 				return nil, 0, framework.AsStatus(err)
@@ -170,6 +204,9 @@ func (pl *DefaultPreemption) SelectVictimsOnNode(
 
 	// No potential victims are found, and so we don't need to evaluate the node again since its state didn't change.
 	if len(potentialVictims) == 0 {
+		if protected.Len() != 0 {
+			return nil, 0, framework.NewStatus(framework.UnschedulableAndUnresolvable, protected.List()...)
+		}
 		message := fmt.Sprintf("No victims found on node %v for preemptor pod %v", nodeInfo.Node().Name, pod.Name)
 		return nil, 0, framework.NewStatus(framework.UnschedulableAndUnresolvable, message)
 	}
@@ -181,15 +218,32 @@ func (pl *DefaultPreemption) SelectVictimsOnNode(
 	// support this case for performance reasons. Having affinity to lower
 	// priority pods is not a recommended configuration anyway.
 	if status := pl.fh.RunFilterPluginsWithNominatedPods(ctx, state, pod, nodeInfo); !status.IsSuccess() {
+		if protected.Len() != 0 && status.Code() != framework.Error {
+			// Tell that preempting the protected pods might have helped.
+			return nil, 0, framework.NewStatus(status.Code(), append(status.Reasons(), protected.List()...)...)
+		}
 		return nil, 0, status
 	}
 	var victims []*v1.Pod
 	numViolatingVictim := 0
 	sort.Slice(potentialVictims, func(i, j int) bool { return util.MoreImportantPod(potentialVictims[i].Pod, potentialVictims[j].Pod) })
+	if pl.args.PodGroupAware {
+		// Preempting a member of a pod group costs the whole group, so among
+		// victims of the same priority the members of larger groups are
+		// reprieved first.
+		sizes := groupSizes(pl.podLister, potentialVictims)
+		sort.SliceStable(potentialVictims, func(i, j int) bool {
+			pi, pj := corev1helpers.PodPriority(potentialVictims[i].Pod), corev1helpers.PodPriority(potentialVictims[j].Pod)
+			if pi != pj {
+				return pi > pj
+			}
+			return sizes[potentialVictims[i].Pod.UID] > sizes[potentialVictims[j].Pod.UID]
+		})
+	}
 	// Try to reprieve as many pods as possible. We first try to reprieve the PDB
 	// violating victims and then other non-violating ones. In both cases, we start
 	// from the highest priority victims.
-	violatingVictims, nonViolatingVictims := filterPodsWithPDBViolation(potentialVictims, pdbs)
+	violatingVictims, nonViolatingVictims := FilterPodsWithPDBViolation(potentialVictims, pdbs)
 	reprievePod := func(pi *framework.PodInfo) (bool, error) {
 		if err := addPod(pi); err != nil {
 			return false, err
@@ -219,19 +273,47 @@ func (pl *DefaultPreemption) SelectVictimsOnNode(
 			return nil, 0, framework.AsStatus(err)
 		}
 	}
+	// The siblings preempted along with the victims of whole groups are
+	// subject to the policy as well, and protect the node if any of them is
+	// protected.
+	preempted := victims
+	if pl.args.PreemptWholeGroups && pl.preemptionPolicy != nil {
+		preempted = preemption.WholeGroups(pl.podLister, victims, pod)
+		for _, sibling := range preempted[len(victims):] {
+			if reason := pl.preemptionPolicy.protects(sibling, now); len(reason) != 0 {
+				klog.V(5).InfoS("Pod group member of a victim is protected from preemption", "pod", klog.KObj(sibling), "node", klog.KObj(nodeInfo.Node()), "reason", reason)
+				return nil, 0, framework.NewStatus(framework.Unschedulable, reason)
+			}
+		}
+	}
+	if !pl.preemptionPolicy.evictionBudget().Allows(preempted, now) {
+		return nil, 0, framework.NewStatus(framework.Unschedulable, ErrReasonEvictionBudget)
+	}
 	return victims, numViolatingVictim, framework.NewStatus(framework.Success)
 }
 
 // PodEligibleToPreemptOthers determines whether this pod should be considered
 // for preempting other pods or not. If this pod has already preempted other
-// pods and those are in their graceful termination period, it shouldn't be
-// considered for preemption.
+// pods and those are in their graceful termination period, or still have time to
+// checkpoint, it shouldn't be considered for preemption.
 // We look at the node that is nominated for this pod and as long as there are
 // terminating pods on the node, we don't consider this for preempting more pods.
-func (pl *DefaultPreemption) PodEligibleToPreemptOthers(pod *v1.Pod, nominatedNodeStatus *framework.Status) bool {
+// Neither is it considered if all the lower priority pods are protected from
+// preemption.
+func (pl *DefaultPreemption) PodEligibleToPreemptOthers(pod *v1.Pod, nominatedNodeStatus *framework.Status) (bool, string) {
+	if eligible, reason := pl.PodEligibleToPreemptAnyPriority(pod, nominatedNodeStatus); !eligible {
+		return false, reason
+	}
+	return pl.anyPreemptible(pod, pl.fh.SnapshotSharedLister().NodeInfos())
+}
+
+// PodEligibleToPreemptAnyPriority is PodEligibleToPreemptOthers for plugins
+// that may preempt pods of any priority, such as ElasticQuota. It doesn't
+// check whether all the lower priority pods are protected from preemption.
+func (pl *DefaultPreemption) PodEligibleToPreemptAnyPriority(pod *v1.Pod, nominatedNodeStatus *framework.Status) (bool, string) {
 	if pod.Spec.PreemptionPolicy != nil && *pod.Spec.PreemptionPolicy == v1.PreemptNever {
 		klog.V(5).InfoS("Pod is not eligible for preemption because it has a preemptionPolicy of Never", "pod", klog.KObj(pod))
-		return false
+		return false, "not eligible due to preemptionPolicy=Never."
 	}
 	nodeInfos := pl.fh.SnapshotSharedLister().NodeInfos()
 	nomNodeName := pod.Status.NominatedNodeName
@@ -239,28 +321,80 @@ func (pl *DefaultPreemption) PodEligibleToPreemptOthers(pod *v1.Pod, nominatedNo
 		// If the pod's nominated node is considered as UnschedulableAndUnresolvable by the filters,
 		// then the pod should be considered for preempting again.
 		if nominatedNodeStatus.Code() == framework.UnschedulableAndUnresolvable {
-			return true
+			return true, ""
 		}
 
 		if nodeInfo, _ := nodeInfos.Get(nomNodeName); nodeInfo != nil {
 			podPriority := corev1helpers.PodPriority(pod)
+			now := time.Now()
 			for _, p := range nodeInfo.Pods {
-				if p.Pod.DeletionTimestamp != nil && corev1helpers.PodPriority(p.Pod) < podPriority {
+				if corev1helpers.PodPriority(p.Pod) >= podPriority {
+					continue
+				}
+				if p.Pod.DeletionTimestamp != nil {
 					// There is a terminating pod on the nominated node.
-					return false
+					return false, "not eligible due to a terminating pod on the nominated node."
+				}
+				if preemption.UnderPreemptionNotice(p.Pod, now) {
+					// There is a victim checkpointing before it's deleted.
+					return false, "not eligible due to a pod checkpointing before its preemption on the nominated node."
 				}
 			}
 		}
 	}
-	return true
+	return true, ""
+}
+
+// Protects returns why the preemption policy of the plugin protects the pod
+// from preemption at the given time, or an empty string if it doesn't.
+func (pl *DefaultPreemption) Protects(pod *v1.Pod, now time.Time) string {
+	return pl.preemptionPolicy.protects(pod, now)
+}
+
+// EvictionBudget returns the eviction budget of the plugin, if any.
+func (pl *DefaultPreemption) EvictionBudget() *preemption.EvictionBudget {
+	return pl.preemptionPolicy.evictionBudget()
+}
+
+// anyPreemptible returns true unless all the pods of lower priority than the
+// given pod are protected from preemption, and otherwise why.
+func (pl *DefaultPreemption) anyPreemptible(pod *v1.Pod, nodeInfos framework.NodeInfoLister) (bool, string) {
+	if pl.preemptionPolicy == nil {
+		return true, ""
+	}
+	allNodes, err := nodeInfos.List()
+	if err != nil {
+		// Let the preemption itself fail on the snapshot.
+		return true, ""
+	}
+	podPriority := corev1helpers.PodPriority(pod)
+	now := time.Now()
+	protected := sets.NewString()
+	for _, nodeInfo := range allNodes {
+		for _, p := range nodeInfo.Pods {
+			if corev1helpers.PodPriority(p.Pod) >= podPriority {
+				continue
+			}
+			reason := pl.preemptionPolicy.protects(p.Pod, now)
+			if len(reason) == 0 {
+				return true, ""
+			}
+			protected.Insert(reason)
+		}
+	}
+	if protected.Len() == 0 {
+		return true, ""
+	}
+	klog.V(5).InfoS("Pod is not eligible for preemption because all lower priority pods are protected", "pod", klog.KObj(pod))
+	return false, fmt.Sprintf("not eligible due to all lower priority pods being protected from preemption: %v.", strings.Join(protected.List(), ", "))
 }
 
-// filterPodsWithPDBViolation groups the given "pods" into two groups of "violatingPods"
+// FilterPodsWithPDBViolation groups the given "pods" into two groups of "violatingPods"
 // and "nonViolatingPods" based on whether their PDBs will be violated if they are
 // preempted.
 // This function is stable and does not change the order of received pods. So, if it
 // receives a sorted list, grouping will preserve the order of the input list.
-func filterPodsWithPDBViolation(podInfos []*framework.PodInfo, pdbs []*policy.PodDisruptionBudget) (violatingPodInfos, nonViolatingPodInfos []*framework.PodInfo) {
+func FilterPodsWithPDBViolation(podInfos []*framework.PodInfo, pdbs []*policy.PodDisruptionBudget) (violatingPodInfos, nonViolatingPodInfos []*framework.PodInfo) {
 	pdbsAllowed := make([]int32, len(pdbs))
 	for i, pdb := range pdbs {
 		pdbsAllowed[i] = pdb.Status.DisruptionsAllowed
@@ -307,6 +441,64 @@ func filterPodsWithPDBViolation(podInfos []*framework.PodInfo, pdbs []*policy.Po
 	return violatingPodInfos, nonViolatingPodInfos
 }
 
+// groupSizes returns the number of members assigned to a node of the pod
+// group of each of the pods, or 1 for pods without a group.
+func groupSizes(podLister corelisters.PodLister, podInfos []*framework.PodInfo) map[types.UID]int {
+	sizes := make(map[types.UID]int, len(podInfos))
+	groups := make(map[types.NamespacedName]int)
+	for _, pi := range podInfos {
+		groupName := podgroup.Name(pi.Pod)
+		if len(groupName) == 0 {
+			sizes[pi.Pod.UID] = 1
+			continue
+		}
+		key := types.NamespacedName{Namespace: pi.Pod.Namespace, Name: groupName}
+		n, ok := groups[key]
+		if !ok {
+			n = len(preemption.GroupMembers(podLister, pi.Pod))
+			if n == 0 {
+				n = 1
+			}
+			groups[key] = n
+		}
+		sizes[pi.Pod.UID] = n
+	}
+	return sizes
+}
+
+// candidateCriteria returns the criteria the candidates are ranked by, or nil
+// for the default ones.
+func candidateCriteria(args *config.DefaultPreemptionArgs) []preemption.CandidateCriterion {
+	if len(args.CandidateRanking) == 0 {
+		return nil
+	}
+	weights := map[v1.ResourceName]int64{v1.ResourceCPU: 1, v1.ResourceMemory: 1, "nvidia.com/gpu": 1}
+	if len(args.LostWorkResources) != 0 {
+		weights = make(map[v1.ResourceName]int64, len(args.LostWorkResources))
+		for _, r := range args.LostWorkResources {
+			weights[v1.ResourceName(r.Name)] = r.Weight
+		}
+	}
+	criteria := make([]preemption.CandidateCriterion, 0, len(args.CandidateRanking))
+	for _, c := range args.CandidateRanking {
+		switch c {
+		case config.RankByPDBViolations:
+			criteria = append(criteria, preemption.PDBViolations)
+		case config.RankByHighestPriority:
+			criteria = append(criteria, preemption.HighestPriority)
+		case config.RankBySumOfPriorities:
+			criteria = append(criteria, preemption.SumOfPriorities)
+		case config.RankByNumVictims:
+			criteria = append(criteria, preemption.NumVictims)
+		case config.RankByLatestStartTime:
+			criteria = append(criteria, preemption.LatestStartTime)
+		case config.RankByLostWork:
+			criteria = append(criteria, preemption.LostWork(weights))
+		}
+	}
+	return criteria
+}
+
 func getPDBLister(informerFactory informers.SharedInformerFactory, enablePodDisruptionBudget bool) policylisters.PodDisruptionBudgetLister {
 	if enablePodDisruptionBudget {
 		return informerFactory.Policy().V1().PodDisruptionBudgets().Lister()
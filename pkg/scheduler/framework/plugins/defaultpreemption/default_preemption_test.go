@@ -33,6 +33,7 @@ import (
 
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
 	configv1beta2 "github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config/v1beta2"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/scheduling/v1alpha1"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/parallelize"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/defaultbinder"
@@ -48,6 +49,7 @@ import (
 	internalcache "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/cache"
 	internalqueue "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/queue"
 	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/informers"
@@ -139,8 +141,14 @@ func (pl *TestPlugin) Filter(ctx context.Context, state *framework.CycleState, p
 func TestPostFilter(t *testing.T) {
 	onePodRes := map[v1.ResourceName]string{v1.ResourcePods: "1"}
 	nodeRes := map[v1.ResourceName]string{v1.ResourceCPU: "200m", v1.ResourceMemory: "400"}
+	withPolicy := func(set func(args *config.DefaultPreemptionArgs)) *config.DefaultPreemptionArgs {
+		args := getDefaultDefaultPreemptionArgs()
+		set(args)
+		return args
+	}
 	tests := []struct {
 		name                  string
+		args                  *config.DefaultPreemptionArgs
 		pod                   *v1.Pod
 		pods                  []*v1.Pod
 		nodes                 []*v1.Node
@@ -318,6 +326,72 @@ func TestPostFilter(t *testing.T) {
 			wantResult: framework.NewPostFilterResultWithNominatedNode("node2"),
 			wantStatus: framework.NewStatus(framework.Success),
 		},
+		{
+			name: "all lower priority pods are protected from preemption",
+			args: withPolicy(func(args *config.DefaultPreemptionArgs) {
+				args.ProtectedNamespaces = []string{metav1.NamespaceSystem}
+				args.ProtectedPodSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "critical"}}
+				args.MinRuntimeSeconds = 600
+			}),
+			pod: st.MakePod().Name("p").UID("p").Namespace(v1.NamespaceDefault).Priority(highPriority).Obj(),
+			pods: []*v1.Pod{
+				st.MakePod().Name("p1").UID("p1").Namespace(metav1.NamespaceSystem).Node("node1").Obj(),
+				st.MakePod().Name("p2").UID("p2").Namespace(v1.NamespaceDefault).Label("tier", "critical").Node("node2").Obj(),
+				st.MakePod().Name("p3").UID("p3").Namespace(v1.NamespaceDefault).Node("node3").StartTime(metav1.NewTime(time.Now().Add(-time.Minute))).Obj(),
+			},
+			nodes: []*v1.Node{
+				st.MakeNode().Name("node1").Capacity(onePodRes).Obj(),
+				st.MakeNode().Name("node2").Capacity(onePodRes).Obj(),
+				st.MakeNode().Name("node3").Capacity(onePodRes).Obj(),
+			},
+			filteredNodesStatuses: framework.NodeToStatusMap{
+				"node1": framework.NewStatus(framework.Unschedulable),
+				"node2": framework.NewStatus(framework.Unschedulable),
+				"node3": framework.NewStatus(framework.Unschedulable),
+			},
+			wantResult: nil,
+			wantStatus: framework.NewStatus(framework.Unschedulable, "not eligible due to all lower priority pods being protected from preemption: "+
+				ErrReasonProtectedNamespace+", "+ErrReasonProtectedLabels+", "+ErrReasonMinRuntime+"."),
+		},
+		{
+			name: "protected pods are explained in the status",
+			args: withPolicy(func(args *config.DefaultPreemptionArgs) {
+				args.ProtectedNamespaces = []string{metav1.NamespaceSystem}
+			}),
+			pod: st.MakePod().Name("p").UID("p").Namespace(v1.NamespaceDefault).Priority(highPriority).Obj(),
+			pods: []*v1.Pod{
+				st.MakePod().Name("p1").UID("p1").Namespace(v1.NamespaceDefault).Node("node1").Obj(),
+				st.MakePod().Name("p2").UID("p2").Namespace(metav1.NamespaceSystem).Node("node1").Obj(),
+			},
+			nodes: []*v1.Node{
+				st.MakeNode().Name("node1").Capacity(onePodRes).Obj(),
+			},
+			filteredNodesStatuses: framework.NodeToStatusMap{
+				"node1": framework.NewStatus(framework.Unschedulable),
+			},
+			wantResult: framework.NewPostFilterResultWithNominatedNode(""),
+			wantStatus: framework.NewStatus(framework.Unschedulable, "0/1 nodes are available: 1 Too many pods, 1 "+ErrReasonProtectedNamespace+"."),
+		},
+		{
+			name: "preemption would go over the eviction budget of the namespace",
+			args: withPolicy(func(args *config.DefaultPreemptionArgs) {
+				args.MaxEvictionsPerNamespace = 1
+				args.EvictionWindowSeconds = 3600
+			}),
+			pod: st.MakePod().Name("p").UID("p").Namespace(v1.NamespaceDefault).Priority(highPriority).Obj(),
+			pods: []*v1.Pod{
+				st.MakePod().Name("p1").UID("p1").Namespace(v1.NamespaceDefault).Node("node1").Obj(),
+				st.MakePod().Name("p2").UID("p2").Namespace(v1.NamespaceDefault).Node("node1").Obj(),
+			},
+			nodes: []*v1.Node{
+				st.MakeNode().Name("node1").Capacity(onePodRes).Obj(),
+			},
+			filteredNodesStatuses: framework.NodeToStatusMap{
+				"node1": framework.NewStatus(framework.Unschedulable),
+			},
+			wantResult: framework.NewPostFilterResultWithNominatedNode(""),
+			wantStatus: framework.NewStatus(framework.Unschedulable, "0/1 nodes are available: 1 "+ErrReasonEvictionBudget+"."),
+		},
 	}
 
 	for _, tt := range tests {
@@ -356,11 +430,20 @@ func TestPostFilter(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
+			args := tt.args
+			if args == nil {
+				args = getDefaultDefaultPreemptionArgs()
+			}
+			pp, err := newPreemptionPolicy(args)
+			if err != nil {
+				t.Fatal(err)
+			}
 			p := DefaultPreemption{
-				fh:        f,
-				podLister: informerFactory.Core().V1().Pods().Lister(),
-				pdbLister: getPDBLister(informerFactory, true),
-				args:      *getDefaultDefaultPreemptionArgs(),
+				fh:               f,
+				podLister:        informerFactory.Core().V1().Pods().Lister(),
+				pdbLister:        getPDBLister(informerFactory, true),
+				args:             *args,
+				preemptionPolicy: pp,
 			}
 
 			state := framework.NewCycleState()
@@ -1391,6 +1474,7 @@ func TestPodEligibleToPreemptOthers(t *testing.T) {
 	tests := []struct {
 		name                string
 		pod                 *v1.Pod
+		args                *config.DefaultPreemptionArgs
 		pods                []*v1.Pod
 		nodes               []string
 		nominatedNodeStatus *framework.Status
@@ -1412,6 +1496,18 @@ func TestPodEligibleToPreemptOthers(t *testing.T) {
 			nominatedNodeStatus: nil,
 			expected:            false,
 		},
+		{
+			name: "Pod with nominated node, and a victim checkpointing on it",
+			pod:  st.MakePod().Name("p_with_checkpointing_victim").UID("p").Priority(highPriority).NominatedNodeName("node1").Obj(),
+			pods: []*v1.Pod{func() *v1.Pod {
+				p := st.MakePod().Name("p1").UID("p1").Priority(lowPriority).Node("node1").Annotation(preemption.CheckpointGracePeriodAnnotation, "1h").Obj()
+				p.Status.Conditions = []v1.PodCondition{{Type: preemption.PreemptionNoticeCondition, Status: v1.ConditionTrue, LastTransitionTime: metav1.Now()}}
+				return p
+			}()},
+			nodes:               []string{"node1"},
+			nominatedNodeStatus: nil,
+			expected:            false,
+		},
 		{
 			name:                "Pod without nominated node",
 			pod:                 st.MakePod().Name("p_without_nominated_node").UID("p").Priority(highPriority).Obj(),
@@ -1428,6 +1524,30 @@ func TestPodEligibleToPreemptOthers(t *testing.T) {
 			nominatedNodeStatus: nil,
 			expected:            false,
 		},
+		{
+			name: "Pod whose lower priority pods are all protected",
+			pod:  st.MakePod().Name("p_with_protected_pods").UID("p").Priority(highPriority).Obj(),
+			args: &config.DefaultPreemptionArgs{ProtectedNamespaces: []string{metav1.NamespaceSystem}},
+			pods: []*v1.Pod{
+				st.MakePod().Name("p1").UID("p1").Namespace(metav1.NamespaceSystem).Priority(lowPriority).Node("node1").Obj(),
+				st.MakePod().Name("p2").UID("p2").Namespace(v1.NamespaceDefault).Priority(veryHighPriority).Node("node1").Obj(),
+			},
+			nodes:               []string{"node1"},
+			nominatedNodeStatus: nil,
+			expected:            false,
+		},
+		{
+			name: "Pod with a lower priority pod that is not protected",
+			pod:  st.MakePod().Name("p_with_preemptible_pod").UID("p").Priority(highPriority).Obj(),
+			args: &config.DefaultPreemptionArgs{ProtectedNamespaces: []string{metav1.NamespaceSystem}},
+			pods: []*v1.Pod{
+				st.MakePod().Name("p1").UID("p1").Namespace(metav1.NamespaceSystem).Priority(lowPriority).Node("node1").Obj(),
+				st.MakePod().Name("p2").UID("p2").Namespace(v1.NamespaceDefault).Priority(lowPriority).Node("node2").Obj(),
+			},
+			nodes:               []string{"node1", "node2"},
+			nominatedNodeStatus: nil,
+			expected:            true,
+		},
 	}
 
 	for _, test := range tests {
@@ -1447,7 +1567,12 @@ func TestPodEligibleToPreemptOthers(t *testing.T) {
 				t.Fatal(err)
 			}
 			pl := DefaultPreemption{fh: f}
-			if got := pl.PodEligibleToPreemptOthers(test.pod, test.nominatedNodeStatus); got != test.expected {
+			if test.args != nil {
+				if pl.preemptionPolicy, err = newPreemptionPolicy(test.args); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got, _ := pl.PodEligibleToPreemptOthers(test.pod, test.nominatedNodeStatus); got != test.expected {
 				t.Errorf("expected %t, got %t for pod: %s", test.expected, got, test.pod.Name)
 			}
 		})
@@ -1729,3 +1854,416 @@ func TestPreempt(t *testing.T) {
 		})
 	}
 }
+
+func TestPreemptWithEviction(t *testing.T) {
+	tests := []struct {
+		name        string
+		refused     []string // victims whose eviction is refused
+		lateRefused []string // victims whose eviction is only refused once it isn't a dry-run
+		grace       []string // victims asking for a grace period
+		want        *framework.PostFilterResult
+		wantCode    framework.Code
+		wantEvicted []string
+	}{
+		{
+			name:        "victims are evicted",
+			want:        framework.NewPostFilterResultWithNominatedNode("node1"),
+			wantEvicted: []string{"p1.1", "p1.2"},
+		},
+		{
+			name:        "refused eviction falls back to the next best candidate",
+			refused:     []string{"p1.1", "p1.2"},
+			want:        framework.NewPostFilterResultWithNominatedNode("node3"),
+			wantEvicted: []string{"p3.1"},
+		},
+		{
+			name:        "refused eviction of one victim doesn't evict the others",
+			refused:     []string{"p1.2"},
+			want:        framework.NewPostFilterResultWithNominatedNode("node3"),
+			wantEvicted: []string{"p3.1"},
+		},
+		{
+			name:        "eviction refused after the dry-run cancels the notices of the candidate",
+			lateRefused: []string{"p1.1"},
+			grace:       []string{"p1.2"},
+			want:        framework.NewPostFilterResultWithNominatedNode("node3"),
+			wantEvicted: []string{"p3.1"},
+		},
+		{
+			name:     "refused evictions on all candidates",
+			refused:  []string{"p1.1", "p1.2", "p3.1"},
+			wantCode: framework.Unschedulable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := st.MakePod().Name("p").UID("p").Namespace(v1.NamespaceDefault).Priority(highPriority).Req(veryLargeRes).Obj()
+			pods := []*v1.Pod{
+				st.MakePod().Name("p1.1").UID("p1.1").Namespace(v1.NamespaceDefault).Node("node1").Priority(lowPriority).Req(smallRes).Obj(),
+				st.MakePod().Name("p1.2").UID("p1.2").Namespace(v1.NamespaceDefault).Node("node1").Priority(lowPriority).Req(smallRes).Obj(),
+				st.MakePod().Name("p2.1").UID("p2.1").Namespace(v1.NamespaceDefault).Node("node2").Priority(highPriority).Req(largeRes).Obj(),
+				st.MakePod().Name("p3.1").UID("p3.1").Namespace(v1.NamespaceDefault).Node("node3").Priority(midPriority).Req(mediumRes).Obj(),
+			}
+			grace := sets.NewString(tt.grace...)
+			var objs []runtime.Object
+			for _, p := range pods {
+				if grace.Has(p.Name) {
+					p.Annotations = map[string]string{preemption.CheckpointGracePeriodAnnotation: "1h"}
+				}
+				objs = append(objs, p)
+			}
+			client := clientsetfake.NewSimpleClientset(objs...)
+			informerFactory := informers.NewSharedInformerFactory(client, 0)
+			podInformer := informerFactory.Core().V1().Pods().Informer()
+			podInformer.GetStore().Add(pod)
+			for i := range pods {
+				podInformer.GetStore().Add(pods[i])
+			}
+
+			refused := sets.NewString(tt.refused...)
+			lateRefused := sets.NewString(tt.lateRefused...)
+			evicted := sets.NewString()
+			client.PrependReactor("delete", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				t.Errorf("Unexpected deletion of pod %v", action.(clienttesting.DeleteAction).GetName())
+				return true, nil, nil
+			})
+			client.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				eviction := action.(clienttesting.CreateAction).GetObject().(*policy.Eviction)
+				dryRun := len(eviction.DeleteOptions.DryRun) != 0
+				if refused.Has(eviction.Name) || (!dryRun && lateRefused.Has(eviction.Name)) {
+					return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+				}
+				if !dryRun {
+					evicted.Insert(eviction.Name)
+				}
+				return true, nil, nil
+			})
+
+			var nodes []*v1.Node
+			for _, name := range []string{"node1", "node2", "node3"} {
+				nodes = append(nodes, st.MakeNode().Name(name).Capacity(veryLargeRes).Obj())
+			}
+			fwk, err := st.NewFramework(
+				[]st.RegisterPluginFunc{
+					st.RegisterPluginAsExtensions(noderesources.FitName, nodeResourcesFitFunc, "Filter", "PreFilter"),
+					st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+					st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+				},
+				"",
+				frameworkruntime.WithClientSet(client),
+				frameworkruntime.WithEventRecorder(&events.FakeRecorder{}),
+				frameworkruntime.WithPodNominator(internalqueue.NewPodNominator(informerFactory.Core().V1().Pods().Lister())),
+				frameworkruntime.WithSnapshotSharedLister(internalcache.NewSnapshot(pods, nodes)),
+				frameworkruntime.WithInformerFactory(informerFactory),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			state := framework.NewCycleState()
+			if s := fwk.RunPreFilterPlugins(context.Background(), state, pod); !s.IsSuccess() {
+				t.Fatalf("Unexpected preFilterStatus: %v", s)
+			}
+			args := getDefaultDefaultPreemptionArgs()
+			args.EvictVictims = true
+			pl := DefaultPreemption{
+				fh:        fwk,
+				podLister: informerFactory.Core().V1().Pods().Lister(),
+				pdbLister: getPDBLister(informerFactory, true),
+				args:      *args,
+			}
+			pe := preemption.Evaluator{
+				PluginName:   names.DefaultPreemption,
+				Handler:      pl.fh,
+				PodLister:    pl.podLister,
+				PdbLister:    pl.pdbLister,
+				State:        state,
+				Notices:      preemption.NewNotices(client, podInformer, true),
+				EvictVictims: true,
+				Interface:    &pl,
+			}
+			res, status := pe.Preempt(context.Background(), pod, make(framework.NodeToStatusMap))
+			if status.Code() != tt.wantCode {
+				t.Errorf("Unexpected status code %v, want %v: %v", status.Code(), tt.wantCode, status.Message())
+			}
+			if diff := cmp.Diff(tt.want, res); diff != "" {
+				t.Errorf("Unexpected result (-want, +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(sets.NewString(tt.wantEvicted...), evicted); diff != "" {
+				t.Errorf("Unexpected evicted pods (-want, +got):\n%s", diff)
+			}
+			for _, name := range tt.grace {
+				got, err := client.CoreV1().Pods(v1.NamespaceDefault).Get(context.Background(), name, metav1.GetOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				canceled := false
+				for _, c := range got.Status.Conditions {
+					if c.Type == preemption.PreemptionNoticeCondition {
+						canceled = c.Status == v1.ConditionFalse
+					}
+				}
+				if !canceled {
+					t.Errorf("Preemption notice of pod %v wasn't canceled", name)
+				}
+			}
+		})
+	}
+}
+
+func TestPreemptPodGroups(t *testing.T) {
+	tests := []struct {
+		name               string
+		podGroupAware      bool
+		preemptWholeGroups bool
+		standalone         int // number of victims without a group on node3
+		standaloneNS       string
+		evicted            int // number of pods of the default namespace already preempted
+		policy             func(*config.DefaultPreemptionArgs)
+		wantNodes          []string
+		wantDeleted        []string
+	}{
+		{
+			name:          "group members are costed with their siblings",
+			podGroupAware: true,
+			standalone:    2,
+			wantNodes:     []string{"node3"},
+			wantDeleted:   []string{"s3.1", "s3.2"},
+		},
+		{
+			name:               "whole group is preempted",
+			preemptWholeGroups: true,
+			standalone:         4,
+			wantNodes:          []string{"node1", "node2", "node4"},
+			wantDeleted:        []string{"m1", "m2", "m3"},
+		},
+		{
+			name:               "whole group with a protected member is not preempted",
+			preemptWholeGroups: true,
+			standalone:         4,
+			policy: func(args *config.DefaultPreemptionArgs) {
+				args.ProtectedPodSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"protected": "true"}}
+			},
+			wantNodes:   []string{"node3"},
+			wantDeleted: []string{"s3.1", "s3.2", "s3.3", "s3.4"},
+		},
+		{
+			name:               "whole group over the eviction budget is not preempted",
+			preemptWholeGroups: true,
+			standalone:         4,
+			standaloneNS:       "batch",
+			evicted:            2,
+			policy: func(args *config.DefaultPreemptionArgs) {
+				args.MaxEvictionsPerNamespace = 4
+				args.EvictionWindowSeconds = 3600
+			},
+			wantNodes:   []string{"node3"},
+			wantDeleted: []string{"s3.1", "s3.2", "s3.3", "s3.4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := st.MakePod().Name("p").UID("p").Namespace(v1.NamespaceDefault).Priority(highPriority).Req(veryLargeRes).Obj()
+			pods := []*v1.Pod{
+				st.MakePod().Name("m1").UID("m1").Namespace(v1.NamespaceDefault).Node("node1").Label(v1alpha1.PodGroupLabel, "mpi").Priority(lowPriority).Req(smallRes).Obj(),
+				st.MakePod().Name("m2").UID("m2").Namespace(v1.NamespaceDefault).Node("node2").Label(v1alpha1.PodGroupLabel, "mpi").Priority(lowPriority).Req(smallRes).Obj(),
+				st.MakePod().Name("m3").UID("m3").Namespace(v1.NamespaceDefault).Node("node4").Label(v1alpha1.PodGroupLabel, "mpi").Label("protected", "true").Priority(lowPriority).Req(smallRes).Obj(),
+			}
+			for i := 1; i <= tt.standalone; i++ {
+				name := fmt.Sprintf("s3.%d", i)
+				ns := tt.standaloneNS
+				if len(ns) == 0 {
+					ns = v1.NamespaceDefault
+				}
+				pods = append(pods, st.MakePod().Name(name).UID(name).Namespace(ns).Node("node3").Priority(lowPriority).Req(smallRes).Obj())
+			}
+			client := clientsetfake.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(client, 0)
+			podInformer := informerFactory.Core().V1().Pods().Informer()
+			podInformer.GetStore().Add(pod)
+			for i := range pods {
+				podInformer.GetStore().Add(pods[i])
+			}
+			deleted := sets.NewString()
+			client.PrependReactor("delete", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				deleted.Insert(action.(clienttesting.DeleteAction).GetName())
+				return true, nil, nil
+			})
+
+			var nodes []*v1.Node
+			for _, name := range []string{"node1", "node2", "node3", "node4"} {
+				nodes = append(nodes, st.MakeNode().Name(name).Capacity(veryLargeRes).Obj())
+			}
+			fwk, err := st.NewFramework(
+				[]st.RegisterPluginFunc{
+					st.RegisterPluginAsExtensions(noderesources.FitName, nodeResourcesFitFunc, "Filter", "PreFilter"),
+					st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+					st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+				},
+				"",
+				frameworkruntime.WithClientSet(client),
+				frameworkruntime.WithEventRecorder(&events.FakeRecorder{}),
+				frameworkruntime.WithPodNominator(internalqueue.NewPodNominator(informerFactory.Core().V1().Pods().Lister())),
+				frameworkruntime.WithSnapshotSharedLister(internalcache.NewSnapshot(pods, nodes)),
+				frameworkruntime.WithInformerFactory(informerFactory),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			state := framework.NewCycleState()
+			if s := fwk.RunPreFilterPlugins(context.Background(), state, pod); !s.IsSuccess() {
+				t.Fatalf("Unexpected preFilterStatus: %v", s)
+			}
+			args := getDefaultDefaultPreemptionArgs()
+			args.PodGroupAware = tt.podGroupAware
+			args.PreemptWholeGroups = tt.preemptWholeGroups
+			if tt.policy != nil {
+				tt.policy(args)
+			}
+			pp, err := newPreemptionPolicy(args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < tt.evicted; i++ {
+				pp.evictionBudget().Record(st.MakePod().Namespace(v1.NamespaceDefault).Obj(), time.Now())
+			}
+			pl := DefaultPreemption{
+				fh:               fwk,
+				podLister:        informerFactory.Core().V1().Pods().Lister(),
+				pdbLister:        getPDBLister(informerFactory, true),
+				args:             *args,
+				preemptionPolicy: pp,
+			}
+			pe := preemption.Evaluator{
+				PluginName:         names.DefaultPreemption,
+				Handler:            pl.fh,
+				PodLister:          pl.podLister,
+				PdbLister:          pl.pdbLister,
+				State:              state,
+				PodGroupAware:      tt.podGroupAware,
+				PreemptWholeGroups: tt.preemptWholeGroups,
+				Budget:             pp.evictionBudget(),
+				Interface:          &pl,
+			}
+			res, status := pe.Preempt(context.Background(), pod, make(framework.NodeToStatusMap))
+			if !status.IsSuccess() {
+				t.Fatalf("Unexpected status: %v", status)
+			}
+			if !sets.NewString(tt.wantNodes...).Has(res.NominatingInfo.NominatedNodeName) {
+				t.Errorf("Nominated node %q, want one of %v", res.NominatingInfo.NominatedNodeName, tt.wantNodes)
+			}
+			if diff := cmp.Diff(sets.NewString(tt.wantDeleted...), deleted); diff != "" {
+				t.Errorf("Unexpected deleted pods (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPreemptForPodGroupReplicas(t *testing.T) {
+	member := func(name string) *st.PodWrapper {
+		return st.MakePod().Name(name).UID(name).Namespace(v1.NamespaceDefault).Label(v1alpha1.PodGroupLabel, "train").Priority(highPriority)
+	}
+	pod := member("w0").Req(veryLargeRes).Obj()
+	replicas := []*v1.Pod{
+		member("w1").Req(veryLargeRes).Obj(),
+		member("w2").Req(veryLargeRes).Obj(),
+		// Not a replica of the preemptor, it preempts in its own cycle.
+		member("w3").Req(largeRes).Obj(),
+	}
+	pods := []*v1.Pod{
+		st.MakePod().Name("v1").UID("v1").Namespace(v1.NamespaceDefault).Node("node1").Priority(lowPriority).Req(smallRes).Obj(),
+		st.MakePod().Name("v2").UID("v2").Namespace(v1.NamespaceDefault).Node("node2").Priority(lowPriority).Req(smallRes).Obj(),
+		st.MakePod().Name("v3").UID("v3").Namespace(v1.NamespaceDefault).Node("node3").Priority(lowPriority).Req(smallRes).Obj(),
+		st.MakePod().Name("h4").UID("h4").Namespace(v1.NamespaceDefault).Node("node4").Priority(veryHighPriority).Req(smallRes).Obj(),
+	}
+	objs := []runtime.Object{pod}
+	for _, p := range append(append([]*v1.Pod(nil), replicas...), pods...) {
+		objs = append(objs, p)
+	}
+	client := clientsetfake.NewSimpleClientset(objs...)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	podInformer := informerFactory.Core().V1().Pods().Informer()
+	for _, obj := range objs {
+		podInformer.GetStore().Add(obj)
+	}
+	deleted := sets.NewString()
+	client.PrependReactor("delete", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		deleted.Insert(action.(clienttesting.DeleteAction).GetName())
+		return false, nil, nil
+	})
+
+	var nodes []*v1.Node
+	for _, name := range []string{"node1", "node2", "node3", "node4"} {
+		nodes = append(nodes, st.MakeNode().Name(name).Capacity(veryLargeRes).Obj())
+	}
+	fwk, err := st.NewFramework(
+		[]st.RegisterPluginFunc{
+			st.RegisterPluginAsExtensions(noderesources.FitName, nodeResourcesFitFunc, "Filter", "PreFilter"),
+			st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		},
+		"",
+		frameworkruntime.WithClientSet(client),
+		frameworkruntime.WithEventRecorder(&events.FakeRecorder{}),
+		frameworkruntime.WithPodNominator(internalqueue.NewPodNominator(informerFactory.Core().V1().Pods().Lister())),
+		frameworkruntime.WithSnapshotSharedLister(internalcache.NewSnapshot(pods, nodes)),
+		frameworkruntime.WithInformerFactory(informerFactory),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := framework.NewCycleState()
+	if s := fwk.RunPreFilterPlugins(context.Background(), state, pod); !s.IsSuccess() {
+		t.Fatalf("Unexpected preFilterStatus: %v", s)
+	}
+	args := getDefaultDefaultPreemptionArgs()
+	args.PodGroupAware = true
+	pl := DefaultPreemption{
+		fh:        fwk,
+		podLister: informerFactory.Core().V1().Pods().Lister(),
+		pdbLister: getPDBLister(informerFactory, true),
+		args:      *args,
+	}
+	pe := preemption.Evaluator{
+		PluginName:    names.DefaultPreemption,
+		Handler:       pl.fh,
+		PodLister:     pl.podLister,
+		PdbLister:     pl.pdbLister,
+		State:         state,
+		PodGroupAware: true,
+		Interface:     &pl,
+	}
+	res, status := pe.Preempt(context.Background(), pod, make(framework.NodeToStatusMap))
+	if !status.IsSuccess() {
+		t.Fatalf("Unexpected status: %v", status)
+	}
+	if diff := cmp.Diff(sets.NewString("v1", "v2", "v3"), deleted); diff != "" {
+		t.Errorf("Unexpected deleted pods (-want, +got):\n%s", diff)
+	}
+	nominated := sets.NewString(res.NominatingInfo.NominatedNodeName)
+	for _, name := range []string{"w1", "w2", "w3"} {
+		p, err := client.CoreV1().Pods(v1.NamespaceDefault).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodeName := p.Status.NominatedNodeName
+		if name == "w3" {
+			if len(nodeName) != 0 {
+				t.Errorf("Pod w3 is not a replica of the preemptor but was nominated to %q", nodeName)
+			}
+			continue
+		}
+		if len(nodeName) == 0 || nominated.Has(nodeName) {
+			t.Errorf("Pod %v was nominated to %q, want a node of its own", name, nodeName)
+		}
+		nominated.Insert(nodeName)
+	}
+	if diff := cmp.Diff(sets.NewString("node1", "node2", "node3"), nominated); diff != "" {
+		t.Errorf("Unexpected nominated nodes (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaultpreemption
+
+import (
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/preemption"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// ErrReasonProtectedNamespace is used for nodes where only pods in
+	// protected namespaces could be preempted.
+	ErrReasonProtectedNamespace = "node(s) had lower priority pods in namespaces protected from preemption"
+	// ErrReasonProtectedLabels is used for nodes where only pods selected by
+	// the protected pod selector could be preempted.
+	ErrReasonProtectedLabels = "node(s) had lower priority pods protected from preemption by their labels"
+	// ErrReasonMinRuntime is used for nodes where only pods that haven't run
+	// for the minimum runtime yet could be preempted.
+	ErrReasonMinRuntime = "node(s) had lower priority pods that did not run long enough to be preempted"
+	// ErrReasonEvictionBudget is used for nodes where preemption would go over
+	// the eviction budget of a namespace.
+	ErrReasonEvictionBudget = "node(s) had lower priority pods in namespaces out of eviction budget"
+)
+
+// preemptionPolicy tells which pods are protected from preemption, on top of
+// their priority and preemption policy. A nil policy protects no pod.
+type preemptionPolicy struct {
+	protectedNamespaces sets.String
+	// protectedSelector is nil if no pod is protected by its labels.
+	protectedSelector labels.Selector
+	minRuntime        time.Duration
+	// budget belongs to the plugin instance, so each profile has its own.
+	budget *preemption.EvictionBudget
+}
+
+// newPreemptionPolicy returns the policy set by the args, or nil if they
+// don't set any.
+func newPreemptionPolicy(args *config.DefaultPreemptionArgs) (*preemptionPolicy, error) {
+	p := &preemptionPolicy{
+		protectedNamespaces: sets.NewString(args.ProtectedNamespaces...),
+		minRuntime:          time.Duration(args.MinRuntimeSeconds) * time.Second,
+	}
+	if args.ProtectedPodSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(args.ProtectedPodSelector)
+		if err != nil {
+			return nil, err
+		}
+		// An empty selector protects no pod, rather than all of them.
+		if !selector.Empty() {
+			p.protectedSelector = selector
+		}
+	}
+	if args.MaxEvictionsPerNamespace > 0 {
+		p.budget = preemption.NewEvictionBudget(int(args.MaxEvictionsPerNamespace), time.Duration(args.EvictionWindowSeconds)*time.Second)
+	}
+	if p.protectedNamespaces.Len() == 0 && p.protectedSelector == nil && p.minRuntime == 0 && p.budget == nil {
+		return nil, nil
+	}
+	return p, nil
+}
+
+// protects returns why the pod may not be preempted at the given time, or
+// an empty string if it may.
+func (p *preemptionPolicy) protects(pod *v1.Pod, now time.Time) string {
+	if p == nil {
+		return ""
+	}
+	if p.protectedNamespaces.Has(pod.Namespace) {
+		return ErrReasonProtectedNamespace
+	}
+	if p.protectedSelector != nil && p.protectedSelector.Matches(labels.Set(pod.Labels)) {
+		return ErrReasonProtectedLabels
+	}
+	// A pod that didn't start yet has no work to lose.
+	if pod.Status.StartTime != nil && now.Sub(pod.Status.StartTime.Time) < p.minRuntime {
+		return ErrReasonMinRuntime
+	}
+	if p.budget.Remaining(pod.Namespace, now) <= 0 {
+		return ErrReasonEvictionBudget
+	}
+	return ""
+}
+
+// evictionBudget returns the eviction budget of the policy, if any.
+func (p *preemptionPolicy) evictionBudget() *preemption.EvictionBudget {
+	if p == nil {
+		return nil
+	}
+	return p.budget
+}
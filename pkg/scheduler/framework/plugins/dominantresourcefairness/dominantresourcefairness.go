@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dominantresourcefairness
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config/validation"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/names"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+)
+
+// Name is the name of the plugin used in the plugin registry and configurations.
+const Name = names.DominantResourceFairness
+
+// DominantResourceFairness is a plugin that sorts pods by the dominant share
+// of their namespace: the largest fraction of the allocatable resources of
+// the cluster the namespace uses, over the configured resources. Pods of the
+// namespace with the lowest dominant share come first. When shares are
+// equal, pods are sorted by priority and then by timestamp.
+//
+// The usage of namespaces comes from the scheduler cache, so it changes while
+// pods wait in the queue. Pods are ordered by the shares at the time they are
+// added to or moved in the queue, which are kept in their sort key.
+type DominantResourceFairness struct {
+	resources []v1.ResourceName
+	usages    framework.NamespaceUsageLister
+
+	// mu guards allocatable.
+	mu sync.RWMutex
+	// allocatable is the sum of the allocatable resources of the nodes.
+	allocatable map[v1.ResourceName]int64
+}
+
+var _ framework.QueueSortKeyPlugin = &DominantResourceFairness{}
+
+// New initializes a new plugin and returns it.
+func New(obj runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+	args, ok := obj.(*config.DominantResourceFairnessArgs)
+	if !ok {
+		return nil, fmt.Errorf("got args of type %T, want *DominantResourceFairnessArgs", obj)
+	}
+	if err := validation.ValidateDominantResourceFairnessArgs(nil, args); err != nil {
+		return nil, err
+	}
+	pl := &DominantResourceFairness{
+		usages:      fh.NamespaceUsages(),
+		allocatable: make(map[v1.ResourceName]int64),
+	}
+	for _, name := range args.Resources {
+		pl.resources = append(pl.resources, v1.ResourceName(name))
+	}
+	// Scheduling workers never sort the queue.
+	if fh.SharedInformerFactory() != nil && !fh.SchedulingWorker() {
+		fh.SharedInformerFactory().Core().V1().Nodes().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				if node, ok := obj.(*v1.Node); ok {
+					pl.updateAllocatable(nil, node)
+				}
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				oldNode, oldOK := oldObj.(*v1.Node)
+				newNode, newOK := newObj.(*v1.Node)
+				if oldOK && newOK {
+					pl.updateAllocatable(oldNode, newNode)
+				}
+			},
+			DeleteFunc: func(obj interface{}) {
+				if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				if node, ok := obj.(*v1.Node); ok {
+					pl.updateAllocatable(node, nil)
+				}
+			},
+		})
+	}
+	return pl, nil
+}
+
+// Name returns name of the plugin.
+func (pl *DominantResourceFairness) Name() string {
+	return Name
+}
+
+// SortKey returns the dominant share of the namespace of the pod.
+func (pl *DominantResourceFairness) SortKey(pInfo *framework.QueuedPodInfo) float64 {
+	return pl.dominantShare(pInfo.Pod.Namespace)
+}
+
+// Less is the function used by the activeQ heap algorithm to sort pods.
+// It sorts pods based on the dominant share of their namespace, as stored in
+// their sort key. When shares are equal, it sorts them by priority and then
+// by PodQueueInfo.timestamp.
+func (pl *DominantResourceFairness) Less(pInfo1, pInfo2 *framework.QueuedPodInfo) bool {
+	if s1, s2 := pInfo1.SortKey, pInfo2.SortKey; s1 != s2 {
+		return s1 < s2
+	}
+	p1 := corev1helpers.PodPriority(pInfo1.Pod)
+	p2 := corev1helpers.PodPriority(pInfo2.Pod)
+	return (p1 > p2) || (p1 == p2 && pInfo1.Timestamp.Before(pInfo2.Timestamp))
+}
+
+// dominantShare returns the largest fraction of the allocatable resources
+// used by the namespace.
+func (pl *DominantResourceFairness) dominantShare(namespace string) float64 {
+	if pl.usages == nil {
+		return 0
+	}
+	usage := pl.usages.Get(namespace)
+
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	var share float64
+	for _, name := range pl.resources {
+		allocatable := pl.allocatable[name]
+		if allocatable <= 0 {
+			continue
+		}
+		if s := float64(usage.Value(name)) / float64(allocatable); s > share {
+			share = s
+		}
+	}
+	return share
+}
+
+// updateAllocatable replaces the allocatable resources of the old node by
+// the ones of the new node in the sum. Either node can be nil.
+func (pl *DominantResourceFairness) updateAllocatable(oldNode, newNode *v1.Node) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	for _, name := range pl.resources {
+		if oldNode != nil {
+			pl.allocatable[name] -= allocatableValue(oldNode, name)
+		}
+		if newNode != nil {
+			pl.allocatable[name] += allocatableValue(newNode, name)
+		}
+	}
+}
+
+// allocatableValue returns the allocatable amount of the named resource of
+// the node, with CPU in millicores.
+func allocatableValue(node *v1.Node, name v1.ResourceName) int64 {
+	q, ok := node.Status.Allocatable[name]
+	if !ok {
+		return 0
+	}
+	if name == v1.ResourceCPU {
+		return q.MilliValue()
+	}
+	return q.Value()
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dominantresourcefairness
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/fake"
+	frameworkruntime "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/runtime"
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+)
+
+const gpu = "nvidia.com/gpu"
+
+func TestLess(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The cluster has 16 CPUs, 32Gi of memory and 4 GPUs.
+	client := clientsetfake.NewSimpleClientset(
+		st.MakeNode().Name("n1").Capacity(map[v1.ResourceName]string{v1.ResourceCPU: "8", v1.ResourceMemory: "16Gi", gpu: "4"}).Obj(),
+		st.MakeNode().Name("n2").Capacity(map[v1.ResourceName]string{v1.ResourceCPU: "8", v1.ResourceMemory: "16Gi"}).Obj(),
+	)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	usages := fake.NamespaceUsageLister{
+		"cpu-heavy": {MilliCPU: 8000, Memory: 1 << 30},
+		"mem-light": {MilliCPU: 1000, Memory: 4 << 30},
+		"gpu-heavy": {MilliCPU: 1000, ScalarResources: map[v1.ResourceName]int64{gpu: 3}},
+	}
+	fh, err := frameworkruntime.NewFramework(nil, nil,
+		frameworkruntime.WithInformerFactory(informerFactory),
+		frameworkruntime.WithNamespaceUsages(usages))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := New(&config.DominantResourceFairnessArgs{Resources: []string{"cpu", "memory", gpu}}, fh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pl := p.(*DominantResourceFairness)
+	informerFactory.Start(ctx.Done())
+	informerFactory.WaitForCacheSync(ctx.Done())
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		pl.mu.RLock()
+		defer pl.mu.RUnlock()
+		return pl.allocatable[v1.ResourceCPU] == 16000 && pl.allocatable[gpu] == 4, nil
+	}); err != nil {
+		t.Fatalf("allocatable resources of the nodes not accounted for: %v", pl.allocatable)
+	}
+
+	var lowPriority, highPriority = int32(10), int32(100)
+	t1 := time.Now()
+	t2 := t1.Add(time.Second)
+	podInfo := func(namespace string, priority int32, timestamp time.Time) *framework.QueuedPodInfo {
+		pInfo := &framework.QueuedPodInfo{
+			PodInfo:   framework.NewPodInfo(st.MakePod().Namespace(namespace).Name("p").Priority(priority).Obj()),
+			Timestamp: timestamp,
+		}
+		pInfo.SortKey = pl.SortKey(pInfo)
+		return pInfo
+	}
+	for _, tt := range []struct {
+		name     string
+		p1       *framework.QueuedPodInfo
+		p2       *framework.QueuedPodInfo
+		expected bool
+	}{
+		{
+			name:     "lower dominant share comes first regardless of priority",
+			p1:       podInfo("mem-light", lowPriority, t2),
+			p2:       podInfo("cpu-heavy", highPriority, t1),
+			expected: true,
+		},
+		{
+			name:     "GPU share dominates",
+			p1:       podInfo("gpu-heavy", highPriority, t1),
+			p2:       podInfo("cpu-heavy", highPriority, t1),
+			expected: false,
+		},
+		{
+			name:     "same namespace falls back to priority",
+			p1:       podInfo("cpu-heavy", lowPriority, t1),
+			p2:       podInfo("cpu-heavy", highPriority, t2),
+			expected: false,
+		},
+		{
+			name:     "equal shares fall back to timestamp",
+			p1:       podInfo("idle-1", highPriority, t1),
+			p2:       podInfo("idle-2", highPriority, t2),
+			expected: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pl.Less(tt.p1, tt.p2); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	// The order of pods already in the queue doesn't change with the usage.
+	light, heavy := podInfo("mem-light", highPriority, t1), podInfo("cpu-heavy", highPriority, t1)
+	usages["mem-light"] = &framework.Resource{MilliCPU: 16000}
+	if !pl.Less(light, heavy) {
+		t.Error("expected the order of queued pods to be kept when the usage changes")
+	}
+	if light = podInfo("mem-light", highPriority, t1); pl.Less(light, heavy) {
+		t.Error("expected the new usage to be used when the pod is added again")
+	}
+}
@@ -0,0 +1,415 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config/validation"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/defaultpreemption"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/feature"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/names"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/preemption"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/metrics"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/util"
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	policylisters "k8s.io/client-go/listers/policy/v1"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Name is the name of the plugin used in the plugin registry and configurations.
+	Name = names.ElasticQuota
+
+	// preFilterStateKey is the key in CycleState to ElasticQuota pre-computed data.
+	preFilterStateKey = "PreFilter" + Name
+)
+
+// ElasticQuota is a plugin that admits pods according to the ElasticQuota of
+// their namespace. A namespace may always use its minimum, and may borrow
+// the unused minimums of other namespaces up to its maximum. Usage is
+// tracked per namespace by the scheduler cache and includes assumed pods.
+//
+// At PostFilter, a pod within the minimum of its namespace preempts pods of
+// namespaces that borrow resources before lower priority pods of its own
+// namespace. A pod borrowing resources only preempts lower priority pods of
+// its own namespace. Either way, the pods protected by the preemption policy
+// in the args of the plugin are never preempted.
+type ElasticQuota struct {
+	fh        framework.Handle
+	lister    *lister
+	dp        *defaultpreemption.DefaultPreemption
+	podLister corelisters.PodLister
+	pdbLister policylisters.PodDisruptionBudgetLister
+	notices   *preemption.Notices
+}
+
+var _ framework.PreFilterPlugin = &ElasticQuota{}
+var _ framework.FilterPlugin = &ElasticQuota{}
+var _ framework.PostFilterPlugin = &ElasticQuota{}
+var _ framework.EnqueueExtensions = &ElasticQuota{}
+
+// New initializes a new plugin and returns it.
+func New(obj runtime.Object, fh framework.Handle, fts feature.Features) (framework.Plugin, error) {
+	args, ok := obj.(*config.ElasticQuotaArgs)
+	if !ok {
+		return nil, fmt.Errorf("got args of type %T, want *ElasticQuotaArgs", obj)
+	}
+	if err := validation.ValidateElasticQuotaArgs(nil, args); err != nil {
+		return nil, err
+	}
+	// Candidates for preemption are shortlisted like DefaultPreemption does,
+	// and the pods it protects are protected the same way.
+	dpArgs := &config.DefaultPreemptionArgs{
+		MinCandidateNodesPercentage: args.MinCandidateNodesPercentage,
+		MinCandidateNodesAbsolute:   args.MinCandidateNodesAbsolute,
+		ProtectedNamespaces:         args.ProtectedNamespaces,
+		ProtectedPodSelector:        args.ProtectedPodSelector,
+		MaxEvictionsPerNamespace:    args.MaxEvictionsPerNamespace,
+		EvictionWindowSeconds:       args.EvictionWindowSeconds,
+		MinRuntimeSeconds:           args.MinRuntimeSeconds,
+	}
+	dp, err := defaultpreemption.New(dpArgs, fh, fts)
+	if err != nil {
+		return nil, err
+	}
+	pl := &ElasticQuota{
+		fh:        fh,
+		dp:        dp.(*defaultpreemption.DefaultPreemption),
+		podLister: fh.SharedInformerFactory().Core().V1().Pods().Lister(),
+	}
+	// Scheduling workers never preempt.
+	if !fh.SchedulingWorker() {
+		pl.notices = preemption.NewNotices(fh.ClientSet(), fh.SharedInformerFactory().Core().V1().Pods().Informer(), false)
+	}
+	if fts.EnablePodDisruptionBudget {
+		pl.pdbLister = fh.SharedInformerFactory().Policy().V1().PodDisruptionBudgets().Lister()
+	}
+	if fh.DynInformerFactory() != nil {
+		pl.lister = newLister(fh.DynInformerFactory())
+	}
+	return pl, nil
+}
+
+// Name returns name of the plugin. It is used in logs, etc.
+func (pl *ElasticQuota) Name() string {
+	return Name
+}
+
+// EventsToRegister returns the possible events that may make a Pod
+// failed by this plugin schedulable.
+func (pl *ElasticQuota) EventsToRegister() []framework.ClusterEvent {
+	return []framework.ClusterEvent{
+		{Resource: framework.Pod, ActionType: framework.Delete},
+		{Resource: GVK, ActionType: framework.Add | framework.Update},
+	}
+}
+
+// preFilterState computed at PreFilter and used at Filter and PostFilter.
+type preFilterState struct {
+	podReq *framework.Resource
+	quotas quotaInfos
+}
+
+// Clone the prefilter state.
+func (s *preFilterState) Clone() framework.StateData {
+	return &preFilterState{
+		podReq: s.podReq,
+		quotas: s.quotas.clone(),
+	}
+}
+
+// PreFilter checks the pod against the quota of its namespace.
+func (pl *ElasticQuota) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod) *framework.Status {
+	s := &preFilterState{
+		podReq: framework.PodRequests(pod),
+		quotas: make(quotaInfos),
+	}
+	if pl.lister != nil {
+		eqs, err := pl.lister.list()
+		if err != nil {
+			return framework.AsStatus(fmt.Errorf("listing ElasticQuotas: %w", err))
+		}
+		usages := pl.fh.SnapshotSharedLister().NamespaceUsages()
+		for _, eq := range eqs {
+			// Only the first quota of a namespace is considered.
+			if _, ok := s.quotas[eq.Namespace]; !ok {
+				s.quotas[eq.Namespace] = newQuotaInfo(eq, usages.Get(eq.Namespace))
+			}
+		}
+	}
+	cycleState.Write(preFilterStateKey, s)
+	return s.admit(pod)
+}
+
+// PreFilterExtensions returns prefilter extensions, pod add and remove.
+func (pl *ElasticQuota) PreFilterExtensions() framework.PreFilterExtensions {
+	return pl
+}
+
+// AddPod from pre-computed data in cycleState.
+func (pl *ElasticQuota) AddPod(ctx context.Context, cycleState *framework.CycleState, podToSchedule *v1.Pod, podInfoToAdd *framework.PodInfo, nodeInfo *framework.NodeInfo) *framework.Status {
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if q, ok := s.quotas[podInfoToAdd.Pod.Namespace]; ok {
+		q.used.AddResource(framework.PodRequests(podInfoToAdd.Pod))
+	}
+	return nil
+}
+
+// RemovePod from pre-computed data in cycleState.
+func (pl *ElasticQuota) RemovePod(ctx context.Context, cycleState *framework.CycleState, podToSchedule *v1.Pod, podInfoToRemove *framework.PodInfo, nodeInfo *framework.NodeInfo) *framework.Status {
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if q, ok := s.quotas[podInfoToRemove.Pod.Namespace]; ok {
+		q.used.SubResource(framework.PodRequests(podInfoToRemove.Pod))
+	}
+	return nil
+}
+
+func getPreFilterState(cycleState *framework.CycleState) (*preFilterState, error) {
+	c, err := cycleState.Read(preFilterStateKey)
+	if err != nil {
+		// preFilterState doesn't exist, likely PreFilter wasn't invoked.
+		return nil, fmt.Errorf("error reading %q from cycleState: %w", preFilterStateKey, err)
+	}
+
+	s, ok := c.(*preFilterState)
+	if !ok {
+		return nil, fmt.Errorf("%+v  convert to ElasticQuota.preFilterState error", c)
+	}
+	return s, nil
+}
+
+// admit checks that the pod fits in the maximum of the quota of its
+// namespace, and that the quotas can lend it what exceeds the minimum.
+func (s *preFilterState) admit(pod *v1.Pod) *framework.Status {
+	q, ok := s.quotas[pod.Namespace]
+	if !ok {
+		return nil
+	}
+	if name, over := q.overMax(s.podReq); over {
+		return framework.NewStatus(framework.Unschedulable,
+			fmt.Sprintf("Pod exceeds the maximum %v of ElasticQuota %v/%v", name, pod.Namespace, q.name))
+	}
+	if !q.overMin(s.podReq) {
+		return nil
+	}
+	if name, ok := s.quotas.canBorrow(q, s.podReq); !ok {
+		return framework.NewStatus(framework.Unschedulable,
+			fmt.Sprintf("Pod exceeds the minimum %v of ElasticQuota %v/%v and no unused %v can be borrowed", name, pod.Namespace, q.name, name))
+	}
+	return nil
+}
+
+// Filter invoked at the filter extension point. It checks the quota again
+// so that preemption accounts for the victims it removes.
+func (pl *ElasticQuota) Filter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	return s.admit(pod)
+}
+
+// PostFilter invoked at the postFilter extension point. It preempts pods to
+// give the pod back the minimum of its namespace. Pods of namespaces without
+// an ElasticQuota are left to other PostFilter plugins.
+func (pl *ElasticQuota) PostFilter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, m framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return nil, framework.AsStatus(err)
+	}
+	if _, ok := s.quotas[pod.Namespace]; !ok {
+		return nil, framework.NewStatus(framework.Unschedulable, "Pod's namespace has no ElasticQuota")
+	}
+
+	defer func() {
+		metrics.PreemptionAttempts.Inc()
+	}()
+
+	pe := preemption.Evaluator{
+		PluginName: Name,
+		Handler:    pl.fh,
+		PodLister:  pl.podLister,
+		PdbLister:  pl.pdbLister,
+		State:      cycleState,
+		Notices:    pl.notices,
+		Budget:     pl.dp.EvictionBudget(),
+		Interface:  &preemptor{DefaultPreemption: pl.dp, fh: pl.fh},
+	}
+	return pe.Preempt(ctx, pod, m)
+}
+
+// preemptor selects victims according to the quotas of their namespaces,
+// and otherwise behaves like DefaultPreemption.
+type preemptor struct {
+	*defaultpreemption.DefaultPreemption
+	fh framework.Handle
+}
+
+// PodEligibleToPreemptOthers determines whether the pod should be considered
+// for preempting other pods, like DefaultPreemption does. As pods of
+// namespaces borrowing resources are preempted whatever their priority, it
+// doesn't matter whether all the lower priority pods are protected.
+func (p *preemptor) PodEligibleToPreemptOthers(pod *v1.Pod, nominatedNodeStatus *framework.Status) (bool, string) {
+	return p.PodEligibleToPreemptAnyPriority(pod, nominatedNodeStatus)
+}
+
+// SelectVictimsOnNode finds minimum set of pods on the given node that should be preempted in order to make enough room
+// for "pod" to be scheduled. Pods of namespaces borrowing resources are preempted first.
+func (p *preemptor) SelectVictimsOnNode(
+	ctx context.Context,
+	state *framework.CycleState,
+	pod *v1.Pod,
+	nodeInfo *framework.NodeInfo,
+	pdbs []*policy.PodDisruptionBudget) ([]*v1.Pod, int, *framework.Status) {
+	s, err := getPreFilterState(state)
+	if err != nil {
+		return nil, 0, framework.AsStatus(err)
+	}
+	q := s.quotas[pod.Namespace]
+	// Only pods within the minimum of their namespace reclaim borrowed resources.
+	reclaim := q != nil && !q.overMin(s.podReq)
+
+	removePod := func(rpi *framework.PodInfo) error {
+		if err := nodeInfo.RemovePod(rpi.Pod); err != nil {
+			return err
+		}
+		status := p.fh.RunPreFilterExtensionRemovePod(ctx, state, pod, rpi, nodeInfo)
+		if !status.IsSuccess() {
+			return status.AsError()
+		}
+		return nil
+	}
+	addPod := func(api *framework.PodInfo) error {
+		nodeInfo.AddPodInfo(api)
+		status := p.fh.RunPreFilterExtensionAddPod(ctx, state, pod, api, nodeInfo)
+		if !status.IsSuccess() {
+			return status.AsError()
+		}
+		return nil
+	}
+
+	// Lower priority pods of the namespace of the pod, or of namespaces
+	// without quota, may be preempted. So may any pod of a namespace borrowing
+	// resources when the pod reclaims them. Neither may if the preemption
+	// policy protects them.
+	now := time.Now()
+	protected := sets.NewString()
+	preemptible := func(pi *framework.PodInfo) bool {
+		reason := p.Protects(pi.Pod, now)
+		if len(reason) == 0 {
+			return true
+		}
+		klog.V(5).InfoS("Pod is protected from preemption", "pod", klog.KObj(pi.Pod), "node", klog.KObj(nodeInfo.Node()), "reason", reason)
+		protected.Insert(reason)
+		return false
+	}
+	var lowerPriority, borrowed []*framework.PodInfo
+	podPriority := corev1helpers.PodPriority(pod)
+	for _, pi := range nodeInfo.Pods {
+		vq, hasQuota := s.quotas[pi.Pod.Namespace]
+		switch {
+		case pi.Pod.Namespace != pod.Namespace && hasQuota:
+			if reclaim && vq.borrowing() && preemptible(pi) {
+				borrowed = append(borrowed, pi)
+			}
+		case corev1helpers.PodPriority(pi.Pod) < podPriority:
+			if preemptible(pi) {
+				lowerPriority = append(lowerPriority, pi)
+			}
+		}
+	}
+	sort.SliceStable(lowerPriority, func(i, j int) bool { return util.MoreImportantPod(lowerPriority[i].Pod, lowerPriority[j].Pod) })
+	sort.SliceStable(borrowed, func(i, j int) bool { return util.MoreImportantPod(borrowed[i].Pod, borrowed[j].Pod) })
+	potentialVictims := append(lowerPriority, borrowed...)
+	for _, pi := range potentialVictims {
+		if err := removePod(pi); err != nil {
+			return nil, 0, framework.AsStatus(err)
+		}
+	}
+
+	// No potential victims are found, and so we don't need to evaluate the node again since its state didn't change.
+	if len(potentialVictims) == 0 {
+		if protected.Len() != 0 {
+			return nil, 0, framework.NewStatus(framework.UnschedulableAndUnresolvable, protected.List()...)
+		}
+		message := fmt.Sprintf("No victims found on node %v for preemptor pod %v", nodeInfo.Node().Name, pod.Name)
+		return nil, 0, framework.NewStatus(framework.UnschedulableAndUnresolvable, message)
+	}
+
+	if status := p.fh.RunFilterPluginsWithNominatedPods(ctx, state, pod, nodeInfo); !status.IsSuccess() {
+		if protected.Len() != 0 && status.Code() != framework.Error {
+			// Tell that preempting the protected pods might have helped.
+			return nil, 0, framework.NewStatus(status.Code(), append(status.Reasons(), protected.List()...)...)
+		}
+		return nil, 0, status
+	}
+
+	// Try to reprieve as many pods as possible, starting from the lower
+	// priority pods so that borrowed resources are reclaimed first. In both
+	// groups, the highest priority victims are reprieved first.
+	var victims []*v1.Pod
+	numViolatingVictim := 0
+	violatingVictims, nonViolatingVictims := defaultpreemption.FilterPodsWithPDBViolation(potentialVictims, pdbs)
+	reprievePod := func(pi *framework.PodInfo) (bool, error) {
+		if err := addPod(pi); err != nil {
+			return false, err
+		}
+		status := p.fh.RunFilterPluginsWithNominatedPods(ctx, state, pod, nodeInfo)
+		fits := status.IsSuccess()
+		if !fits {
+			if err := removePod(pi); err != nil {
+				return false, err
+			}
+			victims = append(victims, pi.Pod)
+			klog.V(5).InfoS("Pod is a potential preemption victim on node", "pod", klog.KObj(pi.Pod), "node", klog.KObj(nodeInfo.Node()))
+		}
+		return fits, nil
+	}
+	for _, pi := range violatingVictims {
+		if fits, err := reprievePod(pi); err != nil {
+			return nil, 0, framework.AsStatus(err)
+		} else if !fits {
+			numViolatingVictim++
+		}
+	}
+	for _, pi := range nonViolatingVictims {
+		if _, err := reprievePod(pi); err != nil {
+			return nil, 0, framework.AsStatus(err)
+		}
+	}
+	if !p.EvictionBudget().Allows(victims, now) {
+		return nil, 0, framework.NewStatus(framework.Unschedulable, defaultpreemption.ErrReasonEvictionBudget)
+	}
+	return victims, numViolatingVictim, framework.NewStatus(framework.Success)
+}
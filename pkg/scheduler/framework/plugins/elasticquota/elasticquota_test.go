@@ -0,0 +1,332 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/scheduling/v1alpha1"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/defaultbinder"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/defaultpreemption"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/feature"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/noderesources"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/runtime"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/cache"
+	internalqueue "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/queue"
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dyfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func makeQuota(namespace, min, max string) *v1alpha1.ElasticQuota {
+	eq := &v1alpha1.ElasticQuota{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "ElasticQuota"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "quota"},
+	}
+	if min != "" {
+		eq.Spec.Min = v1.ResourceList{v1.ResourceCPU: resource.MustParse(min)}
+	}
+	if max != "" {
+		eq.Spec.Max = v1.ResourceList{v1.ResourceCPU: resource.MustParse(max)}
+	}
+	return eq
+}
+
+func withPods(eq *v1alpha1.ElasticQuota, min, max string) *v1alpha1.ElasticQuota {
+	if min != "" {
+		if eq.Spec.Min == nil {
+			eq.Spec.Min = v1.ResourceList{}
+		}
+		eq.Spec.Min[v1.ResourcePods] = resource.MustParse(min)
+	}
+	if max != "" {
+		if eq.Spec.Max == nil {
+			eq.Spec.Max = v1.ResourceList{}
+		}
+		eq.Spec.Max[v1.ResourcePods] = resource.MustParse(max)
+	}
+	return eq
+}
+
+func cpuPod(namespace, name, cpu string, priority int32) *st.PodWrapper {
+	return st.MakePod().Namespace(namespace).Name(name).UID(name).Priority(priority).
+		Req(map[v1.ResourceName]string{v1.ResourceCPU: cpu})
+}
+
+func defaultArgs() *config.ElasticQuotaArgs {
+	return &config.ElasticQuotaArgs{MinCandidateNodesPercentage: 10, MinCandidateNodesAbsolute: 100}
+}
+
+func newTestFramework(ctx context.Context, t *testing.T, quotas []runtime.Object, pods []*v1.Pod, nodes []*v1.Node) framework.Framework {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	dynClient := dyfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{Resource: "ElasticQuotaList"}, quotas...)
+	dynInformerFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynClient, 0)
+	informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0)
+
+	fwk, err := st.NewFramework(
+		[]st.RegisterPluginFunc{
+			st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			st.RegisterPluginAsExtensions(noderesources.FitName, frameworkruntime.FactoryAdapter(feature.Features{}, noderesources.NewFit), "Filter", "PreFilter"),
+			st.RegisterPluginAsExtensions(Name, func(_ runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+				return New(defaultArgs(), fh, feature.Features{})
+			}, "Filter", "PreFilter"),
+		},
+		"",
+		frameworkruntime.WithInformerFactory(informerFactory),
+		frameworkruntime.WithDynInformerFactory(dynInformerFactory),
+		frameworkruntime.WithPodNominator(internalqueue.NewPodNominator(informerFactory.Core().V1().Pods().Lister())),
+		frameworkruntime.WithSnapshotSharedLister(cache.NewSnapshot(pods, nodes)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	informerFactory.Start(ctx.Done())
+	informerFactory.WaitForCacheSync(ctx.Done())
+	dynInformerFactory.Start(ctx.Done())
+	dynInformerFactory.WaitForCacheSync(ctx.Done())
+	return fwk
+}
+
+func TestPreFilter(t *testing.T) {
+	node := st.MakeNode().Name("node").Capacity(map[v1.ResourceName]string{v1.ResourceCPU: "16"}).Obj()
+	tests := []struct {
+		name     string
+		pod      *v1.Pod
+		existing []*v1.Pod
+		quotas   []runtime.Object
+		wantCode framework.Code
+	}{
+		{
+			name:     "namespace without quota",
+			pod:      cpuPod("a", "p", "8", 0).Obj(),
+			quotas:   []runtime.Object{makeQuota("b", "2", "4")},
+			wantCode: framework.Success,
+		},
+		{
+			name:     "within min",
+			pod:      cpuPod("a", "p", "1", 0).Obj(),
+			existing: []*v1.Pod{cpuPod("a", "e", "1", 0).Node("node").Obj()},
+			quotas:   []runtime.Object{makeQuota("a", "2", "4")},
+			wantCode: framework.Success,
+		},
+		{
+			name:     "over max",
+			pod:      cpuPod("a", "p", "2", 0).Obj(),
+			existing: []*v1.Pod{cpuPod("a", "e", "3", 0).Node("node").Obj()},
+			quotas:   []runtime.Object{makeQuota("a", "2", "4"), makeQuota("b", "8", "")},
+			wantCode: framework.Unschedulable,
+		},
+		{
+			name:     "borrows unused min",
+			pod:      cpuPod("a", "p", "2", 0).Obj(),
+			existing: []*v1.Pod{cpuPod("a", "e", "2", 0).Node("node").Obj()},
+			quotas:   []runtime.Object{makeQuota("a", "2", "8"), makeQuota("b", "4", "")},
+			wantCode: framework.Success,
+		},
+		{
+			name: "no unused min to borrow",
+			pod:  cpuPod("a", "p", "2", 0).Obj(),
+			existing: []*v1.Pod{
+				cpuPod("a", "e1", "2", 0).Node("node").Obj(),
+				cpuPod("b", "e2", "3", 0).Node("node").Obj(),
+			},
+			quotas:   []runtime.Object{makeQuota("a", "2", "8"), makeQuota("b", "4", "")},
+			wantCode: framework.Unschedulable,
+		},
+		{
+			name:     "over max pods",
+			pod:      cpuPod("a", "p", "1", 0).Obj(),
+			existing: []*v1.Pod{cpuPod("a", "e", "1", 0).Node("node").Obj()},
+			quotas:   []runtime.Object{withPods(makeQuota("a", "4", "8"), "", "1")},
+			wantCode: framework.Unschedulable,
+		},
+		{
+			name:     "borrows unused min pods",
+			pod:      cpuPod("a", "p", "1", 0).Obj(),
+			existing: []*v1.Pod{cpuPod("a", "e", "1", 0).Node("node").Obj()},
+			quotas:   []runtime.Object{withPods(makeQuota("a", "4", "8"), "1", ""), withPods(makeQuota("b", "4", ""), "2", "")},
+			wantCode: framework.Success,
+		},
+		{
+			name: "no unused min pods to borrow",
+			pod:  cpuPod("a", "p", "1", 0).Obj(),
+			existing: []*v1.Pod{
+				cpuPod("a", "e1", "1", 0).Node("node").Obj(),
+				cpuPod("b", "e2", "1", 0).Node("node").Obj(),
+			},
+			quotas:   []runtime.Object{withPods(makeQuota("a", "4", "8"), "1", ""), withPods(makeQuota("b", "4", ""), "1", "")},
+			wantCode: framework.Unschedulable,
+		},
+		{
+			name:     "pods of quotas without min pods aren't counted",
+			pod:      cpuPod("a", "p", "1", 0).Obj(),
+			existing: []*v1.Pod{cpuPod("b", "e", "1", 0).Node("node").Obj()},
+			quotas:   []runtime.Object{withPods(makeQuota("a", "4", "8"), "1", ""), makeQuota("b", "4", "")},
+			wantCode: framework.Success,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			fwk := newTestFramework(ctx, t, tt.quotas, tt.existing, []*v1.Node{node})
+			status := fwk.RunPreFilterPlugins(ctx, framework.NewCycleState(), tt.pod)
+			if status.Code() != tt.wantCode {
+				t.Errorf("unexpected status code: want %v, got %v (%v)", tt.wantCode, status.Code(), status.Message())
+			}
+		})
+	}
+}
+
+func TestSelectVictimsOnNode(t *testing.T) {
+	node := st.MakeNode().Name("node").Capacity(map[v1.ResourceName]string{v1.ResourceCPU: "4"}).Obj()
+	quotas := []runtime.Object{makeQuota("a", "2", "4"), makeQuota("b", "2", "4")}
+	now := time.Now()
+	tests := []struct {
+		name        string
+		args        func(*config.ElasticQuotaArgs)
+		evicted     int // number of pods of namespace b already preempted
+		pod         *v1.Pod
+		existing    []*v1.Pod
+		wantVictims []string
+		wantStatus  *framework.Status
+	}{
+		{
+			name: "borrowed resources are reclaimed before lower priority pods",
+			pod:  cpuPod("a", "p", "1", 10).Obj(),
+			existing: []*v1.Pod{
+				cpuPod("a", "a1", "1", 0).Node("node").Obj(),
+				cpuPod("b", "b1", "1", 100).Node("node").StartTime(metav1.NewTime(now.Add(-2 * time.Hour))).Obj(),
+				cpuPod("b", "b2", "1", 100).Node("node").StartTime(metav1.NewTime(now.Add(-time.Hour))).Obj(),
+				cpuPod("b", "b3", "1", 100).Node("node").StartTime(metav1.NewTime(now)).Obj(),
+			},
+			wantVictims: []string{"b3"},
+		},
+		{
+			name: "borrowing pods don't preempt other namespaces within min",
+			pod:  cpuPod("a", "p", "1", 10).Obj(),
+			existing: []*v1.Pod{
+				cpuPod("a", "a1", "1", 0).Node("node").StartTime(metav1.NewTime(now.Add(-time.Hour))).Obj(),
+				cpuPod("a", "a2", "1", 0).Node("node").StartTime(metav1.NewTime(now)).Obj(),
+				cpuPod("b", "b1", "1", 0).Node("node").Obj(),
+				cpuPod("b", "b2", "1", 0).Node("node").Obj(),
+			},
+			wantVictims: []string{"a2"},
+		},
+		{
+			name: "borrowed resources of protected namespaces aren't reclaimed",
+			args: func(args *config.ElasticQuotaArgs) {
+				args.ProtectedNamespaces = []string{"b"}
+			},
+			pod: cpuPod("a", "p", "1", 10).Obj(),
+			existing: []*v1.Pod{
+				cpuPod("a", "a1", "1", 100).Node("node").Obj(),
+				cpuPod("b", "b1", "1", 100).Node("node").Obj(),
+				cpuPod("b", "b2", "1", 100).Node("node").Obj(),
+				cpuPod("b", "b3", "1", 100).Node("node").Obj(),
+			},
+			wantStatus: framework.NewStatus(framework.UnschedulableAndUnresolvable, defaultpreemption.ErrReasonProtectedNamespace),
+		},
+		{
+			name: "protected lower priority pods aren't preempted",
+			args: func(args *config.ElasticQuotaArgs) {
+				args.ProtectedPodSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"protected": "true"}}
+			},
+			pod: cpuPod("a", "p", "1", 10).Obj(),
+			existing: []*v1.Pod{
+				cpuPod("a", "a1", "1", 0).Node("node").Label("protected", "true").Obj(),
+				cpuPod("a", "a2", "1", 0).Node("node").Obj(),
+				cpuPod("b", "b1", "1", 0).Node("node").Obj(),
+				cpuPod("b", "b2", "1", 0).Node("node").Obj(),
+			},
+			wantVictims: []string{"a2"},
+		},
+		{
+			name: "reclaiming would go over the eviction budget",
+			args: func(args *config.ElasticQuotaArgs) {
+				args.MaxEvictionsPerNamespace = 2
+				args.EvictionWindowSeconds = 3600
+			},
+			evicted: 1,
+			pod:     cpuPod("a", "p", "2", 10).Obj(),
+			existing: []*v1.Pod{
+				cpuPod("b", "b1", "1", 100).Node("node").Obj(),
+				cpuPod("b", "b2", "1", 100).Node("node").Obj(),
+				cpuPod("b", "b3", "1", 100).Node("node").Obj(),
+				cpuPod("b", "b4", "1", 100).Node("node").Obj(),
+			},
+			wantStatus: framework.NewStatus(framework.Unschedulable, defaultpreemption.ErrReasonEvictionBudget),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			fwk := newTestFramework(ctx, t, quotas, tt.existing, []*v1.Node{node})
+
+			state := framework.NewCycleState()
+			// The pod may be rejected by PreFilter, which still populates state.
+			fwk.RunPreFilterPlugins(ctx, state, tt.pod)
+			nodeInfo, err := fwk.SnapshotSharedLister().NodeInfos().Get("node")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			args := defaultArgs()
+			if tt.args != nil {
+				tt.args(args)
+			}
+			pl, err := New(args, fwk, feature.Features{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := &preemptor{DefaultPreemption: pl.(*ElasticQuota).dp, fh: fwk}
+			for i := 0; i < tt.evicted; i++ {
+				p.EvictionBudget().Record(st.MakePod().Namespace("b").Obj(), time.Now())
+			}
+			victims, _, status := p.SelectVictimsOnNode(ctx, state, tt.pod, nodeInfo.Clone(), nil)
+			if diff := cmp.Diff(tt.wantStatus, status); diff != "" {
+				t.Errorf("unexpected status (-want,+got):\n%s", diff)
+			}
+			var got []string
+			for _, v := range victims {
+				got = append(got, v.Name)
+			}
+			if diff := cmp.Diff(tt.wantVictims, got); diff != "" {
+				t.Errorf("unexpected victims (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
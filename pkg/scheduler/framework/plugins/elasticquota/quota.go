@@ -0,0 +1,204 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticquota
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/scheduling/v1alpha1"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	// Resource is the resource of the ElasticQuota custom resource.
+	Resource = v1alpha1.SchemeGroupVersion.WithResource("elasticquotas")
+
+	// GVK is the ElasticQuota resource in the format plugins use to register
+	// cluster events.
+	GVK = framework.GVK(fmt.Sprintf("%v.%v.%v", Resource.Resource, Resource.Version, Resource.Group))
+)
+
+// lister lists ElasticQuotas from an informer's cache.
+type lister struct {
+	lister cache.GenericLister
+}
+
+func newLister(dynInformerFactory dynamicinformer.DynamicSharedInformerFactory) *lister {
+	return &lister{lister: dynInformerFactory.ForResource(Resource).Lister()}
+}
+
+// list returns the ElasticQuotas of all namespaces, sorted by namespace and
+// name.
+func (l *lister) list() ([]*v1alpha1.ElasticQuota, error) {
+	objs, err := l.lister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	quotas := make([]*v1alpha1.ElasticQuota, 0, len(objs))
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("unexpected object of type %T for ElasticQuota", obj)
+		}
+		eq := &v1alpha1.ElasticQuota{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), eq); err != nil {
+			return nil, fmt.Errorf("converting ElasticQuota %v/%v: %w", u.GetNamespace(), u.GetName(), err)
+		}
+		quotas = append(quotas, eq)
+	}
+	sort.Slice(quotas, func(i, j int) bool {
+		if quotas[i].Namespace != quotas[j].Namespace {
+			return quotas[i].Namespace < quotas[j].Namespace
+		}
+		return quotas[i].Name < quotas[j].Name
+	})
+	return quotas, nil
+}
+
+// quotaInfo is the quota of a namespace along with its usage.
+type quotaInfo struct {
+	name string
+	min  *framework.Resource
+	max  *framework.Resource
+	// maxNames are the resources bounded by max.
+	maxNames []v1.ResourceName
+	// minPods is whether min guarantees a number of pods. Pods only count
+	// against the guarantees of quotas that set it.
+	minPods bool
+	used    *framework.Resource
+}
+
+func newQuotaInfo(eq *v1alpha1.ElasticQuota, used *framework.Resource) *quotaInfo {
+	q := &quotaInfo{
+		name: eq.Name,
+		min:  framework.NewResource(eq.Spec.Min),
+		max:  framework.NewResource(eq.Spec.Max),
+		used: used.Clone(),
+	}
+	for name := range eq.Spec.Max {
+		q.maxNames = append(q.maxNames, name)
+	}
+	_, q.minPods = eq.Spec.Min[v1.ResourcePods]
+	return q
+}
+
+// names returns the resources with a non-zero amount that count against the
+// guarantees of the quota.
+func (q *quotaInfo) names(r *framework.Resource) []v1.ResourceName {
+	names := resourceNames(r)
+	if q.minPods && r.AllowedPodNumber != 0 {
+		names = append(names, v1.ResourcePods)
+	}
+	return names
+}
+
+func (q *quotaInfo) clone() *quotaInfo {
+	c := *q
+	c.used = q.used.Clone()
+	return &c
+}
+
+// overMax returns a resource requested by the pod that would exceed the
+// maximum of the quota, if any.
+func (q *quotaInfo) overMax(req *framework.Resource) (v1.ResourceName, bool) {
+	for _, name := range q.maxNames {
+		if r := req.Value(name); r > 0 && q.used.Value(name)+r > q.max.Value(name) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// overMin returns whether the usage would exceed the guarantees of the quota
+// for any resource requested by the pod.
+func (q *quotaInfo) overMin(req *framework.Resource) bool {
+	for _, name := range q.names(req) {
+		if q.used.Value(name)+req.Value(name) > q.min.Value(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// borrowing returns whether the usage exceeds the guarantees of the quota for
+// any resource.
+func (q *quotaInfo) borrowing() bool {
+	for _, name := range q.names(q.used) {
+		if q.used.Value(name) > q.min.Value(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// quotaInfos are the quotas of namespaces, keyed by namespace.
+type quotaInfos map[string]*quotaInfo
+
+func (qs quotaInfos) clone() quotaInfos {
+	c := make(quotaInfos, len(qs))
+	for ns, q := range qs {
+		c[ns] = q.clone()
+	}
+	return c
+}
+
+// canBorrow returns the resource requested by a pod under quota q that the
+// quotas don't have enough unused guarantees of to lend, if any.
+func (qs quotaInfos) canBorrow(q *quotaInfo, req *framework.Resource) (v1.ResourceName, bool) {
+	for _, name := range q.names(req) {
+		var used, min int64
+		for _, q := range qs {
+			if name == v1.ResourcePods && !q.minPods {
+				continue
+			}
+			used += q.used.Value(name)
+			min += q.min.Value(name)
+		}
+		if used+req.Value(name) > min {
+			return name, false
+		}
+	}
+	return "", true
+}
+
+// resourceNames returns the resources with a non-zero amount, other than
+// pods.
+func resourceNames(r *framework.Resource) []v1.ResourceName {
+	var names []v1.ResourceName
+	if r.MilliCPU != 0 {
+		names = append(names, v1.ResourceCPU)
+	}
+	if r.Memory != 0 {
+		names = append(names, v1.ResourceMemory)
+	}
+	if r.EphemeralStorage != 0 {
+		names = append(names, v1.ResourceEphemeralStorage)
+	}
+	for name, v := range r.ScalarResources {
+		if v != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
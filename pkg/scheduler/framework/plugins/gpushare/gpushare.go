@@ -0,0 +1,291 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gpushare
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/names"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Name is the name of the plugin used in the plugin registry and configurations.
+	Name = names.GPUShare
+
+	// preFilterStateKey is the key in CycleState to GPUShare pre-computed data.
+	preFilterStateKey = "PreFilter" + Name
+	// reserveStateKey is the key in CycleState to the GPU reserved for the pod.
+	reserveStateKey = "Reserve" + Name
+
+	// deviceCompute is the compute capacity of a GPU, in percent.
+	deviceCompute = 100
+)
+
+// GPUShare is a plugin that lets pods share GPUs by requesting a slice of
+// the memory, and optionally of the compute, of a single GPU with the
+// framework.GPUMemoryResourceName and framework.GPUComputeResourceName
+// resources. Nodes advertise the number of their shareable GPUs with
+// framework.GPUCountResourceName and their total memory with
+// framework.GPUMemoryResourceName. Nodes must also advertise
+// framework.GPUComputeResourceName, as 100 per GPU, for pods requesting it
+// to pass NodeResourcesFit.
+//
+// Pods are bin-packed on the GPU with the least free memory that fits them.
+// The chosen GPU is recorded in the framework.GPUShareDeviceAnnotation of
+// the pod, which the scheduler cache accounts for in NodeInfo.GPUShares
+// from Reserve on, and which is persisted at PreBind for the device plugin.
+type GPUShare struct {
+	fh framework.Handle
+}
+
+var _ framework.PreFilterPlugin = &GPUShare{}
+var _ framework.FilterPlugin = &GPUShare{}
+var _ framework.CacheableFilterPlugin = &GPUShare{}
+var _ framework.ScorePlugin = &GPUShare{}
+var _ framework.ReservePlugin = &GPUShare{}
+var _ framework.PreBindPlugin = &GPUShare{}
+var _ framework.EnqueueExtensions = &GPUShare{}
+
+// New initializes a new plugin and returns it.
+func New(_ runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+	return &GPUShare{fh: fh}, nil
+}
+
+// Name returns name of the plugin. It is used in logs, etc.
+func (pl *GPUShare) Name() string {
+	return Name
+}
+
+// EventsToRegister returns the possible events that may make a Pod
+// failed by this plugin schedulable.
+func (pl *GPUShare) EventsToRegister() []framework.ClusterEvent {
+	return []framework.ClusterEvent{
+		{Resource: framework.Pod, ActionType: framework.Delete},
+		{Resource: framework.Node, ActionType: framework.Add | framework.UpdateNodeAllocatable},
+	}
+}
+
+// preFilterState computed at PreFilter and used at Filter, Score and Reserve.
+type preFilterState struct {
+	memory  int64
+	compute int64
+}
+
+// Clone the prefilter state.
+func (s *preFilterState) Clone() framework.StateData {
+	return s
+}
+
+// reserveState is the GPU of the node chosen for the pod at Reserve.
+type reserveState struct {
+	device int
+}
+
+// Clone the reserve state.
+func (s *reserveState) Clone() framework.StateData {
+	return s
+}
+
+// PreFilter computes the GPU share requested by the pod.
+func (pl *GPUShare) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod) *framework.Status {
+	memory, compute := framework.GPUShareRequest(pod)
+	if compute > deviceCompute {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable,
+			fmt.Sprintf("%s request %d is greater than %d", framework.GPUComputeResourceName, compute, deviceCompute))
+	}
+	cycleState.Write(preFilterStateKey, &preFilterState{memory: memory, compute: compute})
+	return nil
+}
+
+// PreFilterExtensions returns nil as the GPU shares of a node are tracked in
+// its NodeInfo.
+func (pl *GPUShare) PreFilterExtensions() framework.PreFilterExtensions {
+	return nil
+}
+
+func getPreFilterState(cycleState *framework.CycleState) (*preFilterState, error) {
+	c, err := cycleState.Read(preFilterStateKey)
+	if err != nil {
+		// preFilterState doesn't exist, likely PreFilter wasn't invoked.
+		return nil, fmt.Errorf("error reading %q from cycleState: %w", preFilterStateKey, err)
+	}
+
+	s, ok := c.(*preFilterState)
+	if !ok {
+		return nil, fmt.Errorf("%+v  convert to GPUShare.preFilterState error", c)
+	}
+	return s, nil
+}
+
+// Filter invoked at the filter extension point. It checks that a single GPU
+// of the node has enough free memory and compute for the pod.
+func (pl *GPUShare) Filter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if s.memory == 0 {
+		return nil
+	}
+	devices := deviceUsage(nodeInfo)
+	if len(devices) == 0 {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, "Node doesn't have shareable GPUs")
+	}
+	if bestFit(devices, s) < 0 {
+		return framework.NewStatus(framework.Unschedulable, "Insufficient GPU memory or compute on a single device")
+	}
+	return nil
+}
+
+// FilterCacheable returns true as the verdict of Filter only depends on the
+// pod and on the node.
+func (pl *GPUShare) FilterCacheable(_ *framework.CycleState, _ *v1.Pod) bool {
+	return true
+}
+
+// Score invoked at the score extension point. Nodes get a higher score the
+// fuller the GPU the pod would be placed on, to keep whole GPUs free.
+func (pl *GPUShare) Score(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return 0, framework.AsStatus(err)
+	}
+	if s.memory == 0 {
+		return 0, nil
+	}
+	nodeInfo, err := pl.fh.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return 0, framework.AsStatus(fmt.Errorf("getting node %q from Snapshot: %w", nodeName, err))
+	}
+	devices := deviceUsage(nodeInfo)
+	i := bestFit(devices, s)
+	if i < 0 {
+		return 0, nil
+	}
+	d := devices[i]
+	return framework.MaxNodeScore * (d.usedMemory + s.memory) / d.memory, nil
+}
+
+// ScoreExtensions of the Score plugin.
+func (pl *GPUShare) ScoreExtensions() framework.ScoreExtensions {
+	return nil
+}
+
+// Reserve chooses the GPU of the node for the pod and records it in the
+// cycle state, and in the annotations of the pod for the scheduler cache to
+// account for it. The scheduler runs Reserve plugins on its own copy of the
+// assumed pod, which it hands over to the cache afterwards.
+func (pl *GPUShare) Reserve(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if s.memory == 0 {
+		return nil
+	}
+	nodeInfo, err := pl.fh.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return framework.AsStatus(fmt.Errorf("getting node %q from Snapshot: %w", nodeName, err))
+	}
+	i := bestFit(deviceUsage(nodeInfo), s)
+	if i < 0 {
+		return framework.NewStatus(framework.Unschedulable, "Insufficient GPU memory or compute on a single device")
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[framework.GPUShareDeviceAnnotation] = strconv.Itoa(i)
+	cycleState.Write(reserveStateKey, &reserveState{device: i})
+	klog.V(4).InfoS("Reserved GPU share", "pod", klog.KObj(pod), "node", nodeName, "device", i, "memory", s.memory, "compute", s.compute)
+	return nil
+}
+
+// Unreserve forgets the GPU chosen for the pod. The pod is left untouched as
+// the scheduler cache shares it by then, and stops accounting for its GPU when
+// the pod is forgotten.
+func (pl *GPUShare) Unreserve(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeName string) {
+	cycleState.Delete(reserveStateKey)
+}
+
+// PreBind persists the GPU chosen for the pod in its annotations.
+func (pl *GPUShare) PreBind(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	c, err := cycleState.Read(reserveStateKey)
+	if err != nil {
+		// No GPU was reserved for the pod.
+		return nil
+	}
+	s, ok := c.(*reserveState)
+	if !ok {
+		return framework.AsStatus(fmt.Errorf("%+v convert to GPUShare.reserveState error", c))
+	}
+	annotations := map[string]string{framework.GPUShareDeviceAnnotation: strconv.Itoa(s.device)}
+	if err := util.PatchPodAnnotations(pl.fh.ClientSet(), pod, annotations); err != nil {
+		return framework.AsStatus(fmt.Errorf("recording GPU of pod %v/%v: %w", pod.Namespace, pod.Name, err))
+	}
+	return nil
+}
+
+// device is the capacity and usage of a shareable GPU.
+type device struct {
+	memory      int64
+	usedMemory  int64
+	usedCompute int64
+}
+
+// deviceUsage returns the shareable GPUs of the node, indexed by device.
+func deviceUsage(nodeInfo *framework.NodeInfo) []device {
+	count := nodeInfo.Allocatable.ScalarResources[framework.GPUCountResourceName]
+	memory := nodeInfo.Allocatable.ScalarResources[framework.GPUMemoryResourceName]
+	if count <= 0 || memory <= 0 {
+		return nil
+	}
+	devices := make([]device, count)
+	for i := range devices {
+		devices[i].memory = memory / count
+	}
+	for _, share := range nodeInfo.GPUShares {
+		if share.Device < len(devices) {
+			devices[share.Device].usedMemory += share.Memory
+			devices[share.Device].usedCompute += share.Compute
+		}
+	}
+	return devices
+}
+
+// bestFit returns the index of the GPU with the least free memory that fits
+// the request, or -1 if none does.
+func bestFit(devices []device, s *preFilterState) int {
+	best := -1
+	var bestFree int64
+	for i, d := range devices {
+		free := d.memory - d.usedMemory
+		if free < s.memory || deviceCompute-d.usedCompute < s.compute {
+			continue
+		}
+		if best < 0 || free < bestFree {
+			best, bestFree = i, free
+		}
+	}
+	return best
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gpushare
+
+import (
+	"context"
+	"testing"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	frameworkruntime "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/runtime"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/cache"
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+)
+
+// makeNode returns a node with two shareable GPUs of 16Gi each.
+func makeNode(name string) *v1.Node {
+	return st.MakeNode().Name(name).Capacity(map[v1.ResourceName]string{
+		framework.GPUCountResourceName:   "2",
+		framework.GPUMemoryResourceName:  "32Gi",
+		framework.GPUComputeResourceName: "200",
+	}).Obj()
+}
+
+func sharePod(name, memory, compute string) *st.PodWrapper {
+	req := map[v1.ResourceName]string{framework.GPUMemoryResourceName: memory}
+	if compute != "" {
+		req[framework.GPUComputeResourceName] = compute
+	}
+	return st.MakePod().Namespace("ns").Name(name).UID(name).Req(req)
+}
+
+func onDevice(p *st.PodWrapper, device string) *v1.Pod {
+	pod := p.Obj()
+	pod.Annotations = map[string]string{framework.GPUShareDeviceAnnotation: device}
+	return pod
+}
+
+func newPlugin(t *testing.T, pods []*v1.Pod, nodes []*v1.Node) (*GPUShare, *clientsetfake.Clientset) {
+	var objs []runtime.Object
+	for _, p := range pods {
+		objs = append(objs, p)
+	}
+	client := clientsetfake.NewSimpleClientset(objs...)
+	fh, err := frameworkruntime.NewFramework(nil, nil,
+		frameworkruntime.WithClientSet(client),
+		frameworkruntime.WithSnapshotSharedLister(cache.NewSnapshot(pods, nodes)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := New(nil, fh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.(*GPUShare), client
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		pod      *v1.Pod
+		existing []*v1.Pod
+		node     *v1.Node
+		wantCode framework.Code
+	}{
+		{
+			name:     "pod without GPU share",
+			pod:      st.MakePod().Namespace("ns").Name("p").Obj(),
+			node:     st.MakeNode().Name("node").Obj(),
+			wantCode: framework.Success,
+		},
+		{
+			name:     "node without shareable GPUs",
+			pod:      sharePod("p", "8Gi", "").Obj(),
+			node:     st.MakeNode().Name("node").Obj(),
+			wantCode: framework.UnschedulableAndUnresolvable,
+		},
+		{
+			name:     "fits next to another pod",
+			pod:      sharePod("p", "8Gi", "").Obj(),
+			existing: []*v1.Pod{onDevice(sharePod("e", "8Gi", "").Node("node"), "0")},
+			node:     makeNode("node"),
+			wantCode: framework.Success,
+		},
+		{
+			name: "free memory split across GPUs",
+			pod:  sharePod("p", "8Gi", "").Obj(),
+			existing: []*v1.Pod{
+				onDevice(sharePod("e1", "10Gi", "").Node("node"), "0"),
+				onDevice(sharePod("e2", "10Gi", "").Node("node"), "1"),
+			},
+			node:     makeNode("node"),
+			wantCode: framework.Unschedulable,
+		},
+		{
+			name: "not enough free compute",
+			pod:  sharePod("p", "4Gi", "50").Obj(),
+			existing: []*v1.Pod{
+				onDevice(sharePod("e1", "4Gi", "100").Node("node"), "0"),
+				onDevice(sharePod("e2", "4Gi", "60").Node("node"), "1"),
+			},
+			node:     makeNode("node"),
+			wantCode: framework.Unschedulable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			p, _ := newPlugin(t, tt.existing, []*v1.Node{tt.node})
+			nodeInfo := framework.NewNodeInfo(tt.existing...)
+			nodeInfo.SetNode(tt.node)
+
+			cycleState := framework.NewCycleState()
+			if status := p.PreFilter(ctx, cycleState, tt.pod); !status.IsSuccess() {
+				t.Fatalf("PreFilter failed: %v", status)
+			}
+			status := p.Filter(ctx, cycleState, tt.pod, nodeInfo)
+			if status.Code() != tt.wantCode {
+				t.Errorf("unexpected status code: want %v, got %v (%v)", tt.wantCode, status.Code(), status.Message())
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	ctx := context.Background()
+	nodes := []*v1.Node{makeNode("busy"), makeNode("empty")}
+	existing := []*v1.Pod{onDevice(sharePod("e", "12Gi", "").Node("busy"), "1")}
+	p, _ := newPlugin(t, existing, nodes)
+
+	pod := sharePod("p", "4Gi", "").Obj()
+	cycleState := framework.NewCycleState()
+	if status := p.PreFilter(ctx, cycleState, pod); !status.IsSuccess() {
+		t.Fatalf("PreFilter failed: %v", status)
+	}
+	want := map[string]int64{
+		"busy":  framework.MaxNodeScore,
+		"empty": framework.MaxNodeScore / 4,
+	}
+	for name, wantScore := range want {
+		score, status := p.Score(ctx, cycleState, pod, name)
+		if !status.IsSuccess() {
+			t.Fatalf("Score failed on node %v: %v", name, status)
+		}
+		if score != wantScore {
+			t.Errorf("unexpected score on node %v: want %v, got %v", name, wantScore, score)
+		}
+	}
+}
+
+func TestReserveAndPreBind(t *testing.T) {
+	ctx := context.Background()
+	node := makeNode("node")
+	existing := onDevice(sharePod("e", "12Gi", "").Node("node"), "1")
+	pod := sharePod("p", "4Gi", "").Obj()
+	p, client := newPlugin(t, []*v1.Pod{existing, pod}, []*v1.Node{node})
+
+	cycleState := framework.NewCycleState()
+	if status := p.PreFilter(ctx, cycleState, pod); !status.IsSuccess() {
+		t.Fatalf("PreFilter failed: %v", status)
+	}
+	if status := p.Reserve(ctx, cycleState, pod, "node"); !status.IsSuccess() {
+		t.Fatalf("Reserve failed: %v", status)
+	}
+	// The pod is packed on the GPU already in use.
+	if device := pod.Annotations[framework.GPUShareDeviceAnnotation]; device != "1" {
+		t.Errorf("unexpected device: want %q, got %q", "1", device)
+	}
+
+	if status := p.PreBind(ctx, cycleState, pod, "node"); !status.IsSuccess() {
+		t.Fatalf("PreBind failed: %v", status)
+	}
+	got, err := client.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if device := got.Annotations[framework.GPUShareDeviceAnnotation]; device != "1" {
+		t.Errorf("unexpected device annotation: want %q, got %q", "1", device)
+	}
+
+	p.Unreserve(ctx, cycleState, pod, "node")
+	if _, err := cycleState.Read(reserveStateKey); err == nil {
+		t.Errorf("expected reserved device to be forgotten after Unreserve")
+	}
+}
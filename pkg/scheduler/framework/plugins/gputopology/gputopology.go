@@ -0,0 +1,348 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gputopology
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config/validation"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/names"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	v1resource "k8s.io/kubernetes/pkg/api/v1/resource"
+)
+
+const (
+	// Name is the name of the plugin used in the plugin registry and configurations.
+	Name = names.GPUTopology
+
+	// preFilterStateKey is the key in CycleState to GPUTopology pre-computed data.
+	preFilterStateKey = "PreFilter" + Name
+	// reserveStateKey is the key in CycleState to the devices chosen at Reserve.
+	reserveStateKey = "Reserve" + Name
+)
+
+// GPUTopology is a plugin that places pods requesting several GPUs on a set
+// of well-connected GPUs of a node, according to the topology the node
+// publishes in the TopologyAnnotation. The chosen GPUs are recorded in the
+// DevicesAnnotation of the pod before binding.
+//
+// Nodes without the annotation are not filtered out, as the plugin can't
+// tell how their GPUs are connected, but they get the lowest score.
+type GPUTopology struct {
+	fh           framework.Handle
+	resourceName v1.ResourceName
+	minimumLink  linkLevel
+
+	mu sync.Mutex
+	// reserved holds the devices chosen for the pods that were reserved but
+	// don't have the DevicesAnnotation in the informer cache yet.
+	reserved map[types.UID][]string
+}
+
+var _ framework.PreFilterPlugin = &GPUTopology{}
+var _ framework.FilterPlugin = &GPUTopology{}
+var _ framework.CacheableFilterPlugin = &GPUTopology{}
+var _ framework.ScorePlugin = &GPUTopology{}
+var _ framework.ReservePlugin = &GPUTopology{}
+var _ framework.PreBindPlugin = &GPUTopology{}
+var _ framework.EnqueueExtensions = &GPUTopology{}
+
+// New initializes a new plugin and returns it.
+func New(obj runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+	args, ok := obj.(*config.GPUTopologyArgs)
+	if !ok {
+		return nil, fmt.Errorf("got args of type %T, want *GPUTopologyArgs", obj)
+	}
+	if err := validation.ValidateGPUTopologyArgs(nil, args); err != nil {
+		return nil, err
+	}
+	pl := &GPUTopology{
+		fh:           fh,
+		resourceName: v1.ResourceName(args.ResourceName),
+		minimumLink:  linkLevels[args.MinimumLinkType],
+		reserved:     make(map[types.UID][]string),
+	}
+	if fh.SchedulingWorker() {
+		// Scheduling workers never reserve devices.
+		return pl, nil
+	}
+	fh.SharedInformerFactory().Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, newObj interface{}) {
+			if pod, ok := newObj.(*v1.Pod); ok {
+				if _, ok := pod.Annotations[DevicesAnnotation]; ok {
+					pl.forget(pod.UID)
+				}
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			switch t := obj.(type) {
+			case *v1.Pod:
+				pl.forget(t.UID)
+			case cache.DeletedFinalStateUnknown:
+				if pod, ok := t.Obj.(*v1.Pod); ok {
+					pl.forget(pod.UID)
+				}
+			}
+		},
+	})
+	return pl, nil
+}
+
+// Name returns name of the plugin. It is used in logs, etc.
+func (pl *GPUTopology) Name() string {
+	return Name
+}
+
+// EventsToRegister returns the possible events that may make a Pod
+// failed by this plugin schedulable.
+func (pl *GPUTopology) EventsToRegister() []framework.ClusterEvent {
+	return []framework.ClusterEvent{
+		// Deleting pods frees GPUs.
+		{Resource: framework.Pod, ActionType: framework.Delete},
+		// The GPU topology of a node is published in its annotations.
+		{Resource: framework.Node, ActionType: framework.Add | framework.Update},
+	}
+}
+
+// preFilterState computed at PreFilter and used at Filter and Score.
+type preFilterState struct {
+	// count is the number of GPUs requested by the pod.
+	count int
+}
+
+// Clone the prefilter state.
+func (s *preFilterState) Clone() framework.StateData {
+	return s
+}
+
+// reserveState holds the devices chosen at Reserve, used at PreBind.
+type reserveState struct {
+	devices []string
+}
+
+// Clone the reserve state.
+func (s *reserveState) Clone() framework.StateData {
+	return s
+}
+
+// PreFilter computes the number of GPUs requested by the pod.
+func (pl *GPUTopology) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod) *framework.Status {
+	reqs, _ := v1resource.PodRequestsAndLimits(pod)
+	quantity := reqs[pl.resourceName]
+	cycleState.Write(preFilterStateKey, &preFilterState{count: int(quantity.Value())})
+	return nil
+}
+
+// PreFilterExtensions returns nil as the GPUs used on a node are computed
+// from the pods of its NodeInfo at Filter.
+func (pl *GPUTopology) PreFilterExtensions() framework.PreFilterExtensions {
+	return nil
+}
+
+func getPreFilterState(cycleState *framework.CycleState) (*preFilterState, error) {
+	c, err := cycleState.Read(preFilterStateKey)
+	if err != nil {
+		// preFilterState doesn't exist, likely PreFilter wasn't invoked.
+		return nil, fmt.Errorf("error reading %q from cycleState: %w", preFilterStateKey, err)
+	}
+
+	s, ok := c.(*preFilterState)
+	if !ok {
+		return nil, fmt.Errorf("%+v  convert to GPUTopology.preFilterState error", c)
+	}
+	return s, nil
+}
+
+// Filter invoked at the filter extension point. It checks that the node can
+// give the pod a set of free GPUs connected with the minimum link type.
+func (pl *GPUTopology) Filter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if s.count == 0 {
+		return nil
+	}
+	topology, a, err := pl.allocate(nodeInfo, s.count)
+	if err != nil {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, err.Error())
+	}
+	if topology == nil {
+		return nil
+	}
+	if a == nil {
+		return framework.NewStatus(framework.Unschedulable, "Insufficient free GPUs")
+	}
+	if a.weakest < pl.minimumLink {
+		return framework.NewStatus(framework.Unschedulable, "Free GPUs are not well connected")
+	}
+	return nil
+}
+
+// FilterCacheable returns true if the pod requests no GPUs, as Filter always
+// passes then. Otherwise the verdict depends on the devices reserved in this
+// plugin.
+func (pl *GPUTopology) FilterCacheable(cycleState *framework.CycleState, _ *v1.Pod) bool {
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return false
+	}
+	return s.count == 0
+}
+
+// Score invoked at the score extension point. Nodes get a higher score the
+// better the GPUs they would give to the pod are connected.
+func (pl *GPUTopology) Score(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return 0, framework.AsStatus(err)
+	}
+	// The interconnect doesn't matter to pods using a single GPU.
+	if s.count < 2 {
+		return 0, nil
+	}
+	nodeInfo, err := pl.fh.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return 0, framework.AsStatus(fmt.Errorf("getting node %q from Snapshot: %w", nodeName, err))
+	}
+	_, a, err := pl.allocate(nodeInfo, s.count)
+	if err != nil || a == nil {
+		return 0, nil
+	}
+	pairs := s.count * (s.count - 1) / 2
+	return framework.MaxNodeScore * int64(a.total) / int64(pairs*int(linkNVLink)), nil
+}
+
+// ScoreExtensions of the Score plugin.
+func (pl *GPUTopology) ScoreExtensions() framework.ScoreExtensions {
+	return nil
+}
+
+// Reserve chooses the GPUs of the node for the pod and keeps them reserved
+// until the pod is bound or unreserved.
+func (pl *GPUTopology) Reserve(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if s.count == 0 {
+		return nil
+	}
+	nodeInfo, err := pl.fh.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return framework.AsStatus(fmt.Errorf("getting node %q from Snapshot: %w", nodeName, err))
+	}
+	topology, a, err := pl.allocate(nodeInfo, s.count)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if topology == nil {
+		return nil
+	}
+	if a == nil {
+		return framework.NewStatus(framework.Unschedulable, "Insufficient free GPUs")
+	}
+	devices := topology.ids(a)
+	pl.mu.Lock()
+	pl.reserved[pod.UID] = devices
+	pl.mu.Unlock()
+	cycleState.Write(reserveStateKey, &reserveState{devices: devices})
+	klog.V(4).InfoS("Reserved GPUs", "pod", klog.KObj(pod), "node", nodeName, "devices", devices)
+	return nil
+}
+
+// Unreserve releases the GPUs reserved for the pod.
+func (pl *GPUTopology) Unreserve(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeName string) {
+	pl.forget(pod.UID)
+}
+
+// PreBind records the GPUs reserved for the pod in its DevicesAnnotation.
+func (pl *GPUTopology) PreBind(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	c, err := cycleState.Read(reserveStateKey)
+	if err != nil {
+		// No GPUs were reserved for the pod.
+		return nil
+	}
+	s, ok := c.(*reserveState)
+	if !ok {
+		return framework.AsStatus(fmt.Errorf("%+v  convert to GPUTopology.reserveState error", c))
+	}
+	annotations := map[string]string{DevicesAnnotation: strings.Join(s.devices, ",")}
+	if err := util.PatchPodAnnotations(pl.fh.ClientSet(), pod, annotations); err != nil {
+		return framework.AsStatus(fmt.Errorf("recording GPUs of pod %v/%v: %w", pod.Namespace, pod.Name, err))
+	}
+	return nil
+}
+
+// allocate returns the topology of the node and the best allocation of
+// count free GPUs on it. The topology is nil if the node doesn't publish
+// one, and the allocation is nil if there aren't enough free GPUs.
+func (pl *GPUTopology) allocate(nodeInfo *framework.NodeInfo, count int) (*NodeTopology, *allocation, error) {
+	node := nodeInfo.Node()
+	if node == nil {
+		return nil, nil, fmt.Errorf("node not found")
+	}
+	topology, err := topologyOf(node)
+	if err != nil || topology == nil {
+		return nil, nil, err
+	}
+	used := pl.usedDevices(nodeInfo)
+	var free []int
+	for i, d := range topology.Devices {
+		if !used[d.ID] {
+			free = append(free, i)
+		}
+	}
+	return topology, topology.allocate(free, count), nil
+}
+
+// usedDevices returns the IDs of the GPUs of the node that are allocated to
+// its pods, either recorded in their annotations or reserved in this
+// scheduler.
+func (pl *GPUTopology) usedDevices(nodeInfo *framework.NodeInfo) map[string]bool {
+	used := make(map[string]bool)
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	for _, pi := range nodeInfo.Pods {
+		if devices, ok := pi.Pod.Annotations[DevicesAnnotation]; ok {
+			for _, id := range strings.Split(devices, ",") {
+				used[id] = true
+			}
+			continue
+		}
+		for _, id := range pl.reserved[pi.Pod.UID] {
+			used[id] = true
+		}
+	}
+	return used
+}
+
+func (pl *GPUTopology) forget(uid types.UID) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	delete(pl.reserved, uid)
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gputopology
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	frameworkruntime "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/runtime"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/cache"
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+)
+
+const gpu = "nvidia.com/gpu"
+
+func makeNode(t *testing.T, name string, topology *NodeTopology) *v1.Node {
+	node := st.MakeNode().Name(name).Capacity(map[v1.ResourceName]string{gpu: "8"}).Obj()
+	if topology != nil {
+		raw, err := json.Marshal(topology)
+		if err != nil {
+			t.Fatal(err)
+		}
+		node.Annotations = map[string]string{TopologyAnnotation: string(raw)}
+	}
+	return node
+}
+
+func gpuPod(name string, count string) *st.PodWrapper {
+	return st.MakePod().Namespace("ns").Name(name).UID(name).Req(map[v1.ResourceName]string{gpu: count})
+}
+
+func withDevices(p *st.PodWrapper, devices string) *v1.Pod {
+	pod := p.Obj()
+	pod.Annotations = map[string]string{DevicesAnnotation: devices}
+	return pod
+}
+
+func newPlugin(ctx context.Context, t *testing.T, args *config.GPUTopologyArgs, pods []*v1.Pod, nodes []*v1.Node) (*GPUTopology, *clientsetfake.Clientset) {
+	var objs []runtime.Object
+	for _, p := range pods {
+		objs = append(objs, p)
+	}
+	client := clientsetfake.NewSimpleClientset(objs...)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	fh, err := frameworkruntime.NewFramework(nil, nil,
+		frameworkruntime.WithClientSet(client),
+		frameworkruntime.WithSnapshotSharedLister(cache.NewSnapshot(pods, nodes)),
+		frameworkruntime.WithInformerFactory(informerFactory))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := New(args, fh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	informerFactory.Start(ctx.Done())
+	informerFactory.WaitForCacheSync(ctx.Done())
+	return p.(*GPUTopology), client
+}
+
+func defaultArgs() *config.GPUTopologyArgs {
+	return &config.GPUTopologyArgs{ResourceName: gpu, MinimumLinkType: config.GPULinkNUMANode}
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     *config.GPUTopologyArgs
+		pod      *v1.Pod
+		existing []*v1.Pod
+		node     *NodeTopology
+		wantCode framework.Code
+	}{
+		{
+			name:     "pod without GPUs",
+			pod:      st.MakePod().Namespace("ns").Name("p").Obj(),
+			node:     dgxTopology(),
+			wantCode: framework.Success,
+		},
+		{
+			name:     "node without topology",
+			pod:      gpuPod("p", "4").Obj(),
+			wantCode: framework.Success,
+		},
+		{
+			name:     "GPUs on one NUMA node",
+			pod:      gpuPod("p", "4").Obj(),
+			existing: []*v1.Pod{withDevices(gpuPod("e", "1").Node("node"), "gpu0")},
+			node:     dgxTopology(),
+			wantCode: framework.Success,
+		},
+		{
+			name: "free GPUs split across NUMA nodes",
+			pod:  gpuPod("p", "4").Obj(),
+			existing: []*v1.Pod{
+				withDevices(gpuPod("e1", "1").Node("node"), "gpu0"),
+				withDevices(gpuPod("e2", "1").Node("node"), "gpu4"),
+			},
+			node:     dgxTopology(),
+			wantCode: framework.Unschedulable,
+		},
+		{
+			name: "free GPUs split across NUMA nodes allowed",
+			args: &config.GPUTopologyArgs{ResourceName: gpu, MinimumLinkType: config.GPULinkSystem},
+			pod:  gpuPod("p", "4").Obj(),
+			existing: []*v1.Pod{
+				withDevices(gpuPod("e1", "1").Node("node"), "gpu0"),
+				withDevices(gpuPod("e2", "1").Node("node"), "gpu4"),
+			},
+			node:     dgxTopology(),
+			wantCode: framework.Success,
+		},
+		{
+			name:     "not enough free GPUs",
+			pod:      gpuPod("p", "4").Obj(),
+			existing: []*v1.Pod{withDevices(gpuPod("e", "6").Node("node"), "gpu0,gpu1,gpu2,gpu3,gpu4,gpu5")},
+			node:     dgxTopology(),
+			wantCode: framework.Unschedulable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			args := tt.args
+			if args == nil {
+				args = defaultArgs()
+			}
+			node := makeNode(t, "node", tt.node)
+			p, _ := newPlugin(ctx, t, args, tt.existing, []*v1.Node{node})
+			nodeInfo := framework.NewNodeInfo(tt.existing...)
+			nodeInfo.SetNode(node)
+
+			cycleState := framework.NewCycleState()
+			if status := p.PreFilter(ctx, cycleState, tt.pod); !status.IsSuccess() {
+				t.Fatalf("PreFilter failed: %v", status)
+			}
+			status := p.Filter(ctx, cycleState, tt.pod, nodeInfo)
+			if status.Code() != tt.wantCode {
+				t.Errorf("unexpected status code: want %v, got %v (%v)", tt.wantCode, status.Code(), status.Message())
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	nodes := []*v1.Node{
+		makeNode(t, "nvlink", dgxTopology()),
+		makeNode(t, "numa", dgxTopology()),
+		makeNode(t, "unknown", nil),
+	}
+	existing := []*v1.Pod{
+		// Leaves gpu1 and gpu3 free on NUMA node 0 of node "numa".
+		withDevices(gpuPod("e1", "6").Node("numa"), "gpu0,gpu2,gpu4,gpu5,gpu6,gpu7"),
+	}
+	p, _ := newPlugin(ctx, t, defaultArgs(), existing, nodes)
+
+	pod := gpuPod("p", "2").Obj()
+	cycleState := framework.NewCycleState()
+	if status := p.PreFilter(ctx, cycleState, pod); !status.IsSuccess() {
+		t.Fatalf("PreFilter failed: %v", status)
+	}
+	want := map[string]int64{
+		"nvlink":  framework.MaxNodeScore,
+		"numa":    framework.MaxNodeScore * int64(linkNUMANode) / int64(linkNVLink),
+		"unknown": 0,
+	}
+	for name, wantScore := range want {
+		score, status := p.Score(ctx, cycleState, pod, name)
+		if !status.IsSuccess() {
+			t.Fatalf("Score failed on node %v: %v", name, status)
+		}
+		if score != wantScore {
+			t.Errorf("unexpected score on node %v: want %v, got %v", name, wantScore, score)
+		}
+	}
+}
+
+func TestReserveAndPreBind(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	node := makeNode(t, "node", dgxTopology())
+	pod := gpuPod("p", "2").Obj()
+	p, client := newPlugin(ctx, t, defaultArgs(), []*v1.Pod{pod}, []*v1.Node{node})
+
+	cycleState := framework.NewCycleState()
+	if status := p.PreFilter(ctx, cycleState, pod); !status.IsSuccess() {
+		t.Fatalf("PreFilter failed: %v", status)
+	}
+	if status := p.Reserve(ctx, cycleState, pod, "node"); !status.IsSuccess() {
+		t.Fatalf("Reserve failed: %v", status)
+	}
+
+	// The devices reserved for an assumed pod are not given to other pods.
+	assumed := pod.DeepCopy()
+	assumed.Spec.NodeName = "node"
+	nodeInfo := framework.NewNodeInfo(assumed)
+	nodeInfo.SetNode(node)
+	if used := p.usedDevices(nodeInfo); !used["gpu0"] || !used["gpu1"] || len(used) != 2 {
+		t.Errorf("expected gpu0 and gpu1 to be used, got %v", used)
+	}
+
+	if status := p.PreBind(ctx, cycleState, pod, "node"); !status.IsSuccess() {
+		t.Fatalf("PreBind failed: %v", status)
+	}
+	got, err := client.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if devices := got.Annotations[DevicesAnnotation]; devices != "gpu0,gpu1" {
+		t.Errorf("unexpected devices annotation: want %q, got %q", "gpu0,gpu1", devices)
+	}
+
+	p.Unreserve(ctx, cycleState, pod, "node")
+	if used := p.usedDevices(nodeInfo); len(used) != 0 {
+		t.Errorf("expected no used devices after Unreserve, got %v", used)
+	}
+}
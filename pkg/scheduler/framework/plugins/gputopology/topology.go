@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gputopology
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// TopologyAnnotation is the node annotation describing the GPUs of the
+	// node and how they are connected, in the format of NodeTopology
+	// serialized as JSON. It's expected to be maintained by a node agent.
+	TopologyAnnotation = "gpu-topology.sched.dev/topology"
+	// DevicesAnnotation is the pod annotation holding the comma-separated IDs
+	// of the GPUs chosen for the pod, for the device plugin to allocate.
+	DevicesAnnotation = "gpu-topology.sched.dev/devices"
+)
+
+// NodeTopology describes the GPUs of a node.
+type NodeTopology struct {
+	Devices []Device `json:"devices"`
+}
+
+// Device is a GPU of a node.
+type Device struct {
+	// ID is the ID the device plugin advertises the GPU with.
+	ID string `json:"id"`
+	// NUMANode is the NUMA node the GPU is attached to.
+	NUMANode int `json:"numaNode"`
+	// PCIeSwitch identifies the PCIe switch the GPU is behind, if any.
+	PCIeSwitch string `json:"pcieSwitch,omitempty"`
+	// NVLinks are the IDs of the GPUs directly connected to this one with
+	// NVLink.
+	NVLinks []string `json:"nvlinks,omitempty"`
+}
+
+// linkLevel ranks the interconnect between two GPUs. Higher is better.
+type linkLevel int
+
+const (
+	linkSystem linkLevel = iota
+	linkNUMANode
+	linkPCIeSwitch
+	linkNVLink
+)
+
+var linkLevels = map[config.GPULinkType]linkLevel{
+	config.GPULinkSystem:     linkSystem,
+	config.GPULinkNUMANode:   linkNUMANode,
+	config.GPULinkPCIeSwitch: linkPCIeSwitch,
+	config.GPULinkNVLink:     linkNVLink,
+}
+
+// topologyOf returns the GPU topology of the node, or nil if the node
+// doesn't publish one.
+func topologyOf(node *v1.Node) (*NodeTopology, error) {
+	raw, ok := node.Annotations[TopologyAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	t := &NodeTopology{}
+	if err := json.Unmarshal([]byte(raw), t); err != nil {
+		return nil, fmt.Errorf("parsing annotation %s of node %q: %w", TopologyAnnotation, node.Name, err)
+	}
+	return t, nil
+}
+
+// link returns the interconnect between the i-th and j-th devices.
+func (t *NodeTopology) link(i, j int) linkLevel {
+	a, b := &t.Devices[i], &t.Devices[j]
+	for _, id := range a.NVLinks {
+		if id == b.ID {
+			return linkNVLink
+		}
+	}
+	for _, id := range b.NVLinks {
+		if id == a.ID {
+			return linkNVLink
+		}
+	}
+	if len(a.PCIeSwitch) != 0 && a.PCIeSwitch == b.PCIeSwitch {
+		return linkPCIeSwitch
+	}
+	if a.NUMANode == b.NUMANode {
+		return linkNUMANode
+	}
+	return linkSystem
+}
+
+// allocation is a set of devices of a node chosen for a pod.
+type allocation struct {
+	// devices are the indexes of the chosen devices in NodeTopology.Devices.
+	devices []int
+	// weakest is the worst interconnect between any two chosen devices.
+	weakest linkLevel
+	// total is the sum of the interconnects between all pairs of chosen
+	// devices.
+	total int
+}
+
+// better returns whether a is a better allocation than b.
+func (a *allocation) better(b *allocation) bool {
+	if b == nil {
+		return true
+	}
+	if a.weakest != b.weakest {
+		return a.weakest > b.weakest
+	}
+	return a.total > b.total
+}
+
+// allocate chooses count devices among the free ones, which are indexes in
+// t.Devices in increasing order, so that they are as well connected as
+// possible. It returns nil if there aren't enough free devices.
+//
+// Finding the best set is expensive, so a set is grown greedily from every
+// free device, adding the device with the best links to the set at each
+// step and preferring the lowest index on ties, which keeps sets contiguous.
+func (t *NodeTopology) allocate(free []int, count int) *allocation {
+	if count <= 0 || len(free) < count {
+		return nil
+	}
+	var best *allocation
+	for _, start := range free {
+		a := &allocation{devices: []int{start}, weakest: linkNVLink}
+		for len(a.devices) < count {
+			next, nextWeakest, nextTotal := -1, linkSystem, -1
+			for _, candidate := range free {
+				if contains(a.devices, candidate) {
+					continue
+				}
+				weakest, total := a.weakest, 0
+				for _, d := range a.devices {
+					l := t.link(d, candidate)
+					if l < weakest {
+						weakest = l
+					}
+					total += int(l)
+				}
+				if next == -1 || weakest > nextWeakest || (weakest == nextWeakest && total > nextTotal) {
+					next, nextWeakest, nextTotal = candidate, weakest, total
+				}
+			}
+			a.devices = append(a.devices, next)
+			a.weakest = nextWeakest
+			a.total += nextTotal
+		}
+		if a.better(best) {
+			best = a
+		}
+	}
+	return best
+}
+
+// ids returns the IDs of the allocated devices.
+func (t *NodeTopology) ids(a *allocation) []string {
+	ids := make([]string, 0, len(a.devices))
+	for _, d := range a.devices {
+		ids = append(ids, t.Devices[d].ID)
+	}
+	return ids
+}
+
+func contains(s []int, v int) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
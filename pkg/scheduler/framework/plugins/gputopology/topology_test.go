@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gputopology
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// dgxTopology returns 8 GPUs on two NUMA nodes, with two PCIe switches per
+// NUMA node and NVLink between the GPUs of each switch.
+func dgxTopology() *NodeTopology {
+	return &NodeTopology{
+		Devices: []Device{
+			{ID: "gpu0", NUMANode: 0, PCIeSwitch: "a", NVLinks: []string{"gpu1"}},
+			{ID: "gpu1", NUMANode: 0, PCIeSwitch: "a"},
+			{ID: "gpu2", NUMANode: 0, PCIeSwitch: "b", NVLinks: []string{"gpu3"}},
+			{ID: "gpu3", NUMANode: 0, PCIeSwitch: "b"},
+			{ID: "gpu4", NUMANode: 1, PCIeSwitch: "c", NVLinks: []string{"gpu5"}},
+			{ID: "gpu5", NUMANode: 1, PCIeSwitch: "c"},
+			{ID: "gpu6", NUMANode: 1, PCIeSwitch: "d"},
+			{ID: "gpu7", NUMANode: 1, PCIeSwitch: "d"},
+		},
+	}
+}
+
+func TestLink(t *testing.T) {
+	topology := dgxTopology()
+	tests := []struct {
+		i, j int
+		want linkLevel
+	}{
+		{i: 0, j: 1, want: linkNVLink},
+		{i: 1, j: 0, want: linkNVLink},
+		{i: 6, j: 7, want: linkPCIeSwitch},
+		{i: 1, j: 2, want: linkNUMANode},
+		{i: 3, j: 4, want: linkSystem},
+	}
+	for _, tt := range tests {
+		if got := topology.link(tt.i, tt.j); got != tt.want {
+			t.Errorf("link(%d, %d) = %v, want %v", tt.i, tt.j, got, tt.want)
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name        string
+		free        []int
+		count       int
+		wantDevices []int
+		wantWeakest linkLevel
+	}{
+		{
+			name:        "single device",
+			free:        []int{3, 4},
+			count:       1,
+			wantDevices: []int{3},
+			wantWeakest: linkNVLink,
+		},
+		{
+			name:        "NVLink pair",
+			free:        []int{0, 1, 2, 3, 4, 5, 6, 7},
+			count:       2,
+			wantDevices: []int{0, 1},
+			wantWeakest: linkNVLink,
+		},
+		{
+			name:        "NVLink pair after a broken one",
+			free:        []int{0, 2, 3, 4, 5, 6, 7},
+			count:       2,
+			wantDevices: []int{2, 3},
+			wantWeakest: linkNVLink,
+		},
+		{
+			name:        "PCIe pair without NVLink",
+			free:        []int{0, 2, 5, 6, 7},
+			count:       2,
+			wantDevices: []int{6, 7},
+			wantWeakest: linkPCIeSwitch,
+		},
+		{
+			name:        "whole NUMA node",
+			free:        []int{0, 1, 2, 3, 4, 5, 6, 7},
+			count:       4,
+			wantDevices: []int{0, 1, 2, 3},
+			wantWeakest: linkNUMANode,
+		},
+		{
+			name:        "NUMA node without used devices",
+			free:        []int{1, 2, 3, 4, 5, 6, 7},
+			count:       4,
+			wantDevices: []int{4, 5, 6, 7},
+			wantWeakest: linkNUMANode,
+		},
+		{
+			name:        "across NUMA nodes",
+			free:        []int{2, 3, 4, 5},
+			count:       4,
+			wantDevices: []int{2, 3, 4, 5},
+			wantWeakest: linkSystem,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := dgxTopology().allocate(tt.free, tt.count)
+			if a == nil {
+				t.Fatal("expected an allocation")
+			}
+			if diff := cmp.Diff(tt.wantDevices, a.devices); diff != "" {
+				t.Errorf("unexpected devices (-want,+got):\n%s", diff)
+			}
+			if a.weakest != tt.wantWeakest {
+				t.Errorf("unexpected weakest link: want %v, got %v", tt.wantWeakest, a.weakest)
+			}
+		})
+	}
+}
+
+func TestAllocateInsufficient(t *testing.T) {
+	if a := dgxTopology().allocate([]int{0, 1}, 3); a != nil {
+		t.Errorf("expected no allocation, got %v", a.devices)
+	}
+}
@@ -395,3 +395,17 @@ func (pl *InterPodAffinity) Filter(ctx context.Context, cycleState *framework.Cy
 
 	return nil
 }
+
+// FilterCacheable returns true if the pod has no required (anti-)affinity
+// terms and no existing pod has anti-affinity terms matching it, as Filter
+// always passes then. Otherwise the verdict depends on the pods running on
+// the other nodes of the topology domains.
+func (pl *InterPodAffinity) FilterCacheable(cycleState *framework.CycleState, _ *v1.Pod) bool {
+	state, err := getPreFilterState(cycleState)
+	if err != nil {
+		return false
+	}
+	return len(state.podInfo.RequiredAffinityTerms) == 0 &&
+		len(state.podInfo.RequiredAntiAffinityTerms) == 0 &&
+		len(state.existingAntiAffinityCounts) == 0
+}
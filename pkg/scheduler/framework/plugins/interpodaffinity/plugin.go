@@ -38,6 +38,7 @@ const (
 
 var _ framework.PreFilterPlugin = &InterPodAffinity{}
 var _ framework.FilterPlugin = &InterPodAffinity{}
+var _ framework.CacheableFilterPlugin = &InterPodAffinity{}
 var _ framework.PreScorePlugin = &InterPodAffinity{}
 var _ framework.ScorePlugin = &InterPodAffinity{}
 var _ framework.EnqueueExtensions = &InterPodAffinity{}
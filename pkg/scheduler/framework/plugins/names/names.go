@@ -40,4 +40,12 @@ const (
 	VolumeBinding                   = "VolumeBinding"
 	VolumeRestrictions              = "VolumeRestrictions"
 	VolumeZone                      = "VolumeZone"
+	Coscheduling                    = "Coscheduling"
+	GPUTopology                     = "GPUTopology"
+	GPUShare                        = "GPUShare"
+	ElasticQuota                    = "ElasticQuota"
+	DominantResourceFairness        = "DominantResourceFairness"
+	Backfill                        = "Backfill"
+	TopologyPacking                 = "TopologyPacking"
+	NodeResourceTopologyMatch       = "NodeResourceTopologyMatch"
 )
@@ -39,9 +39,11 @@ type NodeAffinity struct {
 
 var _ framework.PreFilterPlugin = &NodeAffinity{}
 var _ framework.FilterPlugin = &NodeAffinity{}
+var _ framework.CacheableFilterPlugin = &NodeAffinity{}
 var _ framework.PreScorePlugin = &NodeAffinity{}
 var _ framework.ScorePlugin = &NodeAffinity{}
 var _ framework.EnqueueExtensions = &NodeAffinity{}
+var _ framework.QueueingHintExtensions = &NodeAffinity{}
 
 const (
 	// Name is the name of the plugin used in the plugin registry and configurations.
@@ -82,6 +84,40 @@ func (pl *NodeAffinity) EventsToRegister() []framework.ClusterEvent {
 	}
 }
 
+// QueueingHints returns the hints telling whether a Node event may make a Pod
+// failed by this plugin schedulable.
+func (pl *NodeAffinity) QueueingHints() map[framework.ClusterEvent]framework.QueueingHintFn {
+	return map[framework.ClusterEvent]framework.QueueingHintFn{
+		{Resource: framework.Node, ActionType: framework.Add | framework.UpdateNodeLabel}: pl.isSchedulableAfterNodeChange,
+	}
+}
+
+// isSchedulableAfterNodeChange queues the pod only if the node matches its
+// required node affinity now but didn't before.
+func (pl *NodeAffinity) isSchedulableAfterNodeChange(pod *v1.Pod, oldObj, newObj interface{}) framework.QueueingHint {
+	newNode, ok := newObj.(*v1.Node)
+	if !ok {
+		return framework.Queue
+	}
+	if !pl.nodeMatches(pod, newNode) {
+		return framework.QueueSkip
+	}
+	if oldNode, ok := oldObj.(*v1.Node); ok && pl.nodeMatches(pod, oldNode) {
+		return framework.QueueSkip
+	}
+	return framework.Queue
+}
+
+// nodeMatches returns true if the node passes Filter for the pod.
+func (pl *NodeAffinity) nodeMatches(pod *v1.Pod, node *v1.Node) bool {
+	if pl.addedNodeSelector != nil && !pl.addedNodeSelector.Match(node) {
+		return false
+	}
+	// Ignore parsing errors for backwards compatibility.
+	match, _ := nodeaffinity.GetRequiredNodeAffinity(pod).Match(node)
+	return match
+}
+
 // PreFilter builds and writes cycle state used by Filter.
 func (pl *NodeAffinity) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod) *framework.Status {
 	state := &preFilterState{requiredNodeSelectorAndAffinity: nodeaffinity.GetRequiredNodeAffinity(pod)}
@@ -121,6 +157,12 @@ func (pl *NodeAffinity) Filter(ctx context.Context, state *framework.CycleState,
 	return nil
 }
 
+// FilterCacheable returns true as the verdict of Filter only depends on the
+// pod and on the node.
+func (pl *NodeAffinity) FilterCacheable(_ *framework.CycleState, _ *v1.Pod) bool {
+	return true
+}
+
 // preScoreState computed at PreScore and used at Score.
 type preScoreState struct {
 	preferredNodeAffinity *nodeaffinity.PreferredSchedulingTerms
@@ -1104,3 +1104,65 @@ func TestNodeAffinityPriority(t *testing.T) {
 		})
 	}
 }
+
+func TestIsSchedulableAfterNodeChange(t *testing.T) {
+	node := func(labels map[string]string) *v1.Node {
+		return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: labels}}
+	}
+	pod := &v1.Pod{Spec: v1.PodSpec{NodeSelector: map[string]string{"foo": "bar"}}}
+	tests := []struct {
+		name   string
+		args   config.NodeAffinityArgs
+		oldObj interface{}
+		newObj interface{}
+		want   framework.QueueingHint
+	}{
+		{
+			name:   "added node matches",
+			newObj: node(map[string]string{"foo": "bar"}),
+			want:   framework.Queue,
+		},
+		{
+			name:   "added node doesn't match",
+			newObj: node(map[string]string{"foo": "baz"}),
+			want:   framework.QueueSkip,
+		},
+		{
+			name:   "node matches after the update",
+			oldObj: node(nil),
+			newObj: node(map[string]string{"foo": "bar"}),
+			want:   framework.Queue,
+		},
+		{
+			name:   "node already matched",
+			oldObj: node(map[string]string{"foo": "bar"}),
+			newObj: node(map[string]string{"foo": "bar", "zone": "a"}),
+			want:   framework.QueueSkip,
+		},
+		{
+			name: "added node doesn't match the added affinity",
+			args: config.NodeAffinityArgs{
+				AddedAffinity: &v1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{{
+							MatchExpressions: []v1.NodeSelectorRequirement{{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"a"}}},
+						}},
+					},
+				},
+			},
+			newObj: node(map[string]string{"foo": "bar"}),
+			want:   framework.QueueSkip,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := New(&test.args, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.(*NodeAffinity).isSchedulableAfterNodeChange(pod, test.oldObj, test.newObj); got != test.want {
+				t.Errorf("got queueing hint %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -29,6 +29,7 @@ import (
 type NodeName struct{}
 
 var _ framework.FilterPlugin = &NodeName{}
+var _ framework.CacheableFilterPlugin = &NodeName{}
 var _ framework.EnqueueExtensions = &NodeName{}
 
 const (
@@ -63,6 +64,12 @@ func (pl *NodeName) Filter(ctx context.Context, _ *framework.CycleState, pod *v1
 	return nil
 }
 
+// FilterCacheable returns true as the verdict of Filter only depends on the
+// pod and on the node.
+func (pl *NodeName) FilterCacheable(_ *framework.CycleState, _ *v1.Pod) bool {
+	return true
+}
+
 // Fits actually checks if the pod fits the node.
 func Fits(pod *v1.Pod, nodeInfo *framework.NodeInfo) bool {
 	return len(pod.Spec.NodeName) == 0 || pod.Spec.NodeName == nodeInfo.Node().Name
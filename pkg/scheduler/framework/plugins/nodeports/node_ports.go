@@ -31,6 +31,7 @@ type NodePorts struct{}
 
 var _ framework.PreFilterPlugin = &NodePorts{}
 var _ framework.FilterPlugin = &NodePorts{}
+var _ framework.CacheableFilterPlugin = &NodePorts{}
 var _ framework.EnqueueExtensions = &NodePorts{}
 
 const (
@@ -124,6 +125,12 @@ func (pl *NodePorts) Filter(ctx context.Context, cycleState *framework.CycleStat
 	return nil
 }
 
+// FilterCacheable returns true as the verdict of Filter only depends on the
+// pod and on the node.
+func (pl *NodePorts) FilterCacheable(_ *framework.CycleState, _ *v1.Pod) bool {
+	return true
+}
+
 // Fits checks if the pod fits the node.
 func Fits(pod *v1.Pod, nodeInfo *framework.NodeInfo) bool {
 	return fitsPorts(getContainerPorts(pod), nodeInfo)
@@ -34,7 +34,9 @@ import (
 
 var _ framework.PreFilterPlugin = &Fit{}
 var _ framework.FilterPlugin = &Fit{}
+var _ framework.CacheableFilterPlugin = &Fit{}
 var _ framework.EnqueueExtensions = &Fit{}
+var _ framework.QueueingHintExtensions = &Fit{}
 var _ framework.ScorePlugin = &Fit{}
 
 const (
@@ -215,6 +217,59 @@ func (f *Fit) EventsToRegister() []framework.ClusterEvent {
 	}
 }
 
+// QueueingHints returns the hints telling whether a Node event may make a Pod
+// failed by this plugin schedulable. Deleted Pods always free resources.
+func (f *Fit) QueueingHints() map[framework.ClusterEvent]framework.QueueingHintFn {
+	return map[framework.ClusterEvent]framework.QueueingHintFn{
+		{Resource: framework.Node, ActionType: framework.Add | framework.UpdateNodeAllocatable}: f.isSchedulableAfterNodeChange,
+	}
+}
+
+// isSchedulableAfterNodeChange queues the pod only if it would fit the node
+// when nothing runs on it and, for updates, if the node allocates more of a
+// resource the pod requests than before.
+func (f *Fit) isSchedulableAfterNodeChange(pod *v1.Pod, oldObj, newObj interface{}) framework.QueueingHint {
+	newNode, ok := newObj.(*v1.Node)
+	if !ok {
+		return framework.Queue
+	}
+	podRequest := computePodResourceRequest(pod, f.enablePodOverhead)
+	emptyNode := framework.NewNodeInfo()
+	emptyNode.SetNode(newNode)
+	if len(fitsRequest(podRequest, emptyNode, f.ignoredResources, f.ignoredResourceGroups)) != 0 {
+		return framework.QueueSkip
+	}
+	if oldNode, ok := oldObj.(*v1.Node); ok && !allocatableGrew(podRequest, oldNode, newNode) {
+		return framework.QueueSkip
+	}
+	return framework.Queue
+}
+
+// allocatableGrew returns true if the new node allocates more pods, or more
+// of a resource requested by the pod, than the old one.
+func allocatableGrew(podRequest *preFilterState, oldNode, newNode *v1.Node) bool {
+	oldAllocatable := framework.NewResource(oldNode.Status.Allocatable)
+	newAllocatable := framework.NewResource(newNode.Status.Allocatable)
+	if newAllocatable.AllowedPodNumber > oldAllocatable.AllowedPodNumber {
+		return true
+	}
+	if podRequest.MilliCPU > 0 && newAllocatable.MilliCPU > oldAllocatable.MilliCPU {
+		return true
+	}
+	if podRequest.Memory > 0 && newAllocatable.Memory > oldAllocatable.Memory {
+		return true
+	}
+	if podRequest.EphemeralStorage > 0 && newAllocatable.EphemeralStorage > oldAllocatable.EphemeralStorage {
+		return true
+	}
+	for rName, rQuant := range podRequest.ScalarResources {
+		if rQuant > 0 && newAllocatable.ScalarResources[rName] > oldAllocatable.ScalarResources[rName] {
+			return true
+		}
+	}
+	return false
+}
+
 // Filter invoked at the filter extension point.
 // Checks if a node has sufficient resources, such as cpu, memory, gpu, opaque int resources etc to run a pod.
 // It returns a list of insufficient resources, if empty, then the node has all the resources requested by the pod.
@@ -237,6 +292,12 @@ func (f *Fit) Filter(ctx context.Context, cycleState *framework.CycleState, pod
 	return nil
 }
 
+// FilterCacheable returns true as the verdict of Filter only depends on the
+// pod and on the node.
+func (f *Fit) FilterCacheable(_ *framework.CycleState, _ *v1.Pod) bool {
+	return true
+}
+
 // InsufficientResource describes what kind of resource limit is hit and caused the pod to not fit the node.
 type InsufficientResource struct {
 	ResourceName v1.ResourceName
@@ -736,3 +736,61 @@ func TestFitScore(t *testing.T) {
 		})
 	}
 }
+
+func TestFitIsSchedulableAfterNodeChange(t *testing.T) {
+	node := func(milliCPU, memory, extendedA int64) *v1.Node {
+		return &v1.Node{Status: v1.NodeStatus{Allocatable: makeAllocatableResources(milliCPU, memory, 10, extendedA, 0, 0)}}
+	}
+	tests := []struct {
+		name   string
+		oldObj interface{}
+		newObj interface{}
+		want   framework.QueueingHint
+	}{
+		{
+			name:   "added node fits the pod",
+			newObj: node(10, 20, 0),
+			want:   framework.Queue,
+		},
+		{
+			name:   "added node is too small for the pod",
+			newObj: node(2, 20, 0),
+			want:   framework.QueueSkip,
+		},
+		{
+			name:   "node allocates more of a requested resource",
+			oldObj: node(8, 20, 0),
+			newObj: node(10, 20, 0),
+			want:   framework.Queue,
+		},
+		{
+			name:   "node allocates more of a resource the pod doesn't request",
+			oldObj: node(10, 20, 0),
+			newObj: node(10, 20, 5),
+			want:   framework.QueueSkip,
+		},
+		{
+			name:   "updated node is still too small for the pod",
+			oldObj: node(2, 20, 0),
+			newObj: node(4, 20, 0),
+			want:   framework.QueueSkip,
+		},
+		{
+			name:   "unexpected object",
+			newObj: "node",
+			want:   framework.Queue,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := NewFit(&config.NodeResourcesFitArgs{ScoringStrategy: defaultScoringStrategy}, nil, plfeature.Features{EnablePodOverhead: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			pod := newResourcePod(framework.Resource{MilliCPU: 5, Memory: 10})
+			if got := p.(*Fit).isSchedulableAfterNodeChange(pod, test.oldObj, test.newObj); got != test.want {
+				t.Errorf("got queueing hint %v, want %v", got, test.want)
+			}
+		})
+	}
+}
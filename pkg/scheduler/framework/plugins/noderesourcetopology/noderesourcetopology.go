@@ -0,0 +1,293 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/scheduling/v1alpha1"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/names"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+)
+
+const (
+	// Name is the name of the plugin used in the plugin registry and configurations.
+	Name = names.NodeResourceTopologyMatch
+
+	// preFilterStateKey is the key in CycleState to NodeResourceTopologyMatch pre-computed data.
+	preFilterStateKey = "PreFilter" + Name
+
+	// ErrReasonNUMAAlignment is the status message for nodes whose NUMA zones
+	// can't satisfy the pod under the single-numa-node policy.
+	ErrReasonNUMAAlignment = "node(s) can't align the pod's resources to a single NUMA zone"
+)
+
+// NodeResourceTopologyMatch is a plugin that places Guaranteed pods on nodes
+// whose kubelet can align their resources to NUMA zones. The NUMA zones of a
+// node and their available CPU, memory and devices are read from its
+// NodeResourceTopology.
+//
+// On nodes with the single-numa-node topology policy, a node is filtered out
+// if the kubelet would reject the pod at admission. Among the nodes that
+// fit, the ones where the pod leaves the least free resources in the zones
+// it's placed on score higher, which keeps whole zones free for larger pods.
+// Nodes that don't publish their topology are neither filtered nor scored.
+type NodeResourceTopologyMatch struct {
+	lister *lister
+
+	mu sync.RWMutex
+	// reserved are the allocations of the pods reserved on each node, keyed
+	// by node name and pod UID. They are taken from the zones until the agent
+	// of the node publishes its topology again.
+	reserved map[string]map[types.UID]*reservation
+}
+
+// reservation is the allocation of a reserved pod.
+type reservation struct {
+	alloc allocation
+	// resourceVersion is the version of the NodeResourceTopology of the node
+	// at Reserve.
+	resourceVersion string
+}
+
+var _ framework.PreFilterPlugin = &NodeResourceTopologyMatch{}
+var _ framework.FilterPlugin = &NodeResourceTopologyMatch{}
+var _ framework.ScorePlugin = &NodeResourceTopologyMatch{}
+var _ framework.ReservePlugin = &NodeResourceTopologyMatch{}
+var _ framework.EnqueueExtensions = &NodeResourceTopologyMatch{}
+
+// New initializes a new plugin and returns it.
+func New(_ runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+	pl := &NodeResourceTopologyMatch{
+		reserved: make(map[string]map[types.UID]*reservation),
+	}
+	if fh.DynInformerFactory() != nil {
+		pl.lister = newLister(fh.DynInformerFactory())
+	}
+	return pl, nil
+}
+
+// Name returns name of the plugin. It is used in logs, etc.
+func (pl *NodeResourceTopologyMatch) Name() string {
+	return Name
+}
+
+// EventsToRegister returns the possible events that may make a Pod
+// failed by this plugin schedulable.
+func (pl *NodeResourceTopologyMatch) EventsToRegister() []framework.ClusterEvent {
+	return []framework.ClusterEvent{
+		// The agent of a node publishes its topology again when pods leave.
+		{Resource: GVK, ActionType: framework.Add | framework.Update},
+		{Resource: framework.Pod, ActionType: framework.Delete},
+	}
+}
+
+// preFilterState computed at PreFilter and used at Filter, Score and Reserve.
+type preFilterState struct {
+	// guaranteed is whether the pod has the Guaranteed QoS class. Only the
+	// resources of such pods are aligned by the kubelet.
+	guaranteed        bool
+	initRequests      []*framework.Resource
+	containerRequests []*framework.Resource
+	podRequests       *framework.Resource
+}
+
+// Clone the prefilter state.
+func (s *preFilterState) Clone() framework.StateData {
+	return s
+}
+
+// PreFilter computes the requests of the pod the kubelet aligns to NUMA
+// zones, for the container and pod scopes.
+func (pl *NodeResourceTopologyMatch) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod) *framework.Status {
+	s := &preFilterState{guaranteed: v1qos.GetPodQOS(pod) == v1.PodQOSGuaranteed}
+	if s.guaranteed {
+		podRequests := &framework.Resource{}
+		for _, c := range pod.Spec.InitContainers {
+			s.initRequests = append(s.initRequests, alignedRequests(framework.NewResource(c.Resources.Requests)))
+		}
+		for _, c := range pod.Spec.Containers {
+			s.containerRequests = append(s.containerRequests, alignedRequests(framework.NewResource(c.Resources.Requests)))
+			podRequests.Add(c.Resources.Requests)
+		}
+		for _, c := range pod.Spec.InitContainers {
+			podRequests.SetMaxResource(c.Resources.Requests)
+		}
+		s.podRequests = alignedRequests(podRequests)
+	}
+	cycleState.Write(preFilterStateKey, s)
+	return nil
+}
+
+// PreFilterExtensions returns nil as the NUMA zones of a node don't depend
+// on the pods the scheduler considers on it.
+func (pl *NodeResourceTopologyMatch) PreFilterExtensions() framework.PreFilterExtensions {
+	return nil
+}
+
+func getPreFilterState(cycleState *framework.CycleState) (*preFilterState, error) {
+	c, err := cycleState.Read(preFilterStateKey)
+	if err != nil {
+		// preFilterState doesn't exist, likely PreFilter wasn't invoked.
+		return nil, fmt.Errorf("error reading %q from cycleState: %w", preFilterStateKey, err)
+	}
+
+	s, ok := c.(*preFilterState)
+	if !ok {
+		return nil, fmt.Errorf("%+v  convert to NodeResourceTopologyMatch.preFilterState error", c)
+	}
+	return s, nil
+}
+
+// topologyOf returns the NUMA zones of the node with the allocations of the
+// pods reserved since the topology was published taken out, or nil if the
+// node doesn't publish its topology or doesn't use the single-numa-node
+// policy.
+func (pl *NodeResourceTopologyMatch) topologyOf(nodeName string) (*topology, error) {
+	if pl.lister == nil {
+		return nil, nil
+	}
+	nrt, err := pl.lister.get(nodeName)
+	if err != nil || nrt == nil || nrt.TopologyPolicy != v1alpha1.SingleNUMANodeTopologyPolicy {
+		return nil, err
+	}
+	t := newTopology(nrt)
+	pl.mu.RLock()
+	defer pl.mu.RUnlock()
+	for _, r := range pl.reserved[nodeName] {
+		if r.resourceVersion == t.resourceVersion {
+			t.take(r.alloc)
+		}
+	}
+	return t, nil
+}
+
+// Filter invoked at the filter extension point. It checks that the kubelet
+// of the node can align the resources of a Guaranteed pod to NUMA zones.
+func (pl *NodeResourceTopologyMatch) Filter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if !s.guaranteed {
+		return nil
+	}
+	node := nodeInfo.Node()
+	if node == nil {
+		return framework.NewStatus(framework.Error, "node not found")
+	}
+	t, err := pl.topologyOf(node.Name)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if t == nil {
+		return nil
+	}
+	if _, ok := t.allocate(s); !ok {
+		return framework.NewStatus(framework.Unschedulable, ErrReasonNUMAAlignment)
+	}
+	return nil
+}
+
+// Score invoked at the score extension point. Nodes get a higher score the
+// less free resources the pod leaves in the NUMA zones it's placed on.
+func (pl *NodeResourceTopologyMatch) Score(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return 0, framework.AsStatus(err)
+	}
+	if !s.guaranteed {
+		return 0, nil
+	}
+	t, err := pl.topologyOf(nodeName)
+	if err != nil {
+		return 0, framework.AsStatus(err)
+	}
+	if t == nil {
+		return 0, nil
+	}
+	a, ok := t.allocate(s)
+	if !ok {
+		return 0, nil
+	}
+	return int64(float64(framework.MaxNodeScore) * (1 - t.leftover(a))), nil
+}
+
+// ScoreExtensions of the Score plugin.
+func (pl *NodeResourceTopologyMatch) ScoreExtensions() framework.ScoreExtensions {
+	return nil
+}
+
+// Reserve records the allocation of the pod on the NUMA zones of the node,
+// so that pods scheduled before the agent of the node publishes its topology
+// again don't count on the same resources.
+func (pl *NodeResourceTopologyMatch) Reserve(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if !s.guaranteed {
+		return nil
+	}
+	t, err := pl.topologyOf(nodeName)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if t == nil {
+		return nil
+	}
+	a, ok := t.allocate(s)
+	if !ok {
+		return framework.NewStatus(framework.Unschedulable, ErrReasonNUMAAlignment)
+	}
+
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	reserved := pl.reserved[nodeName]
+	if reserved == nil {
+		reserved = make(map[types.UID]*reservation)
+		pl.reserved[nodeName] = reserved
+	}
+	// Allocations the agent has reported since are part of the topology.
+	for uid, r := range reserved {
+		if r.resourceVersion != t.resourceVersion {
+			delete(reserved, uid)
+		}
+	}
+	reserved[pod.UID] = &reservation{alloc: a, resourceVersion: t.resourceVersion}
+	klog.V(5).InfoS("Reserved NUMA zones", "pod", klog.KObj(pod), "node", nodeName, "zones", len(a))
+	return nil
+}
+
+// Unreserve forgets the allocation of the pod on the node.
+func (pl *NodeResourceTopologyMatch) Unreserve(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeName string) {
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if reserved := pl.reserved[nodeName]; reserved != nil {
+		delete(reserved, pod.UID)
+		if len(reserved) == 0 {
+			delete(pl.reserved, nodeName)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/scheduling/v1alpha1"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	frameworkruntime "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/runtime"
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dyfake "k8s.io/client-go/dynamic/fake"
+)
+
+const deviceResource v1.ResourceName = "example.com/device"
+
+func makeResources(m map[v1.ResourceName]string) v1.ResourceList {
+	rl := v1.ResourceList{}
+	for name, q := range m {
+		rl[name] = resource.MustParse(q)
+	}
+	return rl
+}
+
+func makeZone(name string, allocatable, available map[v1.ResourceName]string) v1alpha1.NUMAZone {
+	return v1alpha1.NUMAZone{
+		Name:        name,
+		Allocatable: makeResources(allocatable),
+		Available:   makeResources(available),
+	}
+}
+
+func makeTopology(nodeName, policy, scope, resourceVersion string, zones ...v1alpha1.NUMAZone) *v1alpha1.NodeResourceTopology {
+	return &v1alpha1.NodeResourceTopology{
+		TypeMeta:             metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "NodeResourceTopology"},
+		ObjectMeta:           metav1.ObjectMeta{Name: nodeName, ResourceVersion: resourceVersion},
+		TopologyPolicy:       policy,
+		TopologyManagerScope: scope,
+		Zones:                zones,
+	}
+}
+
+// guaranteedPod returns a pod with a container for each of the resource
+// lists, whose limits equal its requests.
+func guaranteedPod(name string, containers ...map[v1.ResourceName]string) *v1.Pod {
+	pod := st.MakePod().Namespace("default").Name(name).UID(name).Obj()
+	for _, m := range containers {
+		rl := makeResources(m)
+		pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{
+			Name:      name,
+			Resources: v1.ResourceRequirements{Requests: rl, Limits: rl},
+		})
+	}
+	return pod
+}
+
+// twoZones are the zones of a node with 4 CPUs and 8Gi of memory on each
+// zone and a device on the second zone.
+var twoZones = []v1alpha1.NUMAZone{
+	makeZone("node-0",
+		map[v1.ResourceName]string{v1.ResourceCPU: "4", v1.ResourceMemory: "8Gi"},
+		map[v1.ResourceName]string{v1.ResourceCPU: "4", v1.ResourceMemory: "8Gi"}),
+	makeZone("node-1",
+		map[v1.ResourceName]string{v1.ResourceCPU: "4", v1.ResourceMemory: "8Gi", deviceResource: "1"},
+		map[v1.ResourceName]string{v1.ResourceCPU: "2", v1.ResourceMemory: "8Gi", deviceResource: "1"}),
+}
+
+func newTestPlugin(ctx context.Context, t *testing.T, topologies ...*v1alpha1.NodeResourceTopology) (*NodeResourceTopologyMatch, dynamic.Interface) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	objs := make([]runtime.Object, 0, len(topologies))
+	for _, nrt := range topologies {
+		objs = append(objs, nrt)
+	}
+	dynClient := dyfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+		map[schema.GroupVersionResource]string{Resource: "NodeResourceTopologyList"}, objs...)
+	dynInformerFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynClient, 0)
+	fh, err := frameworkruntime.NewFramework(nil, nil, frameworkruntime.WithDynInformerFactory(dynInformerFactory))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := New(nil, fh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dynInformerFactory.Start(ctx.Done())
+	dynInformerFactory.WaitForCacheSync(ctx.Done())
+	return p.(*NodeResourceTopologyMatch), dynClient
+}
+
+func nodeInfo(name string) *framework.NodeInfo {
+	ni := framework.NewNodeInfo()
+	ni.SetNode(st.MakeNode().Name(name).Obj())
+	return ni
+}
+
+func TestFilter(t *testing.T) {
+	cpuMem := func(cpu, mem string) map[v1.ResourceName]string {
+		return map[v1.ResourceName]string{v1.ResourceCPU: cpu, v1.ResourceMemory: mem}
+	}
+	tests := map[string]struct {
+		pod      *v1.Pod
+		topology *v1alpha1.NodeResourceTopology
+		want     *framework.Status
+	}{
+		"burstable pod": {
+			pod:      st.MakePod().Name("p").Req(map[v1.ResourceName]string{v1.ResourceCPU: "8"}).Obj(),
+			topology: makeTopology("node", v1alpha1.SingleNUMANodeTopologyPolicy, "", "1", twoZones...),
+		},
+		"fits a zone": {
+			pod:      guaranteedPod("p", cpuMem("4", "4Gi")),
+			topology: makeTopology("node", v1alpha1.SingleNUMANodeTopologyPolicy, "", "1", twoZones...),
+		},
+		"larger than any zone": {
+			pod:      guaranteedPod("p", cpuMem("5", "4Gi")),
+			topology: makeTopology("node", v1alpha1.SingleNUMANodeTopologyPolicy, "", "1", twoZones...),
+			want:     framework.NewStatus(framework.Unschedulable, ErrReasonNUMAAlignment),
+		},
+		"fractional CPUs aren't aligned": {
+			pod:      guaranteedPod("p", cpuMem("5500m", "4Gi")),
+			topology: makeTopology("node", v1alpha1.SingleNUMANodeTopologyPolicy, "", "1", twoZones...),
+		},
+		"containers spread over zones": {
+			pod:      guaranteedPod("p", cpuMem("4", "1Gi"), cpuMem("2", "1Gi")),
+			topology: makeTopology("node", v1alpha1.SingleNUMANodeTopologyPolicy, v1alpha1.ContainerTopologyManagerScope, "1", twoZones...),
+		},
+		"pod scope needs a single zone": {
+			pod:      guaranteedPod("p", cpuMem("2", "1Gi"), cpuMem("2", "1Gi")),
+			topology: makeTopology("node", v1alpha1.SingleNUMANodeTopologyPolicy, v1alpha1.PodTopologyManagerScope, "1", twoZones...),
+		},
+		"pod scope larger than any zone": {
+			pod:      guaranteedPod("p", cpuMem("3", "1Gi"), cpuMem("2", "1Gi")),
+			topology: makeTopology("node", v1alpha1.SingleNUMANodeTopologyPolicy, v1alpha1.PodTopologyManagerScope, "1", twoZones...),
+			want:     framework.NewStatus(framework.Unschedulable, ErrReasonNUMAAlignment),
+		},
+		"device and CPUs on different zones": {
+			pod: guaranteedPod("p", map[v1.ResourceName]string{
+				v1.ResourceCPU: "3", v1.ResourceMemory: "1Gi", deviceResource: "1",
+			}),
+			topology: makeTopology("node", v1alpha1.SingleNUMANodeTopologyPolicy, "", "1", twoZones...),
+			want:     framework.NewStatus(framework.Unschedulable, ErrReasonNUMAAlignment),
+		},
+		"device and CPUs on the same zone": {
+			pod: guaranteedPod("p", map[v1.ResourceName]string{
+				v1.ResourceCPU: "2", v1.ResourceMemory: "1Gi", deviceResource: "1",
+			}),
+			topology: makeTopology("node", v1alpha1.SingleNUMANodeTopologyPolicy, "", "1", twoZones...),
+		},
+		"other policy": {
+			pod:      guaranteedPod("p", cpuMem("5", "4Gi")),
+			topology: makeTopology("node", "best-effort", "", "1", twoZones...),
+		},
+		"no topology": {
+			pod:      guaranteedPod("p", cpuMem("5", "4Gi")),
+			topology: makeTopology("other", v1alpha1.SingleNUMANodeTopologyPolicy, "", "1", twoZones...),
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			p, _ := newTestPlugin(ctx, t, tc.topology)
+			cycleState := framework.NewCycleState()
+			if s := p.PreFilter(ctx, cycleState, tc.pod); !s.IsSuccess() {
+				t.Fatalf("PreFilter failed: %v", s)
+			}
+			got := p.Filter(ctx, cycleState, tc.pod, nodeInfo("node"))
+			if !got.Equal(tc.want) {
+				t.Errorf("Filter returned %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	zones := func(availableCPU string) []v1alpha1.NUMAZone {
+		return []v1alpha1.NUMAZone{
+			makeZone("node-0",
+				map[v1.ResourceName]string{v1.ResourceCPU: "4", v1.ResourceMemory: "8Gi"},
+				map[v1.ResourceName]string{v1.ResourceCPU: availableCPU, v1.ResourceMemory: "8Gi"}),
+		}
+	}
+	p, _ := newTestPlugin(ctx, t,
+		makeTopology("full", v1alpha1.SingleNUMANodeTopologyPolicy, "", "1", zones("2")...),
+		makeTopology("empty", v1alpha1.SingleNUMANodeTopologyPolicy, "", "1", zones("4")...),
+	)
+	pod := guaranteedPod("p", map[v1.ResourceName]string{v1.ResourceCPU: "2", v1.ResourceMemory: "4Gi"})
+	cycleState := framework.NewCycleState()
+	if s := p.PreFilter(ctx, cycleState, pod); !s.IsSuccess() {
+		t.Fatalf("PreFilter failed: %v", s)
+	}
+	// On "full", the pod leaves no CPU and half of the memory of the zone.
+	// On "empty", it leaves half of both.
+	for node, want := range map[string]int64{"full": 75, "empty": 50, "unknown": 0} {
+		got, s := p.Score(ctx, cycleState, pod, node)
+		if !s.IsSuccess() {
+			t.Fatalf("Score failed on %s: %v", node, s)
+		}
+		if got != want {
+			t.Errorf("Score on %s is %d, want %d", node, got, want)
+		}
+	}
+}
+
+func TestReserve(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p, dynClient := newTestPlugin(ctx, t, makeTopology("node", v1alpha1.SingleNUMANodeTopologyPolicy, "", "1", twoZones...))
+	req := map[v1.ResourceName]string{v1.ResourceCPU: "4", v1.ResourceMemory: "1Gi"}
+	first, second := guaranteedPod("first", req), guaranteedPod("second", req)
+
+	filter := func(pod *v1.Pod) *framework.Status {
+		cycleState := framework.NewCycleState()
+		if s := p.PreFilter(ctx, cycleState, pod); !s.IsSuccess() {
+			t.Fatalf("PreFilter failed: %v", s)
+		}
+		return p.Filter(ctx, cycleState, pod, nodeInfo("node"))
+	}
+
+	cycleState := framework.NewCycleState()
+	p.PreFilter(ctx, cycleState, first)
+	if s := p.Reserve(ctx, cycleState, first, "node"); !s.IsSuccess() {
+		t.Fatalf("Reserve failed: %v", s)
+	}
+	// The only zone with 4 free CPUs is taken by the first pod.
+	if s := filter(second); s.Code() != framework.Unschedulable {
+		t.Errorf("Filter after Reserve returned %v, want Unschedulable", s)
+	}
+	p.Unreserve(ctx, cycleState, first, "node")
+	if s := filter(second); !s.IsSuccess() {
+		t.Errorf("Filter after Unreserve returned %v, want success", s)
+	}
+
+	if s := p.Reserve(ctx, cycleState, first, "node"); !s.IsSuccess() {
+		t.Fatalf("Reserve failed: %v", s)
+	}
+	// The agent publishes the topology again, which accounts for the first
+	// pod. Its CPUs are free as it has already terminated.
+	updated := makeTopology("node", v1alpha1.SingleNUMANodeTopologyPolicy, "", "2", twoZones...)
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dynClient.Resource(Resource).Update(ctx, &unstructured.Unstructured{Object: content}, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return filter(second).IsSuccess(), nil
+	}); err != nil {
+		t.Errorf("Filter didn't succeed after the topology was published again: %v", err)
+	}
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/scheduling/v1alpha1"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	// Resource is the resource of the NodeResourceTopology custom resource.
+	Resource = v1alpha1.SchemeGroupVersion.WithResource("noderesourcetopologies")
+
+	// GVK is the NodeResourceTopology resource in the format plugins use to
+	// register cluster events.
+	GVK = framework.GVK(fmt.Sprintf("%v.%v.%v", Resource.Resource, Resource.Version, Resource.Group))
+)
+
+// lister gets NodeResourceTopologies from an informer's cache.
+type lister struct {
+	lister cache.GenericLister
+}
+
+func newLister(dynInformerFactory dynamicinformer.DynamicSharedInformerFactory) *lister {
+	return &lister{lister: dynInformerFactory.ForResource(Resource).Lister()}
+}
+
+// get returns the NodeResourceTopology of the node, or nil if the node
+// doesn't publish one.
+func (l *lister) get(nodeName string) (*v1alpha1.NodeResourceTopology, error) {
+	obj, err := l.lister.Get(nodeName)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object of type %T for NodeResourceTopology", obj)
+	}
+	nrt := &v1alpha1.NodeResourceTopology{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), nrt); err != nil {
+		return nil, fmt.Errorf("converting NodeResourceTopology %v: %w", u.GetName(), err)
+	}
+	return nrt, nil
+}
+
+// zone is a NUMA zone of a node.
+type zone struct {
+	name        string
+	allocatable *framework.Resource
+	available   *framework.Resource
+}
+
+// topology is the NUMA zones of a node.
+type topology struct {
+	policy          string
+	scope           string
+	resourceVersion string
+	// names are the resources bound to NUMA zones. A request for any of them
+	// must be satisfied by a single zone.
+	names []v1.ResourceName
+	// zones are sorted by name, in the order the kubelet prefers them.
+	zones []*zone
+}
+
+func newTopology(nrt *v1alpha1.NodeResourceTopology) *topology {
+	t := &topology{
+		policy:          nrt.TopologyPolicy,
+		scope:           nrt.TopologyManagerScope,
+		resourceVersion: nrt.ResourceVersion,
+	}
+	if t.scope == "" {
+		t.scope = v1alpha1.ContainerTopologyManagerScope
+	}
+	seen := make(map[v1.ResourceName]bool)
+	for _, z := range nrt.Zones {
+		t.zones = append(t.zones, &zone{
+			name:        z.Name,
+			allocatable: framework.NewResource(z.Allocatable),
+			available:   framework.NewResource(z.Available),
+		})
+		for name := range z.Allocatable {
+			if !seen[name] {
+				seen[name] = true
+				t.names = append(t.names, name)
+			}
+		}
+	}
+	sort.Slice(t.zones, func(i, j int) bool { return t.zones[i].name < t.zones[j].name })
+	sort.Slice(t.names, func(i, j int) bool { return t.names[i] < t.names[j] })
+	return t
+}
+
+// take subtracts the allocation from the available resources of the zones.
+func (t *topology) take(a allocation) {
+	for _, z := range t.zones {
+		if r, ok := a[z.name]; ok {
+			z.available.SubResource(r)
+		}
+	}
+}
+
+// fits returns whether the free resources satisfy the request for every
+// resource bound to NUMA zones.
+func (t *topology) fits(free, req *framework.Resource) bool {
+	for _, name := range t.names {
+		if r := req.Value(name); r > 0 && r > free.Value(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// allocation is the resources a pod takes from each NUMA zone of a node,
+// keyed by zone name. Zones the pod takes nothing from are absent.
+type allocation map[string]*framework.Resource
+
+// allocate returns the allocation of the pod under the single-numa-node
+// policy, and false if it can't be aligned. In the container scope, each
+// container is aligned separately and init containers reuse the resources
+// of the app containers. In the pod scope, the pod is aligned as a whole.
+func (t *topology) allocate(s *preFilterState) (allocation, bool) {
+	if t.scope == v1alpha1.PodTopologyManagerScope {
+		return t.allocateRequests([]*framework.Resource{s.podRequests})
+	}
+	for _, req := range s.initRequests {
+		if _, ok := t.allocateRequests([]*framework.Resource{req}); !ok {
+			return nil, false
+		}
+	}
+	return t.allocateRequests(s.containerRequests)
+}
+
+// allocateRequests takes each request from the first zone with enough free
+// resources, like the kubelet prefers the lowest NUMA zone among the ones
+// that fit. It doesn't modify the zones.
+func (t *topology) allocateRequests(reqs []*framework.Resource) (allocation, bool) {
+	free := make([]*framework.Resource, len(t.zones))
+	for i, z := range t.zones {
+		free[i] = z.available.Clone()
+	}
+	a := make(allocation)
+	for _, req := range reqs {
+		i := -1
+		for j := range t.zones {
+			if t.fits(free[j], req) {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			return nil, false
+		}
+		free[i].SubResource(req)
+		name := t.zones[i].name
+		if a[name] == nil {
+			a[name] = &framework.Resource{}
+		}
+		a[name].AddResource(req)
+	}
+	return a, true
+}
+
+// leftover returns the average fraction of the allocatable resources that
+// would stay free in the zones the pod takes resources from, for each
+// resource the pod takes. It's 0 when the pod fills the zones exactly.
+func (t *topology) leftover(a allocation) float64 {
+	var sum float64
+	var n int
+	for _, z := range t.zones {
+		taken, ok := a[z.name]
+		if !ok {
+			continue
+		}
+		for _, name := range t.names {
+			r, alloc := taken.Value(name), z.allocatable.Value(name)
+			if r == 0 || alloc == 0 {
+				continue
+			}
+			free := z.available.Value(name) - r
+			if free < 0 {
+				free = 0
+			}
+			sum += float64(free) / float64(alloc)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// alignedRequests returns the requests of a container the kubelet aligns to
+// NUMA zones. CPUs are only pinned for containers requesting whole CPUs.
+func alignedRequests(r *framework.Resource) *framework.Resource {
+	if r.MilliCPU%1000 != 0 {
+		r.MilliCPU = 0
+	}
+	return r
+}
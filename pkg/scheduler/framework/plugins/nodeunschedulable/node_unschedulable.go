@@ -32,6 +32,7 @@ type NodeUnschedulable struct {
 }
 
 var _ framework.FilterPlugin = &NodeUnschedulable{}
+var _ framework.CacheableFilterPlugin = &NodeUnschedulable{}
 var _ framework.EnqueueExtensions = &NodeUnschedulable{}
 
 // Name is the name of the plugin used in the plugin registry and configurations.
@@ -74,6 +75,12 @@ func (pl *NodeUnschedulable) Filter(ctx context.Context, _ *framework.CycleState
 	return nil
 }
 
+// FilterCacheable returns true as the verdict of Filter only depends on the
+// pod and on the node.
+func (pl *NodeUnschedulable) FilterCacheable(_ *framework.CycleState, _ *v1.Pod) bool {
+	return true
+}
+
 // New initializes a new plugin and returns it.
 func New(_ runtime.Object, _ framework.Handle) (framework.Plugin, error) {
 	return &NodeUnschedulable{}, nil
@@ -59,6 +59,7 @@ type CSILimits struct {
 }
 
 var _ framework.FilterPlugin = &CSILimits{}
+var _ framework.CacheableFilterPlugin = &CSILimits{}
 var _ framework.EnqueueExtensions = &CSILimits{}
 
 // CSIName is the name of the plugin used in the plugin registry and configurations.
@@ -145,6 +146,13 @@ func (pl *CSILimits) Filter(ctx context.Context, _ *framework.CycleState, pod *v
 	return nil
 }
 
+// FilterCacheable returns true if the pod has no attachable volumes, as Filter
+// always passes then. Otherwise the verdict depends on the CSINode of the node
+// and on the PVCs.
+func (pl *CSILimits) FilterCacheable(_ *framework.CycleState, pod *v1.Pod) bool {
+	return !hasAttachableVolumes(pod)
+}
+
 func (pl *CSILimits) filterAttachableVolumes(
 	pod *v1.Pod, csiNode *storagev1.CSINode, newPod bool, result map[string]string) error {
 	for _, vol := range pod.Spec.Volumes {
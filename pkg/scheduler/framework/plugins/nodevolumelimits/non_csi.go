@@ -120,6 +120,7 @@ type nonCSILimits struct {
 }
 
 var _ framework.FilterPlugin = &nonCSILimits{}
+var _ framework.CacheableFilterPlugin = &nonCSILimits{}
 var _ framework.EnqueueExtensions = &nonCSILimits{}
 
 // newNonCSILimitsWithInformerFactory returns a plugin with filter name and informer factory.
@@ -274,6 +275,12 @@ func (pl *nonCSILimits) Filter(ctx context.Context, _ *framework.CycleState, pod
 	return nil
 }
 
+// FilterCacheable returns true if the pod has no attachable volumes, as Filter
+// always passes then. Otherwise the verdict depends on the PVCs.
+func (pl *nonCSILimits) FilterCacheable(_ *framework.CycleState, pod *v1.Pod) bool {
+	return !hasAttachableVolumes(pod)
+}
+
 func (pl *nonCSILimits) filterVolumes(pod *v1.Pod, newPod bool, filteredVolumes sets.String) error {
 	volumes := pod.Spec.Volumes
 	for i := range volumes {
@@ -100,3 +100,17 @@ func volumeLimits(n *framework.NodeInfo) map[v1.ResourceName]int64 {
 	}
 	return volumeLimits
 }
+
+// hasAttachableVolumes returns true if the pod has volumes that may count
+// against the volume limits of a node.
+func hasAttachableVolumes(pod *v1.Pod) bool {
+	for i := range pod.Spec.Volumes {
+		vol := &pod.Spec.Volumes[i]
+		if vol.PersistentVolumeClaim != nil || vol.Ephemeral != nil || vol.CSI != nil ||
+			vol.AWSElasticBlockStore != nil || vol.GCEPersistentDisk != nil ||
+			vol.AzureDisk != nil || vol.Cinder != nil || vol.PortworxVolume != nil {
+			return true
+		}
+	}
+	return false
+}
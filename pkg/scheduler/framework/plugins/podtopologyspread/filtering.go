@@ -329,6 +329,17 @@ func (pl *PodTopologySpread) Filter(ctx context.Context, cycleState *framework.C
 	return nil
 }
 
+// FilterCacheable returns true if the pod has no hard topology spread
+// constraints, as Filter always passes then. Otherwise the verdict depends on
+// the pods running on the other nodes of the topology domains.
+func (pl *PodTopologySpread) FilterCacheable(cycleState *framework.CycleState, _ *v1.Pod) bool {
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return false
+	}
+	return len(s.Constraints) == 0
+}
+
 func sizeHeuristic(nodes int, constraints []topologySpreadConstraint) int {
 	for _, c := range constraints {
 		if c.TopologyKey == v1.LabelHostname {
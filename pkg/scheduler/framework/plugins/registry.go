@@ -17,21 +17,29 @@ limitations under the License.
 package plugins
 
 import (
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/backfill"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/coscheduling"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/defaultbinder"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/defaultpreemption"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/dominantresourcefairness"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/elasticquota"
 	plfeature "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/feature"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/gpushare"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/gputopology"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/imagelocality"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/interpodaffinity"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/nodeaffinity"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/nodename"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/nodeports"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/noderesources"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/noderesourcetopology"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/nodeunschedulable"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/nodevolumelimits"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/podtopologyspread"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/queuesort"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/selectorspread"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/tainttoleration"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/topologypacking"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/volumebinding"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/volumerestrictions"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/volumezone"
@@ -76,5 +84,13 @@ func NewInTreeRegistry() runtime.Registry {
 		queuesort.Name:                       queuesort.New,
 		defaultbinder.Name:                   defaultbinder.New,
 		defaultpreemption.Name:               runtime.FactoryAdapter(fts, defaultpreemption.New),
+		coscheduling.Name:                    coscheduling.New,
+		gputopology.Name:                     gputopology.New,
+		gpushare.Name:                        gpushare.New,
+		elasticquota.Name:                    runtime.FactoryAdapter(fts, elasticquota.New),
+		dominantresourcefairness.Name:        dominantresourcefairness.New,
+		backfill.Name:                        backfill.New,
+		topologypacking.Name:                 topologypacking.New,
+		noderesourcetopology.Name:            noderesourcetopology.New,
 	}
 }
@@ -34,9 +34,11 @@ type TaintToleration struct {
 }
 
 var _ framework.FilterPlugin = &TaintToleration{}
+var _ framework.CacheableFilterPlugin = &TaintToleration{}
 var _ framework.PreScorePlugin = &TaintToleration{}
 var _ framework.ScorePlugin = &TaintToleration{}
 var _ framework.EnqueueExtensions = &TaintToleration{}
+var _ framework.QueueingHintExtensions = &TaintToleration{}
 
 const (
 	// Name is the name of the plugin used in the plugin registry and configurations.
@@ -60,6 +62,39 @@ func (pl *TaintToleration) EventsToRegister() []framework.ClusterEvent {
 	}
 }
 
+// QueueingHints returns the hints telling whether a Node event may make a Pod
+// failed by this plugin schedulable.
+func (pl *TaintToleration) QueueingHints() map[framework.ClusterEvent]framework.QueueingHintFn {
+	return map[framework.ClusterEvent]framework.QueueingHintFn{
+		{Resource: framework.Node, ActionType: framework.Add | framework.UpdateNodeTaint}: isSchedulableAfterNodeChange,
+	}
+}
+
+// isSchedulableAfterNodeChange queues the pod only if it tolerates the taints
+// of the node now but didn't before.
+func isSchedulableAfterNodeChange(pod *v1.Pod, oldObj, newObj interface{}) framework.QueueingHint {
+	newNode, ok := newObj.(*v1.Node)
+	if !ok {
+		return framework.Queue
+	}
+	if !toleratesNode(pod, newNode) {
+		return framework.QueueSkip
+	}
+	if oldNode, ok := oldObj.(*v1.Node); ok && toleratesNode(pod, oldNode) {
+		return framework.QueueSkip
+	}
+	return framework.Queue
+}
+
+// toleratesNode returns true if the pod tolerates the NoSchedule and NoExecute
+// taints of the node.
+func toleratesNode(pod *v1.Pod, node *v1.Node) bool {
+	_, isUntolerated := v1helper.FindMatchingUntoleratedTaint(node.Spec.Taints, pod.Spec.Tolerations, func(t *v1.Taint) bool {
+		return t.Effect == v1.TaintEffectNoSchedule || t.Effect == v1.TaintEffectNoExecute
+	})
+	return !isUntolerated
+}
+
 // Filter invoked at the filter extension point.
 func (pl *TaintToleration) Filter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
 	if nodeInfo == nil || nodeInfo.Node() == nil {
@@ -81,6 +116,12 @@ func (pl *TaintToleration) Filter(ctx context.Context, state *framework.CycleSta
 	return framework.NewStatus(framework.UnschedulableAndUnresolvable, errReason)
 }
 
+// FilterCacheable returns true as the verdict of Filter only depends on the
+// pod and on the node.
+func (pl *TaintToleration) FilterCacheable(_ *framework.CycleState, _ *v1.Pod) bool {
+	return true
+}
+
 // preScoreState computed at PreScore and used at Score.
 type preScoreState struct {
 	tolerationsPreferNoSchedule []v1.Toleration
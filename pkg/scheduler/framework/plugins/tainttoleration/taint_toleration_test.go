@@ -340,3 +340,50 @@ func TestTaintTolerationFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestIsSchedulableAfterNodeChange(t *testing.T) {
+	noSchedule := []v1.Taint{{Key: "dedicated", Value: "user1", Effect: "NoSchedule"}}
+	tests := []struct {
+		name   string
+		oldObj interface{}
+		newObj interface{}
+		want   framework.QueueingHint
+	}{
+		{
+			name:   "added node without taints",
+			newObj: nodeWithTaints("node", nil),
+			want:   framework.Queue,
+		},
+		{
+			name:   "added node with untolerated taints",
+			newObj: nodeWithTaints("node", noSchedule),
+			want:   framework.QueueSkip,
+		},
+		{
+			name:   "untolerated taint removed",
+			oldObj: nodeWithTaints("node", noSchedule),
+			newObj: nodeWithTaints("node", nil),
+			want:   framework.Queue,
+		},
+		{
+			name:   "untolerated taint added",
+			oldObj: nodeWithTaints("node", nil),
+			newObj: nodeWithTaints("node", noSchedule),
+			want:   framework.QueueSkip,
+		},
+		{
+			name:   "node was already tolerated",
+			oldObj: nodeWithTaints("node", []v1.Taint{{Key: "dedicated", Value: "user1", Effect: "PreferNoSchedule"}}),
+			newObj: nodeWithTaints("node", nil),
+			want:   framework.QueueSkip,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pod := podWithTolerations("pod", nil)
+			if got := isSchedulableAfterNodeChange(pod, test.oldObj, test.newObj); got != test.want {
+				t.Errorf("got queueing hint %v, want %v", got, test.want)
+			}
+		})
+	}
+}
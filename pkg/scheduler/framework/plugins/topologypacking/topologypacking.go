@@ -0,0 +1,357 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topologypacking
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config/validation"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/helper"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/names"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/podgroup"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// Name is the name of the plugin used in the plugin registry and configurations.
+	Name = names.TopologyPacking
+
+	// preFilterStateKey is the key in CycleState to TopologyPacking pre-computed data.
+	preFilterStateKey = "PreFilter" + Name
+	// preScoreStateKey is the key in CycleState to TopologyPacking pre-computed data for Scoring.
+	preScoreStateKey = "PreScore" + Name
+
+	// ErrReasonMissingLabel is used for the Filter error of nodes without
+	// the required topology label.
+	ErrReasonMissingLabel = "node(s) didn't have the required topology label"
+	// ErrReasonOutsideDomain is used for the Filter error of nodes outside
+	// the required topology domain of the pod group.
+	ErrReasonOutsideDomain = "node(s) didn't match the topology domain of the pod group"
+)
+
+// TopologyPacking is a plugin that packs the members of a pod group into as
+// few network domains as possible. The network is described by a hierarchy
+// of node labels, from the broadest domain, such as a spine block, to the
+// narrowest, such as a host. Nodes get a higher score the lower the common
+// ancestor of the node and of the members already placed is. The first
+// member goes to the domain just above hosts that has the most feasible
+// nodes, for the rest of the group to follow.
+//
+// The placement of the members is taken from the scheduler cache, which
+// accounts for assumed pods, and kept in the CycleState.
+type TopologyPacking struct {
+	fh           framework.Handle
+	topologyKeys []string
+	// requiredLevel is the index in topologyKeys of the domain all members
+	// must share, or -1.
+	requiredLevel int
+}
+
+var _ framework.PreFilterPlugin = &TopologyPacking{}
+var _ framework.FilterPlugin = &TopologyPacking{}
+var _ framework.PreScorePlugin = &TopologyPacking{}
+var _ framework.ScorePlugin = &TopologyPacking{}
+var _ framework.EnqueueExtensions = &TopologyPacking{}
+
+// New initializes a new plugin and returns it.
+func New(obj runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+	args, ok := obj.(*config.TopologyPackingArgs)
+	if !ok {
+		return nil, fmt.Errorf("got args of type %T, want *TopologyPackingArgs", obj)
+	}
+	if err := validation.ValidateTopologyPackingArgs(nil, args); err != nil {
+		return nil, err
+	}
+	pl := &TopologyPacking{
+		fh:            fh,
+		topologyKeys:  args.TopologyKeys,
+		requiredLevel: -1,
+	}
+	for i, key := range args.TopologyKeys {
+		if key == args.RequiredTopologyKey {
+			pl.requiredLevel = i
+		}
+	}
+	return pl, nil
+}
+
+// Name returns name of the plugin. It is used in logs, etc.
+func (pl *TopologyPacking) Name() string {
+	return Name
+}
+
+// EventsToRegister returns the possible events that may make a Pod
+// failed by this plugin schedulable.
+func (pl *TopologyPacking) EventsToRegister() []framework.ClusterEvent {
+	return []framework.ClusterEvent{
+		// Deleting the members placed so far frees the group from their domain.
+		{Resource: framework.Pod, ActionType: framework.Delete},
+		// The topology of a node is published in its labels.
+		{Resource: framework.Node, ActionType: framework.Add | framework.UpdateNodeLabel},
+	}
+}
+
+// preFilterState computed at PreFilter and used at Filter and PreScore.
+type preFilterState struct {
+	// namespace and group identify the pod group of the pod. group is empty
+	// if the pod doesn't belong to any.
+	namespace string
+	group     string
+	// members is the number of members of the group placed on nodes.
+	members int
+	// domains holds, for each level of the hierarchy, the number of members
+	// placed in each domain.
+	domains []map[string]int
+}
+
+// Clone the prefilter state.
+func (s *preFilterState) Clone() framework.StateData {
+	if s == nil {
+		return nil
+	}
+	out := &preFilterState{
+		namespace: s.namespace,
+		group:     s.group,
+		members:   s.members,
+		domains:   make([]map[string]int, len(s.domains)),
+	}
+	for i, counts := range s.domains {
+		out.domains[i] = make(map[string]int, len(counts))
+		for value, count := range counts {
+			out.domains[i][value] = count
+		}
+	}
+	return out
+}
+
+// update adds or removes members placed on the node with the given labels.
+func (s *preFilterState) update(keys []string, labels map[string]string, delta int) {
+	s.members += delta
+	for i, key := range keys {
+		if value, ok := labels[key]; ok {
+			s.domains[i][value] += delta
+			if s.domains[i][value] <= 0 {
+				delete(s.domains[i], value)
+			}
+		}
+	}
+}
+
+// isMember returns true if the pod belongs to the pod group of the state.
+func (s *preFilterState) isMember(pod *v1.Pod) bool {
+	return s.group != "" && pod.Namespace == s.namespace && podgroup.Name(pod) == s.group
+}
+
+// PreFilter computes the placement of the members of the pod's group.
+func (pl *TopologyPacking) PreFilter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod) *framework.Status {
+	s := &preFilterState{
+		namespace: pod.Namespace,
+		group:     podgroup.Name(pod),
+		domains:   make([]map[string]int, len(pl.topologyKeys)),
+	}
+	for i := range s.domains {
+		s.domains[i] = make(map[string]int)
+	}
+	if s.group != "" {
+		lister := pl.fh.SnapshotSharedLister()
+		for nodeName, count := range lister.PodGroupPlacements().Get(pod.Namespace, s.group) {
+			nodeInfo, err := lister.NodeInfos().Get(nodeName)
+			if err != nil || nodeInfo.Node() == nil {
+				// The node was removed, and its pods will be too.
+				continue
+			}
+			s.update(pl.topologyKeys, nodeInfo.Node().Labels, count)
+		}
+	}
+	cycleState.Write(preFilterStateKey, s)
+	return nil
+}
+
+// PreFilterExtensions returns prefilter extensions, pod add and remove.
+func (pl *TopologyPacking) PreFilterExtensions() framework.PreFilterExtensions {
+	return pl
+}
+
+// AddPod from pre-computed data in cycleState.
+func (pl *TopologyPacking) AddPod(ctx context.Context, cycleState *framework.CycleState, podToSchedule *v1.Pod, podInfoToAdd *framework.PodInfo, nodeInfo *framework.NodeInfo) *framework.Status {
+	return pl.updateWithPod(cycleState, podToSchedule, podInfoToAdd.Pod, nodeInfo, 1)
+}
+
+// RemovePod from pre-computed data in cycleState.
+func (pl *TopologyPacking) RemovePod(ctx context.Context, cycleState *framework.CycleState, podToSchedule *v1.Pod, podInfoToRemove *framework.PodInfo, nodeInfo *framework.NodeInfo) *framework.Status {
+	return pl.updateWithPod(cycleState, podToSchedule, podInfoToRemove.Pod, nodeInfo, -1)
+}
+
+func (pl *TopologyPacking) updateWithPod(cycleState *framework.CycleState, podToSchedule, pod *v1.Pod, nodeInfo *framework.NodeInfo, delta int) *framework.Status {
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if pod.UID == podToSchedule.UID || !s.isMember(pod) || nodeInfo.Node() == nil {
+		return nil
+	}
+	s.update(pl.topologyKeys, nodeInfo.Node().Labels, delta)
+	return nil
+}
+
+func getPreFilterState(cycleState *framework.CycleState) (*preFilterState, error) {
+	c, err := cycleState.Read(preFilterStateKey)
+	if err != nil {
+		// preFilterState doesn't exist, likely PreFilter wasn't invoked.
+		return nil, fmt.Errorf("error reading %q from cycleState: %w", preFilterStateKey, err)
+	}
+
+	s, ok := c.(*preFilterState)
+	if !ok {
+		return nil, fmt.Errorf("%+v  convert to TopologyPacking.preFilterState error", c)
+	}
+	return s, nil
+}
+
+// Filter invoked at the filter extension point. If a required topology key
+// is configured, it checks that the node is in the same domain as the
+// members of the pod's group placed so far.
+func (pl *TopologyPacking) Filter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	if pl.requiredLevel < 0 {
+		return nil
+	}
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	if s.group == "" {
+		return nil
+	}
+	node := nodeInfo.Node()
+	if node == nil {
+		return framework.NewStatus(framework.Error, "node not found")
+	}
+	value, ok := node.Labels[pl.topologyKeys[pl.requiredLevel]]
+	if !ok {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrReasonMissingLabel)
+	}
+	if s.members > 0 && s.domains[pl.requiredLevel][value] == 0 {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, ErrReasonOutsideDomain)
+	}
+	return nil
+}
+
+// preScoreState computed at PreScore and used at Score.
+type preScoreState struct {
+	*preFilterState
+	// feasible is the number of feasible nodes in each domain of the level
+	// just above hosts, used to place the first member of a group.
+	feasible map[string]int
+}
+
+// Clone implements the mandatory Clone interface. We don't really copy the data since
+// there is no need for that.
+func (s *preScoreState) Clone() framework.StateData {
+	return s
+}
+
+// PreScore counts the feasible nodes in each domain if no member of the
+// pod's group is placed yet.
+func (pl *TopologyPacking) PreScore(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodes []*v1.Node) *framework.Status {
+	s, err := getPreFilterState(cycleState)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	state := &preScoreState{preFilterState: s}
+	if s.group != "" && s.members == 0 {
+		key := pl.topologyKeys[pl.packingLevel()]
+		state.feasible = make(map[string]int)
+		for _, node := range nodes {
+			if value, ok := node.Labels[key]; ok {
+				state.feasible[value]++
+			}
+		}
+	}
+	cycleState.Write(preScoreStateKey, state)
+	return nil
+}
+
+// packingLevel returns the level of the hierarchy the first member of a
+// group picks its domain at: the one just above hosts.
+func (pl *TopologyPacking) packingLevel() int {
+	if len(pl.topologyKeys) < 2 {
+		return 0
+	}
+	return len(pl.topologyKeys) - 2
+}
+
+func getPreScoreState(cycleState *framework.CycleState) (*preScoreState, error) {
+	c, err := cycleState.Read(preScoreStateKey)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %q from cycleState: %w", preScoreStateKey, err)
+	}
+
+	s, ok := c.(*preScoreState)
+	if !ok {
+		return nil, fmt.Errorf("%+v  convert to TopologyPacking.preScoreState error", c)
+	}
+	return s, nil
+}
+
+// Score invoked at the score extension point. The raw score of a node is the
+// depth of the lowest common ancestor of the node and of the members of the
+// pod's group placed so far, or the number of feasible nodes in its domain
+// for the first member.
+func (pl *TopologyPacking) Score(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+	s, err := getPreScoreState(cycleState)
+	if err != nil {
+		return 0, framework.AsStatus(err)
+	}
+	if s.group == "" {
+		return 0, nil
+	}
+	nodeInfo, err := pl.fh.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return 0, framework.AsStatus(fmt.Errorf("getting node %q from Snapshot: %w", nodeName, err))
+	}
+	labels := nodeInfo.Node().Labels
+	if s.members == 0 {
+		value, ok := labels[pl.topologyKeys[pl.packingLevel()]]
+		if !ok {
+			return 0, nil
+		}
+		return int64(s.feasible[value]), nil
+	}
+	var depth int64
+	for i, key := range pl.topologyKeys {
+		value, ok := labels[key]
+		if !ok || s.domains[i][value] != s.members {
+			break
+		}
+		depth++
+	}
+	return depth, nil
+}
+
+// ScoreExtensions of the Score plugin.
+func (pl *TopologyPacking) ScoreExtensions() framework.ScoreExtensions {
+	return pl
+}
+
+// NormalizeScore invoked after scoring all nodes.
+func (pl *TopologyPacking) NormalizeScore(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, scores framework.NodeScoreList) *framework.Status {
+	return helper.DefaultNormalizeScore(framework.MaxNodeScore, false, scores)
+}
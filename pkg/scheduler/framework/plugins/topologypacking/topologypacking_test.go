@@ -0,0 +1,219 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topologypacking
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/scheduling/v1alpha1"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	frameworkruntime "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/runtime"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/cache"
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	blockKey = "topology.sched.dev/block"
+	rackKey  = "topology.sched.dev/rack"
+)
+
+// Nodes h1 and h2 are in rack r1 and h3 in rack r2, both in block b1. Nodes
+// h4 and h5 are in rack r3, in block b2.
+var nodes = []*v1.Node{
+	makeNode("h1", "b1", "r1"),
+	makeNode("h2", "b1", "r1"),
+	makeNode("h3", "b1", "r2"),
+	makeNode("h4", "b2", "r3"),
+	makeNode("h5", "b2", "r3"),
+}
+
+func makeNode(name, block, rack string) *v1.Node {
+	return st.MakeNode().Name(name).Label(v1.LabelHostname, name).Label(blockKey, block).Label(rackKey, rack).Obj()
+}
+
+func member(name string) *st.PodWrapper {
+	return st.MakePod().Namespace("default").Name(name).UID(name).Label(v1alpha1.PodGroupLabel, "job")
+}
+
+func newPlugin(t *testing.T, requiredKey string, pods []*v1.Pod, nodes []*v1.Node) *TopologyPacking {
+	fh, err := frameworkruntime.NewFramework(nil, nil,
+		frameworkruntime.WithSnapshotSharedLister(cache.NewSnapshot(pods, nodes)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := New(&config.TopologyPackingArgs{
+		TopologyKeys:        []string{blockKey, rackKey, v1.LabelHostname},
+		RequiredTopologyKey: requiredKey,
+	}, fh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.(*TopologyPacking)
+}
+
+func TestFilter(t *testing.T) {
+	unlabeled := st.MakeNode().Name("unlabeled").Obj()
+	tests := []struct {
+		name     string
+		pod      *v1.Pod
+		existing []*v1.Pod
+		node     *v1.Node
+		wantCode framework.Code
+	}{
+		{
+			name:     "first member can go to any labeled node",
+			pod:      member("p").Obj(),
+			node:     nodes[3],
+			wantCode: framework.Success,
+		},
+		{
+			name:     "node in the domain of the placed members",
+			pod:      member("p").Obj(),
+			existing: []*v1.Pod{member("m1").Node("h1").Obj()},
+			node:     nodes[2],
+			wantCode: framework.Success,
+		},
+		{
+			name:     "node outside the domain of the placed members",
+			pod:      member("p").Obj(),
+			existing: []*v1.Pod{member("m1").Node("h1").Obj()},
+			node:     nodes[3],
+			wantCode: framework.UnschedulableAndUnresolvable,
+		},
+		{
+			name:     "node without the required label",
+			pod:      member("p").Obj(),
+			node:     unlabeled,
+			wantCode: framework.UnschedulableAndUnresolvable,
+		},
+		{
+			name:     "pod without a group",
+			pod:      st.MakePod().Name("p").Obj(),
+			existing: []*v1.Pod{member("m1").Node("h1").Obj()},
+			node:     unlabeled,
+			wantCode: framework.Success,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			pl := newPlugin(t, blockKey, tt.existing, append(nodes, unlabeled))
+			state := framework.NewCycleState()
+			if s := pl.PreFilter(ctx, state, tt.pod); !s.IsSuccess() {
+				t.Fatalf("PreFilter failed: %v", s)
+			}
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(tt.node)
+			if s := pl.Filter(ctx, state, tt.pod, nodeInfo); s.Code() != tt.wantCode {
+				t.Errorf("unexpected status code: want %v, got %v (%v)", tt.wantCode, s.Code(), s.Message())
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name       string
+		pod        *v1.Pod
+		existing   []*v1.Pod
+		wantScores []int64
+	}{
+		{
+			name:       "first member goes to the racks with the most nodes",
+			pod:        member("p").Obj(),
+			wantScores: []int64{100, 100, 50, 100, 100},
+		},
+		{
+			name:       "lower common ancestors score higher",
+			pod:        member("p").Obj(),
+			existing:   []*v1.Pod{member("m1").Node("h1").Obj()},
+			wantScores: []int64{100, 66, 33, 0, 0},
+		},
+		{
+			name:       "members spread across racks pack into their block",
+			pod:        member("p").Obj(),
+			existing:   []*v1.Pod{member("m1").Node("h1").Obj(), member("m2").Node("h3").Obj()},
+			wantScores: []int64{100, 100, 100, 0, 0},
+		},
+		{
+			name:       "pod without a group",
+			pod:        st.MakePod().Name("p").Obj(),
+			existing:   []*v1.Pod{member("m1").Node("h1").Obj()},
+			wantScores: []int64{0, 0, 0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			pl := newPlugin(t, "", tt.existing, nodes)
+			state := framework.NewCycleState()
+			if s := pl.PreFilter(ctx, state, tt.pod); !s.IsSuccess() {
+				t.Fatalf("PreFilter failed: %v", s)
+			}
+			if s := pl.PreScore(ctx, state, tt.pod, nodes); !s.IsSuccess() {
+				t.Fatalf("PreScore failed: %v", s)
+			}
+			var scores framework.NodeScoreList
+			for _, node := range nodes {
+				score, s := pl.Score(ctx, state, tt.pod, node.Name)
+				if !s.IsSuccess() {
+					t.Fatalf("Score failed: %v", s)
+				}
+				scores = append(scores, framework.NodeScore{Name: node.Name, Score: score})
+			}
+			if s := pl.NormalizeScore(ctx, state, tt.pod, scores); !s.IsSuccess() {
+				t.Fatalf("NormalizeScore failed: %v", s)
+			}
+			var got []int64
+			for _, score := range scores {
+				got = append(got, score.Score)
+			}
+			if !reflect.DeepEqual(got, tt.wantScores) {
+				t.Errorf("unexpected scores: want %v, got %v", tt.wantScores, got)
+			}
+		})
+	}
+}
+
+func TestAddPod(t *testing.T) {
+	ctx := context.Background()
+	pl := newPlugin(t, blockKey, nil, nodes)
+	pod := member("p").Obj()
+	state := framework.NewCycleState()
+	if s := pl.PreFilter(ctx, state, pod); !s.IsSuccess() {
+		t.Fatalf("PreFilter failed: %v", s)
+	}
+	// A member nominated to h4 confines the group to block b2.
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(nodes[3])
+	stateCopy := state.Clone()
+	if s := pl.AddPod(ctx, stateCopy, pod, framework.NewPodInfo(member("m1").Obj()), nodeInfo); !s.IsSuccess() {
+		t.Fatalf("AddPod failed: %v", s)
+	}
+	h1 := framework.NewNodeInfo()
+	h1.SetNode(nodes[0])
+	if s := pl.Filter(ctx, stateCopy, pod, h1); s.Code() != framework.UnschedulableAndUnresolvable {
+		t.Errorf("expected h1 to be filtered out after AddPod, got %v", s)
+	}
+	if s := pl.Filter(ctx, state, pod, h1); !s.IsSuccess() {
+		t.Errorf("expected AddPod to leave the original state unchanged, got %v", s)
+	}
+}
@@ -77,6 +77,7 @@ type VolumeBinding struct {
 
 var _ framework.PreFilterPlugin = &VolumeBinding{}
 var _ framework.FilterPlugin = &VolumeBinding{}
+var _ framework.CacheableFilterPlugin = &VolumeBinding{}
 var _ framework.ReservePlugin = &VolumeBinding{}
 var _ framework.PreBindPlugin = &VolumeBinding{}
 var _ framework.ScorePlugin = &VolumeBinding{}
@@ -261,6 +262,16 @@ func (pl *VolumeBinding) Filter(ctx context.Context, cs *framework.CycleState, p
 	return nil
 }
 
+// FilterCacheable returns true if the pod has no PVCs to bind, as Filter
+// always passes then. Otherwise the verdict depends on the PVs and PVCs.
+func (pl *VolumeBinding) FilterCacheable(cs *framework.CycleState, _ *v1.Pod) bool {
+	state, err := getStateData(cs)
+	if err != nil {
+		return false
+	}
+	return state.skip
+}
+
 // Score invoked at the score extension point.
 func (pl *VolumeBinding) Score(ctx context.Context, cs *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
 	if pl.scorer == nil {
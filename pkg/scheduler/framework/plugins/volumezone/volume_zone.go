@@ -42,6 +42,7 @@ type VolumeZone struct {
 }
 
 var _ framework.FilterPlugin = &VolumeZone{}
+var _ framework.CacheableFilterPlugin = &VolumeZone{}
 var _ framework.EnqueueExtensions = &VolumeZone{}
 
 const (
@@ -165,6 +166,17 @@ func (pl *VolumeZone) Filter(ctx context.Context, _ *framework.CycleState, pod *
 	return nil
 }
 
+// FilterCacheable returns true if the pod has no PVCs, as Filter always passes
+// then. Otherwise the verdict depends on the zones of the bound PVs.
+func (pl *VolumeZone) FilterCacheable(_ *framework.CycleState, pod *v1.Pod) bool {
+	for i := range pod.Spec.Volumes {
+		if pod.Spec.Volumes[i].PersistentVolumeClaim != nil {
+			return false
+		}
+	}
+	return true
+}
+
 func getErrorAsStatus(err error) *framework.Status {
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preemption
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// EvictionBudget limits how many pods of each namespace are preempted within
+// a sliding time window. A nil budget doesn't limit anything.
+//
+// The evictions are only recorded in memory, by the evaluators sharing the
+// budget: it starts over on restart, and doesn't know about the pods
+// preempted by other scheduler replicas or by plugins holding another budget,
+// such as ElasticQuota.
+type EvictionBudget struct {
+	max    int
+	window time.Duration
+
+	mu sync.Mutex
+	// evictions are the times pods of each namespace were preempted at,
+	// oldest first.
+	evictions map[string][]time.Time
+}
+
+// NewEvictionBudget returns a budget of max evictions per namespace within
+// the given window.
+func NewEvictionBudget(max int, window time.Duration) *EvictionBudget {
+	return &EvictionBudget{
+		max:       max,
+		window:    window,
+		evictions: make(map[string][]time.Time),
+	}
+}
+
+// Remaining returns how many more pods of the namespace may be preempted at
+// the given time.
+func (b *EvictionBudget) Remaining(namespace string, now time.Time) int {
+	if b == nil {
+		return math.MaxInt32
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.max - len(b.prune(namespace, now))
+}
+
+// Allows returns true if all the victims may be preempted at the given time
+// without going over the budget of their namespaces.
+func (b *EvictionBudget) Allows(victims []*v1.Pod, now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	perNamespace := make(map[string]int)
+	for _, victim := range victims {
+		perNamespace[victim.Namespace]++
+	}
+	for namespace, n := range perNamespace {
+		if n > b.Remaining(namespace, now) {
+			return false
+		}
+	}
+	return true
+}
+
+// Record takes the preemption of the victim at the given time out of the
+// budget of its namespace.
+func (b *EvictionBudget) Record(victim *v1.Pod, now time.Time) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.evictions[victim.Namespace] = append(b.prune(victim.Namespace, now), now)
+}
+
+// prune forgets the evictions of the namespace that are out of the window at
+// the given time and returns the others. It must be called with the lock
+// held.
+func (b *EvictionBudget) prune(namespace string, now time.Time) []time.Time {
+	times := b.evictions[namespace]
+	i := 0
+	for i < len(times) && !times[i].After(now.Add(-b.window)) {
+		i++
+	}
+	if i == len(times) {
+		delete(b.evictions, namespace)
+		return nil
+	}
+	times = times[i:]
+	b.evictions[namespace] = times
+	return times
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preemption
+
+import (
+	"testing"
+	"time"
+
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestEvictionBudget(t *testing.T) {
+	now := time.Now()
+	pod := func(namespace, name string) *v1.Pod {
+		return st.MakePod().Namespace(namespace).Name(name).UID(name).Obj()
+	}
+	b := NewEvictionBudget(2, time.Hour)
+	b.Record(pod("ns1", "p1"), now.Add(-2*time.Hour))
+	b.Record(pod("ns1", "p2"), now.Add(-30*time.Minute))
+
+	if got := b.Remaining("ns1", now); got != 1 {
+		t.Errorf("Remaining(ns1) = %v, want 1", got)
+	}
+	if got := b.Remaining("ns2", now); got != 2 {
+		t.Errorf("Remaining(ns2) = %v, want 2", got)
+	}
+	if b.Allows([]*v1.Pod{pod("ns1", "p3"), pod("ns1", "p4")}, now) {
+		t.Error("Allows two more pods of ns1, want only one")
+	}
+	if !b.Allows([]*v1.Pod{pod("ns1", "p3"), pod("ns2", "p4"), pod("ns2", "p5")}, now) {
+		t.Error("Doesn't allow one pod of ns1 and two of ns2")
+	}
+	if got := b.Remaining("ns1", now.Add(31*time.Minute)); got != 2 {
+		t.Errorf("Remaining(ns1) once out of the window = %v, want 2", got)
+	}
+
+	var unlimited *EvictionBudget
+	unlimited.Record(pod("ns1", "p1"), now)
+	if !unlimited.Allows([]*v1.Pod{pod("ns1", "p1"), pod("ns1", "p2")}, now) {
+		t.Error("A nil budget doesn't allow the pods")
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preemption
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/metrics"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/util"
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+// errEvictionRefused is returned when the apiserver keeps refusing to evict a
+// victim, typically because it would violate a PodDisruptionBudget.
+var errEvictionRefused = errors.New("eviction refused")
+
+// evictionBackoff is how the eviction of a victim is retried while the
+// apiserver answers 429 Too Many Requests. The retries hold up the scheduling
+// cycle of the preemptor, so they are kept short.
+var evictionBackoff = wait.Backoff{
+	Duration: 200 * time.Millisecond,
+	Factor:   2,
+	Steps:    3,
+}
+
+// removeVictim evicts the victim through the Eviction API if evict is true,
+// or deletes it otherwise. A victim that is already gone, e.g. as a member of
+// a pod group preempted for another node, is not an error.
+func removeVictim(cs kubernetes.Interface, victim *v1.Pod, evict bool) error {
+	if !evict {
+		if err := util.DeletePod(cs, victim); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	return evictVictim(cs, victim, false)
+}
+
+// checkEvictions dry-runs the eviction of the victims, so that a candidate
+// whose victims can't all be evicted is given up before any of them is gone.
+func checkEvictions(cs kubernetes.Interface, victims []*v1.Pod) error {
+	for _, victim := range victims {
+		if err := evictVictim(cs, victim, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// evictVictim evicts the victim through the Eviction API, retrying while the
+// apiserver answers 429 Too Many Requests.
+func evictVictim(cs kubernetes.Interface, victim *v1.Pod, dryRun bool) error {
+	var lastErr error
+	err := wait.ExponentialBackoff(evictionBackoff, func() (bool, error) {
+		lastErr = util.EvictPod(cs, victim, dryRun)
+		switch {
+		case lastErr == nil, apierrors.IsNotFound(lastErr):
+			return true, nil
+		case apierrors.IsTooManyRequests(lastErr):
+			return false, nil
+		}
+		return false, lastErr
+	})
+	if err == wait.ErrWaitTimeout {
+		metrics.PreemptionEvictionsRefused.Inc()
+		return fmt.Errorf("evicting pod %v: %w: %v", klog.KObj(victim), errEvictionRefused, lastErr)
+	}
+	return err
+}
+
+// pdbCoveredFirst orders the victims covered by a PodDisruptionBudget first,
+// keeping the order of the others. An eviction refused for one of them then
+// happens before the other victims of the candidate are gone.
+func pdbCoveredFirst(victims []*v1.Pod, pdbs []*policy.PodDisruptionBudget) []*v1.Pod {
+	if len(pdbs) == 0 {
+		return victims
+	}
+	covered := make(map[*v1.Pod]bool, len(victims))
+	for _, victim := range victims {
+		for _, pdb := range pdbs {
+			if pdb.Namespace != victim.Namespace {
+				continue
+			}
+			selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+			if err != nil {
+				continue
+			}
+			// A PDB with a nil or empty selector matches nothing.
+			if !selector.Empty() && selector.Matches(labels.Set(victim.Labels)) {
+				covered[victim] = true
+				break
+			}
+		}
+	}
+	ordered := make([]*v1.Pod, len(victims))
+	copy(ordered, victims)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return covered[ordered[i]] && !covered[ordered[j]]
+	})
+	return ordered
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preemption
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/scheduling/v1alpha1"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/podgroup"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/util"
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+)
+
+// GroupMembers returns the live members of the pod group of the pod that are
+// assigned to a node, the pod included if it is. It returns nil if the pod
+// doesn't belong to a group.
+func GroupMembers(podLister corelisters.PodLister, pod *v1.Pod) []*v1.Pod {
+	groupName := podgroup.Name(pod)
+	if len(groupName) == 0 {
+		return nil
+	}
+	pods, err := podLister.Pods(pod.Namespace).List(labels.SelectorFromSet(labels.Set{v1alpha1.PodGroupLabel: groupName}))
+	if err != nil {
+		klog.ErrorS(err, "Listing the members of a pod group", "podGroup", klog.KRef(pod.Namespace, groupName))
+		return nil
+	}
+	var members []*v1.Pod
+	for _, p := range pods {
+		if len(p.Spec.NodeName) == 0 || p.DeletionTimestamp != nil || p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed {
+			continue
+		}
+		members = append(members, p)
+	}
+	return members
+}
+
+// podGroups lists the members of the pod groups of victims, listing each
+// group once.
+type podGroups struct {
+	podLister corelisters.PodLister
+	members   map[types.NamespacedName][]*v1.Pod
+}
+
+func newPodGroups(podLister corelisters.PodLister) *podGroups {
+	return &podGroups{podLister: podLister, members: make(map[types.NamespacedName][]*v1.Pod)}
+}
+
+// siblings returns the members of the groups of the victims that are
+// assigned to a node and are not victims themselves.
+func (g *podGroups) siblings(victims []*v1.Pod) []*v1.Pod {
+	seen := make(map[types.UID]bool, len(victims))
+	for _, victim := range victims {
+		seen[victim.UID] = true
+	}
+	var siblings []*v1.Pod
+	for _, victim := range victims {
+		groupName := podgroup.Name(victim)
+		if len(groupName) == 0 {
+			continue
+		}
+		key := types.NamespacedName{Namespace: victim.Namespace, Name: groupName}
+		members, ok := g.members[key]
+		if !ok {
+			members = GroupMembers(g.podLister, victim)
+			g.members[key] = members
+		}
+		for _, m := range members {
+			if !seen[m.UID] {
+				seen[m.UID] = true
+				siblings = append(siblings, m)
+			}
+		}
+	}
+	return siblings
+}
+
+// withSiblings returns the victims of each node along with the siblings they
+// leave useless, ordered by decreasing importance, for the candidates to be
+// ranked by the whole cost of preempting them.
+func (g *podGroups) withSiblings(nodesToVictims map[string]*extenderv1.Victims) map[string]*extenderv1.Victims {
+	m := make(map[string]*extenderv1.Victims, len(nodesToVictims))
+	for node, victims := range nodesToVictims {
+		siblings := g.siblings(victims.Pods)
+		if len(siblings) == 0 {
+			m[node] = victims
+			continue
+		}
+		pods := append(append(make([]*v1.Pod, 0, len(victims.Pods)+len(siblings)), victims.Pods...), siblings...)
+		sort.SliceStable(pods, func(i, j int) bool { return util.MoreImportantPod(pods[i], pods[j]) })
+		m[node] = &extenderv1.Victims{Pods: pods, NumPDBViolations: victims.NumPDBViolations}
+	}
+	return m
+}
+
+// wholeGroups returns the victims followed by the siblings of their groups
+// that have a lower priority than the preemptor.
+func (g *podGroups) wholeGroups(victims []*v1.Pod, preemptor *v1.Pod) []*v1.Pod {
+	priority := corev1helpers.PodPriority(preemptor)
+	all := append(make([]*v1.Pod, 0, len(victims)), victims...)
+	for _, sibling := range g.siblings(victims) {
+		if corev1helpers.PodPriority(sibling) < priority {
+			all = append(all, sibling)
+		}
+	}
+	return all
+}
+
+// WholeGroups returns the victims followed by the siblings of their groups
+// that are preempted along with them when whole groups are preempted.
+func WholeGroups(podLister corelisters.PodLister, victims []*v1.Pod, preemptor *v1.Pod) []*v1.Pod {
+	return newPodGroups(podLister).wholeGroups(victims, preemptor)
+}
+
+// pendingReplicas returns the members of the pod group of the preemptor that
+// wait to be scheduled, have no nominated node and are replicas of the
+// preemptor: they have the same priority, requests and node constraints, so
+// that the candidates found for the preemptor fit them as well.
+func pendingReplicas(podLister corelisters.PodLister, preemptor *v1.Pod) []*v1.Pod {
+	groupName := podgroup.Name(preemptor)
+	if len(groupName) == 0 {
+		return nil
+	}
+	pods, err := podLister.Pods(preemptor.Namespace).List(labels.SelectorFromSet(labels.Set{v1alpha1.PodGroupLabel: groupName}))
+	if err != nil {
+		klog.ErrorS(err, "Listing the members of a pod group", "podGroup", klog.KRef(preemptor.Namespace, groupName))
+		return nil
+	}
+	requests := framework.PodRequests(preemptor)
+	var replicas []*v1.Pod
+	for _, p := range pods {
+		if p.UID == preemptor.UID || len(p.Spec.NodeName) != 0 || len(p.Status.NominatedNodeName) != 0 || p.DeletionTimestamp != nil {
+			continue
+		}
+		if corev1helpers.PodPriority(p) != corev1helpers.PodPriority(preemptor) ||
+			!reflect.DeepEqual(framework.PodRequests(p), requests) ||
+			!apiequality.Semantic.DeepEqual(p.Spec.NodeSelector, preemptor.Spec.NodeSelector) ||
+			!apiequality.Semantic.DeepEqual(p.Spec.Affinity, preemptor.Spec.Affinity) ||
+			!apiequality.Semantic.DeepEqual(p.Spec.Tolerations, preemptor.Spec.Tolerations) {
+			continue
+		}
+		replicas = append(replicas, p)
+	}
+	// Nominate the replicas in a stable order across cycles.
+	sort.Slice(replicas, func(i, j int) bool { return replicas[i].Name < replicas[j].Name })
+	return replicas
+}
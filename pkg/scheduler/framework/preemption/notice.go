@@ -0,0 +1,250 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preemption
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/metrics"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/util"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+const (
+	// CheckpointGracePeriodAnnotation opts a pod into graceful preemption, as
+	// a duration such as "10m". When the pod is selected as a victim, the
+	// scheduler sets its PreemptionNotice condition and waits up to this long
+	// for the pod to checkpoint before deleting it.
+	CheckpointGracePeriodAnnotation = "preemption.scheduling.sched.dev/checkpoint-grace-period"
+	// CheckpointReadyAnnotation is set to "true" by a victim under preemption
+	// notice once it has checkpointed, to be deleted without waiting for the
+	// end of its grace period.
+	CheckpointReadyAnnotation = "preemption.scheduling.sched.dev/checkpoint-ready"
+	// PreemptionNoticeCondition is the condition of the victims the scheduler
+	// waits for. Its last transition time is the start of the grace period.
+	PreemptionNoticeCondition v1.PodConditionType = "scheduling.sched.dev/PreemptionNotice"
+
+	// Results of a preemption notice.
+	noticeReady      = "ready"
+	noticeExpired    = "expired"
+	noticeTerminated = "terminated"
+	noticeCanceled   = "canceled"
+)
+
+// CheckpointGracePeriod returns the grace period the pod asked for before
+// being preempted, or 0 if it didn't.
+func CheckpointGracePeriod(pod *v1.Pod) time.Duration {
+	v, ok := pod.Annotations[CheckpointGracePeriodAnnotation]
+	if !ok {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		klog.V(4).InfoS("Ignoring invalid annotation", "pod", klog.KObj(pod), "annotation", CheckpointGracePeriodAnnotation, "value", v)
+		return 0
+	}
+	return d
+}
+
+// checkpointReady returns true if the pod signaled that it checkpointed.
+func checkpointReady(pod *v1.Pod) bool {
+	return pod.Annotations[CheckpointReadyAnnotation] == "true"
+}
+
+// noticeStart returns when the pod was notified of its preemption, or false
+// if it wasn't.
+func noticeStart(pod *v1.Pod) (time.Time, bool) {
+	_, cond := podutil.GetPodCondition(&pod.Status, PreemptionNoticeCondition)
+	if cond == nil || cond.Status != v1.ConditionTrue {
+		return time.Time{}, false
+	}
+	return cond.LastTransitionTime.Time, true
+}
+
+// UnderPreemptionNotice returns true if the pod was notified of its
+// preemption and the scheduler is still waiting for it to checkpoint. Such a
+// pod is about to be deleted, like a terminating pod.
+func UnderPreemptionNotice(pod *v1.Pod, now time.Time) bool {
+	start, ok := noticeStart(pod)
+	return ok && !checkpointReady(pod) && now.Before(start.Add(CheckpointGracePeriod(pod)))
+}
+
+// Notices holds the preemption victims that are given a grace period to
+// checkpoint, and deletes them once they are ready or their grace period is
+// over. A nil *Notices deletes all victims right away.
+type Notices struct {
+	cs          kubernetes.Interface
+	useEviction bool
+
+	mu      sync.Mutex
+	pending map[types.UID]*notice
+}
+
+type notice struct {
+	victim *v1.Pod
+	start  time.Time
+	timer  *time.Timer
+}
+
+// NewNotices returns Notices deleting victims with the given client, or
+// evicting them if evict is true. Victims signaling that they're ready are
+// observed through the pod informer.
+func NewNotices(cs kubernetes.Interface, podInformer cache.SharedIndexInformer, evict bool) *Notices {
+	n := &Notices{
+		cs:          cs,
+		useEviction: evict,
+		pending:     make(map[types.UID]*notice),
+	}
+	podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, newObj interface{}) {
+			if pod, ok := newObj.(*v1.Pod); ok && checkpointReady(pod) {
+				n.evict(pod.UID, noticeReady)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			switch t := obj.(type) {
+			case *v1.Pod:
+				n.evict(t.UID, noticeTerminated)
+			case cache.DeletedFinalStateUnknown:
+				if pod, ok := t.Obj.(*v1.Pod); ok {
+					n.evict(pod.UID, noticeTerminated)
+				}
+			}
+		},
+	})
+	return n
+}
+
+// Notify notifies the victim of its preemption by the preemptor if the
+// victim asked for a grace period. It returns true if the victim is given
+// time to checkpoint, in which case it's deleted later on, or false if the
+// caller should delete it now.
+func (n *Notices) Notify(victim, preemptor *v1.Pod, nodeName string) (bool, error) {
+	if n == nil {
+		return false, nil
+	}
+	grace := CheckpointGracePeriod(victim)
+	if grace == 0 || checkpointReady(victim) {
+		return false, nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if _, ok := n.pending[victim.UID]; ok {
+		return true, nil
+	}
+	now := time.Now()
+	start, notified := noticeStart(victim)
+	if !notified {
+		// The condition is kept on the victim so that the grace period
+		// survives restarts of the scheduler.
+		start = now
+		newStatus := victim.Status.DeepCopy()
+		podutil.UpdatePodCondition(newStatus, &v1.PodCondition{
+			Type:               PreemptionNoticeCondition,
+			Status:             v1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(now),
+			Reason:             "Preempted",
+			Message: fmt.Sprintf("Preempted by %v/%v on node %v, the pod is deleted in %v or once it is annotated with %v=true",
+				preemptor.Namespace, preemptor.Name, nodeName, grace, CheckpointReadyAnnotation),
+		})
+		if err := util.PatchPodStatus(n.cs, victim, newStatus); err != nil {
+			return false, err
+		}
+	}
+	remaining := start.Add(grace).Sub(now)
+	if remaining <= 0 {
+		metrics.PreemptionNoticeWaitDuration.WithLabelValues(noticeExpired).Observe(now.Sub(start).Seconds())
+		return false, nil
+	}
+	uid := victim.UID
+	n.pending[uid] = &notice{
+		victim: victim,
+		start:  start,
+		timer: time.AfterFunc(remaining, func() {
+			n.evict(uid, noticeExpired)
+		}),
+	}
+	metrics.PreemptionNoticesPending.Inc()
+	klog.V(3).InfoS("Waiting for preemption victim to checkpoint", "pod", klog.KObj(victim), "preemptor", klog.KObj(preemptor), "node", nodeName, "remaining", remaining)
+	return true, nil
+}
+
+// Cancel stops waiting for the victim with the given UID without deleting it,
+// e.g. when the preemptor falls back to another node, and clears the
+// PreemptionNotice condition of the victim.
+func (n *Notices) Cancel(uid types.UID) error {
+	if n == nil {
+		return nil
+	}
+	n.mu.Lock()
+	nt, ok := n.pending[uid]
+	if ok {
+		nt.timer.Stop()
+		delete(n.pending, uid)
+	}
+	n.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	metrics.PreemptionNoticesPending.Dec()
+	metrics.PreemptionNoticeWaitDuration.WithLabelValues(noticeCanceled).Observe(metrics.SinceInSeconds(nt.start))
+	klog.V(3).InfoS("Canceling preemption notice", "pod", klog.KObj(nt.victim))
+	newStatus := nt.victim.Status.DeepCopy()
+	podutil.UpdatePodCondition(newStatus, &v1.PodCondition{
+		Type:               PreemptionNoticeCondition,
+		Status:             v1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "PreemptionCanceled",
+	})
+	return util.PatchPodStatus(n.cs, nt.victim, newStatus)
+}
+
+// evict stops waiting for the victim with the given UID and deletes it,
+// unless it already terminated.
+func (n *Notices) evict(uid types.UID, result string) {
+	n.mu.Lock()
+	nt, ok := n.pending[uid]
+	if ok {
+		nt.timer.Stop()
+		delete(n.pending, uid)
+	}
+	n.mu.Unlock()
+	if !ok {
+		return
+	}
+	metrics.PreemptionNoticesPending.Dec()
+	metrics.PreemptionNoticeWaitDuration.WithLabelValues(result).Observe(metrics.SinceInSeconds(nt.start))
+	if result == noticeTerminated {
+		return
+	}
+	klog.V(3).InfoS("Deleting preemption victim", "pod", klog.KObj(nt.victim), "result", result)
+	if err := removeVictim(n.cs, nt.victim, n.useEviction); err != nil && !apierrors.IsNotFound(err) {
+		// The preemptor preempts the victim again in a later attempt as the
+		// grace period of the victim is over.
+		klog.ErrorS(err, "Deleting preemption victim", "pod", klog.KObj(nt.victim))
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preemption
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+func withNotice(pod *v1.Pod, start time.Time) *v1.Pod {
+	pod.Status.Conditions = append(pod.Status.Conditions, v1.PodCondition{
+		Type:               PreemptionNoticeCondition,
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(start),
+	})
+	return pod
+}
+
+func TestNoticesNotify(t *testing.T) {
+	now := time.Now()
+	preemptor := st.MakePod().Namespace("ns").Name("preemptor").UID("preemptor").Obj()
+	tests := []struct {
+		name         string
+		victim       *v1.Pod
+		wantNotified bool
+		wantNotice   bool
+	}{
+		{
+			name:   "no grace period",
+			victim: st.MakePod().Namespace("ns").Name("victim").UID("victim").Node("node1").Obj(),
+		},
+		{
+			name:   "invalid grace period",
+			victim: st.MakePod().Namespace("ns").Name("victim").UID("victim").Node("node1").Annotation(CheckpointGracePeriodAnnotation, "soon").Obj(),
+		},
+		{
+			name:         "grace period",
+			victim:       st.MakePod().Namespace("ns").Name("victim").UID("victim").Node("node1").Annotation(CheckpointGracePeriodAnnotation, "1h").Obj(),
+			wantNotified: true,
+			wantNotice:   true,
+		},
+		{
+			name: "already checkpointed",
+			victim: st.MakePod().Namespace("ns").Name("victim").UID("victim").Node("node1").
+				Annotation(CheckpointGracePeriodAnnotation, "1h").Annotation(CheckpointReadyAnnotation, "true").Obj(),
+		},
+		{
+			name: "notified before",
+			victim: withNotice(st.MakePod().Namespace("ns").Name("victim").UID("victim").Node("node1").
+				Annotation(CheckpointGracePeriodAnnotation, "1h").Obj(), now.Add(-30*time.Minute)),
+			wantNotified: true,
+			wantNotice:   true,
+		},
+		{
+			name: "grace period over",
+			victim: withNotice(st.MakePod().Namespace("ns").Name("victim").UID("victim").Node("node1").
+				Annotation(CheckpointGracePeriodAnnotation, "1h").Obj(), now.Add(-2*time.Hour)),
+			wantNotice: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := clientsetfake.NewSimpleClientset(tt.victim)
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			n := NewNotices(cs, informerFactory.Core().V1().Pods().Informer(), false)
+
+			notified, err := n.Notify(tt.victim, preemptor, "node1")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if notified != tt.wantNotified {
+				t.Errorf("Notify() = %v, want %v", notified, tt.wantNotified)
+			}
+			if _, ok := n.pending[tt.victim.UID]; ok != tt.wantNotified {
+				t.Errorf("Victim pending = %v, want %v", ok, tt.wantNotified)
+			}
+			got, err := cs.CoreV1().Pods("ns").Get(context.Background(), "victim", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			_, cond := podutil.GetPodCondition(&got.Status, PreemptionNoticeCondition)
+			if (cond != nil) != tt.wantNotice {
+				t.Errorf("Victim has the %v condition: %v, want %v", PreemptionNoticeCondition, cond != nil, tt.wantNotice)
+			}
+		})
+	}
+}
+
+func TestNoticesEvict(t *testing.T) {
+	preemptor := st.MakePod().Namespace("ns").Name("preemptor").UID("preemptor").Obj()
+	tests := []struct {
+		name   string
+		grace  string
+		evict  string
+		wantOK bool
+	}{
+		{
+			name:  "checkpoint ready",
+			grace: "1h",
+			evict: noticeReady,
+		},
+		{
+			name:  "grace period over",
+			grace: "50ms",
+		},
+		{
+			name:   "victim terminated",
+			grace:  "1h",
+			evict:  noticeTerminated,
+			wantOK: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			victim := st.MakePod().Namespace("ns").Name("victim").UID("victim").Node("node1").Annotation(CheckpointGracePeriodAnnotation, tt.grace).Obj()
+			cs := clientsetfake.NewSimpleClientset(victim)
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			n := NewNotices(cs, informerFactory.Core().V1().Pods().Informer(), false)
+			if notified, err := n.Notify(victim, preemptor, "node1"); err != nil || !notified {
+				t.Fatalf("Notify() = %v, %v, want true", notified, err)
+			}
+			if tt.evict != "" {
+				n.evict(victim.UID, tt.evict)
+			}
+
+			// The victim is deleted unless it terminated on its own.
+			if err := wait.Poll(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+				n.mu.Lock()
+				defer n.mu.Unlock()
+				return len(n.pending) == 0, nil
+			}); err != nil {
+				t.Fatalf("Victim still pending: %v", err)
+			}
+			_, err := cs.CoreV1().Pods("ns").Get(context.Background(), "victim", metav1.GetOptions{})
+			if gotOK := err == nil; gotOK != tt.wantOK {
+				t.Errorf("Victim exists: %v, want %v", gotOK, tt.wantOK)
+			}
+			if err != nil && !apierrors.IsNotFound(err) {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNoticesCancel(t *testing.T) {
+	preemptor := st.MakePod().Namespace("ns").Name("preemptor").UID("preemptor").Obj()
+	victim := st.MakePod().Namespace("ns").Name("victim").UID("victim").Node("node1").Annotation(CheckpointGracePeriodAnnotation, "50ms").Obj()
+	cs := clientsetfake.NewSimpleClientset(victim)
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	n := NewNotices(cs, informerFactory.Core().V1().Pods().Informer(), false)
+	if notified, err := n.Notify(victim, preemptor, "node1"); err != nil || !notified {
+		t.Fatalf("Notify() = %v, %v, want true", notified, err)
+	}
+	if err := n.Cancel(victim.UID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(n.pending) != 0 {
+		t.Errorf("Victim still pending")
+	}
+
+	// The victim is kept after its grace period, and no longer under notice.
+	time.Sleep(100 * time.Millisecond)
+	got, err := cs.CoreV1().Pods("ns").Get(context.Background(), "victim", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	_, cond := podutil.GetPodCondition(&got.Status, PreemptionNoticeCondition)
+	if cond == nil || cond.Status != v1.ConditionFalse {
+		t.Errorf("Got %v condition %v, want it false", PreemptionNoticeCondition, cond)
+	}
+}
+
+func TestUnderPreemptionNotice(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+		want bool
+	}{
+		{
+			name: "not notified",
+			pod:  st.MakePod().Name("p").Annotation(CheckpointGracePeriodAnnotation, "1h").Obj(),
+		},
+		{
+			name: "within grace period",
+			pod:  withNotice(st.MakePod().Name("p").Annotation(CheckpointGracePeriodAnnotation, "1h").Obj(), now.Add(-time.Minute)),
+			want: true,
+		},
+		{
+			name: "grace period over",
+			pod:  withNotice(st.MakePod().Name("p").Annotation(CheckpointGracePeriodAnnotation, "1h").Obj(), now.Add(-time.Hour)),
+		},
+		{
+			name: "checkpoint ready",
+			pod: withNotice(st.MakePod().Name("p").Annotation(CheckpointGracePeriodAnnotation, "1h").
+				Annotation(CheckpointReadyAnnotation, "true").Obj(), now.Add(-time.Minute)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UnderPreemptionNotice(tt.pod, now); got != tt.want {
+				t.Errorf("UnderPreemptionNotice() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -20,9 +20,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/metrics"
@@ -105,8 +105,8 @@ type Interface interface {
 	// CandidatesToVictimsMap builds a map from the target node to a list of to-be-preempted Pods and the number of PDB violation.
 	CandidatesToVictimsMap(candidates []Candidate) map[string]*extenderv1.Victims
 	// PodEligibleToPreemptOthers determines whether this pod should be considered
-	// for preempting other pods or not.
-	PodEligibleToPreemptOthers(pod *v1.Pod, nominatedNodeStatus *framework.Status) bool
+	// for preempting other pods or not. If not, it also returns why.
+	PodEligibleToPreemptOthers(pod *v1.Pod, nominatedNodeStatus *framework.Status) (bool, string)
 	// SelectVictimsOnNode finds minimum set of pods on the given node that should be preempted in order to make enough room
 	// for "pod" to be scheduled.
 	// Note that both `state` and `nodeInfo` are deep copied.
@@ -120,6 +120,32 @@ type Evaluator struct {
 	PodLister  corelisters.PodLister
 	PdbLister  policylisters.PodDisruptionBudgetLister
 	State      *framework.CycleState
+	// Notices, if set, gives the victims that ask for it a grace period to
+	// checkpoint before they are deleted.
+	Notices *Notices
+	// EvictVictims evicts the victims through the Eviction API instead of
+	// deleting them. A candidate whose victims can't be evicted is given up for
+	// the next best one. The evictions are dry-run before any victim is
+	// evicted, but one can still be refused once others went through, e.g. if
+	// a PodDisruptionBudget changed meanwhile. Those victims stay evicted and
+	// count against the Budget, while the notices given to the other victims
+	// of the candidate are canceled.
+	EvictVictims bool
+	// CandidateCriteria rank the candidates to pick the one to preempt on.
+	// Defaults to DefaultCandidateCriteria.
+	CandidateCriteria []CandidateCriterion
+	// PodGroupAware ranks the candidates by the cost of the victims along with
+	// the members of their pod groups they leave useless, and makes a member
+	// of a pod group also preempt for its pending replicas.
+	PodGroupAware bool
+	// PreemptWholeGroups also preempts the members of the pod groups of the
+	// victims that run on other nodes.
+	PreemptWholeGroups bool
+	// Budget, if set, records the preempted pods, and the candidates that
+	// would go over the budget of a namespace are not used for the pending
+	// replicas of a pod group. The siblings preempted along with the victims
+	// of whole groups count against it.
+	Budget *EvictionBudget
 	Interface
 }
 
@@ -148,9 +174,9 @@ func (ev *Evaluator) Preempt(ctx context.Context, pod *v1.Pod, m framework.NodeT
 	}
 
 	// 1) Ensure the preemptor is eligible to preempt other pods.
-	if !ev.PodEligibleToPreemptOthers(pod, m[pod.Status.NominatedNodeName]) {
-		klog.V(5).InfoS("Pod is not eligible for more preemption", "pod", klog.KObj(pod))
-		return nil, framework.NewStatus(framework.Unschedulable)
+	if eligible, msg := ev.PodEligibleToPreemptOthers(pod, m[pod.Status.NominatedNodeName]); !eligible {
+		klog.V(5).InfoS("Pod is not eligible for more preemption", "pod", klog.KObj(pod), "reason", msg)
+		return nil, framework.NewStatus(framework.Unschedulable, msg)
 	}
 
 	// 2) Find all preemption candidates.
@@ -179,18 +205,92 @@ func (ev *Evaluator) Preempt(ctx context.Context, pod *v1.Pod, m framework.NodeT
 		return nil, status
 	}
 
-	// 4) Find the best candidate.
-	bestCandidate := ev.SelectCandidate(candidates)
-	if bestCandidate == nil || len(bestCandidate.Name()) == 0 {
-		return nil, framework.NewStatus(framework.Unschedulable)
+	for {
+		// 4) Find the best candidate.
+		bestCandidate := ev.SelectCandidate(candidates)
+		if bestCandidate == nil || len(bestCandidate.Name()) == 0 {
+			return nil, framework.NewStatus(framework.Unschedulable)
+		}
+
+		// 5) Perform preparation work before nominating the selected candidate.
+		status := ev.prepareCandidate(bestCandidate, pod, ev.PluginName)
+		if status.IsSuccess() {
+			if ev.PodGroupAware {
+				ev.preemptForReplicas(pod, removeCandidate(candidates, bestCandidate.Name()))
+			}
+			return framework.NewPostFilterResultWithNominatedNode(bestCandidate.Name()), framework.NewStatus(framework.Success)
+		}
+		if !errors.Is(status.AsError(), errEvictionRefused) {
+			return nil, status
+		}
+		// The eviction of a victim was refused, fall back to the next best candidate.
+		klog.V(3).InfoS("Falling back to the next preemption candidate", "pod", klog.KObj(pod), "node", bestCandidate.Name(), "err", status.AsError())
+		candidates = removeCandidate(candidates, bestCandidate.Name())
+		if len(candidates) == 0 {
+			return nil, framework.NewStatus(framework.Unschedulable, status.Message())
+		}
 	}
+}
 
-	// 5) Perform preparation work before nominating the selected candidate.
-	if status := ev.prepareCandidate(bestCandidate, pod, ev.PluginName); !status.IsSuccess() {
-		return nil, status
+// preemptForReplicas preempts on the given candidates for the pending
+// replicas of the preemptor in its pod group and nominates them, so that a
+// gang takes several nodes in one cycle rather than one node per member. The
+// candidates were found for the preemptor, which the replicas are identical
+// to, and each of them is used for one replica at most.
+func (ev *Evaluator) preemptForReplicas(pod *v1.Pod, candidates []Candidate) {
+	for _, replica := range pendingReplicas(ev.PodLister, pod) {
+		nominated := false
+		for !nominated && len(candidates) != 0 {
+			c := ev.SelectCandidate(candidates)
+			if c == nil || len(c.Name()) == 0 {
+				return
+			}
+			candidates = removeCandidate(candidates, c.Name())
+			// The budget was checked when the candidate was found, but the
+			// preemptions since may have used it up.
+			if !ev.Budget.Allows(ev.preempted(c.Victims().Pods, replica), time.Now()) {
+				klog.V(3).InfoS("Skipping preemption candidate over the eviction budget", "pod", klog.KObj(replica), "node", c.Name())
+				continue
+			}
+			status := ev.prepareCandidate(c, replica, ev.PluginName)
+			if errors.Is(status.AsError(), errEvictionRefused) {
+				klog.V(3).InfoS("Falling back to the next preemption candidate", "pod", klog.KObj(replica), "node", c.Name(), "err", status.AsError())
+				continue
+			}
+			if !status.IsSuccess() {
+				klog.ErrorS(status.AsError(), "Preempting for a pod group member", "pod", klog.KObj(replica), "preemptor", klog.KObj(pod))
+				return
+			}
+			if err := ev.nominate(replica, c.Name()); err != nil {
+				klog.ErrorS(err, "Nominating a pod group member", "pod", klog.KObj(replica), "node", c.Name())
+			}
+			nominated = true
+		}
+		if !nominated {
+			klog.V(3).InfoS("No preemption candidate left for pending pod group members", "preemptor", klog.KObj(pod), "pod", klog.KObj(replica))
+			return
+		}
 	}
+}
+
+// nominate nominates the pod to the node, as the scheduler does for the
+// preemptor.
+func (ev *Evaluator) nominate(pod *v1.Pod, nodeName string) error {
+	ev.Handler.AddNominatedPod(framework.NewPodInfo(pod), &framework.NominatingInfo{NominatingMode: framework.ModeOverride, NominatedNodeName: nodeName})
+	podStatusCopy := pod.Status.DeepCopy()
+	podStatusCopy.NominatedNodeName = nodeName
+	return util.PatchPodStatus(ev.Handler.ClientSet(), pod, podStatusCopy)
+}
 
-	return framework.NewPostFilterResultWithNominatedNode(bestCandidate.Name()), framework.NewStatus(framework.Success)
+// removeCandidate returns the candidates without the ones on the given node.
+func removeCandidate(candidates []Candidate, nodeName string) []Candidate {
+	var rest []Candidate
+	for _, c := range candidates {
+		if c.Name() != nodeName {
+			rest = append(rest, c)
+		}
+	}
+	return rest
 }
 
 // FindCandidates calculates a slice of preemption candidates.
@@ -307,7 +407,15 @@ func (ev *Evaluator) SelectCandidate(candidates []Candidate) Candidate {
 	}
 
 	victimsMap := ev.CandidatesToVictimsMap(candidates)
-	candidateNode := pickOneNodeForPreemption(victimsMap)
+	criteria := ev.CandidateCriteria
+	if len(criteria) == 0 {
+		criteria = DefaultCandidateCriteria
+	}
+	costs := victimsMap
+	if ev.PodGroupAware || ev.PreemptWholeGroups {
+		costs = newPodGroups(ev.PodLister).withSiblings(victimsMap)
+	}
+	candidateNode := pickOneNodeForPreemption(costs, criteria)
 
 	// Same as candidatesToVictimsMap, this logic is not applicable for out-of-tree
 	// preemption plugins that exercise different candidates on the same nominated node.
@@ -324,26 +432,76 @@ func (ev *Evaluator) SelectCandidate(candidates []Candidate) Candidate {
 	return candidates[0]
 }
 
+// preempted returns the pods preempted along with the victims, the victims
+// included.
+func (ev *Evaluator) preempted(victims []*v1.Pod, preemptor *v1.Pod) []*v1.Pod {
+	if !ev.PreemptWholeGroups {
+		return victims
+	}
+	return WholeGroups(ev.PodLister, victims, preemptor)
+}
+
 // prepareCandidate does some preparation work before nominating the selected candidate:
-// - Evict the victim pods
+// - Evict the victim pods, or notify those asking for a grace period to checkpoint first.
+//   Victims covered by a PodDisruptionBudget are evicted first when the Eviction API is used,
+//   and the evictions are dry-run before any victim is evicted.
+//   The members of the pod groups of the victims are evicted along with them if whole groups are preempted
 // - Reject the victim pods if they are in waitingPod map
 // - Clear the low-priority pods' nominatedNodeName status if needed
+// If preparing the candidate fails, the victims evicted so far stay evicted,
+// while the notices given to the others are canceled.
 func (ev *Evaluator) prepareCandidate(c Candidate, pod *v1.Pod, pluginName string) *framework.Status {
 	fh := ev.Handler
 	cs := ev.Handler.ClientSet()
-	for _, victim := range c.Victims().Pods {
+	victims := ev.preempted(c.Victims().Pods, pod)
+	if ev.EvictVictims {
+		pdbs, err := getPodDisruptionBudgets(ev.PdbLister)
+		if err != nil {
+			return framework.AsStatus(err)
+		}
+		victims = pdbCoveredFirst(victims, pdbs)
+		var toEvict []*v1.Pod
+		for _, victim := range victims {
+			if fh.GetWaitingPod(victim.UID) == nil {
+				toEvict = append(toEvict, victim)
+			}
+		}
+		if err := checkEvictions(cs, toEvict); err != nil {
+			return framework.AsStatus(err)
+		}
+	}
+	var noticed []*v1.Pod
+	for _, victim := range victims {
 		// If the victim is a WaitingPod, send a reject message to the PermitPlugin.
 		// Otherwise we should delete the victim.
+		// Victims asking for a grace period are deleted once they checkpointed,
+		// while the preemptor stays nominated to the node.
 		if waitingPod := fh.GetWaitingPod(victim.UID); waitingPod != nil {
 			waitingPod.Reject(pluginName, "preempted")
-		} else if err := util.DeletePod(cs, victim); err != nil {
+		} else if notified, err := ev.Notices.Notify(victim, pod, c.Name()); err != nil {
+			klog.ErrorS(err, "Notifying preemption victim", "pod", klog.KObj(victim), "preemptor", klog.KObj(pod))
+			ev.cancelNotices(noticed)
+			return framework.AsStatus(err)
+		} else if notified {
+			noticed = append(noticed, victim)
+			continue
+		} else if err := removeVictim(cs, victim, ev.EvictVictims); err != nil {
 			klog.ErrorS(err, "Preempting pod", "pod", klog.KObj(victim), "preemptor", klog.KObj(pod))
+			ev.cancelNotices(noticed)
 			return framework.AsStatus(err)
 		}
 		fh.EventRecorder().Eventf(victim, pod, v1.EventTypeNormal, "Preempted", "Preempting", "Preempted by %v/%v on node %v",
 			pod.Namespace, pod.Name, c.Name())
+		ev.Budget.Record(victim, time.Now())
+	}
+	// The victims under notice are only preempted once all the others are
+	// gone, as the notices are canceled otherwise.
+	for _, victim := range noticed {
+		fh.EventRecorder().Eventf(victim, pod, v1.EventTypeNormal, "PreemptionNotice", "Preempting", "Preempted by %v/%v on node %v, waiting up to %v for a checkpoint",
+			pod.Namespace, pod.Name, c.Name(), CheckpointGracePeriod(victim))
+		ev.Budget.Record(victim, time.Now())
 	}
-	metrics.PreemptionVictims.Observe(float64(len(c.Victims().Pods)))
+	metrics.PreemptionVictims.Observe(float64(len(victims)))
 
 	// Lower priority pods nominated to run on this node, may no longer fit on
 	// this node. So, we should remove their nomination. Removing their
@@ -358,6 +516,16 @@ func (ev *Evaluator) prepareCandidate(c Candidate, pod *v1.Pod, pluginName strin
 	return nil
 }
 
+// cancelNotices cancels the notices given to the victims of a candidate that
+// was given up.
+func (ev *Evaluator) cancelNotices(victims []*v1.Pod) {
+	for _, victim := range victims {
+		if err := ev.Notices.Cancel(victim.UID); err != nil {
+			klog.ErrorS(err, "Canceling preemption notice", "pod", klog.KObj(victim))
+		}
+	}
+}
+
 // nodesWherePreemptionMightHelp returns a list of nodes with failed predicates
 // that may be satisfied by removing pods from the node.
 func nodesWherePreemptionMightHelp(nodes []*framework.NodeInfo, m framework.NodeToStatusMap) ([]*framework.NodeInfo, framework.NodeToStatusMap) {
@@ -383,135 +551,47 @@ func getPodDisruptionBudgets(pdbLister policylisters.PodDisruptionBudgetLister)
 	return nil, nil
 }
 
-// pickOneNodeForPreemption chooses one node among the given nodes. It assumes
-// pods in each map entry are ordered by decreasing priority.
-// It picks a node based on the following criteria:
+// pickOneNodeForPreemption chooses one node among the given nodes. Each
+// criterion in turn keeps the nodes where preempting the victims costs the
+// least, until a single node is left. If there are still ties after the last
+// criterion, the first such node is picked (sort of randomly).
+// The default criteria pick a node based on the following:
 // 1. A node with minimum number of PDB violations.
 // 2. A node with minimum highest priority victim is picked.
 // 3. Ties are broken by sum of priorities of all victims.
 // 4. If there are still ties, node with the minimum number of victims is picked.
 // 5. If there are still ties, node with the latest start time of all highest priority victims is picked.
-// 6. If there are still ties, the first such node is picked (sort of randomly).
-// The 'minNodes1' and 'minNodes2' are being reused here to save the memory
-// allocation and garbage collection time.
-func pickOneNodeForPreemption(nodesToVictims map[string]*extenderv1.Victims) string {
+func pickOneNodeForPreemption(nodesToVictims map[string]*extenderv1.Victims, criteria []CandidateCriterion) string {
 	if len(nodesToVictims) == 0 {
 		return ""
 	}
-	minNumPDBViolatingPods := int64(math.MaxInt32)
-	var minNodes1 []string
-	lenNodes1 := 0
-	for node, victims := range nodesToVictims {
-		numPDBViolatingPods := victims.NumPDBViolations
-		if numPDBViolatingPods < minNumPDBViolatingPods {
-			minNumPDBViolatingPods = numPDBViolatingPods
-			minNodes1 = nil
-			lenNodes1 = 0
-		}
-		if numPDBViolatingPods == minNumPDBViolatingPods {
-			minNodes1 = append(minNodes1, node)
-			lenNodes1++
-		}
+	nodes := make([]string, 0, len(nodesToVictims))
+	for node := range nodesToVictims {
+		nodes = append(nodes, node)
 	}
-	if lenNodes1 == 1 {
-		return minNodes1[0]
-	}
-
-	// There are more than one node with minimum number PDB violating pods. Find
-	// the one with minimum highest priority victim.
-	minHighestPriority := int32(math.MaxInt32)
-	var minNodes2 = make([]string, lenNodes1)
-	lenNodes2 := 0
-	for i := 0; i < lenNodes1; i++ {
-		node := minNodes1[i]
-		victims := nodesToVictims[node]
-		// highestPodPriority is the highest priority among the victims on this node.
-		highestPodPriority := corev1helpers.PodPriority(victims.Pods[0])
-		if highestPodPriority < minHighestPriority {
-			minHighestPriority = highestPodPriority
-			lenNodes2 = 0
-		}
-		if highestPodPriority == minHighestPriority {
-			minNodes2[lenNodes2] = node
-			lenNodes2++
-		}
-	}
-	if lenNodes2 == 1 {
-		return minNodes2[0]
-	}
-
-	// There are a few nodes with minimum highest priority victim. Find the
-	// smallest sum of priorities.
-	minSumPriorities := int64(math.MaxInt64)
-	lenNodes1 = 0
-	for i := 0; i < lenNodes2; i++ {
-		var sumPriorities int64
-		node := minNodes2[i]
-		for _, pod := range nodesToVictims[node].Pods {
-			// We add MaxInt32+1 to all priorities to make all of them >= 0. This is
-			// needed so that a node with a few pods with negative priority is not
-			// picked over a node with a smaller number of pods with the same negative
-			// priority (and similar scenarios).
-			sumPriorities += int64(corev1helpers.PodPriority(pod)) + int64(math.MaxInt32+1)
-		}
-		if sumPriorities < minSumPriorities {
-			minSumPriorities = sumPriorities
-			lenNodes1 = 0
-		}
-		if sumPriorities == minSumPriorities {
-			minNodes1[lenNodes1] = node
-			lenNodes1++
-		}
-	}
-	if lenNodes1 == 1 {
-		return minNodes1[0]
-	}
-
-	// There are a few nodes with minimum highest priority victim and sum of priorities.
-	// Find one with the minimum number of pods.
-	minNumPods := math.MaxInt32
-	lenNodes2 = 0
-	for i := 0; i < lenNodes1; i++ {
-		node := minNodes1[i]
-		numPods := len(nodesToVictims[node].Pods)
-		if numPods < minNumPods {
-			minNumPods = numPods
-			lenNodes2 = 0
-		}
-		if numPods == minNumPods {
-			minNodes2[lenNodes2] = node
-			lenNodes2++
-		}
-	}
-	if lenNodes2 == 1 {
-		return minNodes2[0]
-	}
-
-	// There are a few nodes with same number of pods.
-	// Find the node that satisfies latest(earliestStartTime(all highest-priority pods on node))
-	latestStartTime := util.GetEarliestPodStartTime(nodesToVictims[minNodes2[0]])
-	if latestStartTime == nil {
-		// If the earliest start time of all pods on the 1st node is nil, just return it,
-		// which is not expected to happen.
-		klog.ErrorS(errors.New("earliestStartTime is nil for node"), "Should not reach here", "node", klog.KRef("", minNodes2[0]))
-		return minNodes2[0]
-	}
-	nodeToReturn := minNodes2[0]
-	for i := 1; i < lenNodes2; i++ {
-		node := minNodes2[i]
-		// Get earliest start time of all pods on the current node.
-		earliestStartTimeOnNode := util.GetEarliestPodStartTime(nodesToVictims[node])
-		if earliestStartTimeOnNode == nil {
-			klog.ErrorS(errors.New("earliestStartTime is nil for node"), "Should not reach here", "node", klog.KRef("", node))
-			continue
+	now := time.Now()
+	for _, criterion := range criteria {
+		if len(nodes) == 1 {
+			break
 		}
-		if earliestStartTimeOnNode.After(latestStartTime.Time) {
-			latestStartTime = earliestStartTimeOnNode
-			nodeToReturn = node
+		// The nodes with the minimum cost are moved to the front of the slice,
+		// which is then cut to them.
+		var minCost float64
+		n := 0
+		for _, node := range nodes {
+			cost := criterion(nodesToVictims[node], now)
+			if n == 0 || cost < minCost {
+				minCost = cost
+				n = 0
+			}
+			if cost == minCost {
+				nodes[n] = node
+				n++
+			}
 		}
+		nodes = nodes[:n]
 	}
-
-	return nodeToReturn
+	return nodes[0]
 }
 
 // getLowerPriorityNominatedPods returns pods whose priority is smaller than the
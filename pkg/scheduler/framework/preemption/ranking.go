@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preemption
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/util"
+	v1 "k8s.io/api/core/v1"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+)
+
+// LastCheckpointAnnotation is set by a pod to the time, in RFC 3339, of its
+// last checkpoint. The work the pod loses when preempted is counted from
+// then rather than from its start.
+const LastCheckpointAnnotation = "preemption.scheduling.sched.dev/last-checkpoint"
+
+// CandidateCriterion returns the cost of preempting the victims of a
+// candidate node at the given time. Candidates are ranked by a list of
+// criteria: each keeps the candidates with the lowest cost and the next one
+// breaks the ties.
+type CandidateCriterion func(victims *extenderv1.Victims, now time.Time) float64
+
+// DefaultCandidateCriteria are the criteria candidates are ranked by upstream.
+var DefaultCandidateCriteria = []CandidateCriterion{
+	PDBViolations,
+	HighestPriority,
+	SumOfPriorities,
+	NumVictims,
+	LatestStartTime,
+}
+
+// PDBViolations is the number of victims whose PodDisruptionBudget would be
+// violated.
+func PDBViolations(victims *extenderv1.Victims, _ time.Time) float64 {
+	return float64(victims.NumPDBViolations)
+}
+
+// HighestPriority is the priority of the highest priority victim. It assumes
+// that the victims are ordered by decreasing priority.
+func HighestPriority(victims *extenderv1.Victims, _ time.Time) float64 {
+	return float64(corev1helpers.PodPriority(victims.Pods[0]))
+}
+
+// SumOfPriorities is the sum of the priorities of the victims.
+func SumOfPriorities(victims *extenderv1.Victims, _ time.Time) float64 {
+	var sumPriorities int64
+	for _, pod := range victims.Pods {
+		// We add MaxInt32+1 to all priorities to make all of them >= 0. This is
+		// needed so that a node with a few pods with negative priority is not
+		// picked over a node with a smaller number of pods with the same negative
+		// priority (and similar scenarios).
+		sumPriorities += int64(corev1helpers.PodPriority(pod)) + int64(math.MaxInt32+1)
+	}
+	return float64(sumPriorities)
+}
+
+// NumVictims is the number of victims.
+func NumVictims(victims *extenderv1.Victims, _ time.Time) float64 {
+	return float64(len(victims.Pods))
+}
+
+// LatestStartTime favors the candidate whose highest priority victims started
+// the latest.
+func LatestStartTime(victims *extenderv1.Victims, _ time.Time) float64 {
+	earliestStartTime := util.GetEarliestPodStartTime(victims)
+	if earliestStartTime == nil {
+		klog.ErrorS(errors.New("earliestStartTime is nil"), "Should not reach here")
+		return math.Inf(1)
+	}
+	return -float64(earliestStartTime.UnixNano())
+}
+
+// LostWork returns a criterion that costs the work the victims lose: the sum
+// of their requests, weighted by the given weights, times the hours they ran
+// since they started or last checkpointed. CPU is counted in cores, memory
+// and ephemeral storage in GiB and other resources in units.
+func LostWork(weights map[v1.ResourceName]int64) CandidateCriterion {
+	return func(victims *extenderv1.Victims, now time.Time) float64 {
+		var cost float64
+		for _, pod := range victims.Pods {
+			hours := now.Sub(lastCheckpoint(pod)).Hours()
+			if hours <= 0 {
+				continue
+			}
+			requests := framework.PodRequests(pod)
+			for name, weight := range weights {
+				cost += float64(weight) * requestedUnits(requests, name) * hours
+			}
+		}
+		return cost
+	}
+}
+
+// lastCheckpoint returns the later of the start time of the pod and its last
+// checkpoint.
+func lastCheckpoint(pod *v1.Pod) time.Time {
+	since := util.GetPodStartTime(pod).Time
+	if v, ok := pod.Annotations[LastCheckpointAnnotation]; ok {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			klog.V(4).InfoS("Ignoring invalid annotation", "pod", klog.KObj(pod), "annotation", LastCheckpointAnnotation, "value", v)
+		} else if t.After(since) {
+			since = t
+		}
+	}
+	return since
+}
+
+// requestedUnits returns how much of the resource is requested, in the units
+// LostWork counts it in.
+func requestedUnits(r *framework.Resource, name v1.ResourceName) float64 {
+	switch name {
+	case v1.ResourceCPU:
+		return float64(r.MilliCPU) / 1000
+	case v1.ResourceMemory:
+		return float64(r.Memory) / (1 << 30)
+	case v1.ResourceEphemeralStorage:
+		return float64(r.EphemeralStorage) / (1 << 30)
+	default:
+		return float64(r.ScalarResources[name])
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preemption
+
+import (
+	"testing"
+	"time"
+
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	extenderv1 "k8s.io/kube-scheduler/extender/v1"
+)
+
+func TestPickOneNodeForPreemption(t *testing.T) {
+	now := time.Now()
+	gpuRes := map[v1.ResourceName]string{v1.ResourceCPU: "8", v1.ResourceMemory: "64Gi", "nvidia.com/gpu": "8"}
+	smallRes := map[v1.ResourceName]string{v1.ResourceCPU: "1", v1.ResourceMemory: "1Gi"}
+	longRun := func() *st.PodWrapper {
+		return st.MakePod().Name("train").UID("train").Node("node1").Priority(10).Req(gpuRes).StartTime(metav1.NewTime(now.Add(-72 * time.Hour)))
+	}
+	freshJobs := []*v1.Pod{
+		st.MakePod().Name("job1").UID("job1").Node("node2").Priority(10).Req(smallRes).StartTime(metav1.NewTime(now.Add(-10 * time.Minute))).Obj(),
+		st.MakePod().Name("job2").UID("job2").Node("node2").Priority(10).Req(smallRes).StartTime(metav1.NewTime(now.Add(-10 * time.Minute))).Obj(),
+	}
+	lostWork := []CandidateCriterion{
+		PDBViolations,
+		HighestPriority,
+		LostWork(map[v1.ResourceName]int64{v1.ResourceCPU: 1, v1.ResourceMemory: 1, "nvidia.com/gpu": 1}),
+		NumVictims,
+	}
+
+	tests := []struct {
+		name     string
+		victims  map[string]*extenderv1.Victims
+		criteria []CandidateCriterion
+		want     string
+	}{
+		{
+			name: "default criteria preempt the single long run",
+			victims: map[string]*extenderv1.Victims{
+				"node1": {Pods: []*v1.Pod{longRun().Obj()}},
+				"node2": {Pods: freshJobs},
+			},
+			criteria: DefaultCandidateCriteria,
+			want:     "node1",
+		},
+		{
+			name: "lost work preempts the fresh small jobs",
+			victims: map[string]*extenderv1.Victims{
+				"node1": {Pods: []*v1.Pod{longRun().Obj()}},
+				"node2": {Pods: freshJobs},
+			},
+			criteria: lostWork,
+			want:     "node2",
+		},
+		{
+			name: "lost work is counted from the last checkpoint",
+			victims: map[string]*extenderv1.Victims{
+				"node1": {Pods: []*v1.Pod{longRun().Annotation(LastCheckpointAnnotation, now.Add(-10*time.Second).Format(time.RFC3339)).Obj()}},
+				"node2": {Pods: freshJobs},
+			},
+			criteria: lostWork,
+			want:     "node1",
+		},
+		{
+			name: "invalid last checkpoint is ignored",
+			victims: map[string]*extenderv1.Victims{
+				"node1": {Pods: []*v1.Pod{longRun().Annotation(LastCheckpointAnnotation, "yesterday").Obj()}},
+				"node2": {Pods: freshJobs},
+			},
+			criteria: lostWork,
+			want:     "node2",
+		},
+		{
+			name: "PDB violations come before lost work",
+			victims: map[string]*extenderv1.Victims{
+				"node1": {Pods: []*v1.Pod{longRun().Obj()}},
+				"node2": {Pods: freshJobs, NumPDBViolations: 1},
+			},
+			criteria: lostWork,
+			want:     "node1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickOneNodeForPreemption(tt.victims, tt.criteria); got != tt.want {
+				t.Errorf("pickOneNodeForPreemption() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
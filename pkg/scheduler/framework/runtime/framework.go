@@ -31,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
@@ -75,6 +76,7 @@ type frameworkImpl struct {
 	snapshotSharedLister framework.SharedLister
 	waitingPods          *waitingPodsMap
 	scorePluginWeight    map[string]int
+	preEnqueuePlugins    []framework.PreEnqueuePlugin
 	queueSortPlugins     []framework.QueueSortPlugin
 	preFilterPlugins     []framework.PreFilterPlugin
 	filterPlugins        []framework.FilterPlugin
@@ -87,10 +89,12 @@ type frameworkImpl struct {
 	postBindPlugins      []framework.PostBindPlugin
 	permitPlugins        []framework.PermitPlugin
 
-	clientSet       clientset.Interface
-	kubeConfig      *restclient.Config
-	eventRecorder   events.EventRecorder
-	informerFactory informers.SharedInformerFactory
+	clientSet          clientset.Interface
+	kubeConfig         *restclient.Config
+	eventRecorder      events.EventRecorder
+	informerFactory    informers.SharedInformerFactory
+	dynInformerFactory dynamicinformer.DynamicSharedInformerFactory
+	namespaceUsages    framework.NamespaceUsageLister
 
 	metricsRecorder *metricsRecorder
 	profileName     string
@@ -103,6 +107,9 @@ type frameworkImpl struct {
 	// Indicates that RunFilterPlugins should accumulate all failed statuses and not return
 	// after the first failure.
 	runAllFilters bool
+
+	// Indicates that the framework only finds nodes for a scheduling worker.
+	schedulingWorker bool
 }
 
 // extensionPoint encapsulates desired and applied set of plugins at a specific extension
@@ -143,14 +150,18 @@ type frameworkOptions struct {
 	kubeConfig             *restclient.Config
 	eventRecorder          events.EventRecorder
 	informerFactory        informers.SharedInformerFactory
+	dynInformerFactory     dynamicinformer.DynamicSharedInformerFactory
 	snapshotSharedLister   framework.SharedLister
+	namespaceUsages        framework.NamespaceUsageLister
 	metricsRecorder        *metricsRecorder
 	podNominator           framework.PodNominator
 	extenders              []framework.Extender
 	runAllFilters          bool
 	captureProfile         CaptureProfile
 	clusterEventMap        map[framework.ClusterEvent]sets.String
+	queueingHintMap        framework.QueueingHintMap
 	parallelizer           parallelize.Parallelizer
+	schedulingWorker       bool
 }
 
 // Option for the frameworkImpl.
@@ -194,6 +205,20 @@ func WithInformerFactory(informerFactory informers.SharedInformerFactory) Option
 	}
 }
 
+// WithDynInformerFactory sets the dynamic informer factory for the scheduling frameworkImpl.
+func WithDynInformerFactory(dynInformerFactory dynamicinformer.DynamicSharedInformerFactory) Option {
+	return func(o *frameworkOptions) {
+		o.dynInformerFactory = dynInformerFactory
+	}
+}
+
+// WithNamespaceUsages sets the NamespaceUsageLister backed by the scheduler cache.
+func WithNamespaceUsages(namespaceUsages framework.NamespaceUsageLister) Option {
+	return func(o *frameworkOptions) {
+		o.namespaceUsages = namespaceUsages
+	}
+}
+
 // WithSnapshotSharedLister sets the SharedLister of the snapshot.
 func WithSnapshotSharedLister(snapshotSharedLister framework.SharedLister) Option {
 	return func(o *frameworkOptions) {
@@ -230,6 +255,14 @@ func WithParallelism(parallelism int) Option {
 	}
 }
 
+// WithSchedulingWorker sets whether the frameworkImpl only finds nodes for
+// pods on behalf of a scheduling worker.
+func WithSchedulingWorker(schedulingWorker bool) Option {
+	return func(o *frameworkOptions) {
+		o.schedulingWorker = schedulingWorker
+	}
+}
+
 // CaptureProfile is a callback to capture a finalized profile.
 type CaptureProfile func(config.KubeSchedulerProfile)
 
@@ -244,6 +277,7 @@ func defaultFrameworkOptions() frameworkOptions {
 	return frameworkOptions{
 		metricsRecorder: newMetricsRecorder(1000, time.Second),
 		clusterEventMap: make(map[framework.ClusterEvent]sets.String),
+		queueingHintMap: make(framework.QueueingHintMap),
 		parallelizer:    parallelize.NewParallelizer(parallelize.DefaultParallelism),
 	}
 }
@@ -255,6 +289,13 @@ func WithClusterEventMap(m map[framework.ClusterEvent]sets.String) Option {
 	}
 }
 
+// WithQueueingHintMap sets queueingHintMap for the scheduling frameworkImpl.
+func WithQueueingHintMap(m framework.QueueingHintMap) Option {
+	return func(o *frameworkOptions) {
+		o.queueingHintMap = m
+	}
+}
+
 var _ framework.Framework = &frameworkImpl{}
 
 // NewFramework initializes plugins given the configuration and the registry.
@@ -273,11 +314,14 @@ func NewFramework(r Registry, profile *config.KubeSchedulerProfile, opts ...Opti
 		kubeConfig:           options.kubeConfig,
 		eventRecorder:        options.eventRecorder,
 		informerFactory:      options.informerFactory,
+		dynInformerFactory:   options.dynInformerFactory,
+		namespaceUsages:      options.namespaceUsages,
 		metricsRecorder:      options.metricsRecorder,
 		runAllFilters:        options.runAllFilters,
 		extenders:            options.extenders,
 		PodNominator:         options.podNominator,
 		parallelizer:         options.parallelizer,
+		schedulingWorker:     options.schedulingWorker,
 	}
 
 	if profile == nil {
@@ -328,6 +372,20 @@ func NewFramework(r Registry, profile *config.KubeSchedulerProfile, opts ...Opti
 
 		// Update ClusterEventMap in place.
 		fillEventToPluginMap(p, options.clusterEventMap)
+		fillQueueingHintMap(p, options.queueingHintMap)
+	}
+
+	// PreEnqueue plugins aren't configured at an extension point, so run the
+	// enabled ones in the order of their names.
+	names := make([]string, 0, len(pluginsMap))
+	for name := range pluginsMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if pl, ok := pluginsMap[name].(framework.PreEnqueuePlugin); ok {
+			f.preEnqueuePlugins = append(f.preEnqueuePlugins, pl)
+		}
 	}
 
 	// initialize plugins per individual extension points
@@ -505,6 +563,19 @@ func fillEventToPluginMap(p framework.Plugin, eventToPlugins map[framework.Clust
 	registerClusterEvents(p.Name(), eventToPlugins, events)
 }
 
+func fillQueueingHintMap(p framework.Plugin, hints framework.QueueingHintMap) {
+	ext, ok := p.(framework.QueueingHintExtensions)
+	if !ok {
+		return
+	}
+	for evt, fn := range ext.QueueingHints() {
+		if hints[evt] == nil {
+			hints[evt] = make(map[string][]framework.QueueingHintFn)
+		}
+		hints[evt][p.Name()] = append(hints[evt][p.Name()], fn)
+	}
+}
+
 func registerClusterEvents(name string, eventToPlugins map[framework.ClusterEvent]sets.String, evts []framework.ClusterEvent) {
 	for _, evt := range evts {
 		if eventToPlugins[evt] == nil {
@@ -557,6 +628,18 @@ func (f *frameworkImpl) QueueSortFunc() framework.LessFunc {
 	return f.queueSortPlugins[0].Less
 }
 
+// QueueSortKeyFunc returns the function to compute the sort key of pods in
+// scheduling queue, if the QueueSort plugin implements QueueSortKeyPlugin.
+func (f *frameworkImpl) QueueSortKeyFunc() framework.SortKeyFunc {
+	if f == nil || len(f.queueSortPlugins) == 0 {
+		return nil
+	}
+	if pl, ok := f.queueSortPlugins[0].(framework.QueueSortKeyPlugin); ok {
+		return pl.SortKey
+	}
+	return nil
+}
+
 // RunPreFilterPlugins runs the set of configured PreFilter plugins. It returns
 // *Status and its code is set to non-success if any of the plugins returns
 // anything but Success. If a non-success status is returned, then the scheduling
@@ -806,10 +889,19 @@ func addNominatedPods(ctx context.Context, fh framework.Handle, pod *v1.Pod, sta
 	if len(nominatedPodInfos) == 0 {
 		return false, state, nodeInfo, nil
 	}
+	var ignored *framework.IgnoredNominatedPods
+	if state != nil {
+		if c, err := state.Read(framework.IgnoredNominatedPodsKey); err == nil {
+			ignored, _ = c.(*framework.IgnoredNominatedPods)
+		}
+	}
 	nodeInfoOut := nodeInfo.Clone()
 	stateOut := state.Clone()
 	podsAdded := false
 	for _, pi := range nominatedPodInfos {
+		if ignored.Has(pi.Pod.UID) {
+			continue
+		}
 		if corev1.PodPriority(pi.Pod) >= corev1.PodPriority(pod) && pi.Pod.UID != pod.UID {
 			nodeInfoOut.AddPodInfo(pi)
 			status := fh.RunPreFilterExtensionAddPod(ctx, stateOut, pod, pi, nodeInfoOut)
@@ -1203,11 +1295,28 @@ func (f *frameworkImpl) RejectWaitingPod(uid types.UID) bool {
 	return false
 }
 
+// PreEnqueuePlugins returns the enabled plugins implementing PreEnqueuePlugin.
+func (f *frameworkImpl) PreEnqueuePlugins() []framework.PreEnqueuePlugin {
+	return f.preEnqueuePlugins
+}
+
 // HasFilterPlugins returns true if at least one filter plugin is defined.
 func (f *frameworkImpl) HasFilterPlugins() bool {
 	return len(f.filterPlugins) > 0
 }
 
+// FilterVerdictsCacheable returns true if all the filter plugins report that
+// their verdicts for the pod can be reused for equivalent pods.
+func (f *frameworkImpl) FilterVerdictsCacheable(state *framework.CycleState, pod *v1.Pod) bool {
+	for _, pl := range f.filterPlugins {
+		cpl, ok := pl.(framework.CacheableFilterPlugin)
+		if !ok || !cpl.FilterCacheable(state, pod) {
+			return false
+		}
+	}
+	return true
+}
+
 // HasPostFilterPlugins returns true if at least one postFilter plugin is defined.
 func (f *frameworkImpl) HasPostFilterPlugins() bool {
 	return len(f.postFilterPlugins) > 0
@@ -1263,6 +1372,17 @@ func (f *frameworkImpl) SharedInformerFactory() informers.SharedInformerFactory
 	return f.informerFactory
 }
 
+// DynInformerFactory returns a dynamic shared informer factory, which can be nil.
+func (f *frameworkImpl) DynInformerFactory() dynamicinformer.DynamicSharedInformerFactory {
+	return f.dynInformerFactory
+}
+
+// NamespaceUsages returns the resources used by namespaces as tracked by the
+// scheduler cache, which can be nil.
+func (f *frameworkImpl) NamespaceUsages() framework.NamespaceUsageLister {
+	return f.namespaceUsages
+}
+
 func (f *frameworkImpl) pluginsNeeded(plugins *config.Plugins) map[string]config.Plugin {
 	pgMap := make(map[string]config.Plugin)
 
@@ -1293,3 +1413,9 @@ func (f *frameworkImpl) ProfileName() string {
 func (f *frameworkImpl) Parallelizer() parallelize.Parallelizer {
 	return f.parallelizer
 }
+
+// SchedulingWorker returns true if the framework only finds nodes for pods on
+// behalf of a scheduling worker.
+func (f *frameworkImpl) SchedulingWorker() bool {
+	return f.schedulingWorker
+}
@@ -823,6 +823,30 @@ func (*fakeNoopRuntimePlugin) Filter(_ context.Context, _ *framework.CycleState,
 
 func (*fakeNoopRuntimePlugin) EventsToRegister() []framework.ClusterEvent { return nil }
 
+// fakeHintedPlugin registers a queueing hint that skips every Node Add event.
+type fakeHintedPlugin struct{}
+
+func (*fakeHintedPlugin) Name() string { return "fakeHinted" }
+
+func (*fakeHintedPlugin) Filter(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ *framework.NodeInfo) *framework.Status {
+	return nil
+}
+
+func (*fakeHintedPlugin) EventsToRegister() []framework.ClusterEvent {
+	return []framework.ClusterEvent{
+		{Resource: framework.Node, ActionType: framework.Add},
+		{Resource: framework.Pod, ActionType: framework.Delete},
+	}
+}
+
+func (*fakeHintedPlugin) QueueingHints() map[framework.ClusterEvent]framework.QueueingHintFn {
+	return map[framework.ClusterEvent]framework.QueueingHintFn{
+		{Resource: framework.Node, ActionType: framework.Add}: func(_ *v1.Pod, _, _ interface{}) framework.QueueingHint {
+			return framework.QueueSkip
+		},
+	}
+}
+
 func TestNewFrameworkFillEventToPluginMap(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -925,6 +949,79 @@ func TestNewFrameworkFillEventToPluginMap(t *testing.T) {
 	}
 }
 
+func TestNewFrameworkFillQueueingHintMap(t *testing.T) {
+	registry := Registry{}
+	cfgPls := &config.Plugins{}
+	for _, pl := range []framework.Plugin{&fakeHintedPlugin{}, &fakeNodePlugin{}} {
+		tmpPl := pl
+		if err := registry.Register(pl.Name(), func(_ runtime.Object, _ framework.Handle) (framework.Plugin, error) {
+			return tmpPl, nil
+		}); err != nil {
+			t.Fatalf("fail to register filter plugin (%s)", pl.Name())
+		}
+		cfgPls.Filter.Enabled = append(cfgPls.Filter.Enabled, config.Plugin{Name: pl.Name()})
+	}
+
+	got := make(framework.QueueingHintMap)
+	profile := config.KubeSchedulerProfile{Plugins: cfgPls}
+	if _, err := newFrameworkWithQueueSortAndBind(registry, profile, WithQueueingHintMap(got)); err != nil {
+		t.Fatal(err)
+	}
+
+	nodeAdd := framework.ClusterEvent{Resource: framework.Node, ActionType: framework.Add}
+	if len(got) != 1 || len(got[nodeAdd]) != 1 {
+		t.Fatalf("Expected a single hint registered for %v, got %v", nodeAdd, got)
+	}
+	fns := got[nodeAdd]["fakeHinted"]
+	if len(fns) != 1 {
+		t.Fatalf("Expected one hint of plugin fakeHinted, got %d", len(fns))
+	}
+	if hint := fns[0](&v1.Pod{}, nil, &v1.Node{}); hint != framework.QueueSkip {
+		t.Errorf("Expected the registered hint to return QueueSkip, got %v", hint)
+	}
+}
+
+// fakePreEnqueuePlugin is a filter plugin also gating the pods in PreEnqueue.
+type fakePreEnqueuePlugin struct {
+	name string
+}
+
+func (pl *fakePreEnqueuePlugin) Name() string { return pl.name }
+
+func (pl *fakePreEnqueuePlugin) Filter(_ context.Context, _ *framework.CycleState, _ *v1.Pod, _ *framework.NodeInfo) *framework.Status {
+	return nil
+}
+
+func (pl *fakePreEnqueuePlugin) PreEnqueue(_ context.Context, _ *v1.Pod) *framework.Status {
+	return nil
+}
+
+func TestPreEnqueuePlugins(t *testing.T) {
+	registry := Registry{}
+	cfgPls := &config.Plugins{}
+	for _, pl := range []framework.Plugin{&fakePreEnqueuePlugin{name: "gateB"}, &fakeNodePlugin{}, &fakePreEnqueuePlugin{name: "gateA"}} {
+		tmpPl := pl
+		if err := registry.Register(pl.Name(), func(_ runtime.Object, _ framework.Handle) (framework.Plugin, error) {
+			return tmpPl, nil
+		}); err != nil {
+			t.Fatalf("fail to register filter plugin (%s)", pl.Name())
+		}
+		cfgPls.Filter.Enabled = append(cfgPls.Filter.Enabled, config.Plugin{Name: pl.Name()})
+	}
+
+	f, err := newFrameworkWithQueueSortAndBind(registry, config.KubeSchedulerProfile{Plugins: cfgPls})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, pl := range f.PreEnqueuePlugins() {
+		got = append(got, pl.Name())
+	}
+	if diff := cmp.Diff([]string{"gateA", "gateB"}, got); diff != "" {
+		t.Errorf("Unexpected PreEnqueue plugins (-want,+got):%s", diff)
+	}
+}
+
 func TestRunScorePlugins(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -1473,6 +1570,7 @@ func TestFilterPluginsWithNominatedPods(t *testing.T) {
 		filterPlugin    *TestPlugin
 		pod             *v1.Pod
 		nominatedPod    *v1.Pod
+		ignoreNominated bool
 		node            *v1.Node
 		nodeInfo        *framework.NodeInfo
 		wantStatus      *framework.Status
@@ -1542,6 +1640,22 @@ func TestFilterPluginsWithNominatedPods(t *testing.T) {
 			nodeInfo:     framework.NewNodeInfo(pod),
 			wantStatus:   framework.AsStatus(fmt.Errorf(`running "TestPlugin2" filter plugin: %w`, errInjectedFilterStatus)).WithFailedPlugin("TestPlugin2"),
 		},
+		{
+			name: "node has an ignored high-priority nominated pod and pre filters fail",
+			preFilterPlugin: &TestPlugin{
+				name: "TestPlugin1",
+				inj: injectedResult{
+					PreFilterAddPodStatus: int(framework.Error),
+				},
+			},
+			filterPlugin:    nil,
+			pod:             lowPriorityPod,
+			nominatedPod:    highPriorityPod,
+			ignoreNominated: true,
+			node:            node,
+			nodeInfo:        framework.NewNodeInfo(pod),
+			wantStatus:      nil,
+		},
 		{
 			name: "node has a low-priority nominated pod and pre filters return unschedulable",
 			preFilterPlugin: &TestPlugin{
@@ -1605,8 +1719,15 @@ func TestFilterPluginsWithNominatedPods(t *testing.T) {
 			if err != nil {
 				t.Fatalf("fail to create framework: %s", err)
 			}
+			var state *framework.CycleState
+			if tt.ignoreNominated {
+				state = framework.NewCycleState()
+				state.Write(framework.IgnoredNominatedPodsKey, &framework.IgnoredNominatedPods{
+					UIDs: map[types.UID]struct{}{tt.nominatedPod.UID: {}},
+				})
+			}
 			tt.nodeInfo.SetNode(tt.node)
-			gotStatus := f.RunFilterPluginsWithNominatedPods(context.TODO(), nil, tt.pod, tt.nodeInfo)
+			gotStatus := f.RunFilterPluginsWithNominatedPods(context.TODO(), state, tt.pod, tt.nodeInfo)
 			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
 				t.Errorf("Unexpected status. got: %v, want: %v", gotStatus, tt.wantStatus)
 			}
@@ -28,6 +28,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
@@ -88,6 +89,29 @@ func (ce ClusterEvent) IsWildCard() bool {
 	return ce.Resource == WildCard && ce.ActionType == All
 }
 
+// QueueingHint tells whether an occurrence of a cluster event may make a Pod
+// schedulable.
+type QueueingHint int
+
+const (
+	// Queue means that the event may make the Pod schedulable, so the Pod is
+	// moved to activeQ or podBackoffQ.
+	Queue QueueingHint = iota
+	// QueueSkip means that the event doesn't make the Pod schedulable, so the
+	// Pod stays in unschedulableQ.
+	QueueSkip
+)
+
+// QueueingHintFn tells whether the change of an object from oldObj to newObj
+// may make the Pod schedulable. oldObj is nil for Add events and newObj is nil
+// for Delete events.
+type QueueingHintFn func(pod *v1.Pod, oldObj, newObj interface{}) QueueingHint
+
+// QueueingHintMap holds the QueueingHintFns registered by plugins, keyed by
+// cluster event and plugin name. A plugin enabled in several profiles
+// registers a function per profile.
+type QueueingHintMap map[ClusterEvent]map[string][]QueueingHintFn
+
 // QueuedPodInfo is a Pod wrapper with additional information related to
 // the pod's status in the scheduling queue, such as the timestamp when
 // it's added to the queue.
@@ -105,6 +129,9 @@ type QueuedPodInfo struct {
 	InitialAttemptTimestamp time.Time
 	// If a Pod failed in a scheduling cycle, record the plugin names it failed by.
 	UnschedulablePlugins sets.String
+	// SortKey is computed by the QueueSort plugin, if it implements
+	// QueueSortKeyPlugin, when the pod is added to or moved in the active queue.
+	SortKey float64
 }
 
 // DeepCopy returns a deep copy of the QueuedPodInfo object.
@@ -114,6 +141,7 @@ func (pqi *QueuedPodInfo) DeepCopy() *QueuedPodInfo {
 		Timestamp:               pqi.Timestamp,
 		Attempts:                pqi.Attempts,
 		InitialAttemptTimestamp: pqi.InitialAttemptTimestamp,
+		SortKey:                 pqi.SortKey,
 	}
 }
 
@@ -393,6 +421,11 @@ type NodeInfo struct {
 	// Keys are in the format "namespace/name".
 	PVCRefCounts map[string]int
 
+	// GPUShares contains the shares of the GPUs of the node allocated to its
+	// pods, keyed by pod UID. It includes assumed pods once the device they
+	// were placed on is recorded in their GPUShareDeviceAnnotation.
+	GPUShares map[types.UID]GPUShare
+
 	// Whenever NodeInfo changes, generation is bumped.
 	// This is used to avoid cloning it if the object didn't change.
 	Generation int64
@@ -452,6 +485,44 @@ func (r *Resource) Add(rl v1.ResourceList) {
 	}
 }
 
+// AddResource adds the given Resource into Resource.
+func (r *Resource) AddResource(rr *Resource) {
+	r.MilliCPU += rr.MilliCPU
+	r.Memory += rr.Memory
+	r.EphemeralStorage += rr.EphemeralStorage
+	r.AllowedPodNumber += rr.AllowedPodNumber
+	for rName, rQuant := range rr.ScalarResources {
+		r.AddScalar(rName, rQuant)
+	}
+}
+
+// SubResource subtracts the given Resource from Resource.
+func (r *Resource) SubResource(rr *Resource) {
+	r.MilliCPU -= rr.MilliCPU
+	r.Memory -= rr.Memory
+	r.EphemeralStorage -= rr.EphemeralStorage
+	r.AllowedPodNumber -= rr.AllowedPodNumber
+	for rName, rQuant := range rr.ScalarResources {
+		r.AddScalar(rName, -rQuant)
+	}
+}
+
+// Value returns the amount of the named resource, with CPU in millicores.
+func (r *Resource) Value(name v1.ResourceName) int64 {
+	switch name {
+	case v1.ResourceCPU:
+		return r.MilliCPU
+	case v1.ResourceMemory:
+		return r.Memory
+	case v1.ResourceEphemeralStorage:
+		return r.EphemeralStorage
+	case v1.ResourcePods:
+		return int64(r.AllowedPodNumber)
+	default:
+		return r.ScalarResources[name]
+	}
+}
+
 // Clone returns a copy of this resource.
 func (r *Resource) Clone() *Resource {
 	res := &Resource{
@@ -565,6 +636,12 @@ func (n *NodeInfo) Clone() *NodeInfo {
 	if len(n.PodsWithRequiredAntiAffinity) > 0 {
 		clone.PodsWithRequiredAntiAffinity = append([]*PodInfo(nil), n.PodsWithRequiredAntiAffinity...)
 	}
+	if len(n.GPUShares) > 0 {
+		clone.GPUShares = make(map[types.UID]GPUShare, len(n.GPUShares))
+		for uid, share := range n.GPUShares {
+			clone.GPUShares[uid] = share
+		}
+	}
 	return clone
 }
 
@@ -604,6 +681,12 @@ func (n *NodeInfo) AddPodInfo(podInfo *PodInfo) {
 	// Consume ports when pods added.
 	n.updateUsedPorts(podInfo.Pod, true)
 	n.updatePVCRefCounts(podInfo.Pod, true)
+	if share, ok := GPUShareOf(podInfo.Pod); ok {
+		if n.GPUShares == nil {
+			n.GPUShares = make(map[types.UID]GPUShare)
+		}
+		n.GPUShares[podInfo.Pod.UID] = share
+	}
 
 	n.Generation = nextGeneration()
 }
@@ -682,6 +765,7 @@ func (n *NodeInfo) RemovePod(pod *v1.Pod) error {
 			// Release ports when remove Pods.
 			n.updateUsedPorts(pod, false)
 			n.updatePVCRefCounts(pod, false)
+			delete(n.GPUShares, pod.UID)
 
 			n.Generation = nextGeneration()
 			n.resetSlicesIfEmpty()
@@ -711,6 +795,15 @@ func max(a, b int64) int64 {
 	return b
 }
 
+// PodRequests returns the resources requested by the pod, as they are
+// accounted for in the Requested resources of its node. The pod itself is
+// counted in AllowedPodNumber.
+func PodRequests(pod *v1.Pod) *Resource {
+	res, _, _ := calculateResource(pod)
+	res.AllowedPodNumber = 1
+	return &res
+}
+
 // resourceRequest = max(sum(podSpec.Containers), podSpec.InitContainers) + overHead
 func calculateResource(pod *v1.Pod) (res Resource, non0CPU int64, non0Mem int64) {
 	resPtr := &res
@@ -30,6 +30,7 @@ import (
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/parallelize"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/runtime"
 	internalcache "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/cache"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/equivalence"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/metrics"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -50,6 +51,9 @@ const (
 	// to ensure that a certain minimum of nodes are checked for feasibility.
 	// This in turn helps ensure a minimum level of spreading.
 	minFeasibleNodesPercentageToFind = 5
+	// equivalenceCacheMaxClasses is the maximum number of equivalence classes
+	// of pods whose Filter verdicts are cached.
+	equivalenceCacheMaxClasses = 64
 )
 
 // ErrNoNodesAvailable is used to describe the error that no nodes available to schedule pods.
@@ -71,6 +75,9 @@ type ScheduleResult struct {
 	EvaluatedNodes int
 	// Number of feasible nodes on one pod scheduled
 	FeasibleNodes int
+	// Scores of the feasible nodes by each score plugin. It's nil when no
+	// score plugin ran.
+	PluginToNodeScores framework.PluginToNodeScores
 }
 
 type genericScheduler struct {
@@ -78,6 +85,9 @@ type genericScheduler struct {
 	nodeInfoSnapshot         *internalcache.Snapshot
 	percentageOfNodesToScore int32
 	nextStartNodeIndex       int
+	// equivalenceCache holds the Filter verdicts of nodes for equivalent
+	// pods. It can be nil, e.g. in tests.
+	equivalenceCache *equivalence.Cache
 }
 
 // snapshot snapshots scheduler cache and node infos for all fit and priority
@@ -126,7 +136,7 @@ func (g *genericScheduler) Schedule(ctx context.Context, extenders []framework.E
 		}, nil
 	}
 
-	priorityList, err := prioritizeNodes(ctx, extenders, fwk, state, pod, feasibleNodes)
+	priorityList, scoresMap, err := prioritizeNodes(ctx, extenders, fwk, state, pod, feasibleNodes)
 	if err != nil {
 		return result, err
 	}
@@ -134,11 +144,15 @@ func (g *genericScheduler) Schedule(ctx context.Context, extenders []framework.E
 	host, err := g.selectHost(priorityList)
 	trace.Step("Prioritizing done")
 
-	return ScheduleResult{
+	result = ScheduleResult{
 		SuggestedHost:  host,
 		EvaluatedNodes: len(feasibleNodes) + len(diagnosis.NodeToStatusMap),
 		FeasibleNodes:  len(feasibleNodes),
-	}, err
+	}
+	if len(scoresMap) != 0 {
+		result.PluginToNodeScores = scoresMap
+	}
+	return result, err
 }
 
 // selectHost takes a prioritized list of nodes and then picks one
@@ -285,6 +299,15 @@ func (g *genericScheduler) findNodesThatPassFilters(
 		return feasibleNodes, nil
 	}
 
+	// The verdicts of the previous equivalent pods are reused for the nodes
+	// whose NodeInfo didn't change since, unless a Filter plugin depends on
+	// more than the pod and the node for this pod.
+	var classCache *equivalence.ClassCache
+	if g.equivalenceCache != nil && fwk.FilterVerdictsCacheable(state, pod) {
+		classCache = g.equivalenceCache.ForPod(fwk.ProfileName(), pod)
+	}
+	var cacheHits, cacheMisses int32
+
 	errCh := parallelize.NewErrorChannel()
 	var statusesLock sync.Mutex
 	var feasibleNodesLen int32
@@ -293,7 +316,23 @@ func (g *genericScheduler) findNodesThatPassFilters(
 		// We check the nodes starting from where we left off in the previous scheduling cycle,
 		// this is to make sure all nodes have the same chance of being examined across pods.
 		nodeInfo := nodes[(g.nextStartNodeIndex+i)%len(nodes)]
-		status := fwk.RunFilterPluginsWithNominatedPods(ctx, state, pod, nodeInfo)
+		// The verdict on a node also depends on the pods nominated to it,
+		// which aren't tracked in its NodeInfo.
+		cacheable := classCache != nil && len(fwk.NominatedPodsForNode(nodeInfo.Node().Name)) == 0
+		var status *framework.Status
+		cached := false
+		if cacheable {
+			status, cached = classCache.Lookup(nodeInfo)
+		}
+		if cached {
+			atomic.AddInt32(&cacheHits, 1)
+		} else {
+			status = fwk.RunFilterPluginsWithNominatedPods(ctx, state, pod, nodeInfo)
+			if cacheable {
+				atomic.AddInt32(&cacheMisses, 1)
+				classCache.Store(nodeInfo, status)
+			}
+		}
 		if status.Code() == framework.Error {
 			errCh.SendErrorWithCancel(status.AsError(), cancel)
 			return
@@ -326,6 +365,10 @@ func (g *genericScheduler) findNodesThatPassFilters(
 	// Stops searching for more nodes once the configured number of feasible nodes
 	// are found.
 	fwk.Parallelizer().Until(ctx, len(nodes), checkNode)
+	if classCache != nil {
+		metrics.EquivalenceCacheLookups.WithLabelValues(fwk.ProfileName(), "hit").Add(float64(cacheHits))
+		metrics.EquivalenceCacheLookups.WithLabelValues(fwk.ProfileName(), "miss").Add(float64(cacheMisses))
+	}
 	processedNodes := int(feasibleNodesLen) + len(diagnosis.NodeToStatusMap)
 	g.nextStartNodeIndex = (g.nextStartNodeIndex + processedNodes) % len(nodes)
 
@@ -394,7 +437,8 @@ func findNodesThatPassExtenders(extenders []framework.Extender, pod *v1.Pod, fea
 // which return a score for each node from the call to RunScorePlugins().
 // The scores from each plugin are added together to make the score for that node, then
 // any extenders are run as well.
-// All scores are finally combined (added) to get the total weighted scores of all nodes
+// All scores are finally combined (added) to get the total weighted scores of all nodes.
+// The scores of each plugin are returned as well.
 func prioritizeNodes(
 	ctx context.Context,
 	extenders []framework.Extender,
@@ -402,7 +446,7 @@ func prioritizeNodes(
 	state *framework.CycleState,
 	pod *v1.Pod,
 	nodes []*v1.Node,
-) (framework.NodeScoreList, error) {
+) (framework.NodeScoreList, framework.PluginToNodeScores, error) {
 	// If no priority configs are provided, then all nodes will have a score of one.
 	// This is required to generate the priority list in the required format
 	if len(extenders) == 0 && !fwk.HasScorePlugins() {
@@ -413,19 +457,19 @@ func prioritizeNodes(
 				Score: 1,
 			})
 		}
-		return result, nil
+		return result, nil, nil
 	}
 
 	// Run PreScore plugins.
 	preScoreStatus := fwk.RunPreScorePlugins(ctx, state, pod, nodes)
 	if !preScoreStatus.IsSuccess() {
-		return nil, preScoreStatus.AsError()
+		return nil, nil, preScoreStatus.AsError()
 	}
 
 	// Run the Score plugins.
 	scoresMap, scoreStatus := fwk.RunScorePlugins(ctx, state, pod, nodes)
 	if !scoreStatus.IsSuccess() {
-		return nil, scoreStatus.AsError()
+		return nil, nil, scoreStatus.AsError()
 	}
 
 	if klog.V(10).Enabled() {
@@ -491,7 +535,7 @@ func prioritizeNodes(
 			klog.InfoS("Calculated node's final score for pod", "pod", klog.KObj(pod), "node", result[i].Name, "score", result[i].Score)
 		}
 	}
-	return result, nil
+	return result, scoresMap, nil
 }
 
 // NewGenericScheduler creates a genericScheduler object.
@@ -503,5 +547,6 @@ func NewGenericScheduler(
 		cache:                    cache,
 		nodeInfoSnapshot:         nodeInfoSnapshot,
 		percentageOfNodesToScore: percentageOfNodesToScore,
+		equivalenceCache:         equivalence.New(equivalenceCacheMaxClasses),
 	}
 }
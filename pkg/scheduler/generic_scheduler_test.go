@@ -1192,6 +1192,122 @@ func TestFindFitPredicateCallCounts(t *testing.T) {
 	}
 }
 
+// cacheableFilterPlugin is a FakeFilterPlugin that lets the scheduler reuse
+// its verdicts for equivalent pods.
+type cacheableFilterPlugin struct {
+	st.FakeFilterPlugin
+	cacheable bool
+}
+
+func (pl *cacheableFilterPlugin) FilterCacheable(_ *framework.CycleState, _ *v1.Pod) bool {
+	return pl.cacheable
+}
+
+func TestFindNodesThatPassFiltersWithEquivalenceCache(t *testing.T) {
+	tests := []struct {
+		name      string
+		cacheable bool
+		// nominate nominates a pod to node "1".
+		nominate bool
+		// addPod adds a pod to node "2" before the second pod is scheduled.
+		addPod bool
+		// secondPod is scheduled after a pod of the "job" replicas.
+		secondPod     *v1.Pod
+		expectedCount int32
+	}{
+		{
+			name:          "equivalent pod reuses the verdicts",
+			cacheable:     true,
+			secondPod:     st.MakePod().Namespace("ns").Name("b").UID("b").Label("app", "job").Obj(),
+			expectedCount: 3,
+		},
+		{
+			name:          "plugin verdicts aren't cacheable",
+			secondPod:     st.MakePod().Namespace("ns").Name("b").UID("b").Label("app", "job").Obj(),
+			expectedCount: 6,
+		},
+		{
+			name:          "different pod doesn't reuse the verdicts",
+			cacheable:     true,
+			secondPod:     st.MakePod().Namespace("ns").Name("b").UID("b").Label("app", "other").Obj(),
+			expectedCount: 6,
+		},
+		{
+			name:          "changed node is filtered again",
+			cacheable:     true,
+			addPod:        true,
+			secondPod:     st.MakePod().Namespace("ns").Name("b").UID("b").Label("app", "job").Obj(),
+			expectedCount: 4,
+		},
+		{
+			name:          "node with nominated pods is always filtered",
+			cacheable:     true,
+			nominate:      true,
+			secondPod:     st.MakePod().Namespace("ns").Name("b").UID("b").Label("app", "job").Obj(),
+			expectedCount: 6,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nodes := makeNodeList([]string{"1", "2", "3"})
+			plugin := cacheableFilterPlugin{
+				FakeFilterPlugin: st.FakeFilterPlugin{FailedNodeReturnCodeMap: map[string]framework.Code{"3": framework.Unschedulable}},
+				cacheable:        test.cacheable,
+			}
+			registerPlugins := []st.RegisterPluginFunc{
+				st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+				st.RegisterFilterPlugin(
+					"FakeFilter",
+					func(_ runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+						return &plugin, nil
+					},
+				),
+				st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			}
+			fwk, err := st.NewFramework(
+				registerPlugins, "",
+				frameworkruntime.WithPodNominator(internalqueue.NewPodNominator(nil)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.nominate {
+				fwk.AddNominatedPod(framework.NewPodInfo(&v1.Pod{ObjectMeta: metav1.ObjectMeta{UID: "nominated"}, Spec: v1.PodSpec{Priority: &midPriority}}),
+					&framework.NominatingInfo{NominatingMode: framework.ModeOverride, NominatedNodeName: "1"})
+			}
+
+			scheduler := makeScheduler(nodes)
+			firstPod := st.MakePod().Namespace("ns").Name("a").UID("a").Label("app", "job").Obj()
+			if _, _, err := scheduler.findNodesThatFitPod(context.Background(), nil, fwk, framework.NewCycleState(), firstPod); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if test.addPod {
+				if err := scheduler.cache.AddPod(st.MakePod().Name("running").UID("running").Node("2").Obj()); err != nil {
+					t.Fatal(err)
+				}
+				if err := scheduler.cache.UpdateSnapshot(scheduler.nodeInfoSnapshot); err != nil {
+					t.Fatal(err)
+				}
+			}
+			feasibleNodes, diagnosis, err := scheduler.findNodesThatFitPod(context.Background(), nil, fwk, framework.NewCycleState(), test.secondPod)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if test.expectedCount != plugin.NumFilterCalled {
+				t.Errorf("filter was called %d times, expected is %d", plugin.NumFilterCalled, test.expectedCount)
+			}
+			if len(feasibleNodes) != 2 {
+				t.Errorf("got %d feasible nodes, want 2", len(feasibleNodes))
+			}
+			status := diagnosis.NodeToStatusMap["3"]
+			if status.Code() != framework.Unschedulable || status.FailedPlugin() != "FakeFilter" {
+				t.Errorf("got status %v for node 3, want Unschedulable by FakeFilter", status)
+			}
+		})
+	}
+}
+
 func makeNode(node string, milliCPU, memory int64) *v1.Node {
 	return &v1.Node{
 		ObjectMeta: metav1.ObjectMeta{Name: node},
@@ -1343,14 +1459,23 @@ func TestZeroRequest(t *testing.T) {
 				t.Fatalf("error filtering nodes: %+v", err)
 			}
 			fwk.RunPreScorePlugins(ctx, state, test.pod, test.nodes)
-			list, err := prioritizeNodes(ctx, nil, fwk, state, test.pod, test.nodes)
+			list, scoresMap, err := prioritizeNodes(ctx, nil, fwk, state, test.pod, test.nodes)
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
 			}
-			for _, hp := range list {
+			for i, hp := range list {
 				if hp.Score != test.expectedScore {
 					t.Errorf("expected %d for all priorities, got list %#v", test.expectedScore, list)
 				}
+				// Without extenders, the score of a node is the sum of the
+				// scores of the plugins.
+				var sum int64
+				for _, pluginScores := range scoresMap {
+					sum += pluginScores[i].Score
+				}
+				if sum != hp.Score {
+					t.Errorf("expected the plugin scores of %s to add up to %d, got %d", hp.Name, hp.Score, sum)
+				}
 			}
 		})
 	}
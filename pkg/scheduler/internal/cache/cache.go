@@ -24,6 +24,7 @@ import (
 
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/metrics"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/podgroup"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -72,6 +73,26 @@ type schedulerCache struct {
 	nodeTree *nodeTree
 	// A map from image name to its imageState.
 	imageStates map[string]*imageState
+	// A map from namespace to the resources requested by its pods that are
+	// assigned or assumed to nodes.
+	namespaceUsage map[string]*namespaceUsage
+	// namespaceUsageGeneration is bumped whenever namespaceUsage changes.
+	namespaceUsageGeneration int64
+	// namespaceUsageRemoval is the namespaceUsageGeneration at which the last
+	// pod of a namespace was last removed, along with its usage.
+	namespaceUsageRemoval int64
+	// A map from pod group key to the number of its members assigned or
+	// assumed to each node.
+	podGroupPlacement map[string]map[string]int
+	// podGroupPlacementGeneration is bumped whenever podGroupPlacement changes.
+	podGroupPlacementGeneration int64
+}
+
+type namespaceUsage struct {
+	usage *framework.Resource
+	// generation is the namespaceUsageGeneration of the cache when usage was
+	// last changed.
+	generation int64
 }
 
 type podState struct {
@@ -108,6 +129,9 @@ func newSchedulerCache(ttl, period time.Duration, stop <-chan struct{}) *schedul
 		assumedPods: make(sets.String),
 		podStates:   make(map[string]*podState),
 		imageStates: make(map[string]*imageState),
+
+		namespaceUsage:    make(map[string]*namespaceUsage),
+		podGroupPlacement: make(map[string]map[string]int),
 	}
 }
 
@@ -169,6 +193,27 @@ func (cache *schedulerCache) removeNodeInfoFromList(name string) {
 	delete(cache.nodes, name)
 }
 
+// NamespaceUsages returns a NamespaceUsageLister backed by the cache.
+func (cache *schedulerCache) NamespaceUsages() framework.NamespaceUsageLister {
+	return cacheNamespaceUsageLister{cache: cache}
+}
+
+// cacheNamespaceUsageLister gets the resources used by namespaces from the
+// cache, taking its lock.
+type cacheNamespaceUsageLister struct {
+	cache *schedulerCache
+}
+
+// Get returns a copy of the resources requested by the pods of the namespace.
+func (l cacheNamespaceUsageLister) Get(namespace string) *framework.Resource {
+	l.cache.mu.RLock()
+	defer l.cache.mu.RUnlock()
+	if nu, ok := l.cache.namespaceUsage[namespace]; ok {
+		return nu.usage.Clone()
+	}
+	return &framework.Resource{}
+}
+
 // Dump produces a dump of the current scheduler cache. This is used for
 // debugging purposes only and shouldn't be confused with UpdateSnapshot
 // function.
@@ -188,6 +233,18 @@ func (cache *schedulerCache) Dump() *Dump {
 	}
 }
 
+// NodeInfo returns a clone of the NodeInfo of the node.
+func (cache *schedulerCache) NodeInfo(nodeName string) (*framework.NodeInfo, error) {
+	cache.mu.RLock()
+	defer cache.mu.RUnlock()
+
+	n, ok := cache.nodes[nodeName]
+	if !ok || n.info.Node() == nil {
+		return nil, fmt.Errorf("node %q not found in the cache", nodeName)
+	}
+	return n.info.Clone(), nil
+}
+
 // UpdateSnapshot takes a snapshot of cached NodeInfo map. This is called at
 // beginning of every scheduling cycle.
 // The snapshot only includes Nodes that are not deleted at the time this function is called.
@@ -255,6 +312,32 @@ func (cache *schedulerCache) UpdateSnapshot(nodeSnapshot *Snapshot) error {
 		updateAllLists = true
 	}
 
+	// Only the usages of the namespaces that changed since the last snapshot
+	// are copied, and those of the namespaces without pods anymore removed.
+	if nodeSnapshot.namespaceUsageGeneration != cache.namespaceUsageGeneration {
+		if nodeSnapshot.namespaceUsageGeneration < cache.namespaceUsageRemoval {
+			for namespace := range nodeSnapshot.namespaceUsage {
+				if _, ok := cache.namespaceUsage[namespace]; !ok {
+					delete(nodeSnapshot.namespaceUsage, namespace)
+				}
+			}
+		}
+		for namespace, nu := range cache.namespaceUsage {
+			if nu.generation > nodeSnapshot.namespaceUsageGeneration {
+				nodeSnapshot.namespaceUsage[namespace] = nu.usage.Clone()
+			}
+		}
+		nodeSnapshot.namespaceUsageGeneration = cache.namespaceUsageGeneration
+	}
+
+	if nodeSnapshot.podGroupPlacementGeneration != cache.podGroupPlacementGeneration {
+		nodeSnapshot.podGroupPlacement = make(map[string]map[string]int, len(cache.podGroupPlacement))
+		for key, placement := range cache.podGroupPlacement {
+			nodeSnapshot.podGroupPlacement[key] = copyPlacement(placement)
+		}
+		nodeSnapshot.podGroupPlacementGeneration = cache.podGroupPlacementGeneration
+	}
+
 	if updateAllLists || updateNodesHavePodsWithAffinity || updateNodesHavePodsWithRequiredAntiAffinity {
 		cache.updateNodeInfoSnapshotList(nodeSnapshot, updateAllLists)
 	}
@@ -421,6 +504,31 @@ func (cache *schedulerCache) ForgetPod(pod *v1.Pod) error {
 	return nil
 }
 
+func (cache *schedulerCache) UpdateAssumedPod(pod *v1.Pod) error {
+	key, err := framework.GetPodKey(pod)
+	if err != nil {
+		return err
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	currState, ok := cache.podStates[key]
+	switch {
+	case ok && cache.assumedPods.Has(key):
+		if currState.pod.Spec.NodeName != pod.Spec.NodeName {
+			return fmt.Errorf("pod %v was assumed on %v but assigned to %v", key, currState.pod.Spec.NodeName, pod.Spec.NodeName)
+		}
+		if err := cache.updatePod(currState.pod, pod); err != nil {
+			return err
+		}
+		currState.pod = pod
+	default:
+		return fmt.Errorf("pod %v wasn't assumed so cannot be updated", key)
+	}
+	return nil
+}
+
 // Assumes that lock is already acquired.
 func (cache *schedulerCache) addPod(pod *v1.Pod) {
 	n, ok := cache.nodes[pod.Spec.NodeName]
@@ -429,6 +537,8 @@ func (cache *schedulerCache) addPod(pod *v1.Pod) {
 		cache.nodes[pod.Spec.NodeName] = n
 	}
 	n.info.AddPod(pod)
+	cache.updateNamespaceUsage(pod, true)
+	cache.updatePodGroupPlacement(pod, true)
 	cache.moveNodeInfoToHead(pod.Spec.NodeName)
 }
 
@@ -454,6 +564,8 @@ func (cache *schedulerCache) removePod(pod *v1.Pod) error {
 	if err := n.info.RemovePod(pod); err != nil {
 		return err
 	}
+	cache.updateNamespaceUsage(pod, false)
+	cache.updatePodGroupPlacement(pod, false)
 	if len(n.info.Pods) == 0 && n.info.Node() == nil {
 		cache.removeNodeInfoFromList(pod.Spec.NodeName)
 	} else {
@@ -462,6 +574,55 @@ func (cache *schedulerCache) removePod(pod *v1.Pod) error {
 	return nil
 }
 
+// Assumes that lock is already acquired.
+// Adds or subtracts the requests of a pod to the usage of its namespace.
+func (cache *schedulerCache) updateNamespaceUsage(pod *v1.Pod, add bool) {
+	nu, ok := cache.namespaceUsage[pod.Namespace]
+	if !ok {
+		nu = &namespaceUsage{usage: &framework.Resource{}}
+		cache.namespaceUsage[pod.Namespace] = nu
+	}
+	if add {
+		nu.usage.AddResource(framework.PodRequests(pod))
+	} else {
+		nu.usage.SubResource(framework.PodRequests(pod))
+	}
+	cache.namespaceUsageGeneration++
+	nu.generation = cache.namespaceUsageGeneration
+	// The usage counts one pod for each of the pods of the namespace.
+	if nu.usage.AllowedPodNumber <= 0 {
+		delete(cache.namespaceUsage, pod.Namespace)
+		cache.namespaceUsageRemoval = cache.namespaceUsageGeneration
+	}
+}
+
+// Assumes that lock is already acquired.
+// Adds or removes a pod from the placement of its pod group, if any.
+func (cache *schedulerCache) updatePodGroupPlacement(pod *v1.Pod, add bool) {
+	name := podgroup.Name(pod)
+	if name == "" {
+		return
+	}
+	key := podGroupKey(pod.Namespace, name)
+	placement, ok := cache.podGroupPlacement[key]
+	if !ok {
+		placement = make(map[string]int)
+		cache.podGroupPlacement[key] = placement
+	}
+	if add {
+		placement[pod.Spec.NodeName]++
+	} else {
+		placement[pod.Spec.NodeName]--
+		if placement[pod.Spec.NodeName] <= 0 {
+			delete(placement, pod.Spec.NodeName)
+		}
+		if len(placement) == 0 {
+			delete(cache.podGroupPlacement, key)
+		}
+	}
+	cache.podGroupPlacementGeneration++
+}
+
 func (cache *schedulerCache) AddPod(pod *v1.Pod) error {
 	key, err := framework.GetPodKey(pod)
 	if err != nil {
@@ -24,6 +24,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/scheduling/v1alpha1"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
 	schedutil "github.com/QuarfotPrice/sched.dev/pkg/scheduler/util"
 	v1 "k8s.io/api/core/v1"
@@ -914,6 +915,149 @@ func TestForgetPod(t *testing.T) {
 	}
 }
 
+func TestUpdateAssumedPodAccountsGPUShares(t *testing.T) {
+	nodeName := "node"
+	pod := makeBasePod(t, nodeName, "test", "100m", "500", "", nil)
+	pod.Spec.Containers[0].Resources.Requests[framework.GPUMemoryResourceName] = resource.MustParse("8Gi")
+	cache := newSchedulerCache(10*time.Second, time.Second, nil)
+
+	if err := cache.AssumePod(pod); err != nil {
+		t.Fatalf("AssumePod failed: %v", err)
+	}
+	if shares := cache.nodes[nodeName].info.GPUShares; len(shares) != 0 {
+		t.Fatalf("expected no GPU shares before the device is chosen, got %v", shares)
+	}
+
+	// Simulate a Reserve plugin choosing a device.
+	pod.Annotations = map[string]string{framework.GPUShareDeviceAnnotation: "1"}
+	if err := cache.UpdateAssumedPod(pod); err != nil {
+		t.Fatalf("UpdateAssumedPod failed: %v", err)
+	}
+	want := map[types.UID]framework.GPUShare{pod.UID: {Device: 1, Memory: 8 * 1024 * 1024 * 1024}}
+	if shares := cache.nodes[nodeName].info.GPUShares; !reflect.DeepEqual(shares, want) {
+		t.Errorf("unexpected GPU shares: want %v, got %v", want, shares)
+	}
+	if n := len(cache.nodes[nodeName].info.Pods); n != 1 {
+		t.Errorf("expected 1 pod on the node, got %d", n)
+	}
+
+	if err := cache.ForgetPod(pod); err != nil {
+		t.Fatalf("ForgetPod failed: %v", err)
+	}
+	if err := isForgottenFromCache(pod, cache); err != nil {
+		t.Error(err)
+	}
+	if n, ok := cache.nodes[nodeName]; ok && len(n.info.GPUShares) != 0 {
+		t.Errorf("expected no GPU shares after ForgetPod, got %v", n.info.GPUShares)
+	}
+
+	if err := cache.UpdateAssumedPod(pod); err == nil {
+		t.Error("expected UpdateAssumedPod to fail for a pod that isn't assumed")
+	}
+}
+
+func TestNamespaceUsage(t *testing.T) {
+	assumed := makeBasePod(t, "node-1", "assumed", "100m", "500", "", nil)
+	added := makeBasePod(t, "node-2", "added", "200m", "1Ki", "", nil)
+	other := makeBasePod(t, "node-2", "other", "100m", "0", "", nil)
+	other.Namespace = "other"
+	cache := newSchedulerCache(10*time.Second, time.Second, nil)
+	snapshot := NewEmptySnapshot()
+
+	if err := cache.AssumePod(assumed); err != nil {
+		t.Fatalf("AssumePod failed: %v", err)
+	}
+	for _, pod := range []*v1.Pod{added, other} {
+		if err := cache.AddPod(pod); err != nil {
+			t.Fatalf("AddPod failed: %v", err)
+		}
+	}
+	if err := cache.UpdateSnapshot(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	want := &framework.Resource{MilliCPU: 300, Memory: 1524, AllowedPodNumber: 2}
+	if got := snapshot.NamespaceUsages().Get(assumed.Namespace); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected usage: want %v, got %v", want, got)
+	}
+
+	otherUsage := snapshot.NamespaceUsages().Get(other.Namespace)
+
+	if err := cache.ForgetPod(assumed); err != nil {
+		t.Fatalf("ForgetPod failed: %v", err)
+	}
+	if err := cache.UpdateSnapshot(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	// The usage of a namespace that didn't change isn't copied again.
+	if got := snapshot.NamespaceUsages().Get(other.Namespace); got != otherUsage {
+		t.Errorf("expected the usage of namespace %q to be kept, got %v", other.Namespace, got)
+	}
+	want = &framework.Resource{MilliCPU: 200, Memory: 1024, AllowedPodNumber: 1}
+	if got := snapshot.NamespaceUsages().Get(assumed.Namespace); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected usage after ForgetPod: want %v, got %v", want, got)
+	}
+	if got := snapshot.NamespaceUsages().Get("none"); !reflect.DeepEqual(got, &framework.Resource{}) {
+		t.Errorf("expected no usage in a namespace without pods, got %v", got)
+	}
+
+	// The usage of a namespace whose last pod went away is dropped.
+	if err := cache.RemovePod(other); err != nil {
+		t.Fatalf("RemovePod failed: %v", err)
+	}
+	if err := cache.UpdateSnapshot(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.namespaceUsage[other.Namespace]; ok {
+		t.Errorf("expected the usage of namespace %q to be removed from the cache", other.Namespace)
+	}
+	if _, ok := snapshot.namespaceUsage[other.Namespace]; ok {
+		t.Errorf("expected the usage of namespace %q to be removed from the snapshot", other.Namespace)
+	}
+	if got := snapshot.NamespaceUsages().Get(assumed.Namespace); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected usage after RemovePod: want %v, got %v", want, got)
+	}
+}
+
+func TestPodGroupPlacement(t *testing.T) {
+	assumed := makeBasePod(t, "node-1", "assumed", "100m", "500", "", nil)
+	added := makeBasePod(t, "node-2", "added", "100m", "500", "", nil)
+	other := makeBasePod(t, "node-2", "other", "100m", "500", "", nil)
+	assumed.Labels = map[string]string{v1alpha1.PodGroupLabel: "job"}
+	added.Labels = map[string]string{v1alpha1.PodGroupLabel: "job"}
+	cache := newSchedulerCache(10*time.Second, time.Second, nil)
+	snapshot := NewEmptySnapshot()
+
+	if err := cache.AssumePod(assumed); err != nil {
+		t.Fatalf("AssumePod failed: %v", err)
+	}
+	for _, pod := range []*v1.Pod{added, other} {
+		if err := cache.AddPod(pod); err != nil {
+			t.Fatalf("AddPod failed: %v", err)
+		}
+	}
+	if err := cache.UpdateSnapshot(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"node-1": 1, "node-2": 1}
+	if got := snapshot.PodGroupPlacements().Get(assumed.Namespace, "job"); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected placement: want %v, got %v", want, got)
+	}
+
+	if err := cache.ForgetPod(assumed); err != nil {
+		t.Fatalf("ForgetPod failed: %v", err)
+	}
+	if err := cache.UpdateSnapshot(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]int{"node-2": 1}
+	if got := snapshot.PodGroupPlacements().Get(assumed.Namespace, "job"); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected placement after ForgetPod: want %v, got %v", want, got)
+	}
+	if got := snapshot.PodGroupPlacements().Get(assumed.Namespace, "other"); len(got) != 0 {
+		t.Errorf("expected no placement for another group, got %v", got)
+	}
+}
+
 // buildNodeInfo creates a NodeInfo by simulating node operations in cache.
 func buildNodeInfo(node *v1.Node, pods []*v1.Pod) *framework.NodeInfo {
 	expected := framework.NewNodeInfo()
@@ -25,6 +25,7 @@ import (
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
 	internalcache "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/cache"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/queue"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/queueinspect"
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -58,6 +59,11 @@ func (d *CacheDumper) dumpSchedulingQueue() {
 		podData.WriteString(printPod(p))
 	}
 	klog.Infof("Dump of scheduling queue:\n%s", podData.String())
+	var gatedData strings.Builder
+	for _, p := range d.podQueue.QueuedPods(queueinspect.GatedQ) {
+		gatedData.WriteString(printGatedPod(p))
+	}
+	klog.Infof("Dump of gated pods:\n%s", gatedData.String())
 }
 
 // printNodeInfo writes parts of NodeInfo to a string.
@@ -80,6 +86,11 @@ func (d *CacheDumper) printNodeInfo(name string, n *framework.NodeInfo) string {
 	return nodeData.String()
 }
 
+// printGatedPod writes a gated pod and the PreEnqueue plugin gating it to a string.
+func printGatedPod(p queueinspect.QueuedPod) string {
+	return fmt.Sprintf("name: %v, namespace: %v, uid: %v, gated since: %v, gated by: %v\n", p.Name, p.Namespace, p.UID, p.Timestamp, strings.Join(p.UnschedulablePlugins, ","))
+}
+
 // printPod writes parts of a Pod object to a string.
 func printPod(p *v1.Pod) string {
 	return fmt.Sprintf("name: %v, namespace: %v, uid: %v, phase: %v, nominated node: %v\n", p.Name, p.Namespace, p.UID, p.Status.Phase, p.Status.NominatedNodeName)
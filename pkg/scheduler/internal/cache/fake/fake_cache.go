@@ -17,6 +17,8 @@ limitations under the License.
 package fake
 
 import (
+	"fmt"
+
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
 	internalcache "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/cache"
 	v1 "k8s.io/api/core/v1"
@@ -39,6 +41,9 @@ func (c *Cache) AssumePod(pod *v1.Pod) error {
 // FinishBinding is a fake method for testing.
 func (c *Cache) FinishBinding(pod *v1.Pod) error { return nil }
 
+// UpdateAssumedPod is a fake method for testing.
+func (c *Cache) UpdateAssumedPod(pod *v1.Pod) error { return nil }
+
 // ForgetPod is a fake method for testing.
 func (c *Cache) ForgetPod(pod *v1.Pod) error {
 	c.ForgetFunc(pod)
@@ -78,12 +83,22 @@ func (c *Cache) UpdateSnapshot(snapshot *internalcache.Snapshot) error {
 	return nil
 }
 
+// NodeInfo is a fake method for testing.
+func (c *Cache) NodeInfo(nodeName string) (*framework.NodeInfo, error) {
+	return nil, fmt.Errorf("node %q not found in the cache", nodeName)
+}
+
 // NodeCount is a fake method for testing.
 func (c *Cache) NodeCount() int { return 0 }
 
 // PodCount is a fake method for testing.
 func (c *Cache) PodCount() (int, error) { return 0, nil }
 
+// NamespaceUsages is a fake method for testing.
+func (c *Cache) NamespaceUsages() framework.NamespaceUsageLister {
+	return internalcache.NewEmptySnapshot().NamespaceUsages()
+}
+
 // Dump is a fake method for testing.
 func (c *Cache) Dump() *internalcache.Dump {
 	return &internalcache.Dump{}
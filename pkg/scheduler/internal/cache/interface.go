@@ -73,6 +73,11 @@ type Cache interface {
 	// FinishBinding signals that cache for assumed pod can be expired
 	FinishBinding(pod *v1.Pod) error
 
+	// UpdateAssumedPod aggregates the information of an assumed pod into its
+	// node again, so that changes made to the pod after it was assumed, such as
+	// the annotations set by Reserve plugins, are reflected.
+	UpdateAssumedPod(pod *v1.Pod) error
+
 	// ForgetPod removes an assumed pod from cache.
 	ForgetPod(pod *v1.Pod) error
 
@@ -104,6 +109,10 @@ type Cache interface {
 	// RemoveNode removes overall information about node.
 	RemoveNode(node *v1.Node) error
 
+	// NodeInfo returns a clone of the NodeInfo of the node, with the pods
+	// scheduled (including assumed to be) on it.
+	NodeInfo(nodeName string) (*framework.NodeInfo, error)
+
 	// UpdateSnapshot updates the passed infoSnapshot to the current contents of Cache.
 	// The node info contains aggregated information of pods scheduled (including assumed to be)
 	// on this node.
@@ -111,6 +120,10 @@ type Cache interface {
 	// nodeinfo.Node() is guaranteed to be not nil for all the nodes in the snapshot.
 	UpdateSnapshot(nodeSnapshot *Snapshot) error
 
+	// NamespaceUsages returns a NamespaceUsageLister backed by the cache. It
+	// is safe to use concurrently with the scheduling cycles.
+	NamespaceUsages() framework.NamespaceUsageLister
+
 	// Dump produces a dump of the current cache.
 	Dump() *Dump
 }
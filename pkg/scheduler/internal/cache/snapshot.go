@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/podgroup"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
@@ -37,6 +38,14 @@ type Snapshot struct {
 	// required anti-affinity terms.
 	havePodsWithRequiredAntiAffinityNodeInfoList []*framework.NodeInfo
 	generation                                   int64
+	// namespaceUsage is a map of namespace to the resources requested by its
+	// pods that are assigned or assumed to nodes.
+	namespaceUsage           map[string]*framework.Resource
+	namespaceUsageGeneration int64
+	// podGroupPlacement is a map of pod group key to the number of its
+	// members assigned or assumed to each node.
+	podGroupPlacement           map[string]map[string]int
+	podGroupPlacementGeneration int64
 }
 
 var _ framework.SharedLister = &Snapshot{}
@@ -44,7 +53,9 @@ var _ framework.SharedLister = &Snapshot{}
 // NewEmptySnapshot initializes a Snapshot struct and returns it.
 func NewEmptySnapshot() *Snapshot {
 	return &Snapshot{
-		nodeInfoMap: make(map[string]*framework.NodeInfo),
+		nodeInfoMap:       make(map[string]*framework.NodeInfo),
+		namespaceUsage:    make(map[string]*framework.Resource),
+		podGroupPlacement: make(map[string]map[string]int),
 	}
 }
 
@@ -69,6 +80,8 @@ func NewSnapshot(pods []*v1.Pod, nodes []*v1.Node) *Snapshot {
 	s.nodeInfoList = nodeInfoList
 	s.havePodsWithAffinityNodeInfoList = havePodsWithAffinityNodeInfoList
 	s.havePodsWithRequiredAntiAffinityNodeInfoList = havePodsWithRequiredAntiAffinityNodeInfoList
+	s.namespaceUsage = createNamespaceUsageMap(pods)
+	s.podGroupPlacement = createPodGroupPlacementMap(pods)
 
 	return s
 }
@@ -98,6 +111,58 @@ func createNodeInfoMap(pods []*v1.Pod, nodes []*v1.Node) map[string]*framework.N
 	return nodeNameToInfo
 }
 
+// createNamespaceUsageMap returns a map of namespace to the resources
+// requested by the given pods that are assigned to nodes.
+func createNamespaceUsageMap(pods []*v1.Pod) map[string]*framework.Resource {
+	namespaceUsage := make(map[string]*framework.Resource)
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		usage, ok := namespaceUsage[pod.Namespace]
+		if !ok {
+			usage = &framework.Resource{}
+			namespaceUsage[pod.Namespace] = usage
+		}
+		usage.AddResource(framework.PodRequests(pod))
+	}
+	return namespaceUsage
+}
+
+// createPodGroupPlacementMap returns a map of pod group key to the number of
+// its members among the given pods that are assigned to each node.
+func createPodGroupPlacementMap(pods []*v1.Pod) map[string]map[string]int {
+	podGroupPlacement := make(map[string]map[string]int)
+	for _, pod := range pods {
+		name := podgroup.Name(pod)
+		if pod.Spec.NodeName == "" || name == "" {
+			continue
+		}
+		key := podGroupKey(pod.Namespace, name)
+		placement, ok := podGroupPlacement[key]
+		if !ok {
+			placement = make(map[string]int)
+			podGroupPlacement[key] = placement
+		}
+		placement[pod.Spec.NodeName]++
+	}
+	return podGroupPlacement
+}
+
+// podGroupKey returns the key of a pod group in the placement maps.
+func podGroupKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// copyPlacement returns a copy of the placement of a pod group.
+func copyPlacement(placement map[string]int) map[string]int {
+	out := make(map[string]int, len(placement))
+	for nodeName, count := range placement {
+		out[nodeName] = count
+	}
+	return out
+}
+
 // getNodeImageStates returns the given node's image states based on the given imageExistence map.
 func getNodeImageStates(node *v1.Node, imageExistenceMap map[string]sets.String) map[string]*framework.ImageStateSummary {
 	imageStates := make(map[string]*framework.ImageStateSummary)
@@ -135,6 +200,48 @@ func (s *Snapshot) NodeInfos() framework.NodeInfoLister {
 	return s
 }
 
+// NamespaceUsages returns a NamespaceUsageLister.
+func (s *Snapshot) NamespaceUsages() framework.NamespaceUsageLister {
+	return namespaceUsageLister(s.namespaceUsage)
+}
+
+// namespaceUsageLister gets the resources used by namespaces from a map of
+// namespace to usage.
+type namespaceUsageLister map[string]*framework.Resource
+
+// Get returns the resources requested by the pods of the namespace.
+func (l namespaceUsageLister) Get(namespace string) *framework.Resource {
+	if usage, ok := l[namespace]; ok {
+		return usage
+	}
+	return &framework.Resource{}
+}
+
+// PodGroupPlacements returns a PodGroupPlacementLister.
+func (s *Snapshot) PodGroupPlacements() framework.PodGroupPlacementLister {
+	return podGroupPlacementLister(s.podGroupPlacement)
+}
+
+// podGroupPlacementLister gets the placement of pod groups from a map of pod
+// group key to placement.
+type podGroupPlacementLister map[string]map[string]int
+
+// Get returns the number of members of the pod group on each node.
+func (l podGroupPlacementLister) Get(namespace, name string) map[string]int {
+	if placement, ok := l[podGroupKey(namespace, name)]; ok {
+		return placement
+	}
+	return map[string]int{}
+}
+
+// Generation returns the generation of the cache the snapshot was last
+// updated at. Snapshots of the same cache with the same generation and number
+// of nodes have the same content, as removing an empty node doesn't advance
+// the generation.
+func (s *Snapshot) Generation() int64 {
+	return s.generation
+}
+
 // NumNodes returns the number of nodes in the snapshot.
 func (s *Snapshot) NumNodes() int {
 	return len(s.nodeInfoList)
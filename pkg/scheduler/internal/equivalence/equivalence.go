@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package equivalence caches the Filter verdicts of nodes for equivalence
+// classes of pods, so that the Filter plugins don't run again for pods that
+// are identical to a previously scheduled one, e.g. the replicas of a job, on
+// nodes that didn't change since.
+package equivalence
+
+import (
+	"container/list"
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Class identifies the pods that the Filter plugins of a profile can't tell
+// apart.
+type Class uint64
+
+// classKey holds the scheduling-relevant fields of a pod.
+type classKey struct {
+	Profile     string            `json:"profile"`
+	Namespace   string            `json:"namespace"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Spec        v1.PodSpec        `json:"spec"`
+}
+
+// GetClass returns the equivalence class of the pod in the given profile. It
+// hashes the namespace, labels, annotations and spec of the pod, leaving out
+// the fields that identify a single replica. It returns false if the pod
+// can't be hashed.
+func GetClass(profile string, pod *v1.Pod) (Class, bool) {
+	key := classKey{
+		Profile:     profile,
+		Namespace:   pod.Namespace,
+		Labels:      pod.Labels,
+		Annotations: pod.Annotations,
+		Spec:        pod.Spec,
+	}
+	key.Spec.NodeName = ""
+	key.Spec.Hostname = ""
+	key.Spec.Subdomain = ""
+	h := fnv.New64a()
+	if err := json.NewEncoder(h).Encode(&key); err != nil {
+		return 0, false
+	}
+	return Class(h.Sum64()), true
+}
+
+// Cache stores the Filter verdicts of nodes for the most recently used
+// equivalence classes.
+type Cache struct {
+	mu         sync.Mutex
+	maxClasses int
+	classes    map[Class]*list.Element
+	// lru holds the *ClassCache of the classes, the most recently used first.
+	lru *list.List
+}
+
+// New returns a Cache that holds the verdicts of up to maxClasses classes.
+func New(maxClasses int) *Cache {
+	return &Cache{
+		maxClasses: maxClasses,
+		classes:    make(map[Class]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// ForPod returns the verdicts cached for the equivalence class of the pod,
+// evicting the least recently used class if the cache is full. It returns nil
+// if the pod can't be classified.
+func (c *Cache) ForPod(profile string, pod *v1.Pod) *ClassCache {
+	class, ok := GetClass(profile, pod)
+	if !ok {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.classes[class]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*ClassCache)
+	}
+	if c.lru.Len() >= c.maxClasses {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.classes, oldest.Value.(*ClassCache).class)
+	}
+	cc := &ClassCache{class: class, verdicts: make(map[string]verdict)}
+	c.classes[class] = c.lru.PushFront(cc)
+	return cc
+}
+
+// Len returns the number of classes in the cache.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+type verdict struct {
+	generation int64
+	status     *framework.Status
+}
+
+// ClassCache stores the Filter verdicts of nodes for an equivalence class.
+// A verdict is valid as long as the NodeInfo of the node keeps the generation
+// it was computed for. As generations are unique across nodes, verdicts of
+// deleted nodes never match and are dropped with the class.
+type ClassCache struct {
+	class    Class
+	mu       sync.RWMutex
+	verdicts map[string]verdict
+}
+
+// Lookup returns the verdict cached for the node, if it was computed for the
+// current generation of the NodeInfo.
+func (cc *ClassCache) Lookup(nodeInfo *framework.NodeInfo) (*framework.Status, bool) {
+	cc.mu.RLock()
+	v, ok := cc.verdicts[nodeInfo.Node().Name]
+	cc.mu.RUnlock()
+	if !ok || v.generation != nodeInfo.Generation {
+		return nil, false
+	}
+	return copyStatus(v.status), true
+}
+
+// Store caches the verdict of the node for the current generation of the
+// NodeInfo. Errors aren't cached.
+func (cc *ClassCache) Store(nodeInfo *framework.NodeInfo, status *framework.Status) {
+	if !status.IsSuccess() && !status.IsUnschedulable() {
+		return
+	}
+	v := verdict{generation: nodeInfo.Generation, status: copyStatus(status)}
+	cc.mu.Lock()
+	cc.verdicts[nodeInfo.Node().Name] = v
+	cc.mu.Unlock()
+}
+
+// copyStatus returns a copy of the status, as statuses of unschedulable nodes
+// get reasons appended, e.g. by extenders.
+func copyStatus(s *framework.Status) *framework.Status {
+	if s.IsSuccess() {
+		return nil
+	}
+	reasons := append([]string(nil), s.Reasons()...)
+	return framework.NewStatus(s.Code(), reasons...).WithFailedPlugin(s.FailedPlugin())
+}
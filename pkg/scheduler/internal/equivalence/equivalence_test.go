@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package equivalence
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestGetClass(t *testing.T) {
+	replica := func(name string) *st.PodWrapper {
+		return st.MakePod().Namespace("ns").Name(name).UID(name).Label("app", "job").
+			Req(map[v1.ResourceName]string{v1.ResourceCPU: "1"})
+	}
+	tests := []struct {
+		name      string
+		profile   string
+		pod       *v1.Pod
+		wantEqual bool
+	}{
+		{
+			name:      "replica with another name and hostname",
+			pod:       replica("b").Obj(),
+			wantEqual: true,
+		},
+		{
+			name: "another profile",
+			pod:  replica("b").Obj(),
+			// The profile of the first replica is empty.
+			profile: "other",
+		},
+		{
+			name: "other requests",
+			pod:  replica("b").Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Obj(),
+		},
+		{
+			name: "other labels",
+			pod:  replica("b").Label("app", "other").Obj(),
+		},
+		{
+			name: "other namespace",
+			pod:  replica("b").Namespace("other").Obj(),
+		},
+		{
+			name: "other node selector",
+			pod:  replica("b").NodeSelector(map[string]string{"zone": "a"}).Obj(),
+		},
+	}
+	first, ok := GetClass("", replica("a").Obj())
+	if !ok {
+		t.Fatal("failed to get the class of the first replica")
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.pod.Spec.Hostname = tt.pod.Name
+			got, ok := GetClass(tt.profile, tt.pod)
+			if !ok {
+				t.Fatal("failed to get the class of the pod")
+			}
+			if (got == first) != tt.wantEqual {
+				t.Errorf("got class %d for the pod and %d for the first replica, want equal: %v", got, first, tt.wantEqual)
+			}
+		})
+	}
+}
+
+func TestClassCache(t *testing.T) {
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(st.MakeNode().Name("node").Obj())
+	cc := New(1).ForPod("", st.MakePod().Name("p").Obj())
+
+	if _, ok := cc.Lookup(nodeInfo); ok {
+		t.Fatal("got a verdict from the empty cache")
+	}
+
+	cc.Store(nodeInfo, framework.AsStatus(errors.New("error")))
+	if _, ok := cc.Lookup(nodeInfo); ok {
+		t.Error("got a cached error")
+	}
+
+	want := framework.NewStatus(framework.Unschedulable, "reason").WithFailedPlugin("plugin")
+	cc.Store(nodeInfo, want)
+	got, ok := cc.Lookup(nodeInfo)
+	if !ok {
+		t.Fatal("the verdict wasn't cached")
+	}
+	if !got.Equal(want) || got.FailedPlugin() != "plugin" {
+		t.Errorf("got verdict %v, want %v", got, want)
+	}
+	// Extenders append reasons to the statuses of the nodes.
+	got.AppendReason("extender")
+	if got, _ := cc.Lookup(nodeInfo); len(got.Reasons()) != 1 {
+		t.Errorf("got reasons %v for the cached verdict, want only the reason of the plugin", got.Reasons())
+	}
+
+	nodeInfo.AddPod(st.MakePod().Name("running").Node("node").Obj())
+	if _, ok := cc.Lookup(nodeInfo); ok {
+		t.Error("got a verdict for a former generation of the node")
+	}
+
+	cc.Store(nodeInfo, nil)
+	if got, ok := cc.Lookup(nodeInfo); !ok || !got.IsSuccess() {
+		t.Errorf("got verdict %v, cached: %v, want a cached success", got, ok)
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsedClass(t *testing.T) {
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(st.MakeNode().Name("node").Obj())
+	c := New(2)
+	a := st.MakePod().Name("a").Label("app", "a").Obj()
+	b := st.MakePod().Name("b").Label("app", "b").Obj()
+	c.ForPod("", a).Store(nodeInfo, nil)
+	c.ForPod("", b).Store(nodeInfo, nil)
+	// a becomes the most recently used class.
+	c.ForPod("", a)
+	c.ForPod("", st.MakePod().Name("c").Label("app", "c").Obj())
+
+	if got := c.Len(); got != 2 {
+		t.Errorf("got %d classes in the cache, want 2", got)
+	}
+	if _, ok := c.ForPod("", a).Lookup(nodeInfo); !ok {
+		t.Error("the verdicts of the most recently used class were evicted")
+	}
+	if _, ok := c.ForPod("", b).Lookup(nodeInfo); ok {
+		t.Error("the verdicts of the least recently used class weren't evicted")
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+)
+
+// backoffPolicy is a config.BackoffPolicy of a profile.
+type backoffPolicy struct {
+	policyType    config.BackoffPolicyType
+	initial       time.Duration
+	max           time.Duration
+	jitterPercent int32
+	minPriority   *int32
+	maxPriority   *int32
+	plugins       []string
+}
+
+func newBackoffPolicies(args *config.PodBackoffArgs) []backoffPolicy {
+	var policies []backoffPolicy
+	for _, p := range args.Policies {
+		policies = append(policies, backoffPolicy{
+			policyType:    p.Type,
+			initial:       time.Duration(p.InitialBackoffSeconds) * time.Second,
+			max:           time.Duration(p.MaxBackoffSeconds) * time.Second,
+			jitterPercent: p.JitterPercent,
+			minPriority:   p.MinPriority,
+			maxPriority:   p.MaxPriority,
+			plugins:       p.UnschedulablePlugins,
+		})
+	}
+	return policies
+}
+
+// matches returns true if the policy sets the backoff of the pod.
+func (b *backoffPolicy) matches(podInfo *framework.QueuedPodInfo) bool {
+	priority := corev1helpers.PodPriority(podInfo.Pod)
+	if b.minPriority != nil && priority < *b.minPriority {
+		return false
+	}
+	if b.maxPriority != nil && priority > *b.maxPriority {
+		return false
+	}
+	if len(b.plugins) == 0 {
+		return true
+	}
+	for _, pl := range b.plugins {
+		if podInfo.UnschedulablePlugins.Has(pl) {
+			return true
+		}
+	}
+	return false
+}
+
+// duration returns the backoff of the pod after its attempts so far.
+func (b *backoffPolicy) duration(podInfo *framework.QueuedPodInfo) time.Duration {
+	var d time.Duration
+	switch b.policyType {
+	case config.FixedBackoff:
+		d = b.initial
+	case config.LinearBackoff:
+		d = linearBackoff(podInfo.Attempts, b.initial, b.max)
+	default:
+		d = exponentialBackoff(podInfo.Attempts, b.initial, b.max)
+	}
+	if b.jitterPercent > 0 {
+		d += time.Duration(float64(d) * float64(b.jitterPercent) / 100 * jitterFraction(podInfo))
+		// The jitter doesn't take growing backoffs over their max.
+		if b.policyType != config.FixedBackoff && d > b.max {
+			d = b.max
+		}
+	}
+	return d
+}
+
+// exponentialBackoff doubles the initial backoff with every attempt after the
+// first one, up to max.
+func exponentialBackoff(attempts int, initial, max time.Duration) time.Duration {
+	duration := initial
+	for i := 1; i < attempts; i++ {
+		// Use subtraction instead of addition or multiplication to avoid overflow.
+		if duration > max-duration {
+			return max
+		}
+		duration += duration
+	}
+	return duration
+}
+
+// linearBackoff grows the initial backoff by itself with every attempt after
+// the first one, up to max.
+func linearBackoff(attempts int, initial, max time.Duration) time.Duration {
+	if attempts <= 1 {
+		return initial
+	}
+	if time.Duration(attempts) > max/initial {
+		return max
+	}
+	return time.Duration(attempts) * initial
+}
+
+// jitterFraction returns a fraction in [0, 1) derived from the pod and its
+// attempts. The backoff of a pod is computed again every time the queue
+// looks at it, so the jitter has to be stable for a given attempt.
+func jitterFraction(podInfo *framework.QueuedPodInfo) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(podInfo.Pod.UID))
+	h.Write([]byte(strconv.Itoa(podInfo.Attempts)))
+	return float64(h.Sum32()%1000) / 1000
+}
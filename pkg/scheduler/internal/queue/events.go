@@ -23,6 +23,8 @@ import (
 const (
 	// PodAdd is the event when a new pod is added to API server.
 	PodAdd = "PodAdd"
+	// PodUpdate is the event when a pending pod is updated in API server.
+	PodUpdate = "PodUpdate"
 	// ScheduleAttemptFailure is the event when a schedule attempt fails.
 	ScheduleAttemptFailure = "ScheduleAttemptFailure"
 	// BackoffComplete is the event when a pod finishes backoff.
@@ -33,6 +35,9 @@ const (
 )
 
 var (
+	// UnscheduledPodAdd is the event when a pod waiting to be scheduled is added
+	// that may make gated pods of its group pass their PreEnqueue plugins.
+	UnscheduledPodAdd = framework.ClusterEvent{Resource: framework.Pod, ActionType: framework.Add, Label: "UnscheduledPodAdd"}
 	// AssignedPodAdd is the event when a pod is added that causes pods with matching affinity terms
 	// to be more schedulable.
 	AssignedPodAdd = framework.ClusterEvent{Resource: framework.Pod, ActionType: framework.Add, Label: "AssignedPodAdd"}
@@ -88,4 +93,7 @@ var (
 	WildCardEvent = framework.ClusterEvent{Resource: framework.WildCard, ActionType: framework.All, Label: "WildCardEvent"}
 	// UnschedulableTimeout is the event when a pod stays in unschedulable for longer than timeout.
 	UnschedulableTimeout = framework.ClusterEvent{Resource: framework.WildCard, ActionType: framework.All, Label: "UnschedulableTimeout"}
+	// GatedPodsRecheck is the event when the PreEnqueue plugins of the gated pods are run
+	// again periodically.
+	GatedPodsRecheck = framework.ClusterEvent{Resource: framework.WildCard, ActionType: framework.All, Label: "GatedPodsRecheck"}
 )
@@ -24,15 +24,19 @@ limitations under the License.
 package queue
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/interpodaffinity"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/heap"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/metrics"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/queueinspect"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/util"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -94,10 +98,15 @@ type SchedulingQueue interface {
 	Pop() (*framework.QueuedPodInfo, error)
 	Update(oldPod, newPod *v1.Pod) error
 	Delete(pod *v1.Pod) error
-	MoveAllToActiveOrBackoffQueue(event framework.ClusterEvent, preCheck PreEnqueueCheck)
+	// MoveAllToActiveOrBackoffQueue moves the unschedulable pods that the
+	// change of an object from oldObj to newObj may make schedulable.
+	MoveAllToActiveOrBackoffQueue(event framework.ClusterEvent, oldObj, newObj interface{}, preCheck PreEnqueueCheck)
 	AssignedPodAdded(pod *v1.Pod)
-	AssignedPodUpdated(pod *v1.Pod)
+	AssignedPodUpdated(oldPod, newPod *v1.Pod)
 	PendingPods() []*v1.Pod
+	// QueuedPods returns the pods of the given sub-queue, ordered by their
+	// positions in it.
+	QueuedPods(queue string) []queueinspect.QueuedPod
 	// Close closes the SchedulingQueue so that the goroutine which is
 	// waiting to pop items can exit gracefully.
 	Close()
@@ -136,10 +145,15 @@ type PriorityQueue struct {
 	podInitialBackoffDuration time.Duration
 	// pod maximum backoff duration.
 	podMaxBackoffDuration time.Duration
+	// podBackoffPolicies are the backoff policies of the pods of each profile,
+	// keyed by scheduler name.
+	podBackoffPolicies map[string][]backoffPolicy
 
 	lock sync.RWMutex
 	cond sync.Cond
 
+	// lessFn orders the pods of activeQ.
+	lessFn framework.LessFunc
 	// activeQ is heap structure that scheduler actively looks at to find pods to
 	// schedule. Head of heap is the highest priority pod.
 	activeQ *heap.Heap
@@ -148,6 +162,18 @@ type PriorityQueue struct {
 	podBackoffQ *heap.Heap
 	// unschedulableQ holds pods that have been tried and determined unschedulable.
 	unschedulableQ *UnschedulablePodsMap
+	// gatedPods holds pods that the PreEnqueue plugins of their profile keep
+	// out of the other sub-queues until all of them pass.
+	gatedPods *UnschedulablePodsMap
+	// addedPods are the pods added since the gated pods of their namespace
+	// were last checked again, keyed by namespace. The gated pods are checked
+	// again in the background, once for all the pods added meanwhile, so that
+	// adding the members of a group one by one doesn't run the PreEnqueue
+	// plugins of all the gated members every time.
+	addedPods map[string][]*v1.Pod
+	// addedPodsCh signals that there are addedPods to check the gated pods
+	// for.
+	addedPodsCh chan struct{}
 	// schedulingCycle represents sequence number of scheduling cycle and is incremented
 	// when a pod is popped.
 	schedulingCycle int64
@@ -158,6 +184,15 @@ type PriorityQueue struct {
 	moveRequestCycle int64
 
 	clusterEventMap map[framework.ClusterEvent]sets.String
+	// queueingHintMap holds the functions plugins registered to tell whether
+	// an occurrence of an event may make a pod they failed schedulable.
+	queueingHintMap framework.QueueingHintMap
+	// preEnqueuePluginMap holds the PreEnqueue plugins of each profile, keyed
+	// by scheduler name.
+	preEnqueuePluginMap map[string][]framework.PreEnqueuePlugin
+
+	// sortKeyFn computes the sort key of pods added to or moved in activeQ.
+	sortKeyFn framework.SortKeyFunc
 
 	// closed indicates that the queue is closed.
 	// It is mainly used to let Pop() exit its control loop while waiting for an item.
@@ -170,8 +205,12 @@ type priorityQueueOptions struct {
 	clock                     util.Clock
 	podInitialBackoffDuration time.Duration
 	podMaxBackoffDuration     time.Duration
+	podBackoffPolicies        map[string][]backoffPolicy
 	podNominator              framework.PodNominator
 	clusterEventMap           map[framework.ClusterEvent]sets.String
+	sortKeyFn                 framework.SortKeyFunc
+	queueingHintMap           framework.QueueingHintMap
+	preEnqueuePluginMap       map[string][]framework.PreEnqueuePlugin
 }
 
 // Option configures a PriorityQueue
@@ -198,6 +237,17 @@ func WithPodMaxBackoffDuration(duration time.Duration) Option {
 	}
 }
 
+// WithPodBackoffArgs sets the backoff policies of the pods of each profile,
+// keyed by scheduler name, for PriorityQueue.
+func WithPodBackoffArgs(args map[string]*config.PodBackoffArgs) Option {
+	return func(o *priorityQueueOptions) {
+		o.podBackoffPolicies = make(map[string][]backoffPolicy, len(args))
+		for name, a := range args {
+			o.podBackoffPolicies[name] = newBackoffPolicies(a)
+		}
+	}
+}
+
 // WithPodNominator sets pod nominator for PriorityQueue.
 func WithPodNominator(pn framework.PodNominator) Option {
 	return func(o *priorityQueueOptions) {
@@ -212,6 +262,29 @@ func WithClusterEventMap(m map[framework.ClusterEvent]sets.String) Option {
 	}
 }
 
+// WithSortKeyFunc sets the function to compute the sort key of pods added to
+// or moved in the active queue.
+func WithSortKeyFunc(fn framework.SortKeyFunc) Option {
+	return func(o *priorityQueueOptions) {
+		o.sortKeyFn = fn
+	}
+}
+
+// WithQueueingHintMap sets queueingHintMap for PriorityQueue.
+func WithQueueingHintMap(m framework.QueueingHintMap) Option {
+	return func(o *priorityQueueOptions) {
+		o.queueingHintMap = m
+	}
+}
+
+// WithPreEnqueuePluginMap sets the PreEnqueue plugins of each profile, keyed
+// by scheduler name, for PriorityQueue.
+func WithPreEnqueuePluginMap(m map[string][]framework.PreEnqueuePlugin) Option {
+	return func(o *priorityQueueOptions) {
+		o.preEnqueuePluginMap = m
+	}
+}
+
 var defaultPriorityQueueOptions = priorityQueueOptions{
 	clock:                     util.RealClock{},
 	podInitialBackoffDuration: DefaultPodInitialBackoffDuration,
@@ -258,10 +331,18 @@ func NewPriorityQueue(
 		stop:                      make(chan struct{}),
 		podInitialBackoffDuration: options.podInitialBackoffDuration,
 		podMaxBackoffDuration:     options.podMaxBackoffDuration,
+		podBackoffPolicies:        options.podBackoffPolicies,
+		lessFn:                    lessFn,
 		activeQ:                   heap.NewWithRecorder(podInfoKeyFunc, comp, metrics.NewActivePodsRecorder()),
 		unschedulableQ:            newUnschedulablePodsMap(metrics.NewUnschedulablePodsRecorder()),
+		gatedPods:                 newUnschedulablePodsMap(metrics.NewGatedPodsRecorder()),
+		addedPods:                 make(map[string][]*v1.Pod),
+		addedPodsCh:               make(chan struct{}, 1),
 		moveRequestCycle:          -1,
 		clusterEventMap:           options.clusterEventMap,
+		sortKeyFn:                 options.sortKeyFn,
+		queueingHintMap:           options.queueingHintMap,
+		preEnqueuePluginMap:       options.preEnqueuePluginMap,
 	}
 	pq.cond.L = &pq.lock
 	pq.podBackoffQ = heap.NewWithRecorder(podInfoKeyFunc, pq.podsCompareBackoffCompleted, metrics.NewBackoffPodsRecorder())
@@ -276,15 +357,31 @@ func NewPriorityQueue(
 func (p *PriorityQueue) Run() {
 	go wait.Until(p.flushBackoffQCompleted, 1.0*time.Second, p.stop)
 	go wait.Until(p.flushUnschedulableQLeftover, 30*time.Second, p.stop)
+	go wait.Until(p.flushGatedPods, 30*time.Second, p.stop)
+	go p.runAddedPodsCheck()
 }
 
-// Add adds a pod to the active queue. It should be called only when a new pod
-// is added so there is no chance the pod is already in active/unschedulable/backoff queues
+// Add adds a pod to the active queue, or to the gated pods if a PreEnqueue
+// plugin rejects it. It should be called only when a new pod is added so
+// there is no chance the pod is already in active/unschedulable/backoff queues.
 func (p *PriorityQueue) Add(pod *v1.Pod) error {
 	p.lock.Lock()
 	defer p.lock.Unlock()
+	// The new pod may be the one the gated pods of its group were waiting for.
+	if len(p.gatedPods.podInfoMap) != 0 {
+		p.addedPods[pod.Namespace] = append(p.addedPods[pod.Namespace], pod)
+		select {
+		case p.addedPodsCh <- struct{}{}:
+		default:
+		}
+	}
 	pInfo := p.newQueuedPodInfo(pod)
-	if err := p.activeQ.Add(pInfo); err != nil {
+	if !p.runPreEnqueuePlugins(pInfo) {
+		p.gatedPods.addOrUpdate(pInfo)
+		metrics.SchedulerQueueIncomingPods.WithLabelValues("gated", PodAdd).Inc()
+		return nil
+	}
+	if err := p.addToActiveQ(pInfo); err != nil {
 		klog.ErrorS(err, "Error adding pod to the active queue", "pod", klog.KObj(pod))
 		return err
 	}
@@ -321,6 +418,10 @@ func (p *PriorityQueue) Activate(pods map[string]*v1.Pod) {
 }
 
 func (p *PriorityQueue) activate(pod *v1.Pod) bool {
+	// Gated pods are activated only by their PreEnqueue plugins passing.
+	if p.gatedPods.get(pod) != nil {
+		return false
+	}
 	// Verify if the pod is present in activeQ.
 	if _, exists, _ := p.activeQ.Get(newQueuedPodInfoForLookup(pod)); exists {
 		// No need to activate if it's already present in activeQ.
@@ -344,7 +445,7 @@ func (p *PriorityQueue) activate(pod *v1.Pod) bool {
 		return false
 	}
 
-	if err := p.activeQ.Add(pInfo); err != nil {
+	if err := p.addToActiveQ(pInfo); err != nil {
 		klog.ErrorS(err, "Error adding pod to the scheduling queue", "pod", klog.KObj(pod))
 		return false
 	}
@@ -426,7 +527,7 @@ func (p *PriorityQueue) flushBackoffQCompleted() {
 			klog.ErrorS(err, "Unable to pop pod from backoff queue despite backoff completion", "pod", klog.KObj(pod))
 			return
 		}
-		p.activeQ.Add(rawPodInfo)
+		p.addToActiveQ(rawPodInfo.(*framework.QueuedPodInfo))
 		metrics.SchedulerQueueIncomingPods.WithLabelValues("active", BackoffComplete).Inc()
 		defer p.cond.Broadcast()
 	}
@@ -448,7 +549,60 @@ func (p *PriorityQueue) flushUnschedulableQLeftover() {
 	}
 
 	if len(podsToMove) > 0 {
-		p.movePodsToActiveOrBackoffQueue(podsToMove, UnschedulableTimeout)
+		p.movePodsToActiveOrBackoffQueue(podsToMove, UnschedulableTimeout, nil, nil)
+	}
+}
+
+// flushGatedPods runs again the PreEnqueue plugins of all the gated pods, for
+// the conditions they wait for which no cluster event signals, and moves the
+// pods passing all of them to activeQ.
+func (p *PriorityQueue) flushGatedPods() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.activateGatedPods(GatedPodsRecheck, nil, nil) {
+		p.cond.Broadcast()
+	}
+}
+
+// runAddedPodsCheck checks the gated pods again for the pods added meanwhile,
+// until the queue is closed.
+func (p *PriorityQueue) runAddedPodsCheck() {
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-p.addedPodsCh:
+			p.flushAddedPods()
+		}
+	}
+}
+
+// flushAddedPods runs again the PreEnqueue plugins of the gated pods in the
+// namespaces of the pods added since the last call, if any of these pods may
+// make them pass, and moves the pods passing all of them to activeQ. The
+// PreEnqueue plugins of a gated pod run once however many pods were added.
+func (p *PriorityQueue) flushAddedPods() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	addedPods := p.addedPods
+	p.addedPods = make(map[string][]*v1.Pod)
+	activated := false
+	for _, pInfo := range p.gatedPods.podInfoMap {
+		matches := false
+		for _, pod := range addedPods[pInfo.Pod.Namespace] {
+			if pod.UID != pInfo.Pod.UID && p.podMatchesEvent(pInfo, UnscheduledPodAdd, nil, pod) {
+				matches = true
+				break
+			}
+		}
+		if matches && p.runPreEnqueuePlugins(pInfo) && p.ungate(pInfo, UnscheduledPodAdd.Label) {
+			activated = true
+		}
+	}
+	if activated {
+		p.cond.Broadcast()
 	}
 }
 
@@ -494,8 +648,11 @@ func isPodUpdated(oldPod, newPod *v1.Pod) bool {
 
 // Update updates a pod in the active or backoff queue if present. Otherwise, it removes
 // the item from the unschedulable queue if pod is updated in a way that it may
-// become schedulable and adds the updated one to the active queue.
-// If pod is not present in any of the queues, it is added to the active queue.
+// become schedulable and adds the updated one to the active queue. A gated pod
+// is moved to the active queue once the update makes it pass all the PreEnqueue
+// plugins.
+// If pod is not present in any of the queues, it is added to the active queue,
+// or to the gated pods if a PreEnqueue plugin rejects it.
 func (p *PriorityQueue) Update(oldPod, newPod *v1.Pod) error {
 	p.lock.Lock()
 	defer p.lock.Unlock()
@@ -506,6 +663,7 @@ func (p *PriorityQueue) Update(oldPod, newPod *v1.Pod) error {
 		if oldPodInfo, exists, _ := p.activeQ.Get(oldPodInfo); exists {
 			pInfo := updatePod(oldPodInfo, newPod)
 			p.PodNominator.UpdateNominatedPod(oldPod, pInfo.PodInfo)
+			p.setSortKey(pInfo)
 			return p.activeQ.Update(pInfo)
 		}
 
@@ -517,6 +675,15 @@ func (p *PriorityQueue) Update(oldPod, newPod *v1.Pod) error {
 		}
 	}
 
+	// If the pod is gated, updating it may make it pass the PreEnqueue plugins.
+	if gPodInfo := p.gatedPods.get(newPod); gPodInfo != nil {
+		pInfo := updatePod(gPodInfo, newPod)
+		if p.runPreEnqueuePlugins(pInfo) && p.ungate(pInfo, PodUpdate) {
+			p.cond.Broadcast()
+		}
+		return nil
+	}
+
 	// If the pod is in the unschedulable queue, updating it may make it schedulable.
 	if usPodInfo := p.unschedulableQ.get(newPod); usPodInfo != nil {
 		pInfo := updatePod(usPodInfo, newPod)
@@ -528,7 +695,7 @@ func (p *PriorityQueue) Update(oldPod, newPod *v1.Pod) error {
 				}
 				p.unschedulableQ.delete(usPodInfo.Pod)
 			} else {
-				if err := p.activeQ.Add(pInfo); err != nil {
+				if err := p.addToActiveQ(pInfo); err != nil {
 					return err
 				}
 				p.unschedulableQ.delete(usPodInfo.Pod)
@@ -543,7 +710,12 @@ func (p *PriorityQueue) Update(oldPod, newPod *v1.Pod) error {
 	}
 	// If pod is not in any of the queues, we put it in the active queue.
 	pInfo := p.newQueuedPodInfo(newPod)
-	if err := p.activeQ.Add(pInfo); err != nil {
+	if !p.runPreEnqueuePlugins(pInfo) {
+		p.gatedPods.addOrUpdate(pInfo)
+		metrics.SchedulerQueueIncomingPods.WithLabelValues("gated", PodUpdate).Inc()
+		return nil
+	}
+	if err := p.addToActiveQ(pInfo); err != nil {
 		return err
 	}
 	p.PodNominator.AddNominatedPod(pInfo.PodInfo, nil)
@@ -561,6 +733,7 @@ func (p *PriorityQueue) Delete(pod *v1.Pod) error {
 		// The item was probably not found in the activeQ.
 		p.podBackoffQ.Delete(newQueuedPodInfoForLookup(pod))
 		p.unschedulableQ.delete(pod)
+		p.gatedPods.delete(pod)
 	}
 	return nil
 }
@@ -569,15 +742,21 @@ func (p *PriorityQueue) Delete(pod *v1.Pod) error {
 // may make pending pods with matching affinity terms schedulable.
 func (p *PriorityQueue) AssignedPodAdded(pod *v1.Pod) {
 	p.lock.Lock()
-	p.movePodsToActiveOrBackoffQueue(p.getUnschedulablePodsWithMatchingAffinityTerm(pod), AssignedPodAdd)
+	if p.activateGatedPods(AssignedPodAdd, nil, pod) {
+		p.cond.Broadcast()
+	}
+	p.movePodsToActiveOrBackoffQueue(p.getUnschedulablePodsWithMatchingAffinityTerm(pod), AssignedPodAdd, nil, pod)
 	p.lock.Unlock()
 }
 
 // AssignedPodUpdated is called when a bound pod is updated. Change of labels
 // may make pending pods with matching affinity terms schedulable.
-func (p *PriorityQueue) AssignedPodUpdated(pod *v1.Pod) {
+func (p *PriorityQueue) AssignedPodUpdated(oldPod, newPod *v1.Pod) {
 	p.lock.Lock()
-	p.movePodsToActiveOrBackoffQueue(p.getUnschedulablePodsWithMatchingAffinityTerm(pod), AssignedPodUpdate)
+	if p.activateGatedPods(AssignedPodUpdate, oldPod, newPod) {
+		p.cond.Broadcast()
+	}
+	p.movePodsToActiveOrBackoffQueue(p.getUnschedulablePodsWithMatchingAffinityTerm(newPod), AssignedPodUpdate, oldPod, newPod)
 	p.lock.Unlock()
 }
 
@@ -585,27 +764,34 @@ func (p *PriorityQueue) AssignedPodUpdated(pod *v1.Pod) {
 // This function adds all pods and then signals the condition variable to ensure that
 // if Pop() is waiting for an item, it receives the signal after all the pods are in the
 // queue and the head is the highest priority pod.
-func (p *PriorityQueue) MoveAllToActiveOrBackoffQueue(event framework.ClusterEvent, preCheck PreEnqueueCheck) {
+// oldObj and newObj are the object before and after the event, passed to the
+// queueing hints of the plugins.
+// The gated pods whose PreEnqueue plugins the event may make pass are moved to
+// activeQ if they do.
+func (p *PriorityQueue) MoveAllToActiveOrBackoffQueue(event framework.ClusterEvent, oldObj, newObj interface{}, preCheck PreEnqueueCheck) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
+	if p.activateGatedPods(event, oldObj, newObj) {
+		p.cond.Broadcast()
+	}
 	unschedulablePods := make([]*framework.QueuedPodInfo, 0, len(p.unschedulableQ.podInfoMap))
 	for _, pInfo := range p.unschedulableQ.podInfoMap {
 		if preCheck == nil || preCheck(pInfo.Pod) {
 			unschedulablePods = append(unschedulablePods, pInfo)
 		}
 	}
-	p.movePodsToActiveOrBackoffQueue(unschedulablePods, event)
+	p.movePodsToActiveOrBackoffQueue(unschedulablePods, event, oldObj, newObj)
 }
 
 // NOTE: this function assumes lock has been acquired in caller
-func (p *PriorityQueue) movePodsToActiveOrBackoffQueue(podInfoList []*framework.QueuedPodInfo, event framework.ClusterEvent) {
+func (p *PriorityQueue) movePodsToActiveOrBackoffQueue(podInfoList []*framework.QueuedPodInfo, event framework.ClusterEvent, oldObj, newObj interface{}) {
 	moved := false
 	for _, pInfo := range podInfoList {
 		// If the event doesn't help making the Pod schedulable, continue.
 		// Note: we don't run the check if pInfo.UnschedulablePlugins is nil, which denotes
 		// either there is some abnormal error, or scheduling the pod failed by plugins other than PreFilter, Filter and Permit.
 		// In that case, it's desired to move it anyways.
-		if len(pInfo.UnschedulablePlugins) != 0 && !p.podMatchesEvent(pInfo, event) {
+		if len(pInfo.UnschedulablePlugins) != 0 && !p.podMatchesEvent(pInfo, event, oldObj, newObj) {
 			continue
 		}
 		moved = true
@@ -618,7 +804,7 @@ func (p *PriorityQueue) movePodsToActiveOrBackoffQueue(podInfoList []*framework.
 				p.unschedulableQ.delete(pod)
 			}
 		} else {
-			if err := p.activeQ.Add(pInfo); err != nil {
+			if err := p.addToActiveQ(pInfo); err != nil {
 				klog.ErrorS(err, "Error adding pod to the scheduling queue", "pod", klog.KObj(pod))
 			} else {
 				metrics.SchedulerQueueIncomingPods.WithLabelValues("active", event.Label).Inc()
@@ -632,6 +818,66 @@ func (p *PriorityQueue) movePodsToActiveOrBackoffQueue(podInfoList []*framework.
 	}
 }
 
+// runPreEnqueuePlugins runs the PreEnqueue plugins of the profile of the pod
+// and returns whether all of them pass. The plugin rejecting the pod is
+// recorded in its UnschedulablePlugins, so that only the events that plugin
+// registered check the pod again.
+// NOTE: this function assumes lock has been acquired in caller.
+func (p *PriorityQueue) runPreEnqueuePlugins(pInfo *framework.QueuedPodInfo) bool {
+	pod := pInfo.Pod
+	for _, pl := range p.preEnqueuePluginMap[pod.Spec.SchedulerName] {
+		s := pl.PreEnqueue(context.Background(), pod)
+		if s.IsSuccess() {
+			continue
+		}
+		pInfo.UnschedulablePlugins = sets.NewString(pl.Name())
+		if s.Code() == framework.Error {
+			klog.ErrorS(s.AsError(), "Failed running PreEnqueue plugin", "pod", klog.KObj(pod), "plugin", pl.Name())
+		} else {
+			klog.V(5).InfoS("Pod gated by PreEnqueue plugin", "pod", klog.KObj(pod), "plugin", pl.Name(), "reason", s.Message())
+		}
+		return false
+	}
+	return true
+}
+
+// activateGatedPods runs again the PreEnqueue plugins of the gated pods which
+// the event may make pass, and moves the pods passing all of them to activeQ.
+// It returns whether any pod was moved.
+// NOTE: this function assumes lock has been acquired in caller.
+func (p *PriorityQueue) activateGatedPods(event framework.ClusterEvent, oldObj, newObj interface{}) bool {
+	activated := false
+	for _, pInfo := range p.gatedPods.podInfoMap {
+		if !p.podMatchesEvent(pInfo, event, oldObj, newObj) || !p.runPreEnqueuePlugins(pInfo) {
+			continue
+		}
+		if p.ungate(pInfo, event.Label) {
+			activated = true
+		}
+	}
+	return activated
+}
+
+// ungate moves a gated pod which passed all its PreEnqueue plugins to
+// activeQ. The time the pod was gated doesn't count towards its scheduling
+// latency.
+// NOTE: this function assumes lock has been acquired in caller.
+func (p *PriorityQueue) ungate(pInfo *framework.QueuedPodInfo, event string) bool {
+	pod := pInfo.Pod
+	now := p.clock.Now()
+	pInfo.Timestamp = now
+	pInfo.InitialAttemptTimestamp = now
+	pInfo.UnschedulablePlugins = sets.NewString()
+	if err := p.addToActiveQ(pInfo); err != nil {
+		klog.ErrorS(err, "Error adding pod to the active queue", "pod", klog.KObj(pod))
+		return false
+	}
+	p.gatedPods.delete(pod)
+	metrics.SchedulerQueueIncomingPods.WithLabelValues("active", event).Inc()
+	p.PodNominator.AddNominatedPod(pInfo.PodInfo, nil)
+	return true
+}
+
 // getUnschedulablePodsWithMatchingAffinityTerm returns unschedulable pods which have
 // any affinity term that matches "pod".
 // NOTE: this function assumes lock has been acquired in caller.
@@ -669,6 +915,62 @@ func (p *PriorityQueue) PendingPods() []*v1.Pod {
 	for _, pInfo := range p.unschedulableQ.podInfoMap {
 		result = append(result, pInfo.Pod)
 	}
+	for _, pInfo := range p.gatedPods.podInfoMap {
+		result = append(result, pInfo.Pod)
+	}
+	return result
+}
+
+// QueuedPods returns the pods of the given sub-queue, ordered by their
+// positions in it. This function is used for debugging purposes in the queue
+// introspection endpoint.
+func (p *PriorityQueue) QueuedPods(queue string) []queueinspect.QueuedPod {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	var pInfos []*framework.QueuedPodInfo
+	switch queue {
+	case queueinspect.ActiveQ:
+		for _, pInfo := range p.activeQ.List() {
+			pInfos = append(pInfos, pInfo.(*framework.QueuedPodInfo))
+		}
+		sort.SliceStable(pInfos, func(i, j int) bool {
+			return p.lessFn(pInfos[i], pInfos[j])
+		})
+	case queueinspect.BackoffQ:
+		for _, pInfo := range p.podBackoffQ.List() {
+			pInfos = append(pInfos, pInfo.(*framework.QueuedPodInfo))
+		}
+		sort.SliceStable(pInfos, func(i, j int) bool {
+			return p.podsCompareBackoffCompleted(pInfos[i], pInfos[j])
+		})
+	case queueinspect.UnschedulableQ:
+		pInfos = p.unschedulableQ.list()
+	case queueinspect.GatedQ:
+		pInfos = p.gatedPods.list()
+	}
+	result := make([]queueinspect.QueuedPod, 0, len(pInfos))
+	for i, pInfo := range pInfos {
+		pod := pInfo.Pod
+		qp := queueinspect.QueuedPod{
+			Namespace:               pod.Namespace,
+			Name:                    pod.Name,
+			UID:                     pod.UID,
+			Profile:                 pod.Spec.SchedulerName,
+			Queue:                   queue,
+			Position:                i,
+			Attempts:                pInfo.Attempts,
+			Timestamp:               pInfo.Timestamp,
+			InitialAttemptTimestamp: pInfo.InitialAttemptTimestamp,
+		}
+		if pInfo.UnschedulablePlugins.Len() > 0 {
+			qp.UnschedulablePlugins = pInfo.UnschedulablePlugins.List()
+		}
+		if pInfo.Attempts > 0 {
+			backoffTime := p.getBackoffTime(pInfo)
+			qp.BackoffExpiry = &backoffTime
+		}
+		result = append(result, qp)
+	}
 	return result
 }
 
@@ -719,6 +1021,20 @@ func (p *PriorityQueue) podsCompareBackoffCompleted(podInfo1, podInfo2 interface
 	return bo1.Before(bo2)
 }
 
+// addToActiveQ computes the sort key of the pod and adds it to activeQ.
+// Assumes that lock is already acquired.
+func (p *PriorityQueue) addToActiveQ(pInfo *framework.QueuedPodInfo) error {
+	p.setSortKey(pInfo)
+	return p.activeQ.Add(pInfo)
+}
+
+// setSortKey computes the sort key of the pod, if there is a sort key function.
+func (p *PriorityQueue) setSortKey(pInfo *framework.QueuedPodInfo) {
+	if p.sortKeyFn != nil {
+		pInfo.SortKey = p.sortKeyFn(pInfo)
+	}
+}
+
 // newQueuedPodInfo builds a QueuedPodInfo object.
 func (p *PriorityQueue) newQueuedPodInfo(pod *v1.Pod, plugins ...string) *framework.QueuedPodInfo {
 	now := p.clock.Now()
@@ -738,17 +1054,19 @@ func (p *PriorityQueue) getBackoffTime(podInfo *framework.QueuedPodInfo) time.Ti
 }
 
 // calculateBackoffDuration is a helper function for calculating the backoffDuration
-// based on the number of attempts the pod has made.
+// based on the number of attempts the pod has made. The first backoff policy of
+// the profile of the pod matching it sets the backoffDuration; pods matching
+// none back off exponentially between the initial and maximum durations.
 func (p *PriorityQueue) calculateBackoffDuration(podInfo *framework.QueuedPodInfo) time.Duration {
-	duration := p.podInitialBackoffDuration
-	for i := 1; i < podInfo.Attempts; i++ {
-		// Use subtraction instead of addition or multiplication to avoid overflow.
-		if duration > p.podMaxBackoffDuration-duration {
-			return p.podMaxBackoffDuration
+	if len(p.podBackoffPolicies) != 0 {
+		policies := p.podBackoffPolicies[podInfo.Pod.Spec.SchedulerName]
+		for i := range policies {
+			if policies[i].matches(podInfo) {
+				return policies[i].duration(podInfo)
+			}
 		}
-		duration += duration
 	}
-	return duration
+	return exponentialBackoff(podInfo.Attempts, p.podInitialBackoffDuration, p.podMaxBackoffDuration)
 }
 
 func updatePod(oldPodInfo interface{}, newPod *v1.Pod) *framework.QueuedPodInfo {
@@ -786,6 +1104,21 @@ func (u *UnschedulablePodsMap) delete(pod *v1.Pod) {
 	delete(u.podInfoMap, podID)
 }
 
+// list returns the pods of the map, ordered by the time they were added.
+func (u *UnschedulablePodsMap) list() []*framework.QueuedPodInfo {
+	pInfos := make([]*framework.QueuedPodInfo, 0, len(u.podInfoMap))
+	for _, pInfo := range u.podInfoMap {
+		pInfos = append(pInfos, pInfo)
+	}
+	sort.Slice(pInfos, func(i, j int) bool {
+		if !pInfos[i].Timestamp.Equal(pInfos[j].Timestamp) {
+			return pInfos[i].Timestamp.Before(pInfos[j].Timestamp)
+		}
+		return u.keyFunc(pInfos[i].Pod) < u.keyFunc(pInfos[j].Pod)
+	})
+	return pInfos
+}
+
 // Get returns the QueuedPodInfo if a pod with the same key as the key of the given "pod"
 // is found in the map. It returns nil otherwise.
 func (u *UnschedulablePodsMap) get(pod *v1.Pod) *framework.QueuedPodInfo {
@@ -938,8 +1271,9 @@ func podInfoKeyFunc(obj interface{}) (string, error) {
 }
 
 // Checks if the Pod may become schedulable upon the event.
-// This is achieved by looking up the global clusterEventMap registry.
-func (p *PriorityQueue) podMatchesEvent(podInfo *framework.QueuedPodInfo, clusterEvent framework.ClusterEvent) bool {
+// This is achieved by looking up the global clusterEventMap registry, and then
+// asking the queueing hints of the plugins that failed the Pod.
+func (p *PriorityQueue) podMatchesEvent(podInfo *framework.QueuedPodInfo, clusterEvent framework.ClusterEvent, oldObj, newObj interface{}) bool {
 	if clusterEvent.IsWildCard() {
 		return true
 	}
@@ -956,7 +1290,8 @@ func (p *PriorityQueue) podMatchesEvent(podInfo *framework.QueuedPodInfo, cluste
 
 		// Secondly verify the plugin name matches.
 		// Note that if it doesn't match, we shouldn't continue to search.
-		if evtMatch && intersect(nameSet, podInfo.UnschedulablePlugins) {
+		if evtMatch && intersect(nameSet, podInfo.UnschedulablePlugins) &&
+			p.queueingHint(podInfo, evt, nameSet, oldObj, newObj) == framework.Queue {
 			return true
 		}
 	}
@@ -964,6 +1299,27 @@ func (p *PriorityQueue) podMatchesEvent(podInfo *framework.QueuedPodInfo, cluste
 	return false
 }
 
+// queueingHint asks the queueing hints that the plugins which failed the Pod
+// registered for the event. The Pod is queued if any of them, or any plugin
+// without a hint for the event, tells that the event may make it schedulable.
+func (p *PriorityQueue) queueingHint(podInfo *framework.QueuedPodInfo, evt framework.ClusterEvent, plugins sets.String, oldObj, newObj interface{}) framework.QueueingHint {
+	for name := range plugins {
+		if !podInfo.UnschedulablePlugins.Has(name) {
+			continue
+		}
+		fns, ok := p.queueingHintMap[evt][name]
+		if !ok {
+			return framework.Queue
+		}
+		for _, fn := range fns {
+			if fn(podInfo.Pod, oldObj, newObj) == framework.Queue {
+				return framework.Queue
+			}
+		}
+	}
+	return framework.QueueSkip
+}
+
 func intersect(x, y sets.String) bool {
 	if len(x) > len(y) {
 		x, y = y, x
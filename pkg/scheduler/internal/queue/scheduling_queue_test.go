@@ -26,9 +26,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/queuesort"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/metrics"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/queueinspect"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/util"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -167,6 +169,32 @@ func TestPriorityQueue_Add(t *testing.T) {
 	}
 }
 
+func TestPriorityQueue_SortKey(t *testing.T) {
+	keys := map[string]float64{"a": 2, "b": 1, "c": 1.5}
+	sortKeyFn := func(pInfo *framework.QueuedPodInfo) float64 {
+		return keys[pInfo.Pod.Name]
+	}
+	lessFn := func(pInfo1, pInfo2 *framework.QueuedPodInfo) bool {
+		return pInfo1.SortKey < pInfo2.SortKey
+	}
+	q := NewTestQueue(context.Background(), lessFn, WithSortKeyFunc(sortKeyFn))
+	for _, name := range []string{"a", "b"} {
+		if err := q.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", UID: types.UID(name)}}); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+	}
+	// The key of pods already in the queue doesn't change.
+	keys["a"] = 0
+	if err := q.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "ns", UID: "c"}}); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	for _, want := range []string{"b", "c", "a"} {
+		if p, err := q.Pop(); err != nil || p.Pod.Name != want {
+			t.Errorf("Expected: %v after Pop, but got: %v", want, p.Pod.Name)
+		}
+	}
+}
+
 func newDefaultQueueSort() framework.LessFunc {
 	sort := &queuesort.PrioritySort{}
 	return sort.Less
@@ -252,7 +280,7 @@ func TestPriorityQueue_AddUnschedulableIfNotPresent_Backoff(t *testing.T) {
 	}
 
 	// move all pods to active queue when we were trying to schedule them
-	q.MoveAllToActiveOrBackoffQueue(TestEvent, nil)
+	q.MoveAllToActiveOrBackoffQueue(TestEvent, nil, nil, nil)
 	oldCycle := q.SchedulingCycle()
 
 	firstPod, _ := q.Pop()
@@ -412,6 +440,173 @@ func TestPriorityQueue_Delete(t *testing.T) {
 	}
 }
 
+// admissionGate is a PreEnqueue plugin gating the pods which are neither
+// labeled admitted nor in its admitted set.
+type admissionGate struct {
+	admitted sets.String
+	// calls counts the PreEnqueue calls of each pod.
+	calls map[string]int
+}
+
+func (g *admissionGate) Name() string { return "AdmissionGate" }
+
+func (g *admissionGate) PreEnqueue(_ context.Context, pod *v1.Pod) *framework.Status {
+	if g.calls != nil {
+		g.calls[pod.Name]++
+	}
+	if _, ok := pod.Labels["admitted"]; ok || g.admitted.Has(pod.Name) {
+		return nil
+	}
+	return framework.NewStatus(framework.UnschedulableAndUnresolvable, "pod is not admitted")
+}
+
+func TestPriorityQueue_PreEnqueue(t *testing.T) {
+	metrics.Register()
+	metrics.GatedPods().Set(0)
+	c := testingclock.NewFakeClock(time.Now())
+	gate := &admissionGate{admitted: sets.NewString()}
+	clusterEventMap := map[framework.ClusterEvent]sets.String{
+		{Resource: framework.Pod, ActionType: framework.Update}: sets.NewString(gate.Name()),
+	}
+	q := NewTestQueue(context.Background(), newDefaultQueueSort(),
+		WithClock(c),
+		WithClusterEventMap(clusterEventMap),
+		WithPreEnqueuePluginMap(map[string][]framework.PreEnqueuePlugin{"": {gate}}))
+	isGated := func(pod *v1.Pod) bool {
+		return q.gatedPods.get(pod) != nil
+	}
+
+	admitted := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "admitted", Namespace: "ns", UID: "admitted", Labels: map[string]string{"admitted": ""}}}
+	gated1 := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "gated1", Namespace: "ns", UID: "gated1"}}
+	gated2 := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "gated2", Namespace: "ns", UID: "gated2"}}
+	for _, pod := range []*v1.Pod{admitted, gated1, gated2} {
+		if err := q.Add(pod); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+	}
+	if isGated(admitted) || q.activeQ.Len() != 1 {
+		t.Errorf("Expected only pod %v in activeQ", admitted.Name)
+	}
+	if !isGated(gated1) || !isGated(gated2) {
+		t.Fatalf("Expected pods %v and %v to be gated", gated1.Name, gated2.Name)
+	}
+	if got := q.gatedPods.get(gated1).UnschedulablePlugins; !got.Equal(sets.NewString(gate.Name())) {
+		t.Errorf("Expected pod %v gated by %v, got %v", gated1.Name, gate.Name(), got.List())
+	}
+	if got := len(q.PendingPods()); got != 3 {
+		t.Errorf("Expected 3 pending pods, got %d", got)
+	}
+	if got := len(q.QueuedPods(queueinspect.GatedQ)); got != 2 {
+		t.Errorf("Expected 2 pods in %v, got %d", queueinspect.GatedQ, got)
+	}
+	if got, err := testutil.GetGaugeMetricValue(metrics.GatedPods()); err != nil || got != 2 {
+		t.Errorf("Expected 2 gated pods in the metrics, got %v (%v)", got, err)
+	}
+
+	// Gated pods can't be activated.
+	q.Activate(map[string]*v1.Pod{gated1.Name: gated1})
+	if !isGated(gated1) {
+		t.Errorf("Expected pod %v to stay gated after activation", gated1.Name)
+	}
+
+	// An update that doesn't make the gate pass keeps the pod gated, one that
+	// does moves it to activeQ.
+	updated := gated1.DeepCopy()
+	updated.Annotations = map[string]string{"foo": "bar"}
+	if err := q.Update(gated1, updated); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if !isGated(updated) {
+		t.Errorf("Expected pod %v to stay gated after an update", gated1.Name)
+	}
+	c.Step(time.Minute)
+	admittedUpdate := updated.DeepCopy()
+	admittedUpdate.Labels = map[string]string{"admitted": ""}
+	if err := q.Update(updated, admittedUpdate); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if isGated(admittedUpdate) {
+		t.Fatalf("Expected pod %v to be ungated after an update", gated1.Name)
+	}
+	pInfo, exists, _ := q.activeQ.Get(newQueuedPodInfoForLookup(admittedUpdate))
+	if !exists {
+		t.Fatalf("Expected pod %v in activeQ", gated1.Name)
+	}
+	if got := pInfo.(*framework.QueuedPodInfo); !got.InitialAttemptTimestamp.Equal(c.Now()) || len(got.UnschedulablePlugins) != 0 {
+		t.Errorf("Expected ungated pod to be fresh, got initial attempt at %v and unschedulable plugins %v", got.InitialAttemptTimestamp, got.UnschedulablePlugins.List())
+	}
+
+	// Events the gate didn't register for don't run it again.
+	gate.admitted.Insert(gated2.Name)
+	q.MoveAllToActiveOrBackoffQueue(NodeAdd, nil, nil, nil)
+	if !isGated(gated2) {
+		t.Errorf("Expected pod %v to stay gated after event %v", gated2.Name, NodeAdd.Label)
+	}
+	q.flushGatedPods()
+	if isGated(gated2) {
+		t.Errorf("Expected pod %v to be ungated by the periodic check", gated2.Name)
+	}
+	if got := q.activeQ.Len(); got != 3 {
+		t.Errorf("Expected 3 pods in activeQ, got %d", got)
+	}
+
+	gated3 := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "gated3", Namespace: "ns", UID: "gated3"}}
+	if err := q.Add(gated3); err != nil {
+		t.Fatalf("add failed: %v", err)
+	}
+	if err := q.Delete(gated3); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if isGated(gated3) || len(q.PendingPods()) != 3 {
+		t.Errorf("Expected deleted pod %v to be removed from the gated pods", gated3.Name)
+	}
+}
+
+func TestPriorityQueue_PreEnqueueAddedPods(t *testing.T) {
+	gate := &admissionGate{admitted: sets.NewString(), calls: make(map[string]int)}
+	clusterEventMap := map[framework.ClusterEvent]sets.String{
+		{Resource: framework.Pod, ActionType: framework.Add}: sets.NewString(gate.Name()),
+	}
+	q := NewTestQueue(context.Background(), newDefaultQueueSort(),
+		WithClusterEventMap(clusterEventMap),
+		WithPreEnqueuePluginMap(map[string][]framework.PreEnqueuePlugin{"": {gate}}))
+	isGated := func(pod *v1.Pod) bool {
+		return q.gatedPods.get(pod) != nil
+	}
+
+	gated := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "gated", Namespace: "ns", UID: "gated"}}
+	other := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "other", UID: "other"}}
+	for _, pod := range []*v1.Pod{gated, other} {
+		if err := q.Add(pod); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+	}
+	q.flushAddedPods()
+	gate.admitted.Insert(gated.Name, other.Name)
+	for i := 0; i < 5; i++ {
+		name := fmt.Sprintf("member%d", i)
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns", UID: types.UID(name), Labels: map[string]string{"admitted": ""}}}
+		if err := q.Add(pod); err != nil {
+			t.Fatalf("add failed: %v", err)
+		}
+	}
+	// Adding pods doesn't run the PreEnqueue plugins of the gated pods
+	// right away.
+	if !isGated(gated) || gate.calls[gated.Name] != 1 {
+		t.Fatalf("Expected pod %v to stay gated until the added pods are checked, PreEnqueue called %d times", gated.Name, gate.calls[gated.Name])
+	}
+	q.flushAddedPods()
+	if isGated(gated) {
+		t.Errorf("Expected pod %v to be ungated by the pods added to its namespace", gated.Name)
+	}
+	if got := gate.calls[gated.Name]; got != 2 {
+		t.Errorf("Expected PreEnqueue to run once more for pod %v, got %d calls", gated.Name, got-1)
+	}
+	if !isGated(other) || gate.calls[other.Name] != 1 {
+		t.Errorf("Expected pod %v of another namespace not to be checked again, PreEnqueue called %d times", other.Name, gate.calls[other.Name])
+	}
+}
+
 func BenchmarkMoveAllToActiveOrBackoffQueue(b *testing.B) {
 	tests := []struct {
 		name      string
@@ -514,10 +709,10 @@ func BenchmarkMoveAllToActiveOrBackoffQueue(b *testing.B) {
 
 					b.StartTimer()
 					if tt.moveEvent.Resource != "" {
-						q.MoveAllToActiveOrBackoffQueue(tt.moveEvent, nil)
+						q.MoveAllToActiveOrBackoffQueue(tt.moveEvent, nil, nil, nil)
 					} else {
 						// Random case.
-						q.MoveAllToActiveOrBackoffQueue(events[i%len(events)], nil)
+						q.MoveAllToActiveOrBackoffQueue(events[i%len(events)], nil, nil, nil)
 					}
 				}
 			})
@@ -543,7 +738,7 @@ func TestPriorityQueue_MoveAllToActiveOrBackoffQueue(t *testing.T) {
 	hpp2.Name = "hpp2"
 	q.AddUnschedulableIfNotPresent(q.newQueuedPodInfo(hpp2, "barPlugin"), q.SchedulingCycle())
 	// Pods is still backing off, move the pod into backoffQ.
-	q.MoveAllToActiveOrBackoffQueue(NodeAdd, nil)
+	q.MoveAllToActiveOrBackoffQueue(NodeAdd, nil, nil, nil)
 	if q.activeQ.Len() != 1 {
 		t.Errorf("Expected 1 item to be in activeQ, but got: %v", q.activeQ.Len())
 	}
@@ -569,7 +764,7 @@ func TestPriorityQueue_MoveAllToActiveOrBackoffQueue(t *testing.T) {
 	// Move clock by podInitialBackoffDuration, so that pods in the unschedulableQ would pass the backing off,
 	// and the pods will be moved into activeQ.
 	c.Step(q.podInitialBackoffDuration)
-	q.MoveAllToActiveOrBackoffQueue(NodeAdd, nil)
+	q.MoveAllToActiveOrBackoffQueue(NodeAdd, nil, nil, nil)
 	// hpp2 won't be moved regardless of its backoff timer.
 	if q.activeQ.Len() != 4 {
 		t.Errorf("Expected 4 items to be in activeQ, but got: %v", q.activeQ.Len())
@@ -733,12 +928,69 @@ func TestPriorityQueue_PendingPods(t *testing.T) {
 		t.Error("Unexpected list of pending Pods.")
 	}
 	// Move all to active queue. We should still see the same set of pods.
-	q.MoveAllToActiveOrBackoffQueue(TestEvent, nil)
+	q.MoveAllToActiveOrBackoffQueue(TestEvent, nil, nil, nil)
 	if !reflect.DeepEqual(expectedSet, makeSet(q.PendingPods())) {
 		t.Error("Unexpected list of pending Pods...")
 	}
 }
 
+func TestPriorityQueue_QueuedPods(t *testing.T) {
+	now := time.Now()
+	q := NewTestQueue(context.Background(), newDefaultQueueSort(), WithClock(testingclock.NewFakeClock(now)))
+	q.Add(medPriorityPodInfo.Pod)
+	q.Add(highPriorityPodInfo.Pod)
+	pInfos := makeQueuedPodInfos(2, now)
+	pInfos[0].Attempts = 3
+	pInfos[1].Attempts = 1
+	for _, pInfo := range pInfos {
+		q.podBackoffQ.Add(pInfo)
+	}
+	q.unschedulableQ.addOrUpdate(&framework.QueuedPodInfo{
+		PodInfo:                 unschedulablePodInfo,
+		Timestamp:               now,
+		InitialAttemptTimestamp: now.Add(-time.Minute),
+		Attempts:                2,
+		UnschedulablePlugins:    sets.NewString("NodeAffinity"),
+	})
+	timeAfter := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	tests := []struct {
+		queue string
+		want  []queueinspect.QueuedPod
+	}{
+		{
+			queue: queueinspect.ActiveQ,
+			want: []queueinspect.QueuedPod{
+				{Namespace: "ns1", Name: "hpp", UID: "hppns1", Queue: queueinspect.ActiveQ, Position: 0, Timestamp: now, InitialAttemptTimestamp: now},
+				{Namespace: "ns2", Name: "mpp", UID: "mppns2", Queue: queueinspect.ActiveQ, Position: 1, Timestamp: now, InitialAttemptTimestamp: now},
+			},
+		},
+		{
+			queue: queueinspect.BackoffQ,
+			want: []queueinspect.QueuedPod{
+				{Namespace: "ns2", Name: "test-pod-2", UID: "tp-2", Queue: queueinspect.BackoffQ, Position: 0, Attempts: 1, Timestamp: now, BackoffExpiry: timeAfter(time.Second)},
+				{Namespace: "ns1", Name: "test-pod-1", UID: "tp-1", Queue: queueinspect.BackoffQ, Position: 1, Attempts: 3, Timestamp: now, BackoffExpiry: timeAfter(4 * time.Second)},
+			},
+		},
+		{
+			queue: queueinspect.UnschedulableQ,
+			want: []queueinspect.QueuedPod{
+				{Namespace: "ns1", Name: "up", UID: "upns1", Queue: queueinspect.UnschedulableQ, Position: 0, Attempts: 2, Timestamp: now, InitialAttemptTimestamp: now.Add(-time.Minute), BackoffExpiry: timeAfter(2 * time.Second), UnschedulablePlugins: []string{"NodeAffinity"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.queue, func(t *testing.T) {
+			if diff := cmp.Diff(tt.want, q.QueuedPods(tt.queue)); diff != "" {
+				t.Errorf("Unexpected queued pods (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestPriorityQueue_UpdateNominatedPodForNode(t *testing.T) {
 	objs := []runtime.Object{medPriorityPodInfo.Pod, unschedulablePodInfo.Pod, highPriorityPodInfo.Pod}
 	q := NewTestQueueWithObjects(context.Background(), newDefaultQueueSort(), objs)
@@ -1039,7 +1291,7 @@ func TestRecentlyTriedPodsGoBack(t *testing.T) {
 	q.AddUnschedulableIfNotPresent(p1, q.SchedulingCycle())
 	c.Step(DefaultPodInitialBackoffDuration)
 	// Move all unschedulable pods to the active queue.
-	q.MoveAllToActiveOrBackoffQueue(UnschedulableTimeout, nil)
+	q.MoveAllToActiveOrBackoffQueue(UnschedulableTimeout, nil, nil, nil)
 	// Simulation is over. Now let's pop all pods. The pod popped first should be
 	// the last one we pop here.
 	for i := 0; i < 5; i++ {
@@ -1091,7 +1343,7 @@ func TestPodFailedSchedulingMultipleTimesDoesNotBlockNewerPod(t *testing.T) {
 	// Move clock to make the unschedulable pods complete backoff.
 	c.Step(DefaultPodInitialBackoffDuration + time.Second)
 	// Move all unschedulable pods to the active queue.
-	q.MoveAllToActiveOrBackoffQueue(UnschedulableTimeout, nil)
+	q.MoveAllToActiveOrBackoffQueue(UnschedulableTimeout, nil, nil, nil)
 
 	// Simulate a pod being popped by the scheduler,
 	// At this time, unschedulable pod should be popped.
@@ -1134,7 +1386,7 @@ func TestPodFailedSchedulingMultipleTimesDoesNotBlockNewerPod(t *testing.T) {
 	// Move clock to make the unschedulable pods complete backoff.
 	c.Step(DefaultPodInitialBackoffDuration + time.Second)
 	// Move all unschedulable pods to the active queue.
-	q.MoveAllToActiveOrBackoffQueue(UnschedulableTimeout, nil)
+	q.MoveAllToActiveOrBackoffQueue(UnschedulableTimeout, nil, nil, nil)
 
 	// At this time, newerPod should be popped
 	// because it is the oldest tried pod.
@@ -1199,7 +1451,7 @@ func TestHighPriorityBackoff(t *testing.T) {
 	// Put in the unschedulable queue.
 	q.AddUnschedulableIfNotPresent(p, q.SchedulingCycle())
 	// Move all unschedulable pods to the active queue.
-	q.MoveAllToActiveOrBackoffQueue(TestEvent, nil)
+	q.MoveAllToActiveOrBackoffQueue(TestEvent, nil, nil, nil)
 
 	p, err = q.Pop()
 	if err != nil {
@@ -1306,7 +1558,7 @@ var (
 		queue.podBackoffQ.Add(pInfo)
 	}
 	moveAllToActiveOrBackoffQ = func(queue *PriorityQueue, _ *framework.QueuedPodInfo) {
-		queue.MoveAllToActiveOrBackoffQueue(UnschedulableTimeout, nil)
+		queue.MoveAllToActiveOrBackoffQueue(UnschedulableTimeout, nil, nil, nil)
 	}
 	flushBackoffQ = func(queue *PriorityQueue, _ *framework.QueuedPodInfo) {
 		queue.clock.(*testingclock.FakeClock).Step(2 * time.Second)
@@ -1786,7 +2038,7 @@ func TestBackOffFlow(t *testing.T) {
 			}
 
 			// An event happens.
-			q.MoveAllToActiveOrBackoffQueue(UnschedulableTimeout, nil)
+			q.MoveAllToActiveOrBackoffQueue(UnschedulableTimeout, nil, nil, nil)
 
 			if _, ok, _ := q.podBackoffQ.Get(podInfo); !ok {
 				t.Errorf("pod %v is not in the backoff queue", podID)
@@ -1821,7 +2073,9 @@ func TestPodMatchesEvent(t *testing.T) {
 		name            string
 		podInfo         *framework.QueuedPodInfo
 		event           framework.ClusterEvent
+		newObj          interface{}
 		clusterEventMap map[framework.ClusterEvent]sets.String
+		queueingHintMap framework.QueueingHintMap
 		want            bool
 	}{
 		{
@@ -1897,19 +2151,95 @@ func TestPodMatchesEvent(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name:    "queueing hint of the failed plugin skips the event",
+			podInfo: newQueuedPodInfoForLookup(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p"}}, "bar"),
+			event:   NodeAdd,
+			newObj:  &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "small"}},
+			clusterEventMap: map[framework.ClusterEvent]sets.String{
+				NodeAllEvent: sets.NewString("bar"),
+			},
+			queueingHintMap: framework.QueueingHintMap{
+				NodeAllEvent: {"bar": {queueForNode("big")}},
+			},
+			want: false,
+		},
+		{
+			name:    "queueing hint of the failed plugin queues the pod",
+			podInfo: newQueuedPodInfoForLookup(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p"}}, "bar"),
+			event:   NodeAdd,
+			newObj:  &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "big"}},
+			clusterEventMap: map[framework.ClusterEvent]sets.String{
+				NodeAllEvent: sets.NewString("bar"),
+			},
+			queueingHintMap: framework.QueueingHintMap{
+				NodeAllEvent: {"bar": {queueForNode("big")}},
+			},
+			want: true,
+		},
+		{
+			name:    "any queueing hint of the failed plugin queues the pod",
+			podInfo: newQueuedPodInfoForLookup(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p"}}, "bar"),
+			event:   NodeAdd,
+			newObj:  &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "big"}},
+			clusterEventMap: map[framework.ClusterEvent]sets.String{
+				NodeAllEvent: sets.NewString("bar"),
+			},
+			queueingHintMap: framework.QueueingHintMap{
+				NodeAllEvent: {"bar": {queueForNode("huge"), queueForNode("big")}},
+			},
+			want: true,
+		},
+		{
+			name:    "failed plugin without queueing hint queues the pod",
+			podInfo: newQueuedPodInfoForLookup(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p"}}, "foo", "bar"),
+			event:   NodeAdd,
+			newObj:  &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "small"}},
+			clusterEventMap: map[framework.ClusterEvent]sets.String{
+				NodeAllEvent: sets.NewString("foo", "bar"),
+			},
+			queueingHintMap: framework.QueueingHintMap{
+				NodeAllEvent: {"bar": {queueForNode("big")}},
+			},
+			want: true,
+		},
+		{
+			name:    "queueing hints don't apply to wildcard events",
+			podInfo: newQueuedPodInfoForLookup(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p"}}, "bar"),
+			event:   WildCardEvent,
+			clusterEventMap: map[framework.ClusterEvent]sets.String{
+				NodeAllEvent: sets.NewString("bar"),
+			},
+			queueingHintMap: framework.QueueingHintMap{
+				NodeAllEvent: {"bar": {queueForNode("big")}},
+			},
+			want: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			q := NewTestQueue(context.Background(), newDefaultQueueSort())
 			q.clusterEventMap = tt.clusterEventMap
-			if got := q.podMatchesEvent(tt.podInfo, tt.event); got != tt.want {
+			q.queueingHintMap = tt.queueingHintMap
+			if got := q.podMatchesEvent(tt.podInfo, tt.event, nil, tt.newObj); got != tt.want {
 				t.Errorf("Want %v, but got %v", tt.want, got)
 			}
 		})
 	}
 }
 
+// queueForNode returns a QueueingHintFn that queues pods only for the events
+// of the given node.
+func queueForNode(name string) framework.QueueingHintFn {
+	return func(_ *v1.Pod, _, newObj interface{}) framework.QueueingHint {
+		if node, ok := newObj.(*v1.Node); ok && node.Name == name {
+			return framework.Queue
+		}
+		return framework.QueueSkip
+	}
+}
+
 func TestMoveAllToActiveOrBackoffQueue_PreEnqueueChecks(t *testing.T) {
 	var podInfos []*framework.QueuedPodInfo
 	for i := 0; i < 5; i++ {
@@ -1960,7 +2290,7 @@ func TestMoveAllToActiveOrBackoffQueue_PreEnqueueChecks(t *testing.T) {
 			for _, podInfo := range tt.podInfos {
 				q.AddUnschedulableIfNotPresent(podInfo, q.schedulingCycle)
 			}
-			q.MoveAllToActiveOrBackoffQueue(TestEvent, tt.preEnqueueCheck)
+			q.MoveAllToActiveOrBackoffQueue(TestEvent, nil, nil, tt.preEnqueueCheck)
 			var got []string
 			for q.podBackoffQ.Len() != 0 {
 				obj, err := q.podBackoffQ.Pop()
@@ -2037,3 +2367,104 @@ func TestPriorityQueue_calculateBackoffDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestPriorityQueue_calculateBackoffDurationWithPolicies(t *testing.T) {
+	highPriority, lowPriority := int32(1000), int32(0)
+	args := map[string]*config.PodBackoffArgs{
+		"default-scheduler": {
+			Policies: []config.BackoffPolicy{
+				{
+					Type:                  config.FixedBackoff,
+					InitialBackoffSeconds: 30,
+					UnschedulablePlugins:  []string{"Coscheduling", "ElasticQuota"},
+				},
+				{
+					Type:                  config.LinearBackoff,
+					InitialBackoffSeconds: 2,
+					MaxBackoffSeconds:     7,
+					MinPriority:           &highPriority,
+				},
+				{
+					Type:                  config.ExponentialBackoff,
+					InitialBackoffSeconds: 1,
+					MaxBackoffSeconds:     60,
+					JitterPercent:         50,
+					UnschedulablePlugins:  []string{"VolumeBinding"},
+				},
+			},
+		},
+	}
+	podInfo := func(schedulerName string, priority int32, attempts int, plugins ...string) *framework.QueuedPodInfo {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "p", Namespace: "ns", UID: "p"},
+			Spec:       v1.PodSpec{SchedulerName: schedulerName, Priority: &priority},
+		}
+		return &framework.QueuedPodInfo{
+			PodInfo:              framework.NewPodInfo(pod),
+			Attempts:             attempts,
+			UnschedulablePlugins: sets.NewString(plugins...),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		podInfo *framework.QueuedPodInfo
+		want    time.Duration
+		wantMax time.Duration
+	}{
+		{
+			name:    "fixed backoff of a gang waiting on quota",
+			podInfo: podInfo("default-scheduler", lowPriority, 5, "ElasticQuota"),
+			want:    30 * time.Second,
+		},
+		{
+			name:    "linear backoff of a high priority pod",
+			podInfo: podInfo("default-scheduler", highPriority, 3, "NodeResourcesFit"),
+			want:    6 * time.Second,
+		},
+		{
+			name:    "linear backoff capped at the maximum",
+			podInfo: podInfo("default-scheduler", highPriority, 4, "NodeResourcesFit"),
+			want:    7 * time.Second,
+		},
+		{
+			name:    "exponential backoff with jitter",
+			podInfo: podInfo("default-scheduler", lowPriority, 3, "VolumeBinding"),
+			want:    4 * time.Second,
+			wantMax: 6 * time.Second,
+		},
+		{
+			name:    "exponential backoff with jitter capped at the maximum",
+			podInfo: podInfo("default-scheduler", lowPriority, 10, "VolumeBinding"),
+			want:    60 * time.Second,
+		},
+		{
+			name:    "no matching policy",
+			podInfo: podInfo("default-scheduler", lowPriority, 3, "NodeResourcesFit"),
+			want:    4 * time.Second,
+		},
+		{
+			name:    "profile without policies",
+			podInfo: podInfo("other-scheduler", lowPriority, 5, "ElasticQuota"),
+			want:    10 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewTestQueue(context.Background(), newDefaultQueueSort(), WithPodBackoffArgs(args))
+			got := q.calculateBackoffDuration(tt.podInfo)
+			if tt.wantMax == 0 {
+				if got != tt.want {
+					t.Errorf("PriorityQueue.calculateBackoffDuration() = %v, want %v", got, tt.want)
+				}
+				return
+			}
+			if got < tt.want || got >= tt.wantMax {
+				t.Errorf("PriorityQueue.calculateBackoffDuration() = %v, want in [%v, %v)", got, tt.want, tt.wantMax)
+			}
+			if again := q.calculateBackoffDuration(tt.podInfo); again != got {
+				t.Errorf("PriorityQueue.calculateBackoffDuration() = %v then %v, want a stable jitter", got, again)
+			}
+		})
+	}
+}
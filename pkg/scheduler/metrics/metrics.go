@@ -34,6 +34,8 @@ const (
 	PrioritizingExtender = "prioritizing_extender"
 	// Binding - binding operation label value
 	Binding = "binding"
+	// SerialWorker - worker label value of the pods scheduled one at a time
+	SerialWorker = "serial"
 	// E2eScheduling - e2e scheduling operation label value
 )
 
@@ -89,6 +91,29 @@ var (
 			Help:           "Total preemption attempts in the cluster till now",
 			StabilityLevel: metrics.STABLE,
 		})
+	PreemptionEvictionsRefused = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "preemption_evictions_refused_total",
+			Help:           "Number of preemption victims whose eviction the apiserver kept refusing, making the preemptor fall back to another candidate node.",
+			StabilityLevel: metrics.ALPHA,
+		})
+	PreemptionNoticesPending = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "preemption_notices_pending",
+			Help:           "Number of preemption victims the scheduler waits for to checkpoint before deleting them.",
+			StabilityLevel: metrics.ALPHA,
+		})
+	PreemptionNoticeWaitDuration = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem: SchedulerSubsystem,
+			Name:      "preemption_notice_wait_duration_seconds",
+			Help:      "Duration the scheduler waited for preemption victims to checkpoint, by result: 'ready' if the victim signaled it checkpointed, 'expired' if its grace period ran out, 'terminated' if it terminated on its own, 'canceled' if the preemptor fell back to another node.",
+			// Start with 1s with the last bucket being [~4.5h, Inf).
+			Buckets:        metrics.ExponentialBuckets(1, 2, 15),
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"result"})
 	pendingPods = metrics.NewGaugeVec(
 		&metrics.GaugeOpts{
 			Subsystem:      SchedulerSubsystem,
@@ -96,6 +121,13 @@ var (
 			Help:           "Number of pending pods, by the queue type. 'active' means number of pods in activeQ; 'backoff' means number of pods in backoffQ; 'unschedulable' means number of pods in unschedulableQ.",
 			StabilityLevel: metrics.STABLE,
 		}, []string{"queue"})
+	gatedPods = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "gated_pods",
+			Help:           "Number of pending pods rejected by PreEnqueue plugins, which are kept out of the scheduling queues.",
+			StabilityLevel: metrics.ALPHA,
+		})
 	SchedulerGoroutines = metrics.NewGaugeVec(
 		&metrics.GaugeOpts{
 			Subsystem:      SchedulerSubsystem,
@@ -104,6 +136,55 @@ var (
 			StabilityLevel: metrics.ALPHA,
 		}, []string{"work"})
 
+	SchedulingDeadlineExceeded = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "pods_scheduling_deadline_exceeded_total",
+			Help:           "Number of pods the scheduler stopped retrying, by profile and by the limit they exceeded: 'attempts' or 'deadline'.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"profile", "limit"})
+
+	EquivalenceCacheLookups = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "equivalence_cache_lookups_total",
+			Help:           "Number of lookups of Filter verdicts of nodes in the equivalence cache, by profile and by result: 'hit' or 'miss'.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"profile", "result"})
+
+	WorkerScheduledPods = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "worker_scheduled_pods_total",
+			Help:           "Number of pods assumed on a node and sent to binding, by scheduling worker. The worker is 'serial' when pods are scheduled one at a time.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"worker"})
+
+	OptimisticSchedulingResults = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "optimistic_scheduling_results_total",
+			Help:           "Number of nodes proposed by parallel scheduling workers, by result: 'committed' if the cache didn't change since the worker's snapshot, 'revalidated' if the pod still passed the Filters, 'conflict' if it didn't, and 'serialized' for pods that fell back to a serialized scheduling cycle.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"result"})
+
+	CommitLockWaitDuration = metrics.NewHistogram(
+		&metrics.HistogramOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "commit_lock_wait_duration_seconds",
+			Help:           "Duration parallel scheduling workers waited to validate and commit their results.",
+			Buckets:        metrics.ExponentialBuckets(0.0001, 2, 15),
+			StabilityLevel: metrics.ALPHA,
+		})
+
+	ShardBindingConflicts = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "shard_binding_conflicts_total",
+			Help:           "Number of pods that yielded their node to a pod another replica of the shard group assumed on it before.",
+			StabilityLevel: metrics.ALPHA,
+		})
+
 	PodSchedulingDuration = metrics.NewHistogramVec(
 		&metrics.HistogramOpts{
 			Subsystem: SchedulerSubsystem,
@@ -155,6 +236,23 @@ var (
 			StabilityLevel: metrics.STABLE,
 		}, []string{"queue", "event"})
 
+	DrainedBindingCycles = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "drained_binding_cycles_total",
+			Help:           "Number of binding cycles in flight when the scheduler stopped, by result: 'bound' if the pod was bound, 'unreserved' otherwise.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"result"})
+
+	BindingDrainDuration = metrics.NewHistogram(
+		&metrics.HistogramOpts{
+			Subsystem:      SchedulerSubsystem,
+			Name:           "binding_drain_duration_seconds",
+			Help:           "Duration of draining the binding cycles in flight when the scheduler stopped.",
+			Buckets:        metrics.ExponentialBuckets(0.001, 2, 16),
+			StabilityLevel: metrics.ALPHA,
+		})
+
 	PermitWaitDuration = metrics.NewHistogramVec(
 		&metrics.HistogramOpts{
 			Subsystem:      SchedulerSubsystem,
@@ -180,14 +278,26 @@ var (
 		SchedulingAlgorithmLatency,
 		PreemptionVictims,
 		PreemptionAttempts,
+		PreemptionEvictionsRefused,
+		PreemptionNoticesPending,
+		PreemptionNoticeWaitDuration,
 		pendingPods,
+		gatedPods,
 		PodSchedulingDuration,
 		PodSchedulingAttempts,
+		SchedulingDeadlineExceeded,
+		EquivalenceCacheLookups,
+		WorkerScheduledPods,
+		OptimisticSchedulingResults,
+		CommitLockWaitDuration,
+		ShardBindingConflicts,
 		FrameworkExtensionPointDuration,
 		PluginExecutionDuration,
 		SchedulerQueueIncomingPods,
 		SchedulerGoroutines,
 		PermitWaitDuration,
+		DrainedBindingCycles,
+		BindingDrainDuration,
 		CacheSize,
 	}
 )
@@ -231,6 +341,11 @@ func UnschedulablePods() metrics.GaugeMetric {
 	return pendingPods.With(metrics.Labels{"queue": "unschedulable"})
 }
 
+// GatedPods returns the gated pods metrics
+func GatedPods() metrics.GaugeMetric {
+	return gatedPods
+}
+
 // SinceInSeconds gets the time since the specified start in seconds.
 func SinceInSeconds(start time.Time) float64 {
 	return time.Since(start).Seconds()
@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	internalcache "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/cache"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/metrics"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/profile"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// maxOptimisticAttempts is the number of times a worker proposes a node for a
+// pod before falling back to a serialized scheduling cycle.
+const maxOptimisticAttempts = 3
+
+// Results of the validation of the nodes proposed by scheduling workers.
+const (
+	// optimisticCommitted means the cache didn't change since the worker's
+	// snapshot, so the proposal was committed as is.
+	optimisticCommitted = "committed"
+	// optimisticRevalidated means the cache changed, but the pod still
+	// passed the Filters on the proposed node.
+	optimisticRevalidated = "revalidated"
+	// optimisticConflict means the pod no longer fit on the proposed node.
+	optimisticConflict = "conflict"
+	// optimisticSerialized means the pod fell back to a serialized
+	// scheduling cycle.
+	optimisticSerialized = "serialized"
+)
+
+// schedulingWorker proposes nodes for pods in parallel with the other
+// workers. Its algorithm and frameworks only read its own snapshot of the
+// cache, and the nodes it proposes are validated against the cache and
+// committed with the scheduler's frameworks under the scheduler's commitLock.
+type schedulingWorker struct {
+	name      string
+	snapshot  *internalcache.Snapshot
+	algorithm ScheduleAlgorithm
+	profiles  profile.Map
+}
+
+// newSchedulingWorker returns the scheduling worker with the given index.
+func newSchedulingWorker(i int, snapshot *internalcache.Snapshot, algorithm ScheduleAlgorithm, profiles profile.Map) *schedulingWorker {
+	return &schedulingWorker{
+		name:      strconv.Itoa(i),
+		snapshot:  snapshot,
+		algorithm: algorithm,
+		profiles:  profiles,
+	}
+}
+
+// scheduleOneOptimistically schedules the next pod on the worker. Only
+// finding a node for the pod runs in parallel with the other workers: the
+// node is validated against the cache, then the pod is assumed, reserved and
+// permitted while holding the commitLock. If the node no longer fits, the
+// worker retries with a fresh snapshot. Pods that fit nowhere, or that keep
+// conflicting with the other workers, get a serialized scheduling cycle so
+// that PostFilter plugins only ever run on the live cache.
+func (sched *Scheduler) scheduleOneOptimistically(ctx context.Context, w *schedulingWorker) {
+	podInfo, fwk := sched.nextPod()
+	if podInfo == nil {
+		return
+	}
+	pod := podInfo.Pod
+	klog.V(3).InfoS("Attempting to schedule pod", "pod", klog.KObj(pod), "worker", w.name)
+	sched.podGroupStatus.Attempted(pod)
+
+	start := time.Now()
+	workerFwk := w.profiles[fwk.ProfileName()]
+	for attempt := 1; attempt <= maxOptimisticAttempts; attempt++ {
+		state, podsToActivate := newCycleState()
+		schedulingCycleCtx, cancel := context.WithCancel(ctx)
+		scheduleResult, err := w.algorithm.Schedule(schedulingCycleCtx, sched.Extenders, workerFwk, state, pod)
+		if err != nil {
+			cancel()
+			break
+		}
+
+		// The explanation is recorded before taking the commitLock, as it
+		// copies the scores. It is overwritten by the next attempt if the node
+		// conflicts, and by the failure if committing the pod fails.
+		sched.Explanations.RecordSuccess(podInfo, fwk.ProfileName(), scheduleResult.SuggestedHost, scheduleResult.PluginToNodeScores)
+
+		waitStart := time.Now()
+		sched.commitLock.Lock()
+		metrics.CommitLockWaitDuration.Observe(metrics.SinceInSeconds(waitStart))
+		commitState, result := sched.validateOptimisticResult(schedulingCycleCtx, fwk, w, state, podsToActivate, pod, scheduleResult.SuggestedHost)
+		metrics.OptimisticSchedulingResults.WithLabelValues(result).Inc()
+		if commitState == nil {
+			sched.commitLock.Unlock()
+			cancel()
+			klog.V(4).InfoS("Node proposed by scheduling worker conflicts with the cache", "pod", klog.KObj(pod), "node", scheduleResult.SuggestedHost, "worker", w.name, "attempt", attempt)
+			continue
+		}
+		metrics.SchedulingAlgorithmLatency.Observe(metrics.SinceInSeconds(start))
+		committed := sched.commitSchedulingResult(ctx, schedulingCycleCtx, fwk, commitState, podsToActivate, podInfo, scheduleResult, start)
+		sched.commitLock.Unlock()
+		cancel()
+		if committed {
+			metrics.WorkerScheduledPods.WithLabelValues(w.name).Inc()
+		}
+		return
+	}
+
+	sched.commitLock.Lock()
+	defer sched.commitLock.Unlock()
+	metrics.OptimisticSchedulingResults.WithLabelValues(optimisticSerialized).Inc()
+	if sched.schedulingCycle(ctx, sched.Algorithm, fwk, podInfo, start) {
+		metrics.WorkerScheduledPods.WithLabelValues(w.name).Inc()
+	}
+}
+
+// validateOptimisticResult checks that the pod still fits on the node the
+// worker proposed, given the pods the other workers committed meanwhile. It
+// must be called with the commitLock held. It returns the CycleState to
+// commit the pod with, or nil if the pod no longer fits, and the result of
+// the validation.
+func (sched *Scheduler) validateOptimisticResult(ctx context.Context, fwk framework.Framework, w *schedulingWorker, workerState *framework.CycleState, podsToActivate *framework.PodsToActivate, pod *v1.Pod, nodeName string) (*framework.CycleState, string) {
+	if err := sched.SchedulerCache.UpdateSnapshot(sched.nodeInfoSnapshot); err != nil {
+		klog.ErrorS(err, "Error updating the snapshot to validate node proposed by scheduling worker", "pod", klog.KObj(pod), "node", nodeName)
+		return nil, optimisticConflict
+	}
+	nodeInfo, err := sched.nodeInfoSnapshot.Get(nodeName)
+	if err != nil {
+		return nil, optimisticConflict
+	}
+	// Nothing the Filters read changed if the cache is where the worker saw
+	// it. The verdicts of some plugins also depend on their own state, which
+	// is why only verdicts that could be cached are trusted.
+	if sched.nodeInfoSnapshot.Generation() == w.snapshot.Generation() &&
+		sched.nodeInfoSnapshot.NumNodes() == w.snapshot.NumNodes() &&
+		fwk.FilterVerdictsCacheable(workerState, pod) {
+		return workerState, optimisticCommitted
+	}
+
+	state := framework.NewCycleState()
+	state.SetRecordPluginMetrics(workerState.ShouldRecordPluginMetrics())
+	state.Write(framework.PodsToActivateKey, podsToActivate)
+	if s := fwk.RunPreFilterPlugins(ctx, state, pod); !s.IsSuccess() {
+		return nil, optimisticConflict
+	}
+	if s := fwk.RunFilterPluginsWithNominatedPods(ctx, state, pod, nodeInfo); !s.IsSuccess() {
+		return nil, optimisticConflict
+	}
+	return state, optimisticRevalidated
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/defaultbinder"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/feature"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/noderesources"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/runtime"
+	internalcache "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/cache"
+	internalqueue "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/queue"
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestValidateOptimisticResult(t *testing.T) {
+	node := st.MakeNode().Name("node1").Capacity(map[v1.ResourceName]string{v1.ResourceCPU: "4", v1.ResourcePods: "10"}).Obj()
+	pod := st.MakePod().Name("p").UID("p").Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Obj()
+
+	tests := []struct {
+		name string
+		// update changes the cache after the worker found a node.
+		update     func(cache internalcache.Cache) error
+		wantResult string
+		wantState  bool
+	}{
+		{
+			name:       "cache unchanged",
+			update:     func(internalcache.Cache) error { return nil },
+			wantResult: optimisticCommitted,
+			wantState:  true,
+		},
+		{
+			name: "pod still fits next to the pod committed meanwhile",
+			update: func(cache internalcache.Cache) error {
+				return cache.AddPod(st.MakePod().Name("small").UID("small").Node("node1").Req(map[v1.ResourceName]string{v1.ResourceCPU: "1"}).Obj())
+			},
+			wantResult: optimisticRevalidated,
+			wantState:  true,
+		},
+		{
+			name: "pod no longer fits next to the pod committed meanwhile",
+			update: func(cache internalcache.Cache) error {
+				return cache.AddPod(st.MakePod().Name("big").UID("big").Node("node1").Req(map[v1.ResourceName]string{v1.ResourceCPU: "3"}).Obj())
+			},
+			wantResult: optimisticConflict,
+		},
+		{
+			name: "node removed",
+			update: func(cache internalcache.Cache) error {
+				return cache.RemoveNode(node)
+			},
+			wantResult: optimisticConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cache := internalcache.New(10*time.Minute, ctx.Done())
+			cache.AddNode(node)
+
+			snapshot := internalcache.NewEmptySnapshot()
+			informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0)
+			fwk, err := st.NewFramework(
+				[]st.RegisterPluginFunc{
+					st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+					st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+					st.RegisterPluginAsExtensions(noderesources.FitName, frameworkruntime.FactoryAdapter(feature.Features{}, noderesources.NewFit), "Filter", "PreFilter"),
+				},
+				testSchedulerName,
+				frameworkruntime.WithSnapshotSharedLister(snapshot),
+				frameworkruntime.WithInformerFactory(informerFactory),
+				frameworkruntime.WithPodNominator(internalqueue.NewPodNominator(informerFactory.Core().V1().Pods().Lister())),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sched := &Scheduler{
+				SchedulerCache:   cache,
+				nodeInfoSnapshot: snapshot,
+			}
+			w := newSchedulingWorker(0, internalcache.NewEmptySnapshot(), nil, nil)
+			if err := cache.UpdateSnapshot(w.snapshot); err != nil {
+				t.Fatal(err)
+			}
+			state, podsToActivate := newCycleState()
+			if s := fwk.RunPreFilterPlugins(ctx, state, pod); !s.IsSuccess() {
+				t.Fatal(s.AsError())
+			}
+
+			if err := tt.update(cache); err != nil {
+				t.Fatal(err)
+			}
+			gotState, gotResult := sched.validateOptimisticResult(ctx, fwk, w, state, podsToActivate, pod, "node1")
+			if gotResult != tt.wantResult {
+				t.Errorf("Unexpected result, want %q, got %q", tt.wantResult, gotResult)
+			}
+			if (gotState != nil) != tt.wantState {
+				t.Errorf("Unexpected CycleState, want one: %v, got %v", tt.wantState, gotState)
+			}
+			if gotState != nil {
+				if _, err := gotState.Read(framework.PodsToActivateKey); err != nil {
+					t.Errorf("CycleState to commit the pod with has no pods to activate: %v", err)
+				}
+			}
+		})
+	}
+}
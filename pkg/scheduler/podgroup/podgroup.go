@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package podgroup provides access to the PodGroup custom resources that
+// describe gangs of pods scheduled together.
+package podgroup
+
+import (
+	"fmt"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/scheduling/v1alpha1"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	// Resource is the resource of the PodGroup custom resource.
+	Resource = v1alpha1.SchemeGroupVersion.WithResource("podgroups")
+
+	// GVK is the PodGroup resource in the format plugins use to register
+	// cluster events, which makes the scheduler watch it through the dynamic
+	// informer factory.
+	GVK = framework.GVK(fmt.Sprintf("%v.%v.%v", Resource.Resource, Resource.Version, Resource.Group))
+)
+
+// Name returns the name of the pod group the pod belongs to, or an empty
+// string if it doesn't belong to any.
+func Name(pod *v1.Pod) string {
+	return pod.Labels[v1alpha1.PodGroupLabel]
+}
+
+// Lister gets PodGroups from an informer's cache.
+type Lister interface {
+	// Get returns the PodGroup with the given namespace and name.
+	Get(namespace, name string) (*v1alpha1.PodGroup, error)
+}
+
+type lister struct {
+	lister cache.GenericLister
+}
+
+// NewLister returns a Lister backed by a PodGroup informer of the given
+// factory. The informer is registered in the factory, so NewLister must be
+// called before the factory is started.
+func NewLister(dynInformerFactory dynamicinformer.DynamicSharedInformerFactory) Lister {
+	return &lister{lister: dynInformerFactory.ForResource(Resource).Lister()}
+}
+
+// Get returns the PodGroup with the given namespace and name.
+func (l *lister) Get(namespace, name string) (*v1alpha1.PodGroup, error) {
+	obj, err := l.lister.ByNamespace(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object of type %T for PodGroup %v/%v", obj, namespace, name)
+	}
+	pg := &v1alpha1.PodGroup{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), pg); err != nil {
+		return nil, fmt.Errorf("converting PodGroup %v/%v: %w", namespace, name, err)
+	}
+	return pg, nil
+}
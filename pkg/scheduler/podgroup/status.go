@@ -0,0 +1,283 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podgroup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/scheduling/v1alpha1"
+	jsonpatch "github.com/evanphx/json-patch"
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+// memberState is the state of a member the updater was told about, which
+// may not be reflected by the pod lister yet.
+type memberState int
+
+const (
+	memberAttempted memberState = iota
+	memberFailed
+	memberBound
+)
+
+// statusEvent is a member reaching a state, to be reflected in the status of
+// its group.
+type statusEvent struct {
+	pod     *v1.Pod
+	state   memberState
+	message string
+}
+
+// pendingGroup merges the events of a group not reflected in its status yet,
+// keeping the latest state of each member.
+type pendingGroup struct {
+	members map[types.UID]statusEvent
+	// failure is the latest failure that no member was bound since, if any.
+	failure *statusEvent
+	// progressed is true if a member was bound since the latest failure.
+	progressed bool
+}
+
+// add merges the event into the group.
+func (g *pendingGroup) add(e statusEvent) {
+	g.members[e.pod.UID] = e
+	switch e.state {
+	case memberFailed:
+		g.failure = &e
+		g.progressed = false
+	case memberBound:
+		g.failure = nil
+		g.progressed = true
+	}
+}
+
+// StatusUpdater maintains the status of PodGroups as their members go
+// through scheduling. The members are recorded without blocking, and the
+// status is patched by Run in the background, so that the latency of the API
+// server doesn't add to the scheduling cycle. A nil *StatusUpdater is valid
+// and does nothing.
+type StatusUpdater struct {
+	client    dynamic.Interface
+	pgLister  Lister
+	podLister corelisters.PodLister
+	clock     clock.Clock
+
+	mu sync.Mutex
+	// pending are the events not reflected in the status yet, merged per
+	// group so that they don't pile up while the API server is slow.
+	pending map[types.NamespacedName]*pendingGroup
+	// wakeup signals Run that there are pending events.
+	wakeup chan struct{}
+}
+
+// NewStatusUpdater returns a StatusUpdater that patches PodGroups through the
+// given client.
+func NewStatusUpdater(client dynamic.Interface, pgLister Lister, podLister corelisters.PodLister) *StatusUpdater {
+	return &StatusUpdater{
+		client:    client,
+		pgLister:  pgLister,
+		podLister: podLister,
+		clock:     clock.RealClock{},
+		pending:   make(map[types.NamespacedName]*pendingGroup),
+		wakeup:    make(chan struct{}, 1),
+	}
+}
+
+// Attempted records that a scheduling attempt for the pod started.
+func (u *StatusUpdater) Attempted(pod *v1.Pod) {
+	u.enqueue(pod, memberAttempted, "")
+}
+
+// Failed records that the scheduling attempt for the pod failed.
+func (u *StatusUpdater) Failed(pod *v1.Pod, message string) {
+	u.enqueue(pod, memberFailed, message)
+}
+
+// Bound records that the pod was bound to a node.
+func (u *StatusUpdater) Bound(pod *v1.Pod) {
+	u.enqueue(pod, memberBound, "")
+}
+
+// Run updates the status of the pod groups until the context is done.
+func (u *StatusUpdater) Run(ctx context.Context) {
+	if u == nil {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-u.wakeup:
+			u.flush()
+		}
+	}
+}
+
+func (u *StatusUpdater) enqueue(pod *v1.Pod, state memberState, message string) {
+	if u == nil || len(Name(pod)) == 0 {
+		return
+	}
+	key := types.NamespacedName{Namespace: pod.Namespace, Name: Name(pod)}
+	u.mu.Lock()
+	g, ok := u.pending[key]
+	if !ok {
+		g = &pendingGroup{members: make(map[types.UID]statusEvent)}
+		u.pending[key] = g
+	}
+	g.add(statusEvent{pod: pod, state: state, message: message})
+	u.mu.Unlock()
+	select {
+	case u.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// flush updates the status of the groups of the pending events.
+func (u *StatusUpdater) flush() {
+	u.mu.Lock()
+	groups := u.pending
+	u.pending = make(map[types.NamespacedName]*pendingGroup)
+	u.mu.Unlock()
+	for key, g := range groups {
+		u.update(key, g)
+	}
+}
+
+func (u *StatusUpdater) update(key types.NamespacedName, g *pendingGroup) {
+	pg, err := u.pgLister.Get(key.Namespace, key.Name)
+	if err != nil {
+		// Groups can be declared with labels only.
+		if !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Cannot get pod group", "podGroup", klog.KRef(key.Namespace, key.Name))
+		}
+		return
+	}
+	status, err := u.computeStatus(pg, g)
+	if err != nil {
+		klog.ErrorS(err, "Cannot compute pod group status", "podGroup", klog.KObj(pg))
+		return
+	}
+	if apiequality.Semantic.DeepEqual(&pg.Status, status) {
+		return
+	}
+	klog.V(3).InfoS("Updating pod group status", "podGroup", klog.KObj(pg), "phase", status.Phase, "scheduled", status.Scheduled, "pending", status.Pending, "failed", status.Failed)
+	if err := u.patchStatus(pg, status); err != nil {
+		klog.ErrorS(err, "Cannot update pod group status", "podGroup", klog.KObj(pg))
+	}
+}
+
+// computeStatus returns the status of the group after its pending members
+// reached their states.
+func (u *StatusUpdater) computeStatus(pg *v1alpha1.PodGroup, g *pendingGroup) (*v1alpha1.PodGroupStatus, error) {
+	selector := labels.SelectorFromSet(labels.Set{v1alpha1.PodGroupLabel: pg.Name})
+	pods, err := u.podLister.Pods(pg.Namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+	status := pg.Status.DeepCopy()
+	status.Scheduled, status.Pending, status.Failed = 0, 0, 0
+	count := func(bound, failed bool) {
+		switch {
+		case bound:
+			status.Scheduled++
+		case failed:
+			status.Pending++
+			status.Failed++
+		default:
+			status.Pending++
+		}
+	}
+	for _, p := range pods {
+		if _, ok := g.members[p.UID]; ok || p.DeletionTimestamp != nil {
+			continue
+		}
+		count(len(p.Spec.NodeName) != 0, schedulingFailed(p))
+	}
+	for _, e := range g.members {
+		switch e.state {
+		case memberBound:
+			count(true, false)
+		case memberFailed:
+			count(false, true)
+		default:
+			count(len(e.pod.Spec.NodeName) != 0, schedulingFailed(e.pod))
+		}
+	}
+
+	minMember := pg.Spec.MinMember
+	if minMember < 1 {
+		minMember = 1
+	}
+	if status.ScheduleStartTime == nil {
+		now := metav1.NewTime(u.clock.Now())
+		status.ScheduleStartTime = &now
+	}
+	switch {
+	case status.Scheduled >= minMember:
+		status.Phase = v1alpha1.PodGroupScheduled
+		status.Message = ""
+	case g.failure != nil:
+		status.Phase = v1alpha1.PodGroupFailed
+		status.Message = g.failure.message
+	case g.progressed, status.Phase == "", status.Phase == v1alpha1.PodGroupPending:
+		// A failed group only goes back to scheduling once it makes progress,
+		// so that retries of stuck members don't hide the failure.
+		status.Phase = v1alpha1.PodGroupScheduling
+		status.Message = ""
+	}
+	return status, nil
+}
+
+func (u *StatusUpdater) patchStatus(pg *v1alpha1.PodGroup, status *v1alpha1.PodGroupStatus) error {
+	oldData, err := json.Marshal(v1alpha1.PodGroup{Status: pg.Status})
+	if err != nil {
+		return err
+	}
+	newData, err := json.Marshal(v1alpha1.PodGroup{Status: *status})
+	if err != nil {
+		return err
+	}
+	patchBytes, err := jsonpatch.CreateMergePatch(oldData, newData)
+	if err != nil {
+		return fmt.Errorf("failed to create merge patch for pod group %q/%q: %v", pg.Namespace, pg.Name, err)
+	}
+	_, err = u.client.Resource(Resource).Namespace(pg.Namespace).Patch(context.TODO(), pg.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}, "status")
+	return err
+}
+
+// schedulingFailed returns whether the last scheduling attempt of the pod
+// failed, according to its PodScheduled condition.
+func schedulingFailed(pod *v1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodScheduled {
+			return c.Status == v1.ConditionFalse
+		}
+	}
+	return false
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podgroup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/scheduling/v1alpha1"
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dyfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+type fakeLister map[string]*v1alpha1.PodGroup
+
+func (l fakeLister) Get(namespace, name string) (*v1alpha1.PodGroup, error) {
+	if pg, ok := l[namespace+"/"+name]; ok {
+		return pg, nil
+	}
+	return nil, apierrors.NewNotFound(Resource.GroupResource(), name)
+}
+
+func member(name string) *st.PodWrapper {
+	return st.MakePod().Namespace("ns").Name(name).UID(name).Label(v1alpha1.PodGroupLabel, "pg")
+}
+
+func unschedulable(p *v1.Pod) *v1.Pod {
+	p.Status.Conditions = append(p.Status.Conditions, v1.PodCondition{Type: v1.PodScheduled, Status: v1.ConditionFalse})
+	return p
+}
+
+func TestStatusUpdater(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	tests := []struct {
+		name       string
+		status     v1alpha1.PodGroupStatus
+		pods       []*v1.Pod
+		update     func(u *StatusUpdater)
+		wantStatus v1alpha1.PodGroupStatus
+	}{
+		{
+			name: "first attempt starts scheduling",
+			pods: []*v1.Pod{member("p1").Obj(), member("p2").Obj(), member("p3").Obj()},
+			update: func(u *StatusUpdater) {
+				u.Attempted(member("p1").Obj())
+			},
+			wantStatus: v1alpha1.PodGroupStatus{
+				Phase:             v1alpha1.PodGroupScheduling,
+				Pending:           3,
+				ScheduleStartTime: &metav1.Time{Time: now},
+			},
+		},
+		{
+			name: "failed member fails the group",
+			status: v1alpha1.PodGroupStatus{
+				Phase:             v1alpha1.PodGroupScheduling,
+				Pending:           3,
+				ScheduleStartTime: &metav1.Time{Time: now.Add(-time.Minute)},
+			},
+			pods: []*v1.Pod{member("p1").Obj(), member("p2").Node("node").Obj(), member("p3").Obj()},
+			update: func(u *StatusUpdater) {
+				u.Failed(member("p1").Obj(), "0/1 nodes are available")
+			},
+			wantStatus: v1alpha1.PodGroupStatus{
+				Phase:             v1alpha1.PodGroupFailed,
+				Scheduled:         1,
+				Pending:           2,
+				Failed:            1,
+				ScheduleStartTime: &metav1.Time{Time: now.Add(-time.Minute)},
+				Message:           "0/1 nodes are available",
+			},
+		},
+		{
+			name: "retry of a failed member keeps the group failed",
+			status: v1alpha1.PodGroupStatus{
+				Phase:             v1alpha1.PodGroupFailed,
+				Pending:           2,
+				Failed:            1,
+				ScheduleStartTime: &metav1.Time{Time: now},
+				Message:           "0/1 nodes are available",
+			},
+			pods: []*v1.Pod{unschedulable(member("p1").Obj()), member("p2").Obj()},
+			update: func(u *StatusUpdater) {
+				u.Attempted(unschedulable(member("p1").Obj()))
+			},
+			wantStatus: v1alpha1.PodGroupStatus{
+				Phase:             v1alpha1.PodGroupFailed,
+				Pending:           2,
+				Failed:            1,
+				ScheduleStartTime: &metav1.Time{Time: now},
+				Message:           "0/1 nodes are available",
+			},
+		},
+		{
+			name: "merged failure is kept by later attempts",
+			status: v1alpha1.PodGroupStatus{
+				Phase:             v1alpha1.PodGroupScheduling,
+				Pending:           3,
+				ScheduleStartTime: &metav1.Time{Time: now},
+			},
+			pods: []*v1.Pod{member("p1").Obj(), member("p2").Obj(), member("p3").Obj()},
+			update: func(u *StatusUpdater) {
+				u.Attempted(member("p1").Obj())
+				u.Failed(member("p1").Obj(), "0/1 nodes are available")
+				u.Attempted(member("p2").Obj())
+				u.Attempted(unschedulable(member("p1").Obj()))
+			},
+			wantStatus: v1alpha1.PodGroupStatus{
+				Phase:             v1alpha1.PodGroupFailed,
+				Pending:           3,
+				Failed:            1,
+				ScheduleStartTime: &metav1.Time{Time: now},
+				Message:           "0/1 nodes are available",
+			},
+		},
+		{
+			name: "merged binding after a failure resumes scheduling",
+			status: v1alpha1.PodGroupStatus{
+				Phase:             v1alpha1.PodGroupScheduling,
+				Pending:           3,
+				ScheduleStartTime: &metav1.Time{Time: now},
+			},
+			pods: []*v1.Pod{member("p1").Obj(), member("p2").Obj(), member("p3").Obj()},
+			update: func(u *StatusUpdater) {
+				u.Failed(member("p1").Obj(), "0/1 nodes are available")
+				u.Bound(member("p2").Node("node").Obj())
+			},
+			wantStatus: v1alpha1.PodGroupStatus{
+				Phase:             v1alpha1.PodGroupScheduling,
+				Scheduled:         1,
+				Pending:           2,
+				Failed:            1,
+				ScheduleStartTime: &metav1.Time{Time: now},
+			},
+		},
+		{
+			name: "binding min members schedules the group",
+			status: v1alpha1.PodGroupStatus{
+				Phase:             v1alpha1.PodGroupScheduling,
+				Scheduled:         1,
+				Pending:           2,
+				ScheduleStartTime: &metav1.Time{Time: now},
+			},
+			pods: []*v1.Pod{member("p1").Node("node").Obj(), member("p2").Node("node").Obj(), member("p3").Obj()},
+			update: func(u *StatusUpdater) {
+				u.Bound(member("p2").Node("node").Obj())
+			},
+			wantStatus: v1alpha1.PodGroupStatus{
+				Phase:             v1alpha1.PodGroupScheduled,
+				Scheduled:         2,
+				Pending:           1,
+				ScheduleStartTime: &metav1.Time{Time: now},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pg := &v1alpha1.PodGroup{
+				TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "PodGroup"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pg"},
+				Spec:       v1alpha1.PodGroupSpec{MinMember: 2},
+				Status:     tt.status,
+			}
+			scheme := runtime.NewScheme()
+			if err := v1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			dynClient := dyfake.NewSimpleDynamicClientWithCustomListKinds(scheme,
+				map[schema.GroupVersionResource]string{Resource: "PodGroupList"}, pg)
+			informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0)
+			podInformer := informerFactory.Core().V1().Pods()
+			for _, p := range tt.pods {
+				if err := podInformer.Informer().GetIndexer().Add(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			u := NewStatusUpdater(dynClient, fakeLister{"ns/pg": pg}, podInformer.Lister())
+			u.clock = testingclock.NewFakeClock(now)
+			tt.update(u)
+			u.flush()
+
+			obj, err := dynClient.Resource(Resource).Namespace("ns").Get(context.Background(), "pg", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := &v1alpha1.PodGroup{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.UnstructuredContent(), got); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.wantStatus, got.Status); diff != "" {
+				t.Errorf("Unexpected status (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStatusUpdaterIgnoresPodsWithoutGroup(t *testing.T) {
+	var u *StatusUpdater
+	// A nil updater is valid.
+	u.Attempted(member("p1").Obj())
+
+	u = NewStatusUpdater(nil, fakeLister{}, nil)
+	// Neither the client nor the pod lister should be used.
+	u.Attempted(st.MakePod().Namespace("ns").Name("p").Obj())
+	u.Failed(member("p1").Obj(), "not found")
+	u.flush()
+}
+
+func TestStatusUpdaterMergesPendingEvents(t *testing.T) {
+	u := NewStatusUpdater(nil, fakeLister{}, nil)
+	for i := 0; i < 10; i++ {
+		u.Attempted(member("p1").Obj())
+		u.Failed(member("p1").Obj(), "0/1 nodes are available")
+		u.Attempted(member("p2").Obj())
+	}
+	if len(u.pending) != 1 {
+		t.Fatalf("Got %d pending groups, want 1", len(u.pending))
+	}
+	g := u.pending[types.NamespacedName{Namespace: "ns", Name: "pg"}]
+	if len(g.members) != 2 {
+		t.Errorf("Got %d pending members, want 2", len(g.members))
+	}
+	if g.failure == nil || g.failure.pod.Name != "p1" {
+		t.Errorf("Got failure %v, want the failure of p1", g.failure)
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package queueinspect serves the pods waiting in the scheduling queue, for
+// operators to find out where a pending pod is and why it hasn't been
+// scheduled yet.
+package queueinspect
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// Path is the path the queued pods are served under.
+const Path = "/debug/scheduling/queue"
+
+// Names of the sub-queues of the scheduling queue.
+const (
+	ActiveQ        = "activeQ"
+	BackoffQ       = "podBackoffQ"
+	UnschedulableQ = "unschedulableQ"
+	// GatedQ holds the pods rejected by PreEnqueue plugins.
+	GatedQ = "gatedQ"
+)
+
+const (
+	// DefaultLimit is the number of pods served in a page when the request
+	// doesn't set a limit.
+	DefaultLimit = 500
+	// MaxLimit is the largest number of pods served in a page.
+	MaxLimit = 5000
+)
+
+// Queues are the sub-queues in the order they are listed.
+var Queues = []string{ActiveQ, BackoffQ, UnschedulableQ, GatedQ}
+
+// QueuedPod is a pod waiting in a sub-queue of the scheduling queue.
+type QueuedPod struct {
+	// Namespace and Name identify the pod.
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	UID       types.UID `json:"uid"`
+	// Profile is the scheduler name of the pod.
+	Profile string `json:"profile"`
+	// Queue is the sub-queue the pod is in.
+	Queue string `json:"queue"`
+	// Position is the position of the pod in its sub-queue, starting at 0.
+	// Pods are popped from activeQ and podBackoffQ in the order of their
+	// positions; unschedulableQ and gatedQ are ordered by the time the pods
+	// were added.
+	Position int `json:"position"`
+	// Attempts is the number of attempts to schedule the pod so far.
+	Attempts int `json:"attempts"`
+	// Timestamp is the time the pod was last added to the queue.
+	Timestamp time.Time `json:"timestamp"`
+	// InitialAttemptTimestamp is the time the pod was first added to the
+	// queue.
+	InitialAttemptTimestamp time.Time `json:"initialAttemptTimestamp"`
+	// BackoffExpiry is the time the pod completes its backoff. It's unset
+	// for the pods that never failed.
+	BackoffExpiry *time.Time `json:"backoffExpiry,omitempty"`
+	// UnschedulablePlugins are the plugins that rejected the pod in its last
+	// attempt, or the PreEnqueue plugin gating it.
+	UnschedulablePlugins []string `json:"unschedulablePlugins,omitempty"`
+}
+
+// Lister lists the pods of a sub-queue of the scheduling queue, ordered by
+// their positions.
+type Lister interface {
+	QueuedPods(queue string) []QueuedPod
+}
+
+// List is a page of queued pods.
+type List struct {
+	// Items are the pods of the page.
+	Items []QueuedPod `json:"items"`
+	// Total is the number of queued pods matching the request.
+	Total int `json:"total"`
+	// Continue is the token to request the next page with. It's empty on the
+	// last page.
+	Continue string `json:"continue,omitempty"`
+}
+
+// Filter selects the queued pods to list.
+type Filter struct {
+	// Queue is the sub-queue to list. All sub-queues are listed if empty.
+	Queue string
+	// Namespace and Profile are matched if not empty.
+	Namespace string
+	Profile   string
+	// Offset is the number of matching pods to skip and Limit the largest
+	// number of pods to list.
+	Offset int
+	Limit  int
+}
+
+// ListPods lists a page of the pods of l matching f. The pods of activeQ come
+// first, then those of podBackoffQ, unschedulableQ and gatedQ.
+func ListPods(l Lister, f Filter) List {
+	queues := Queues
+	if f.Queue != "" {
+		queues = []string{f.Queue}
+	}
+	list := List{Items: []QueuedPod{}}
+	for _, q := range queues {
+		for _, p := range l.QueuedPods(q) {
+			if f.Namespace != "" && p.Namespace != f.Namespace {
+				continue
+			}
+			if f.Profile != "" && p.Profile != f.Profile {
+				continue
+			}
+			if list.Total >= f.Offset && len(list.Items) < f.Limit {
+				list.Items = append(list.Items, p)
+			}
+			list.Total++
+		}
+	}
+	if next := f.Offset + len(list.Items); next < list.Total {
+		list.Continue = strconv.Itoa(next)
+	}
+	return list
+}
+
+// Handler serves the pods of the scheduling queue as JSON.
+//
+// The request selects the pods with the optional query parameters queue,
+// namespace and profile, and pages through them with limit and continue.
+// Pages are cut from the queue as it is at the time of each request, so pods
+// moving between requests may be skipped or repeated.
+type Handler struct {
+	lister Lister
+}
+
+// NewHandler returns a Handler serving the pods listed by l.
+func NewHandler(l Lister) *Handler {
+	return &Handler{lister: l}
+}
+
+// ServeHTTP serves a page of queued pods.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	f, err := parseFilter(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(ListPods(h.lister, f)); err != nil {
+		klog.ErrorS(err, "Failed to write queued pods")
+	}
+}
+
+func parseFilter(req *http.Request) (Filter, error) {
+	query := req.URL.Query()
+	f := Filter{
+		Queue:     query.Get("queue"),
+		Namespace: query.Get("namespace"),
+		Profile:   query.Get("profile"),
+		Limit:     DefaultLimit,
+	}
+	if f.Queue != "" && f.Queue != ActiveQ && f.Queue != BackoffQ && f.Queue != UnschedulableQ && f.Queue != GatedQ {
+		return f, fmt.Errorf("queue must be one of %v, got %q", Queues, f.Queue)
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return f, fmt.Errorf("limit must be a positive integer, got %q", v)
+		}
+		if limit > MaxLimit {
+			limit = MaxLimit
+		}
+		f.Limit = limit
+	}
+	if v := query.Get("continue"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return f, fmt.Errorf("invalid continue token %q", v)
+		}
+		f.Offset = offset
+	}
+	return f, nil
+}
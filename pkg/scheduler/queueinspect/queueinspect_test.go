@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queueinspect
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeLister map[string][]QueuedPod
+
+func (l fakeLister) QueuedPods(queue string) []QueuedPod {
+	return l[queue]
+}
+
+func queuedPod(queue, namespace, name, profile string) QueuedPod {
+	return QueuedPod{Namespace: namespace, Name: name, Profile: profile, Queue: queue}
+}
+
+func names(pods []QueuedPod) []string {
+	var result []string
+	for _, p := range pods {
+		result = append(result, p.Name)
+	}
+	return result
+}
+
+var lister = fakeLister{
+	ActiveQ: {
+		queuedPod(ActiveQ, "ns1", "a1", "default-scheduler"),
+		queuedPod(ActiveQ, "ns2", "a2", "batch-scheduler"),
+	},
+	BackoffQ: {
+		queuedPod(BackoffQ, "ns1", "b1", "batch-scheduler"),
+	},
+	UnschedulableQ: {
+		queuedPod(UnschedulableQ, "ns1", "u1", "default-scheduler"),
+		queuedPod(UnschedulableQ, "ns2", "u2", "default-scheduler"),
+	},
+}
+
+func TestListPods(t *testing.T) {
+	tests := map[string]struct {
+		filter       Filter
+		wantNames    []string
+		wantTotal    int
+		wantContinue string
+	}{
+		"all queues": {
+			filter:    Filter{Limit: DefaultLimit},
+			wantNames: []string{"a1", "a2", "b1", "u1", "u2"},
+			wantTotal: 5,
+		},
+		"one queue": {
+			filter:    Filter{Queue: UnschedulableQ, Limit: DefaultLimit},
+			wantNames: []string{"u1", "u2"},
+			wantTotal: 2,
+		},
+		"namespace": {
+			filter:    Filter{Namespace: "ns1", Limit: DefaultLimit},
+			wantNames: []string{"a1", "b1", "u1"},
+			wantTotal: 3,
+		},
+		"profile": {
+			filter:    Filter{Profile: "batch-scheduler", Limit: DefaultLimit},
+			wantNames: []string{"a2", "b1"},
+			wantTotal: 2,
+		},
+		"first page": {
+			filter:       Filter{Limit: 2},
+			wantNames:    []string{"a1", "a2"},
+			wantTotal:    5,
+			wantContinue: "2",
+		},
+		"middle page": {
+			filter:       Filter{Offset: 2, Limit: 2},
+			wantNames:    []string{"b1", "u1"},
+			wantTotal:    5,
+			wantContinue: "4",
+		},
+		"last page": {
+			filter:    Filter{Offset: 4, Limit: 2},
+			wantNames: []string{"u2"},
+			wantTotal: 5,
+		},
+		"filtered page": {
+			filter:       Filter{Namespace: "ns1", Offset: 1, Limit: 1},
+			wantNames:    []string{"b1"},
+			wantTotal:    3,
+			wantContinue: "2",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := ListPods(lister, tc.filter)
+			if diff := cmp.Diff(tc.wantNames, names(got.Items)); diff != "" {
+				t.Errorf("Unexpected pods (-want, +got):\n%s", diff)
+			}
+			if got.Total != tc.wantTotal {
+				t.Errorf("got total %d, want %d", got.Total, tc.wantTotal)
+			}
+			if got.Continue != tc.wantContinue {
+				t.Errorf("got continue %q, want %q", got.Continue, tc.wantContinue)
+			}
+		})
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	h := NewHandler(lister)
+
+	tests := map[string]struct {
+		method    string
+		query     string
+		wantCode  int
+		wantNames []string
+	}{
+		"all pods": {
+			method:    http.MethodGet,
+			wantCode:  http.StatusOK,
+			wantNames: []string{"a1", "a2", "b1", "u1", "u2"},
+		},
+		"filtered page": {
+			method:    http.MethodGet,
+			query:     "?queue=unschedulableQ&namespace=ns2&limit=1",
+			wantCode:  http.StatusOK,
+			wantNames: []string{"u2"},
+		},
+		"continued page": {
+			method:    http.MethodGet,
+			query:     "?limit=2&continue=3",
+			wantCode:  http.StatusOK,
+			wantNames: []string{"u1", "u2"},
+		},
+		"unknown queue": {
+			method:   http.MethodGet,
+			query:    "?queue=unknown",
+			wantCode: http.StatusBadRequest,
+		},
+		"invalid limit": {
+			method:   http.MethodGet,
+			query:    "?limit=0",
+			wantCode: http.StatusBadRequest,
+		},
+		"invalid continue": {
+			method:   http.MethodGet,
+			query:    "?continue=abc",
+			wantCode: http.StatusBadRequest,
+		},
+		"not a GET": {
+			method:   http.MethodPost,
+			wantCode: http.StatusMethodNotAllowed,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(tc.method, Path+tc.query, nil))
+			if w.Code != tc.wantCode {
+				t.Fatalf("got status %d, want %d", w.Code, tc.wantCode)
+			}
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+			var l List
+			if err := json.NewDecoder(w.Body).Decode(&l); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.wantNames, names(l.Items)); diff != "" {
+				t.Errorf("Unexpected pods (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -21,11 +21,13 @@ import (
 	"fmt"
 	"math/rand"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
 	schedulerapi "github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config/scheme"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/explanation"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/parallelize"
 	frameworkplugins "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins"
@@ -33,17 +35,21 @@ import (
 	internalcache "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/cache"
 	internalqueue "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/queue"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/metrics"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/podgroup"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/profile"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/shard"
 	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/util"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	clientset "k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
@@ -93,6 +99,36 @@ type Scheduler struct {
 	Profiles profile.Map
 
 	client clientset.Interface
+
+	// podGroupStatus reports the progress of pod groups in their status. It's
+	// nil if no plugin watches PodGroups.
+	podGroupStatus *podgroup.StatusUpdater
+
+	// Explanations hold the outcome of the last scheduling attempt of recent
+	// pods.
+	Explanations *explanation.Store
+
+	// schedulingLimits bound how long the pods of each profile are retried,
+	// keyed by scheduler name.
+	schedulingLimits map[string]schedulingLimits
+
+	// workers schedule pods in parallel. There are none if pods are
+	// scheduled one at a time.
+	workers []*schedulingWorker
+	// commitLock serializes the validation and commit of the nodes the
+	// workers propose.
+	commitLock sync.Mutex
+	// nodeInfoSnapshot is the snapshot Algorithm and Profiles read.
+	nodeInfoSnapshot *internalcache.Snapshot
+
+	// bindingCycles tracks the binding cycles in flight, to drain them when
+	// the scheduler stops.
+	bindingCycles *bindingCycles
+
+	// shard is the membership of the replica in its shard group. It's nil if
+	// the replica schedules all the pods.
+	shard     *shard.Membership
+	podLister corelisters.PodLister
 }
 
 type schedulerOptions struct {
@@ -107,6 +143,11 @@ type schedulerOptions struct {
 	extenders                  []schedulerapi.Extender
 	frameworkCapturer          FrameworkCapturer
 	parallelism                int32
+	schedulingWorkers          int32
+	bindingDrainGracePeriod    time.Duration
+	explanationMaxEntries      int
+	explanationTTL             time.Duration
+	shard                      *shard.Membership
 	applyDefaultProfile        bool
 }
 
@@ -146,6 +187,41 @@ func WithParallelism(threads int32) Option {
 	}
 }
 
+// WithSchedulingWorkers sets the number of pods scheduled in parallel. Default
+// is 1, which schedules pods one at a time.
+func WithSchedulingWorkers(workers int32) Option {
+	return func(o *schedulerOptions) {
+		o.schedulingWorkers = workers
+	}
+}
+
+// WithBindingDrainGracePeriod sets how long the binding cycles in flight are
+// given to finish when the Scheduler stops. Default is 10s.
+func WithBindingDrainGracePeriod(d time.Duration) Option {
+	return func(o *schedulerOptions) {
+		o.bindingDrainGracePeriod = d
+	}
+}
+
+// WithExplanations sets the number of pods the explanation of the last
+// scheduling attempt is kept for, and for how long. Default is 1000 pods for
+// 15m.
+func WithExplanations(maxEntries int, ttl time.Duration) Option {
+	return func(o *schedulerOptions) {
+		o.explanationMaxEntries = maxEntries
+		o.explanationTTL = ttl
+	}
+}
+
+// WithShardMembership makes the Scheduler only schedule the pods of its shard,
+// running active-active with the other replicas of the shard group. The
+// membership must be run for the Scheduler to own any pods.
+func WithShardMembership(m *shard.Membership) Option {
+	return func(o *schedulerOptions) {
+		o.shard = m
+	}
+}
+
 // WithPercentageOfNodesToScore sets percentageOfNodesToScore for Scheduler, the default value is 50
 func WithPercentageOfNodesToScore(percentageOfNodesToScore int32) Option {
 	return func(o *schedulerOptions) {
@@ -197,6 +273,10 @@ var defaultSchedulerOptions = schedulerOptions{
 	podInitialBackoffSeconds: int64(internalqueue.DefaultPodInitialBackoffDuration.Seconds()),
 	podMaxBackoffSeconds:     int64(internalqueue.DefaultPodMaxBackoffDuration.Seconds()),
 	parallelism:              int32(parallelize.DefaultParallelism),
+	schedulingWorkers:        1,
+	bindingDrainGracePeriod:  DefaultBindingDrainGracePeriod,
+	explanationMaxEntries:    explanation.DefaultMaxEntries,
+	explanationTTL:           explanation.DefaultTTL,
 	// Ideally we would statically set the default profile here, but we can't because
 	// creating the default profile may require testing feature gates, which may get
 	// set dynamically in tests. Therefore, we delay creating it until New is actually
@@ -240,6 +320,7 @@ func New(client clientset.Interface,
 
 	snapshot := internalcache.NewEmptySnapshot()
 	clusterEventMap := make(map[framework.ClusterEvent]sets.String)
+	queueingHintMap := make(framework.QueueingHintMap)
 
 	configurator := &Configurator{
 		componentConfigVersion:   options.componentConfigVersion,
@@ -247,6 +328,7 @@ func New(client clientset.Interface,
 		kubeConfig:               options.kubeConfig,
 		recorderFactory:          recorderFactory,
 		informerFactory:          informerFactory,
+		dynInformerFactory:       dynInformerFactory,
 		schedulerCache:           schedulerCache,
 		StopEverything:           stopEverything,
 		percentageOfNodesToScore: options.percentageOfNodesToScore,
@@ -258,7 +340,11 @@ func New(client clientset.Interface,
 		extenders:                options.extenders,
 		frameworkCapturer:        options.frameworkCapturer,
 		parallellism:             options.parallelism,
+		schedulingWorkers:        options.schedulingWorkers,
+		explanationMaxEntries:    options.explanationMaxEntries,
+		explanationTTL:           options.explanationTTL,
 		clusterEventMap:          clusterEventMap,
+		queueingHintMap:          queueingHintMap,
 	}
 
 	metrics.Register()
@@ -272,8 +358,25 @@ func New(client clientset.Interface,
 	// Additional tweaks to the config produced by the configurator.
 	sched.StopEverything = stopEverything
 	sched.client = client
+	sched.bindingCycles = newBindingCycles(options.bindingDrainGracePeriod)
+	if options.shard != nil {
+		sched.shard = options.shard
+		sched.podLister = informerFactory.Core().V1().Pods().Lister()
+		options.shard.AddChangeHandler(sched.reshard)
+	}
+
+	gvkMap := unionedGVKs(clusterEventMap)
+	// PodGroups are only watched when a plugin registered for their events,
+	// as the informer can't sync if the CRD isn't installed.
+	if _, ok := gvkMap[podgroup.GVK]; ok && dynInformerFactory != nil && options.kubeConfig != nil {
+		dynClient, err := dynamic.NewForConfig(options.kubeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create dynamic client: %v", err)
+		}
+		sched.podGroupStatus = podgroup.NewStatusUpdater(dynClient, podgroup.NewLister(dynInformerFactory), informerFactory.Core().V1().Pods().Lister())
+	}
 
-	addAllEventHandlers(sched, informerFactory, dynInformerFactory, unionedGVKs(clusterEventMap))
+	addAllEventHandlers(sched, informerFactory, dynInformerFactory, gvkMap)
 
 	return sched, nil
 }
@@ -291,15 +394,43 @@ func unionedGVKs(m map[framework.ClusterEvent]sets.String) map[framework.GVK]fra
 }
 
 // Run begins watching and scheduling. It starts scheduling and blocked until the context is done.
+// It then stops popping pods, and returns once the binding cycles in flight are drained.
 func (sched *Scheduler) Run(ctx context.Context) {
 	sched.SchedulingQueue.Run()
-	wait.UntilWithContext(ctx, sched.scheduleOne, 0)
+	go sched.podGroupStatus.Run(ctx)
+	// The scheduling loops run in dedicated goroutines, as they block on
+	// popping the next pod until the queue is closed.
+	var wg sync.WaitGroup
+	loop := func(f func(context.Context)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.UntilWithContext(ctx, f, 0)
+		}()
+	}
+	if len(sched.workers) == 0 {
+		loop(sched.scheduleOne)
+	}
+	for _, w := range sched.workers {
+		w := w
+		loop(func(ctx context.Context) {
+			sched.scheduleOneOptimistically(ctx, w)
+		})
+	}
+	<-ctx.Done()
 	sched.SchedulingQueue.Close()
+	wg.Wait()
+	sched.drainBindingCycles()
 }
 
 // recordSchedulingFailure records an event for the pod that indicates the
 // pod has failed to schedule. Also, update the pod condition and nominated node name if set.
 func (sched *Scheduler) recordSchedulingFailure(fwk framework.Framework, podInfo *framework.QueuedPodInfo, err error, reason string, nominatingInfo *framework.NominatingInfo) {
+	sched.Explanations.RecordFailure(podInfo, fwk.ProfileName(), err)
+	if limit, msg := sched.schedulingLimits[fwk.ProfileName()].exceeded(podInfo, time.Now()); limit != "" {
+		sched.giveUpOnPod(fwk, podInfo, err, limit, msg)
+		return
+	}
 	sched.Error(podInfo, err)
 
 	// Update the scheduling queue with the nominated pod information. Without
@@ -321,6 +452,7 @@ func (sched *Scheduler) recordSchedulingFailure(fwk framework.Framework, podInfo
 	}, nominatingInfo); err != nil {
 		klog.ErrorS(err, "Error updating pod", "pod", klog.KObj(pod))
 	}
+	sched.podGroupStatus.Failed(pod, msg)
 }
 
 // truncateMessage truncates a message if it hits the NoteLengthLimit.
@@ -423,10 +555,24 @@ var (
 
 // scheduleOne does the entire scheduling workflow for a single pod. It is serialized on the scheduling algorithm's host fitting.
 func (sched *Scheduler) scheduleOne(ctx context.Context) {
+	podInfo, fwk := sched.nextPod()
+	if podInfo == nil {
+		return
+	}
+	klog.V(3).InfoS("Attempting to schedule pod", "pod", klog.KObj(podInfo.Pod))
+	sched.podGroupStatus.Attempted(podInfo.Pod)
+	if sched.schedulingCycle(ctx, sched.Algorithm, fwk, podInfo, time.Now()) {
+		metrics.WorkerScheduledPods.WithLabelValues(metrics.SerialWorker).Inc()
+	}
+}
+
+// nextPod returns the next pod to schedule and the framework of its profile.
+// It returns a nil pod if there is none or if the pod is skipped.
+func (sched *Scheduler) nextPod() (*framework.QueuedPodInfo, framework.Framework) {
 	podInfo := sched.NextPod()
 	// pod could be nil when schedulerQueue is closed
 	if podInfo == nil || podInfo.Pod == nil {
-		return
+		return nil, nil
 	}
 	pod := podInfo.Pod
 	fwk, err := sched.frameworkForPod(pod)
@@ -434,25 +580,37 @@ func (sched *Scheduler) scheduleOne(ctx context.Context) {
 		// This shouldn't happen, because we only accept for scheduling the pods
 		// which specify a scheduler name that matches one of the profiles.
 		klog.ErrorS(err, "Error occurred")
-		return
+		return nil, nil
 	}
 	if sched.skipPodSchedule(fwk, pod) {
-		return
+		return nil, nil
 	}
+	return podInfo, fwk
+}
 
-	klog.V(3).InfoS("Attempting to schedule pod", "pod", klog.KObj(pod))
-
-	// Synchronously attempt to find a fit for the pod.
-	start := time.Now()
+// newCycleState returns the CycleState of a new scheduling cycle and the
+// podsToActivate written in it.
+func newCycleState() (*framework.CycleState, *framework.PodsToActivate) {
 	state := framework.NewCycleState()
 	state.SetRecordPluginMetrics(rand.Intn(100) < pluginMetricsSamplePercent)
 	// Initialize an empty podsToActivate struct, which will be filled up by plugins or stay empty.
 	podsToActivate := framework.NewPodsToActivate()
 	state.Write(framework.PodsToActivateKey, podsToActivate)
+	return state, podsToActivate
+}
+
+// schedulingCycle finds a node for the pod with the given algorithm, and
+// commits the result or records the failure. start is the time the
+// scheduling attempt started. It returns whether the pod was assumed on a
+// node and went on to its binding cycle.
+func (sched *Scheduler) schedulingCycle(ctx context.Context, algo ScheduleAlgorithm, fwk framework.Framework, podInfo *framework.QueuedPodInfo, start time.Time) bool {
+	pod := podInfo.Pod
+	// Synchronously attempt to find a fit for the pod.
+	state, podsToActivate := newCycleState()
 
 	schedulingCycleCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
-	scheduleResult, err := sched.Algorithm.Schedule(schedulingCycleCtx, sched.Extenders, fwk, state, pod)
+	scheduleResult, err := algo.Schedule(schedulingCycleCtx, sched.Extenders, fwk, state, pod)
 	if err != nil {
 		// Schedule() may have failed because the pod would not fit on any host, so we try to
 		// preempt, with the expectation that the next time the pod is tried for scheduling it
@@ -488,15 +646,24 @@ func (sched *Scheduler) scheduleOne(ctx context.Context) {
 			metrics.PodScheduleError(fwk.ProfileName(), metrics.SinceInSeconds(start))
 		}
 		sched.recordSchedulingFailure(fwk, podInfo, err, v1.PodReasonUnschedulable, nominatingInfo)
-		return
+		return false
 	}
 	metrics.SchedulingAlgorithmLatency.Observe(metrics.SinceInSeconds(start))
+	sched.Explanations.RecordSuccess(podInfo, fwk.ProfileName(), scheduleResult.SuggestedHost, scheduleResult.PluginToNodeScores)
+	return sched.commitSchedulingResult(ctx, schedulingCycleCtx, fwk, state, podsToActivate, podInfo, scheduleResult, start)
+}
+
+// commitSchedulingResult assumes the pod on the node the scheduling algorithm
+// suggested, runs the Reserve and Permit plugins and binds the pod
+// asynchronously. It returns whether the pod went on to its binding cycle.
+func (sched *Scheduler) commitSchedulingResult(ctx, schedulingCycleCtx context.Context, fwk framework.Framework, state *framework.CycleState, podsToActivate *framework.PodsToActivate, podInfo *framework.QueuedPodInfo, scheduleResult ScheduleResult, start time.Time) bool {
+	pod := podInfo.Pod
 	// Tell the cache to assume that a pod now is running on a given node, even though it hasn't been bound yet.
 	// This allows us to keep scheduling without waiting on binding to occur.
 	assumedPodInfo := podInfo.DeepCopy()
 	assumedPod := assumedPodInfo.Pod
 	// assume modifies `assumedPod` by setting NodeName=scheduleResult.SuggestedHost
-	err = sched.assume(assumedPod, scheduleResult.SuggestedHost)
+	err := sched.assume(assumedPod, scheduleResult.SuggestedHost)
 	if err != nil {
 		metrics.PodScheduleError(fwk.ProfileName(), metrics.SinceInSeconds(start))
 		// This is most probably result of a BUG in retrying logic.
@@ -505,20 +672,29 @@ func (sched *Scheduler) scheduleOne(ctx context.Context) {
 		// to a node and if so will not add it back to the unscheduled pods queue
 		// (otherwise this would cause an infinite loop).
 		sched.recordSchedulingFailure(fwk, assumedPodInfo, err, SchedulerError, clearNominatedNode)
-		return
+		return false
 	}
 
 	// Run the Reserve method of reserve plugins.
-	if sts := fwk.RunReservePluginsReserve(schedulingCycleCtx, state, assumedPod, scheduleResult.SuggestedHost); !sts.IsSuccess() {
+	// Reserve plugins may record their decisions in the assumed pod, e.g. the
+	// devices it was given, which the cache accounts for in its node. They are
+	// given a copy of it, as the one in the cache is read concurrently.
+	reservedPod := assumedPod.DeepCopy()
+	if sts := fwk.RunReservePluginsReserve(schedulingCycleCtx, state, reservedPod, scheduleResult.SuggestedHost); !sts.IsSuccess() {
 		metrics.PodScheduleError(fwk.ProfileName(), metrics.SinceInSeconds(start))
 		// trigger un-reserve to clean up state associated with the reserved Pod
-		fwk.RunReservePluginsUnreserve(schedulingCycleCtx, state, assumedPod, scheduleResult.SuggestedHost)
+		fwk.RunReservePluginsUnreserve(schedulingCycleCtx, state, reservedPod, scheduleResult.SuggestedHost)
 		if forgetErr := sched.SchedulerCache.ForgetPod(assumedPod); forgetErr != nil {
 			klog.ErrorS(forgetErr, "Scheduler cache ForgetPod failed")
 		}
 		sched.recordSchedulingFailure(fwk, assumedPodInfo, sts.AsError(), SchedulerError, clearNominatedNode)
-		return
+		return false
+	}
+	if err := sched.SchedulerCache.UpdateAssumedPod(reservedPod); err != nil {
+		klog.ErrorS(err, "Scheduler cache UpdateAssumedPod failed", "pod", klog.KObj(reservedPod))
 	}
+	assumedPod = reservedPod
+	assumedPodInfo.Pod = reservedPod
 
 	// Run "permit" plugins.
 	runPermitStatus := fwk.RunPermitPlugins(schedulingCycleCtx, state, assumedPod, scheduleResult.SuggestedHost)
@@ -537,7 +713,7 @@ func (sched *Scheduler) scheduleOne(ctx context.Context) {
 			klog.ErrorS(forgetErr, "Scheduler cache ForgetPod failed")
 		}
 		sched.recordSchedulingFailure(fwk, assumedPodInfo, runPermitStatus.AsError(), reason, clearNominatedNode)
-		return
+		return false
 	}
 
 	// At the end of a successful scheduling cycle, pop and move up Pods if needed.
@@ -548,8 +724,12 @@ func (sched *Scheduler) scheduleOne(ctx context.Context) {
 	}
 
 	// bind the pod to its host asynchronously (we can do this b/c of the assumption step above).
+	// The binding cycle is drained rather than interrupted when the scheduler stops.
+	bindingCtx := sched.bindingCycles.start(ctx)
 	go func() {
-		bindingCycleCtx, cancel := context.WithCancel(ctx)
+		bound := false
+		defer func() { sched.bindingCycles.finish(bound) }()
+		bindingCycleCtx, cancel := context.WithCancel(bindingCtx)
 		defer cancel()
 		metrics.SchedulerGoroutines.WithLabelValues(metrics.Binding).Inc()
 		defer metrics.SchedulerGoroutines.WithLabelValues(metrics.Binding).Dec()
@@ -575,7 +755,7 @@ func (sched *Scheduler) scheduleOne(ctx context.Context) {
 				// Avoid moving the assumed Pod itself as it's always Unschedulable.
 				// It's intentional to "defer" this operation; otherwise MoveAllToActiveOrBackoffQueue() would
 				// update `q.moveRequest` and thus move the assumed pod to backoffQ anyways.
-				defer sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(internalqueue.AssignedPodDelete, func(pod *v1.Pod) bool {
+				defer sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(internalqueue.AssignedPodDelete, assumedPod, nil, func(pod *v1.Pod) bool {
 					return assumedPod.UID != pod.UID
 				})
 			}
@@ -583,6 +763,20 @@ func (sched *Scheduler) scheduleOne(ctx context.Context) {
 			return
 		}
 
+		// Make sure the other replicas didn't take the node meanwhile.
+		if err := sched.claimNode(bindingCycleCtx, fwk, state, assumedPod, scheduleResult.SuggestedHost); err != nil {
+			metrics.PodScheduleError(fwk.ProfileName(), metrics.SinceInSeconds(start))
+			// trigger un-reserve plugins to clean up state associated with the reserved Pod
+			fwk.RunReservePluginsUnreserve(bindingCycleCtx, state, assumedPod, scheduleResult.SuggestedHost)
+			if forgetErr := sched.SchedulerCache.ForgetPod(assumedPod); forgetErr != nil {
+				klog.ErrorS(forgetErr, "scheduler cache ForgetPod failed")
+			} else {
+				sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(internalqueue.AssignedPodDelete, assumedPod, nil, nil)
+			}
+			sched.recordSchedulingFailure(fwk, assumedPodInfo, err, SchedulerError, clearNominatedNode)
+			return
+		}
+
 		// Run "prebind" plugins.
 		preBindStatus := fwk.RunPreBindPlugins(bindingCycleCtx, state, assumedPod, scheduleResult.SuggestedHost)
 		if !preBindStatus.IsSuccess() {
@@ -595,7 +789,7 @@ func (sched *Scheduler) scheduleOne(ctx context.Context) {
 				// "Forget"ing an assumed Pod in binding cycle should be treated as a PodDelete event,
 				// as the assumed Pod had occupied a certain amount of resources in scheduler cache.
 				// TODO(#103853): de-duplicate the logic.
-				sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(internalqueue.AssignedPodDelete, nil)
+				sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(internalqueue.AssignedPodDelete, assumedPod, nil, nil)
 			}
 			sched.recordSchedulingFailure(fwk, assumedPodInfo, preBindStatus.AsError(), SchedulerError, clearNominatedNode)
 			return
@@ -612,10 +806,11 @@ func (sched *Scheduler) scheduleOne(ctx context.Context) {
 				// "Forget"ing an assumed Pod in binding cycle should be treated as a PodDelete event,
 				// as the assumed Pod had occupied a certain amount of resources in scheduler cache.
 				// TODO(#103853): de-duplicate the logic.
-				sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(internalqueue.AssignedPodDelete, nil)
+				sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(internalqueue.AssignedPodDelete, assumedPod, nil, nil)
 			}
 			sched.recordSchedulingFailure(fwk, assumedPodInfo, fmt.Errorf("binding rejected: %w", err), SchedulerError, clearNominatedNode)
 		} else {
+			bound = true
 			// Calculating nodeResourceString can be heavy. Avoid it if klog verbosity is below 2.
 			if klog.V(2).Enabled() {
 				klog.InfoS("Successfully bound pod to node", "pod", klog.KObj(pod), "node", scheduleResult.SuggestedHost, "evaluatedNodes", scheduleResult.EvaluatedNodes, "feasibleNodes", scheduleResult.FeasibleNodes)
@@ -624,6 +819,8 @@ func (sched *Scheduler) scheduleOne(ctx context.Context) {
 			metrics.PodSchedulingAttempts.Observe(float64(podInfo.Attempts))
 			metrics.PodSchedulingDuration.WithLabelValues(getAttemptsLabel(podInfo)).Observe(metrics.SinceInSeconds(podInfo.InitialAttemptTimestamp))
 
+			sched.podGroupStatus.Bound(assumedPod)
+
 			// Run "postbind" plugins.
 			fwk.RunPostBindPlugins(bindingCycleCtx, state, assumedPod, scheduleResult.SuggestedHost)
 
@@ -635,6 +832,7 @@ func (sched *Scheduler) scheduleOne(ctx context.Context) {
 			}
 		}
 	}()
+	return true
 }
 
 func getAttemptsLabel(p *framework.QueuedPodInfo) string {
@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shard
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// The replicas share the nodes, so a replica announces the pods it assumed
+// on the pods themselves before binding them. The other replicas account
+// for the announced pods in their caches, and a pod that no longer fits
+// next to the pods announced before it yields its node.
+const (
+	// AssumedNodeAnnotation is the node the pod was assumed on.
+	AssumedNodeAnnotation = "shard.scheduling.sched.dev/assumed-node"
+	// AssumedByAnnotation is the identity of the replica that assumed the
+	// pod.
+	AssumedByAnnotation = "shard.scheduling.sched.dev/assumed-by"
+	// AssumedAtAnnotation is when the pod was announced, in RFC 3339 format
+	// with nanoseconds.
+	AssumedAtAnnotation = "shard.scheduling.sched.dev/assumed-at"
+)
+
+// Announcement is a pod a replica announced it assumed on a node.
+type Announcement struct {
+	Node      string
+	Replica   string
+	Timestamp time.Time
+}
+
+// AnnouncementOf returns the announcement made on the pod, if any.
+func AnnouncementOf(pod *v1.Pod) (Announcement, bool) {
+	a := Announcement{
+		Node:    pod.Annotations[AssumedNodeAnnotation],
+		Replica: pod.Annotations[AssumedByAnnotation],
+	}
+	if a.Node == "" || a.Replica == "" {
+		return Announcement{}, false
+	}
+	// A missing or malformed timestamp makes the pod yield to the others.
+	if t, err := time.Parse(time.RFC3339Nano, pod.Annotations[AssumedAtAnnotation]); err == nil {
+		a.Timestamp = t
+	} else {
+		a.Timestamp = time.Unix(1<<62, 0)
+	}
+	return a, true
+}
+
+// Before returns true if the pod of the announcement wins the node over the
+// pod of the other one: the earliest announcement wins, and ties are broken
+// by replica identity.
+func (a Announcement) Before(other Announcement) bool {
+	if !a.Timestamp.Equal(other.Timestamp) {
+		return a.Timestamp.Before(other.Timestamp)
+	}
+	return a.Replica < other.Replica
+}
+
+// AnnouncedByOther returns the announcement made on the pod by another live
+// replica, if any.
+func (m *Membership) AnnouncedByOther(pod *v1.Pod) (Announcement, bool) {
+	a, ok := AnnouncementOf(pod)
+	if !ok || a.Replica == m.identity || !m.Members().Has(a.Replica) {
+		return Announcement{}, false
+	}
+	return a, true
+}
+
+// Announce announces that the replica assumed the pod on the node. It
+// returns the announcement.
+func (m *Membership) Announce(ctx context.Context, pod *v1.Pod, nodeName string) (Announcement, error) {
+	a := Announcement{Node: nodeName, Replica: m.identity, Timestamp: m.clock.Now()}
+	err := m.patchAnnotations(ctx, pod, map[string]interface{}{
+		AssumedNodeAnnotation: a.Node,
+		AssumedByAnnotation:   a.Replica,
+		AssumedAtAnnotation:   a.Timestamp.UTC().Format(time.RFC3339Nano),
+	})
+	return a, err
+}
+
+// Retract removes the announcement made on the pod.
+func (m *Membership) Retract(ctx context.Context, pod *v1.Pod) error {
+	return m.patchAnnotations(ctx, pod, map[string]interface{}{
+		AssumedNodeAnnotation: nil,
+		AssumedByAnnotation:   nil,
+		AssumedAtAnnotation:   nil,
+	})
+}
+
+func (m *Membership) patchAnnotations(ctx context.Context, pod *v1.Pod, annotations map[string]interface{}) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = m.client.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
@@ -0,0 +1,307 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package shard splits the pending pods between the replicas of a scheduler
+// that run active-active. Each replica renews a Lease of its own, and a pod
+// is scheduled by the live replica that wins the rendezvous hash of its
+// namespace, or of its pod group if it belongs to one, so that the pods of a
+// replica that dies are spread over the others.
+package shard
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/podgroup"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+// GroupLabel is set on the Leases of the replicas to the name of the group
+// they share pods in.
+const GroupLabel = "shard.scheduling.sched.dev/group"
+
+// Key returns the key pods are sharded by: the pod group of the pod if it
+// belongs to one, so that gangs are scheduled by a single replica, and its
+// namespace otherwise.
+func Key(pod *v1.Pod) string {
+	if name := podgroup.Name(pod); name != "" {
+		return pod.Namespace + "/" + name
+	}
+	return pod.Namespace
+}
+
+// Members are the identities of the live replicas of a group, sorted.
+type Members []string
+
+// Has returns true if the replica is a member.
+func (m Members) Has(identity string) bool {
+	i := sort.SearchStrings(m, identity)
+	return i < len(m) && m[i] == identity
+}
+
+// Owner returns the member that schedules the pods with the given key, or an
+// empty string if there are no members. Only the keys of a member that
+// leaves change owners. The weights are taken from a cryptographic hash, as
+// the weights of similar keys and members must be independent for the keys
+// to be spread evenly.
+func (m Members) Owner(key string) string {
+	var owner string
+	var maxWeight uint64
+	for _, member := range m {
+		sum := sha256.Sum256([]byte(member + "\x00" + key))
+		if w := binary.BigEndian.Uint64(sum[:8]); owner == "" || w > maxWeight {
+			owner, maxWeight = member, w
+		}
+	}
+	return owner
+}
+
+// ChangeHandler is called with the members before and after a change.
+type ChangeHandler func(oldMembers, newMembers Members)
+
+// observedLease is the renew time of the Lease of a replica, and when this
+// replica saw it change.
+type observedLease struct {
+	renewTime  time.Time
+	observedAt time.Time
+}
+
+// Membership maintains the Lease of a replica and tracks the live members of
+// its group. A replica is live as long as its Lease is renewed within the
+// lease duration. The renewals are timed with the local clock, as replicas'
+// clocks may disagree.
+type Membership struct {
+	client         clientset.Interface
+	namespace      string
+	group          string
+	identity       string
+	leaseDuration  time.Duration
+	conflictWindow time.Duration
+	clock          clock.Clock
+
+	mu        sync.RWMutex
+	members   Members
+	observed  map[string]observedLease
+	lastRenew time.Time
+	handlers  []ChangeHandler
+}
+
+// New returns the Membership of the replica with the given identity in a
+// group. The Leases are kept in the given namespace. conflictWindow is how
+// long the replica waits for the other replicas to announce the pods they
+// assumed before it binds one.
+func New(client clientset.Interface, namespace, group, identity string, leaseDuration, conflictWindow time.Duration) *Membership {
+	return &Membership{
+		client:         client,
+		namespace:      namespace,
+		group:          group,
+		identity:       identity,
+		leaseDuration:  leaseDuration,
+		conflictWindow: conflictWindow,
+		clock:          clock.RealClock{},
+		observed:       make(map[string]observedLease),
+	}
+}
+
+// Identity returns the identity of the replica.
+func (m *Membership) Identity() string {
+	return m.identity
+}
+
+// ConflictWindow returns how long the replica waits for the announcements of
+// the other replicas before binding a pod.
+func (m *Membership) ConflictWindow() time.Duration {
+	return m.conflictWindow
+}
+
+// AddChangeHandler registers a handler called when members join or leave.
+// It must be called before Run.
+func (m *Membership) AddChangeHandler(h ChangeHandler) {
+	m.handlers = append(m.handlers, h)
+}
+
+// Members returns the live members.
+func (m *Membership) Members() Members {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.members
+}
+
+// Owns returns true if the replica schedules the pod. It owns no pods until
+// it sees its own Lease.
+func (m *Membership) Owns(pod *v1.Pod) bool {
+	return m.OwnsIn(m.Members(), pod)
+}
+
+// OwnsIn returns true if the replica schedules the pod given the members.
+// The pods another member announced are left to it, as it's binding them.
+func (m *Membership) OwnsIn(members Members, pod *v1.Pod) bool {
+	if a, ok := AnnouncementOf(pod); ok && a.Replica != m.identity && members.Has(a.Replica) {
+		return false
+	}
+	return members.Owner(Key(pod)) == m.identity
+}
+
+// Run renews the Lease of the replica and tracks the members until the
+// context is done. It then deletes the Lease, so that the pods of the
+// replica are reassigned without waiting for the Lease to expire.
+func (m *Membership) Run(ctx context.Context) {
+	klog.InfoS("Joining scheduler shard group", "group", m.group, "identity", m.identity)
+	wait.UntilWithContext(ctx, m.sync, m.leaseDuration/3)
+	err := m.client.CoordinationV1().Leases(m.namespace).Delete(context.Background(), m.leaseName(), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		klog.ErrorS(err, "Failed to delete shard lease", "lease", klog.KRef(m.namespace, m.leaseName()))
+	}
+}
+
+// sync renews the Lease of the replica and updates the members.
+func (m *Membership) sync(ctx context.Context) {
+	now := m.clock.Now()
+	if err := m.renew(ctx, now); err != nil {
+		klog.ErrorS(err, "Failed to renew shard lease", "lease", klog.KRef(m.namespace, m.leaseName()))
+	} else {
+		m.lastRenew = now
+	}
+	leases, err := m.client.CoordinationV1().Leases(m.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{GroupLabel: m.group}).String(),
+	})
+	if err != nil {
+		klog.ErrorS(err, "Failed to list shard leases", "group", m.group)
+		return
+	}
+	m.update(leases.Items, now)
+}
+
+// update updates the members from the Leases of the group.
+func (m *Membership) update(leases []coordinationv1.Lease, now time.Time) {
+	var members Members
+	observed := make(map[string]observedLease, len(leases))
+	for i := range leases {
+		spec := leases[i].Spec
+		if spec.HolderIdentity == nil || spec.RenewTime == nil {
+			continue
+		}
+		identity := *spec.HolderIdentity
+		o := observedLease{renewTime: spec.RenewTime.Time, observedAt: now}
+		if prev, ok := m.observed[identity]; ok && prev.renewTime.Equal(o.renewTime) {
+			o.observedAt = prev.observedAt
+		}
+		observed[identity] = o
+		duration := m.leaseDuration
+		if spec.LeaseDurationSeconds != nil {
+			duration = time.Duration(*spec.LeaseDurationSeconds) * time.Second
+		}
+		if now.Sub(o.observedAt) < duration {
+			members = append(members, identity)
+		}
+	}
+	// Without a renewed Lease, the other replicas may already have taken
+	// this replica's pods.
+	if now.Sub(m.lastRenew) >= m.leaseDuration {
+		members = removeMember(members, m.identity)
+	}
+	sort.Strings(members)
+	m.observed = observed
+
+	m.mu.Lock()
+	oldMembers := m.members
+	m.members = members
+	m.mu.Unlock()
+	if equalMembers(oldMembers, members) {
+		return
+	}
+	klog.InfoS("Scheduler shard group changed", "group", m.group, "members", members)
+	for _, h := range m.handlers {
+		h(oldMembers, members)
+	}
+}
+
+// renew creates or renews the Lease of the replica.
+func (m *Membership) renew(ctx context.Context, now time.Time) error {
+	leases := m.client.CoordinationV1().Leases(m.namespace)
+	durationSeconds := int32(m.leaseDuration.Seconds())
+	renewTime := metav1.NewMicroTime(now)
+	lease, err := leases.Get(ctx, m.leaseName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      m.leaseName(),
+				Namespace: m.namespace,
+				Labels:    map[string]string{GroupLabel: m.group},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &m.identity,
+				LeaseDurationSeconds: &durationSeconds,
+				AcquireTime:          &renewTime,
+				RenewTime:            &renewTime,
+			},
+		}
+		_, err = leases.Create(ctx, lease, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	lease.Spec.HolderIdentity = &m.identity
+	lease.Spec.LeaseDurationSeconds = &durationSeconds
+	lease.Spec.RenewTime = &renewTime
+	_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	return err
+}
+
+// leaseName returns the name of the Lease of the replica. Identities aren't
+// valid object names, e.g. they contain underscores, so they are hashed.
+func (m *Membership) leaseName() string {
+	h := fnv.New32a()
+	h.Write([]byte(m.identity))
+	return fmt.Sprintf("%s-%08x", m.group, h.Sum32())
+}
+
+func removeMember(members Members, identity string) Members {
+	var out Members
+	for _, member := range members {
+		if member != identity {
+			out = append(out, member)
+		}
+	}
+	return out
+}
+
+func equalMembers(a, b Members) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
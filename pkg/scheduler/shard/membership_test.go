@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shard
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/scheduling/v1alpha1"
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	"github.com/google/go-cmp/cmp"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+func TestMembersOwner(t *testing.T) {
+	members := Members{"a", "b", "c"}
+	if got := (Members{}).Owner("ns"); got != "" {
+		t.Errorf("Unexpected owner without members: %q", got)
+	}
+	remaining := Members{"a", "c"}
+	owned := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("ns-%d", i)
+		owner := members.Owner(key)
+		owned[owner]++
+		// Only the keys of the member that left move.
+		if owner != "b" {
+			if got := remaining.Owner(key); got != owner {
+				t.Errorf("Key %q moved from %q to %q", key, owner, got)
+			}
+		}
+	}
+	for _, m := range members {
+		if owned[m] < 200 {
+			t.Errorf("Member %q owns %d keys out of 1000", m, owned[m])
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	if got := Key(st.MakePod().Namespace("ns").Name("p").Obj()); got != "ns" {
+		t.Errorf("Unexpected key of pod without pod group: %q", got)
+	}
+	if got := Key(st.MakePod().Namespace("ns").Name("p").Label(v1alpha1.PodGroupLabel, "pg").Obj()); got != "ns/pg" {
+		t.Errorf("Unexpected key of pod with pod group: %q", got)
+	}
+}
+
+func TestMembershipSync(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	m := New(client, "kube-system", "sched", "r1", 15*time.Second, time.Second)
+	m.clock = fakeClock
+	var changes []Members
+	m.AddChangeHandler(func(_, newMembers Members) {
+		changes = append(changes, newMembers)
+	})
+
+	m.sync(ctx)
+	if diff := cmp.Diff(Members{"r1"}, m.Members()); diff != "" {
+		t.Errorf("Unexpected members after joining (-want,+got):\n%s", diff)
+	}
+
+	// Another replica joins.
+	other := "r2"
+	renewTime := metav1.NewMicroTime(fakeClock.Now())
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: "sched-r2", Namespace: "kube-system", Labels: map[string]string{GroupLabel: "sched"}},
+		Spec:       coordinationv1.LeaseSpec{HolderIdentity: &other, RenewTime: &renewTime},
+	}
+	if _, err := client.CoordinationV1().Leases("kube-system").Create(ctx, lease, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	fakeClock.Step(5 * time.Second)
+	m.sync(ctx)
+	if diff := cmp.Diff(Members{"r1", "r2"}, m.Members()); diff != "" {
+		t.Errorf("Unexpected members after a replica joined (-want,+got):\n%s", diff)
+	}
+
+	// It stops renewing its Lease.
+	fakeClock.Step(20 * time.Second)
+	m.sync(ctx)
+	if diff := cmp.Diff(Members{"r1"}, m.Members()); diff != "" {
+		t.Errorf("Unexpected members after a replica died (-want,+got):\n%s", diff)
+	}
+
+	wantChanges := []Members{{"r1"}, {"r1", "r2"}, {"r1"}}
+	if diff := cmp.Diff(wantChanges, changes); diff != "" {
+		t.Errorf("Unexpected changes (-want,+got):\n%s", diff)
+	}
+}
+
+func TestMembershipOwnsIn(t *testing.T) {
+	m := New(fake.NewSimpleClientset(), "kube-system", "sched", "r1", 15*time.Second, time.Second)
+	members := Members{"r1"}
+	pod := st.MakePod().Namespace("ns").Name("p").Obj()
+	if !m.OwnsIn(members, pod) {
+		t.Errorf("Expected the only member to own the pod")
+	}
+	if m.OwnsIn(nil, pod) {
+		t.Errorf("Expected no pods to be owned without members")
+	}
+
+	announced := pod.DeepCopy()
+	announced.Annotations = map[string]string{
+		AssumedNodeAnnotation: "node",
+		AssumedByAnnotation:   "r2",
+		AssumedAtAnnotation:   time.Now().Format(time.RFC3339Nano),
+	}
+	if !m.OwnsIn(members, announced) {
+		t.Errorf("Expected the pod announced by a replica that left to be owned")
+	}
+	if m.OwnsIn(Members{"r1", "r2"}, announced) {
+		t.Errorf("Expected the pod announced by another member to be left to it")
+	}
+}
+
+func TestAnnouncementBefore(t *testing.T) {
+	now := time.Now()
+	a := Announcement{Replica: "r2", Timestamp: now}
+	if !a.Before(Announcement{Replica: "r1", Timestamp: now.Add(time.Millisecond)}) {
+		t.Errorf("Expected the earliest announcement to win")
+	}
+	if a.Before(Announcement{Replica: "r1", Timestamp: now}) {
+		t.Errorf("Expected ties to be broken by replica identity")
+	}
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	internalqueue "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/queue"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/metrics"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/shard"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// ownsPod returns true if the replica schedules the pod. Without sharding,
+// it schedules all the pods.
+func (sched *Scheduler) ownsPod(pod *v1.Pod) bool {
+	return sched.shard == nil || sched.shard.Owns(pod)
+}
+
+// announcedByOtherReplica returns true if another live replica announced it
+// assumed the pod.
+func (sched *Scheduler) announcedByOtherReplica(pod *v1.Pod) bool {
+	if sched.shard == nil {
+		return false
+	}
+	_, ok := sched.shard.AnnouncedByOther(pod)
+	return ok
+}
+
+// reshard moves the pending pods whose owner changed in or out of the
+// scheduling queue when replicas join or leave the shard group. The pods a
+// leaving replica announced but didn't bind are forgotten.
+func (sched *Scheduler) reshard(oldMembers, newMembers shard.Members) {
+	pods, err := sched.podLister.List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "Failed to list pods to reshard")
+		return
+	}
+	var added, removed int
+	for _, pod := range pods {
+		if assignedPod(pod) || !responsibleForPod(pod, sched.Profiles) || schedulingDeadlineExceeded(pod) {
+			continue
+		}
+		if a, ok := shard.AnnouncementOf(pod); ok && oldMembers.Has(a.Replica) && !newMembers.Has(a.Replica) && a.Replica != sched.shard.Identity() {
+			sched.forgetAnnouncedPod(pod)
+		}
+		owned, wasOwned := sched.shard.OwnsIn(newMembers, pod), sched.shard.OwnsIn(oldMembers, pod)
+		if owned && !wasOwned {
+			added++
+			sched.addPodToSchedulingQueue(pod)
+		} else if !owned && wasOwned {
+			removed++
+			if err := sched.SchedulingQueue.Delete(pod); err != nil {
+				klog.ErrorS(err, "Failed to remove pod of another replica from the scheduling queue", "pod", klog.KObj(pod))
+			}
+		}
+	}
+	klog.InfoS("Resharded pending pods", "members", newMembers, "added", added, "removed", removed)
+}
+
+func (sched *Scheduler) addAnnouncedPodToCache(obj interface{}) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		klog.ErrorS(nil, "Cannot convert to *v1.Pod", "obj", obj)
+		return
+	}
+	a, ok := shard.AnnouncementOf(pod)
+	if !ok {
+		return
+	}
+	klog.V(3).InfoS("Add event for pod assumed by another replica", "pod", klog.KObj(pod), "node", a.Node, "replica", a.Replica)
+	assumed := pod.DeepCopy()
+	assumed.Spec.NodeName = a.Node
+	if err := sched.SchedulerCache.AssumePod(assumed); err != nil {
+		// The pod may be bound and in the cache already.
+		klog.V(4).InfoS("Scheduler cache AssumePod failed", "pod", klog.KObj(pod), "err", err)
+		return
+	}
+	// The pod expires if the other replica never binds it.
+	if err := sched.SchedulerCache.FinishBinding(assumed); err != nil {
+		klog.ErrorS(err, "Scheduler cache FinishBinding failed", "pod", klog.KObj(pod))
+	}
+}
+
+func (sched *Scheduler) updateAnnouncedPodInCache(oldObj, newObj interface{}) {
+	oldPod, ok := oldObj.(*v1.Pod)
+	if !ok {
+		klog.ErrorS(nil, "Cannot convert oldObj to *v1.Pod", "oldObj", oldObj)
+		return
+	}
+	newPod, ok := newObj.(*v1.Pod)
+	if !ok {
+		klog.ErrorS(nil, "Cannot convert newObj to *v1.Pod", "newObj", newObj)
+		return
+	}
+	oldA, _ := shard.AnnouncementOf(oldPod)
+	newA, _ := shard.AnnouncementOf(newPod)
+	if oldPod.UID == newPod.UID && oldA.Node == newA.Node && oldA.Replica == newA.Replica {
+		return
+	}
+	sched.deleteAnnouncedPodFromCache(oldObj)
+	sched.addAnnouncedPodToCache(newObj)
+}
+
+func (sched *Scheduler) deleteAnnouncedPodFromCache(obj interface{}) {
+	var pod *v1.Pod
+	switch t := obj.(type) {
+	case *v1.Pod:
+		pod = t
+	case cache.DeletedFinalStateUnknown:
+		var ok bool
+		pod, ok = t.Obj.(*v1.Pod)
+		if !ok {
+			klog.ErrorS(nil, "Cannot convert to *v1.Pod", "obj", t.Obj)
+			return
+		}
+	default:
+		klog.ErrorS(nil, "Cannot convert to *v1.Pod", "obj", t)
+		return
+	}
+	klog.V(3).InfoS("Delete event for pod assumed by another replica", "pod", klog.KObj(pod))
+	sched.forgetAnnouncedPod(pod)
+}
+
+// forgetAnnouncedPod removes the pod another replica announced from the
+// cache, unless it was bound meanwhile.
+func (sched *Scheduler) forgetAnnouncedPod(pod *v1.Pod) {
+	if assumed, err := sched.SchedulerCache.IsAssumedPod(pod); err != nil || !assumed {
+		return
+	}
+	cached, err := sched.SchedulerCache.GetPod(pod)
+	if err != nil {
+		return
+	}
+	if err := sched.SchedulerCache.ForgetPod(cached); err != nil {
+		klog.ErrorS(err, "Scheduler cache ForgetPod failed", "pod", klog.KObj(pod))
+		return
+	}
+	sched.SchedulingQueue.MoveAllToActiveOrBackoffQueue(internalqueue.AssignedPodDelete, cached, nil, nil)
+}
+
+// claimNode announces to the other replicas that the pod is assumed on the
+// node. It then gives their announcements time to come in, and checks that
+// the pod still fits next to the pods announced before it. The pods
+// announced after it yield to it in turn. It returns an error if the pod
+// must yield the node.
+func (sched *Scheduler) claimNode(ctx context.Context, fwk framework.Framework, state *framework.CycleState, pod *v1.Pod, nodeName string) error {
+	if sched.shard == nil {
+		return nil
+	}
+	a, err := sched.shard.Announce(ctx, pod, nodeName)
+	if err != nil {
+		return fmt.Errorf("announcing pod to the other replicas: %w", err)
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(sched.shard.ConflictWindow()):
+	}
+
+	nodeInfo, err := sched.SchedulerCache.NodeInfo(nodeName)
+	if err != nil {
+		return err
+	}
+	for _, p := range append([]*framework.PodInfo(nil), nodeInfo.Pods...) {
+		if p.Pod.UID != pod.UID && !sched.yieldsTo(a, p.Pod) {
+			continue
+		}
+		if err := nodeInfo.RemovePod(p.Pod); err != nil {
+			return err
+		}
+	}
+	if s := fwk.RunFilterPlugins(ctx, state, pod, nodeInfo).Merge(); !s.IsSuccess() {
+		metrics.ShardBindingConflicts.Inc()
+		klog.V(2).InfoS("Pod yields node to a pod assumed by another replica", "pod", klog.KObj(pod), "node", nodeName, "status", s)
+		if err := sched.shard.Retract(ctx, pod); err != nil {
+			klog.ErrorS(err, "Failed to retract pod announcement", "pod", klog.KObj(pod))
+		}
+		return fmt.Errorf("pod yields node %q to a pod assumed by another replica: %w", nodeName, s.AsError())
+	}
+	return nil
+}
+
+// yieldsTo returns true if the pod, assumed on a node by another replica,
+// yields the node to the pod of the announcement. Bound pods don't yield.
+func (sched *Scheduler) yieldsTo(a shard.Announcement, pod *v1.Pod) bool {
+	other, ok := sched.shard.AnnouncedByOther(pod)
+	if !ok || !a.Before(other) {
+		return false
+	}
+	assumed, err := sched.SchedulerCache.IsAssumedPod(pod)
+	return err == nil && assumed
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/defaultbinder"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/feature"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/noderesources"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/runtime"
+	internalcache "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/cache"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/shard"
+	st "github.com/QuarfotPrice/sched.dev/pkg/scheduler/testing"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSchedulerClaimNode(t *testing.T) {
+	node := st.MakeNode().Name("node1").Capacity(map[v1.ResourceName]string{v1.ResourceCPU: "4", v1.ResourcePods: "10"}).Obj()
+	pod := st.MakePod().Namespace("ns").Name("p").UID("p").Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Obj()
+
+	tests := []struct {
+		name string
+		// otherAnnouncedAt is when the other replica announced its pod,
+		// relative to now.
+		otherAnnouncedAt time.Duration
+		wantErr          bool
+	}{
+		{
+			name:             "pod of the other replica announced before",
+			otherAnnouncedAt: -time.Hour,
+			wantErr:          true,
+		},
+		{
+			name:             "pod of the other replica announced after",
+			otherAnnouncedAt: time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			client := clientsetfake.NewSimpleClientset(pod)
+			other := "r2"
+			renewTime := metav1.NewMicroTime(time.Now())
+			if _, err := client.CoordinationV1().Leases("kube-system").Create(ctx, &coordinationv1.Lease{
+				ObjectMeta: metav1.ObjectMeta{Name: "sched-r2", Namespace: "kube-system", Labels: map[string]string{shard.GroupLabel: "sched"}},
+				Spec:       coordinationv1.LeaseSpec{HolderIdentity: &other, RenewTime: &renewTime},
+			}, metav1.CreateOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			m := shard.New(client, "kube-system", "sched", "r1", time.Minute, 0)
+			go m.Run(ctx)
+			if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+				return len(m.Members()) == 2, nil
+			}); err != nil {
+				t.Fatalf("Replicas didn't join the shard group: %v", err)
+			}
+
+			cache := internalcache.New(10*time.Minute, ctx.Done())
+			cache.AddNode(node)
+			assumed := pod.DeepCopy()
+			assumed.Spec.NodeName = node.Name
+			if err := cache.AssumePod(assumed); err != nil {
+				t.Fatal(err)
+			}
+			otherPod := st.MakePod().Namespace("other").Name("q").UID("q").Node(node.Name).Req(map[v1.ResourceName]string{v1.ResourceCPU: "3"}).
+				Annotation(shard.AssumedNodeAnnotation, node.Name).
+				Annotation(shard.AssumedByAnnotation, other).
+				Annotation(shard.AssumedAtAnnotation, time.Now().Add(tt.otherAnnouncedAt).Format(time.RFC3339Nano)).Obj()
+			if err := cache.AssumePod(otherPod); err != nil {
+				t.Fatal(err)
+			}
+
+			fwk, err := st.NewFramework(
+				[]st.RegisterPluginFunc{
+					st.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+					st.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+					st.RegisterPluginAsExtensions(noderesources.FitName, frameworkruntime.FactoryAdapter(feature.Features{}, noderesources.NewFit), "Filter", "PreFilter"),
+				},
+				testSchedulerName,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			state := framework.NewCycleState()
+			if s := fwk.RunPreFilterPlugins(ctx, state, pod); !s.IsSuccess() {
+				t.Fatal(s.AsError())
+			}
+
+			sched := &Scheduler{SchedulerCache: cache, shard: m}
+			err = sched.claimNode(ctx, fwk, state, assumed, node.Name)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Errorf("Unexpected error, want one: %v, got %v", tt.wantErr, err)
+			}
+
+			got, err := client.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, announced := shard.AnnouncementOf(got); announced == tt.wantErr {
+				t.Errorf("Unexpected announcement on the pod, want one: %v, got %v", !tt.wantErr, got.Annotations)
+			}
+		})
+	}
+}
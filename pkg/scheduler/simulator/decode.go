@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/scheduling/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+var (
+	scheme = runtime.NewScheme()
+	codecs = serializer.NewCodecFactory(scheme)
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+}
+
+// Decode reads the objects of a YAML or JSON stream. The stream may hold
+// several YAML documents, and v1 Lists are expanded into their items. Objects
+// must be of built-in kinds or of the scheduling.sched.dev group.
+func Decode(r io.Reader) ([]runtime.Object, error) {
+	d := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	var objs []runtime.Object
+	for {
+		var raw runtime.RawExtension
+		if err := d.Decode(&raw); err == io.EOF {
+			return objs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		data := bytes.TrimSpace(raw.Raw)
+		if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+			continue
+		}
+		decoded, err := decode(data)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, decoded...)
+	}
+}
+
+func decode(data []byte) ([]runtime.Object, error) {
+	obj, gvk, err := codecs.UniversalDeserializer().Decode(data, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := obj.(*v1.List)
+	if !ok {
+		return []runtime.Object{obj}, nil
+	}
+	var objs []runtime.Object
+	for i, item := range list.Items {
+		decoded, err := decode(item.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("item %d of %v: %w", i, gvk, err)
+		}
+		objs = append(objs, decoded...)
+	}
+	return objs, nil
+}
@@ -0,0 +1,246 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package simulator schedules pods against a snapshot of a cluster, without
+// access to the cluster.
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/scheduling/v1alpha1"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	frameworkplugins "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/elasticquota"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/noderesourcetopology"
+	frameworkruntime "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/runtime"
+	internalcache "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/cache"
+	internalqueue "github.com/QuarfotPrice/sched.dev/pkg/scheduler/internal/queue"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/podgroup"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/profile"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dyfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
+)
+
+// listKinds are the list kinds of the custom resources plugins may read.
+var listKinds = map[schema.GroupVersionResource]string{
+	podgroup.Resource:             "PodGroupList",
+	elasticquota.Resource:         "ElasticQuotaList",
+	noderesourcetopology.Resource: "NodeResourceTopologyList",
+}
+
+// Result is the outcome of scheduling a pod.
+type Result struct {
+	// Pod is the namespace and name of the pod.
+	Pod string `json:"pod"`
+	// Profile is the scheduler name of the profile that scheduled the pod.
+	Profile string `json:"profile"`
+	// Node is the node the pod is placed on. It's empty if the pod is
+	// unschedulable.
+	Node string `json:"node,omitempty"`
+	// FeasibleNodes is the number of nodes that passed the filters.
+	FeasibleNodes int `json:"feasibleNodes"`
+	// Reason explains why the pod is unschedulable.
+	Reason string `json:"reason,omitempty"`
+	// UnschedulablePlugins are the plugins that rejected the pod.
+	UnschedulablePlugins []string `json:"unschedulablePlugins,omitempty"`
+}
+
+// Simulator schedules the pending pods of a cluster snapshot one after the
+// other. A placed pod is added to the snapshot right away, so that the pods
+// after it see the resources it takes. Pods run through the filter, score
+// and reserve plugins of their profile only: they neither preempt other
+// pods nor wait at permit, and extenders aren't called.
+type Simulator struct {
+	cache     internalcache.Cache
+	algorithm scheduler.ScheduleAlgorithm
+	profiles  profile.Map
+	less      framework.LessFunc
+	pending   []*v1.Pod
+}
+
+// New returns a simulator of the cluster made of the objects, scheduling
+// with the profiles of the configuration. Pods bound to a node make up the
+// snapshot along with the nodes, and the other pods are pending. All objects
+// are served to plugins by informers.
+func New(ctx context.Context, cfg *config.KubeSchedulerConfiguration, objs []runtime.Object, outOfTreeRegistry frameworkruntime.Registry) (*Simulator, error) {
+	s := &Simulator{
+		cache: internalcache.New(0, ctx.Done()),
+	}
+	var typed, custom []runtime.Object
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *v1.Node:
+			s.cache.AddNode(o)
+		case *v1.Pod:
+			defaultPod(o)
+			if o.Spec.NodeName == "" {
+				s.pending = append(s.pending, o)
+			} else if err := s.cache.AddPod(o); err != nil {
+				return nil, err
+			}
+		}
+		switch obj.(type) {
+		case *v1alpha1.PodGroup, *v1alpha1.ElasticQuota, *v1alpha1.NodeResourceTopology:
+			custom = append(custom, obj)
+		default:
+			typed = append(typed, obj)
+		}
+	}
+
+	client := clientsetfake.NewSimpleClientset(typed...)
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	dynInformerFactory := dynamicinformer.NewDynamicSharedInformerFactory(
+		dyfake.NewSimpleDynamicClientWithCustomListKinds(scheme, listKinds, custom...), 0)
+
+	registry := frameworkplugins.NewInTreeRegistry()
+	if err := registry.Merge(outOfTreeRegistry); err != nil {
+		return nil, err
+	}
+	snapshot := internalcache.NewEmptySnapshot()
+	recorderFactory := func(string) events.EventRecorder { return &events.FakeRecorder{} }
+	profiles, err := profile.NewMap(cfg.Profiles, registry, recorderFactory,
+		frameworkruntime.WithComponentConfigVersion(cfg.TypeMeta.APIVersion),
+		frameworkruntime.WithClientSet(client),
+		frameworkruntime.WithInformerFactory(informerFactory),
+		frameworkruntime.WithDynInformerFactory(dynInformerFactory),
+		frameworkruntime.WithSnapshotSharedLister(snapshot),
+		frameworkruntime.WithNamespaceUsages(s.cache.NamespaceUsages()),
+		frameworkruntime.WithPodNominator(internalqueue.NewPodNominator(informerFactory.Core().V1().Pods().Lister())),
+		frameworkruntime.WithParallelism(int(cfg.Parallelism)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("initializing profiles: %v", err)
+	}
+	if len(profiles) == 0 {
+		return nil, errors.New("at least one profile is required")
+	}
+	s.profiles = profiles
+	s.less = profiles[cfg.Profiles[0].SchedulerName].QueueSortFunc()
+	s.algorithm = scheduler.NewGenericScheduler(s.cache, snapshot, cfg.PercentageOfNodesToScore)
+
+	informerFactory.Start(ctx.Done())
+	informerFactory.WaitForCacheSync(ctx.Done())
+	dynInformerFactory.Start(ctx.Done())
+	dynInformerFactory.WaitForCacheSync(ctx.Done())
+	return s, nil
+}
+
+// defaultPod sets the fields of a pod read from a file that the scheduler
+// relies on and the apiserver would set.
+func defaultPod(pod *v1.Pod) {
+	if pod.Namespace == "" {
+		pod.Namespace = metav1.NamespaceDefault
+	}
+	if pod.UID == "" {
+		pod.UID = types.UID(pod.Namespace + "/" + pod.Name)
+	}
+	if pod.Spec.SchedulerName == "" {
+		pod.Spec.SchedulerName = v1.DefaultSchedulerName
+	}
+	if pod.CreationTimestamp.IsZero() {
+		pod.CreationTimestamp = metav1.Now()
+	}
+}
+
+// Schedule schedules the pending pods in the order of the queue sort plugin
+// and returns their results in that order.
+func (s *Simulator) Schedule(ctx context.Context) []Result {
+	now := time.Now()
+	pods := make([]*framework.QueuedPodInfo, 0, len(s.pending))
+	for _, pod := range s.pending {
+		pods = append(pods, &framework.QueuedPodInfo{
+			PodInfo:                 framework.NewPodInfo(pod),
+			Timestamp:               now,
+			InitialAttemptTimestamp: now,
+		})
+	}
+	sort.SliceStable(pods, func(i, j int) bool { return s.less(pods[i], pods[j]) })
+
+	results := make([]Result, 0, len(pods))
+	for _, pi := range pods {
+		results = append(results, s.scheduleOne(ctx, pi.Pod))
+	}
+	return results
+}
+
+// scheduleOne runs a scheduling cycle for the pod and adds it to the
+// snapshot if it's placed on a node.
+func (s *Simulator) scheduleOne(ctx context.Context, pod *v1.Pod) Result {
+	r := Result{Pod: klog.KObj(pod).String(), Profile: pod.Spec.SchedulerName}
+	fwk, ok := s.profiles[pod.Spec.SchedulerName]
+	if !ok {
+		r.Reason = fmt.Sprintf("no profile for scheduler name %q", pod.Spec.SchedulerName)
+		return r
+	}
+
+	state := framework.NewCycleState()
+	state.SetRecordPluginMetrics(false)
+	result, err := s.algorithm.Schedule(ctx, nil, fwk, state, pod)
+	if err != nil {
+		r.Reason = err.Error()
+		if fitErr, ok := err.(*framework.FitError); ok {
+			r.UnschedulablePlugins = fitErr.Diagnosis.UnschedulablePlugins.List()
+		}
+		return r
+	}
+	r.FeasibleNodes = result.FeasibleNodes
+
+	assumed := pod.DeepCopy()
+	assumed.Spec.NodeName = result.SuggestedHost
+	if err := s.cache.AssumePod(assumed); err != nil {
+		r.Reason = err.Error()
+		return r
+	}
+	// Reserve plugins run on a copy of the assumed pod, which the cache is
+	// then updated with, as in the scheduler.
+	reserved := assumed.DeepCopy()
+	if sts := fwk.RunReservePluginsReserve(ctx, state, reserved, result.SuggestedHost); !sts.IsSuccess() {
+		fwk.RunReservePluginsUnreserve(ctx, state, reserved, result.SuggestedHost)
+		if err := s.cache.ForgetPod(assumed); err != nil {
+			klog.ErrorS(err, "Scheduler cache ForgetPod failed")
+		}
+		r.Reason = sts.Message()
+		if sts.FailedPlugin() != "" {
+			r.UnschedulablePlugins = []string{sts.FailedPlugin()}
+		}
+		return r
+	}
+	if err := s.cache.UpdateAssumedPod(reserved); err != nil {
+		klog.ErrorS(err, "Scheduler cache UpdateAssumedPod failed", "pod", klog.KObj(reserved))
+	}
+	// Confirm the pod in the cache as if it was bound.
+	if err := s.cache.AddPod(reserved); err != nil {
+		klog.ErrorS(err, "Scheduler cache AddPod failed")
+	}
+	r.Node = result.SuggestedHost
+	return r
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/apis/config/latest"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework"
+	"github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/plugins/noderesources"
+	frameworkruntime "github.com/QuarfotPrice/sched.dev/pkg/scheduler/framework/runtime"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const cluster = `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: n1
+  status:
+    allocatable:
+      cpu: "4"
+      pods: "10"
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: n2
+  status:
+    allocatable:
+      cpu: "4"
+      pods: "10"
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: running
+spec:
+  nodeName: n1
+  containers:
+  - name: c
+    resources:
+      requests:
+        cpu: "3"
+`
+
+const pending = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: large
+spec:
+  priority: 1
+  containers:
+  - name: c
+    resources:
+      requests:
+        cpu: "8"
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: first
+spec:
+  priority: 3
+  containers:
+  - name: c
+    resources:
+      requests:
+        cpu: "2"
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: second
+  namespace: ns
+spec:
+  priority: 2
+  containers:
+  - name: c
+    resources:
+      requests:
+        cpu: "2"
+`
+
+func TestSchedule(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objs, err := Decode(strings.NewReader(cluster))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 3 {
+		t.Fatalf("got %d objects, want 3", len(objs))
+	}
+	pods, err := Decode(strings.NewReader(pending))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := latest.Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(ctx, cfg, append(objs, pods...), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Pods are scheduled by priority. Only n2 can take the first pod, and
+	// then has room left for the second one only.
+	want := []Result{
+		{Pod: "default/first", Profile: "default-scheduler", Node: "n2", FeasibleNodes: 1},
+		{Pod: "ns/second", Profile: "default-scheduler", Node: "n2", FeasibleNodes: 1},
+		{Pod: "default/large", Profile: "default-scheduler", UnschedulablePlugins: []string{noderesources.FitName}},
+	}
+	got := s.Schedule(ctx)
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Result{}, "Reason")); diff != "" {
+		t.Errorf("Unexpected results (-want,+got):\n%s", diff)
+	}
+	if !strings.Contains(got[2].Reason, "Insufficient cpu") {
+		t.Errorf("Reason of the large pod is %q, want it to mention insufficient cpu", got[2].Reason)
+	}
+}
+
+// annotatingPlugin records the node of the pod in an annotation at Reserve.
+type annotatingPlugin struct{}
+
+const annotatingPluginName = "annotating-plugin"
+
+func (p *annotatingPlugin) Name() string {
+	return annotatingPluginName
+}
+
+func (p *annotatingPlugin) Reserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[annotatingPluginName] = nodeName
+	return nil
+}
+
+func (p *annotatingPlugin) Unreserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) {
+}
+
+func TestScheduleKeepsReservedPod(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objs, err := Decode(strings.NewReader(cluster + "---" + pending))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := latest.Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugins := cfg.Profiles[0].Plugins
+	plugins.Reserve.Enabled = append(plugins.Reserve.Enabled, config.Plugin{Name: annotatingPluginName})
+	registry := frameworkruntime.Registry{
+		annotatingPluginName: func(_ runtime.Object, _ framework.Handle) (framework.Plugin, error) {
+			return &annotatingPlugin{}, nil
+		},
+	}
+	s, err := New(ctx, cfg, objs, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Schedule(ctx)
+
+	// The cache has the pods as the Reserve plugins left them.
+	found := 0
+	for _, n := range s.cache.Dump().Nodes {
+		for _, pi := range n.Pods {
+			if pi.Pod.Name == "running" {
+				continue
+			}
+			found++
+			if got := pi.Pod.Annotations[annotatingPluginName]; got != pi.Pod.Spec.NodeName {
+				t.Errorf("Pod %v has annotation %q, want %q", pi.Pod.Name, got, pi.Pod.Spec.NodeName)
+			}
+		}
+	}
+	if found != 2 {
+		t.Errorf("Found %d scheduled pods in the cache, want 2", found)
+	}
+}
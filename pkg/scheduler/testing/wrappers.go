@@ -416,6 +416,15 @@ func (p *PodWrapper) Label(k, v string) *PodWrapper {
 	return p
 }
 
+// Annotation sets a {k,v} pair to the inner pod's annotations.
+func (p *PodWrapper) Annotation(k, v string) *PodWrapper {
+	if p.Annotations == nil {
+		p.Annotations = make(map[string]string)
+	}
+	p.Annotations[k] = v
+	return p
+}
+
 // Req adds a new container to the inner pod with given resource map.
 func (p *PodWrapper) Req(resMap map[v1.ResourceName]string) *PodWrapper {
 	if len(resMap) == 0 {
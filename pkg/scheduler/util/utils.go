@@ -23,6 +23,7 @@ import (
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
@@ -119,11 +120,52 @@ func PatchPodStatus(cs kubernetes.Interface, old *v1.Pod, newStatus *v1.PodStatu
 	return err
 }
 
+// PatchPodAnnotations submits a request to API server to set the given
+// annotations on the pod. Annotations with an empty value are removed.
+func PatchPodAnnotations(cs kubernetes.Interface, pod *v1.Pod, annotations map[string]string) error {
+	if len(annotations) == 0 {
+		return nil
+	}
+	patch := make(map[string]interface{}, len(annotations))
+	for k, v := range annotations {
+		if len(v) == 0 {
+			patch[k] = nil
+		} else {
+			patch[k] = v
+		}
+	}
+	patchBytes, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": patch},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create annotations patch for pod %q/%q: %v", pod.Namespace, pod.Name, err)
+	}
+	_, err = cs.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{})
+	return err
+}
+
 // DeletePod deletes the given <pod> from API server
 func DeletePod(cs kubernetes.Interface, pod *v1.Pod) error {
 	return cs.CoreV1().Pods(pod.Namespace).Delete(context.TODO(), pod.Name, metav1.DeleteOptions{})
 }
 
+// EvictPod evicts the given <pod> through the Eviction API, which refuses to
+// violate the PodDisruptionBudgets of the pod. With dryRun, the eviction is
+// only checked.
+func EvictPod(cs kubernetes.Interface, pod *v1.Pod, dryRun bool) error {
+	opts := &metav1.DeleteOptions{
+		// Don't evict a pod recreated with the same name.
+		Preconditions: metav1.NewUIDPreconditions(string(pod.UID)),
+	}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	return cs.PolicyV1().Evictions(pod.Namespace).Evict(context.TODO(), &policy.Eviction{
+		ObjectMeta:    metav1.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name},
+		DeleteOptions: opts,
+	})
+}
+
 // ClearNominatedNodeName internally submit a patch request to API server
 // to set each pods[*].Status.NominatedNodeName> to "".
 func ClearNominatedNodeName(cs kubernetes.Interface, pods ...*v1.Pod) utilerrors.Aggregate {
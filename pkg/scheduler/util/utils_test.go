@@ -252,3 +252,25 @@ func TestPatchPodStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestPatchPodAnnotations(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "pod",
+			Annotations: map[string]string{"keep": "a", "remove": "b"},
+		},
+	}
+	client := clientsetfake.NewSimpleClientset(pod)
+	if err := PatchPodAnnotations(client, pod, map[string]string{"add": "c", "remove": ""}); err != nil {
+		t.Fatal(err)
+	}
+	retrievedPod, err := client.CoreV1().Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"keep": "a", "add": "c"}
+	if diff := cmp.Diff(want, retrievedPod.Annotations); diff != "" {
+		t.Errorf("unexpected pod annotations (-want,+got):\n%s", diff)
+	}
+}